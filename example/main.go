@@ -2,8 +2,9 @@ package main
 
 import (
 	"fmt"
+	"os"
+	"sort"
 
-	"github.com/kr/pretty"
 	"github.com/neoul/gtrie"
 )
 
@@ -35,32 +36,53 @@ func main() {
 		trie.Add(key, true)
 	}
 
-	// Find - Find your data with a key.
-	pretty.Println(trie.Find("/interfaces/interface[name=1/3]"))
-
-	// FindByPrefix - Find all keys starting with `prefix` from the trie.
-	pretty.Println(trie.FindByPrefix("/interfaces/interface[name=1/2]/state"))
-
-	// FindByFuzzy - Find all keys by fuzzy search (Approximate string matching).
-	pretty.Println(trie.FindByFuzzy("/interfaces/interface/state"))
-
-	// FindLongestMatchingPrefix - Find a prefix key matching longestly with input `key`.
-	pretty.Println(trie.FindLongestMatchingPrefix("/interfaces/interface[name=1/3]/state/absss"))
-
-	// FindMatchingPrefix - Find all the matching prefixes against to the input `key`.
-	pretty.Println(trie.FindMatchingPrefix("/interfaces/interface[name=1/3]/state/absss"))
+	// Pass the trie as a Reader to the code that must not modify it.
+	find(trie)
 
 	// Remove the key from the trie.
 	trie.Remove("/interfaces")
 	trie.Remove("/interfaces/interface")
 
 	// FindRelativeAll = FindByPrefix + FindByFuzzy + FindMatchingPrefix
+	// 12 = 1 key starting with the input + 11 keys having the input as a subsequence.
+	// No key is a prefix of the input since "/interfaces" and "/interfaces/interface" are removed.
 	m := trie.FindRelativeAll("/interfaces/interface/state")
-	pretty.Print(m)
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Println(key)
+	}
 	if len(m) != 12 {
-		fmt.Printf("got result(%d), expect(12)", len(m))
+		fmt.Fprintf(os.Stderr, "got result(%d), expect(12)\n", len(m))
+		os.Exit(1)
 	}
 
 	// Clear all keys and inserted values.
+	reset(trie)
+}
+
+// find finds the data through the read-only Reader of the trie.
+func find(trie gtrie.Reader) {
+	// Find - Find your data with a key.
+	fmt.Println(trie.Find("/interfaces/interface[name=1/3]"))
+
+	// FindByPrefix - Find all keys starting with `prefix` from the trie.
+	fmt.Println(trie.FindByPrefix("/interfaces/interface[name=1/2]/state"))
+
+	// FindByFuzzy - Find all keys by fuzzy search (Approximate string matching).
+	fmt.Println(trie.FindByFuzzy("/interfaces/interface/state"))
+
+	// FindLongestMatchingPrefix - Find a prefix key matching longestly with input `key`.
+	fmt.Println(trie.FindLongestMatchingPrefix("/interfaces/interface[name=1/3]/state/absss"))
+
+	// FindMatchingPrefix - Find all the matching prefixes against to the input `key`.
+	fmt.Println(trie.FindMatchingPrefix("/interfaces/interface[name=1/3]/state/absss"))
+}
+
+// reset clears the trie through its Writer.
+func reset(trie gtrie.Writer) {
 	trie.Clear()
 }
@@ -0,0 +1,88 @@
+// Package gtrietest provides the helpers for the tests and the benchmarks
+// of the tries: the generator of the gNMI-style keys, the loader of the keys
+// and ModelTrie, the reference implementation on a map for differential testing.
+//
+// The package does not import gtrie, so that the tests of gtrie itself use it.
+package gtrietest
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// containers and lists are the names of the path elements of the keys
+// generated, taken from the OpenConfig models.
+var (
+	containers = []string{
+		"interfaces", "state", "config", "counters", "subinterfaces", "ipv4",
+		"addresses", "oper-status", "admin-status", "enabled", "mtu", "description",
+		"in-octets", "out-octets", "in-pkts", "out-pkts", "network-instances",
+		"protocols", "bgp", "neighbors", "afi-safis", "routing-policy",
+		"components", "temperature", "instant", "system",
+	}
+	lists = []string{"interface", "subinterface", "address", "neighbor", "afi-safi", "component"}
+)
+
+// GeneratePathKeys returns `n` distinct gNMI-style keys, e.g.
+// "/interfaces/interface[name=1/2]/state/counters/in-octets", of 1 to `depth`
+// path elements chosen from `fanout` children at each level. The elements at
+// the odd levels are keyed by a predicate like the lists of the models.
+// The keys are in the order generated and the same for the same `seed`.
+// It returns fewer keys if the tree of `depth` and `fanout` has fewer paths.
+func GeneratePathKeys(n, depth, fanout int, seed int64) []string {
+	if n <= 0 || depth <= 0 || fanout <= 0 {
+		return nil
+	}
+	// the number of the paths bounds the keys generated.
+	paths, level := 0, 1
+	for d := 0; d < depth && paths < n; d++ {
+		level *= fanout
+		paths += level
+	}
+	n = min(n, paths)
+	r := rand.New(rand.NewSource(seed))
+	seen := make(map[string]bool, n)
+	keys := make([]string, 0, n)
+	var sb strings.Builder
+	for len(keys) < n {
+		sb.Reset()
+		// the longer paths are more likely like the leaves of the models.
+		d := depth - r.Intn(r.Intn(depth)+1)
+		for l := 0; l < d; l++ {
+			sb.WriteByte('/')
+			sb.WriteString(element(l, r.Intn(fanout)))
+		}
+		key := sb.String()
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// element returns the path element of the `i`th child at the `level`.
+// The children at the odd levels are the entries of a list.
+func element(level, i int) string {
+	if level%2 == 1 {
+		return fmt.Sprintf("%s[name=%d/%d]", lists[level/2%len(lists)], i/16+1, i%16)
+	}
+	name := containers[(level/2*5+i)%len(containers)]
+	if i >= len(containers) {
+		return fmt.Sprintf("%s-%d", name, i/len(containers))
+	}
+	return name
+}
+
+// Adder is the trie the keys are loaded into, e.g. *gtrie.Trie or *ModelTrie.
+type Adder interface {
+	Add(key string, value interface{})
+}
+
+// LoadKeys adds all the `keys` to the trie with the `value`.
+func LoadKeys(t Adder, keys []string, value interface{}) {
+	for _, key := range keys {
+		t.Add(key, value)
+	}
+}
@@ -0,0 +1,121 @@
+package gtrietest_test
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/neoul/gtrie"
+	"github.com/neoul/gtrie/gtrietest"
+)
+
+func TestGeneratePathKeys(t *testing.T) {
+	keys := gtrietest.GeneratePathKeys(500, 6, 5, 1)
+	if len(keys) != 500 {
+		t.Fatalf("GeneratePathKeys() returns %d keys, want 500", len(keys))
+	}
+	seen := make(map[string]bool)
+	for _, key := range keys {
+		if seen[key] {
+			t.Errorf("GeneratePathKeys() returns %q twice", key)
+		}
+		seen[key] = true
+		if n := strings.Count(key, "/") - strings.Count(key, "["); !strings.HasPrefix(key, "/") || n < 1 || n > 6 {
+			t.Errorf("GeneratePathKeys() returns %q", key)
+		}
+	}
+	if again := gtrietest.GeneratePathKeys(500, 6, 5, 1); !reflect.DeepEqual(again, keys) {
+		t.Errorf("GeneratePathKeys() of the same seed differs")
+	}
+	if other := gtrietest.GeneratePathKeys(500, 6, 5, 2); reflect.DeepEqual(other, keys) {
+		t.Errorf("GeneratePathKeys() of another seed is the same")
+	}
+	// 3 + 9 paths of the depth 2 and the fanout 3.
+	if keys := gtrietest.GeneratePathKeys(100, 2, 3, 1); len(keys) != 12 {
+		t.Errorf("GeneratePathKeys() over the paths returns %d keys, want 12", len(keys))
+	}
+	if keys := gtrietest.GeneratePathKeys(10, 0, 3, 1); keys != nil {
+		t.Errorf("GeneratePathKeys() of no depth = %v", keys)
+	}
+}
+
+func sorted(keys []string) []string {
+	sort.Strings(keys)
+	return keys
+}
+
+// equal compares the results of the methods taking nil and empty as equal.
+func equal(a, b interface{}) bool {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if va.Len() == 0 && vb.Len() == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// TestModelTrie compares ModelTrie with gtrie.Trie, which validates both.
+func TestModelTrie(t *testing.T) {
+	keys := gtrietest.GeneratePathKeys(300, 5, 4, 1)
+	trie, model := gtrie.New(), gtrietest.NewModelTrie()
+	gtrietest.LoadKeys(trie, keys, 1)
+	gtrietest.LoadKeys(model, keys, 1)
+	for i, key := range keys {
+		if i%3 == 0 {
+			trie.Add(key, i)
+			model.Add(key, i)
+		}
+		if i%5 == 0 {
+			if a, b := trie.Remove(key), model.Remove(key); a != b {
+				t.Errorf("Remove(%q) = %v, model %v", key, a, b)
+			}
+		}
+	}
+	if trie.Size() != model.Size() || !reflect.DeepEqual(sorted(trie.Keys()), model.Keys()) {
+		t.Fatalf("Keys() = %d keys, model %d keys", trie.Size(), model.Size())
+	}
+	probes := append(keys[:40:40], "", "/", "/interfaces/interface[", "/x", keys[0]+"/x")
+	for _, key := range probes {
+		v1, ok1 := trie.Find(key)
+		v2, ok2 := model.Find(key)
+		if v1 != v2 || ok1 != ok2 {
+			t.Errorf("Find(%q) = %v, %v, model %v, %v", key, v1, ok1, v2, ok2)
+		}
+		if a, b := sorted(trie.FindByPrefix(key)), model.FindByPrefix(key); !equal(a, b) {
+			t.Errorf("FindByPrefix(%q) = %v, model %v", key, a, b)
+		}
+		if a, b := trie.FindByPrefixAll(key), model.FindByPrefixAll(key); !equal(a, b) {
+			t.Errorf("FindByPrefixAll(%q) = %v, model %v", key, a, b)
+		}
+		if a, b := trie.HasPrefix(key), model.HasPrefix(key); a != b {
+			t.Errorf("HasPrefix(%q) = %v, model %v", key, a, b)
+		}
+		if a, b := trie.FindMatchingPrefixAll(key+"/y"), model.FindMatchingPrefixAll(key+"/y"); !equal(a, b) {
+			t.Errorf("FindMatchingPrefixAll(%q) = %v, model %v", key+"/y", a, b)
+		}
+		k1, v1, ok1 := trie.FindLongestMatchingPrefix(key + "/y")
+		k2, v2, ok2 := model.FindLongestMatchingPrefix(key + "/y")
+		if k1 != k2 || v1 != v2 || ok1 != ok2 {
+			t.Errorf("FindLongestMatchingPrefix(%q) = %q, %v, %v, model %q, %v, %v", key+"/y", k1, v1, ok1, k2, v2, ok2)
+		}
+	}
+	for _, key := range []string{"ifs", "/s/c/i", "[=1/2]", "mtu", "zz", ""} {
+		if a, b := trie.FindByFuzzyAll(key), model.FindByFuzzyAll(key); !equal(a, b) {
+			t.Errorf("FindByFuzzyAll(%q) = %d keys, model %d keys", key, len(a), len(b))
+		}
+	}
+	if a, b := trie.CopyPrefix(keys[1], "/copy"), model.CopyPrefix(keys[1], "/copy"); a != b || !reflect.DeepEqual(trie.All(), model.All()) {
+		t.Errorf("CopyPrefix() = %d, model %d", a, b)
+	}
+	trie.Clear()
+	model.Clear()
+	if trie.Size() != 0 || model.Size() != 0 || len(model.Keys()) != 0 {
+		t.Errorf("Clear() leaves the keys")
+	}
+}
+
+func BenchmarkGeneratePathKeys(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		gtrietest.GeneratePathKeys(10000, 8, 8, int64(i))
+	}
+}
@@ -0,0 +1,180 @@
+package gtrietest
+
+import (
+	"sort"
+	"strings"
+)
+
+// ModelTrie is the reference implementation of the methods of gtrie.Trie
+// on a map for differential testing: each method scans all the keys in the
+// most obvious way. The keys returned are sorted; the results of gtrie.Trie
+// in no particular order must be sorted to compare with them.
+// The zero value is an empty ModelTrie. It is not safe for concurrent use.
+type ModelTrie struct {
+	m map[string]interface{}
+}
+
+// NewModelTrie returns an empty ModelTrie.
+func NewModelTrie() *ModelTrie {
+	return &ModelTrie{}
+}
+
+// Size returns the number of the keys.
+func (t *ModelTrie) Size() int {
+	return len(t.m)
+}
+
+// Add adds the key and the value replacing the old value of the key.
+func (t *ModelTrie) Add(key string, value interface{}) {
+	if t.m == nil {
+		t.m = make(map[string]interface{})
+	}
+	t.m[key] = value
+}
+
+// Find returns the value of the key.
+func (t *ModelTrie) Find(key string) (interface{}, bool) {
+	v, ok := t.m[key]
+	return v, ok
+}
+
+// Remove removes the key and returns its value.
+func (t *ModelTrie) Remove(key string) interface{} {
+	v := t.m[key]
+	delete(t.m, key)
+	return v
+}
+
+// Clear removes all the keys.
+func (t *ModelTrie) Clear() {
+	clear(t.m)
+}
+
+// Keys returns all the keys, or the keys starting with the `prefix` if given.
+func (t *ModelTrie) Keys(prefix ...string) []string {
+	if len(prefix) > 0 {
+		return t.FindByPrefix(prefix[0])
+	}
+	return t.FindByPrefix("")
+}
+
+// All returns all the keys and the values, or the keys starting with
+// the `prefix` if given.
+func (t *ModelTrie) All(prefix ...string) map[string]interface{} {
+	if len(prefix) > 0 {
+		return t.FindByPrefixAll(prefix[0])
+	}
+	return t.FindByPrefixAll("")
+}
+
+// FindByPrefix returns the keys starting with the `prefix`.
+func (t *ModelTrie) FindByPrefix(prefix string) []string {
+	return t.keys(func(key string) bool { return strings.HasPrefix(key, prefix) })
+}
+
+// FindByPrefixValue returns the values of the keys starting with the `prefix`
+// in the order of the keys.
+func (t *ModelTrie) FindByPrefixValue(prefix string) []interface{} {
+	return t.values(t.FindByPrefix(prefix))
+}
+
+// FindByPrefixAll returns the keys starting with the `prefix` and their values.
+func (t *ModelTrie) FindByPrefixAll(prefix string) map[string]interface{} {
+	return t.all(t.FindByPrefix(prefix))
+}
+
+// HasPrefix returns true if any key starts with the `prefix`.
+func (t *ModelTrie) HasPrefix(prefix string) bool {
+	return len(t.FindByPrefix(prefix)) > 0
+}
+
+// FindByFuzzy returns the keys having the runes of the `key` in order.
+func (t *ModelTrie) FindByFuzzy(key string) []string {
+	return t.keys(func(k string) bool {
+		for _, r := range key {
+			i := strings.IndexRune(k, r)
+			if i < 0 {
+				return false
+			}
+			k = k[i+len(string(r)):]
+		}
+		return true
+	})
+}
+
+// FindByFuzzyAll returns the keys having the runes of the `key` in order
+// and their values.
+func (t *ModelTrie) FindByFuzzyAll(key string) map[string]interface{} {
+	return t.all(t.FindByFuzzy(key))
+}
+
+// FindMatchingPrefix returns the keys that are the prefixes of the `key`
+// from the shortest. The empty key is not matched.
+func (t *ModelTrie) FindMatchingPrefix(key string) ([]string, bool) {
+	keys := t.keys(func(k string) bool { return k != "" && strings.HasPrefix(key, k) })
+	return keys, len(keys) > 0
+}
+
+// FindMatchingPrefixValue returns the values of the keys that are the prefixes
+// of the `key` from the shortest.
+func (t *ModelTrie) FindMatchingPrefixValue(key string) []interface{} {
+	keys, _ := t.FindMatchingPrefix(key)
+	return t.values(keys)
+}
+
+// FindMatchingPrefixAll returns the keys that are the prefixes of the `key`
+// and their values.
+func (t *ModelTrie) FindMatchingPrefixAll(key string) map[string]interface{} {
+	keys, _ := t.FindMatchingPrefix(key)
+	return t.all(keys)
+}
+
+// FindLongestMatchingPrefix returns the longest key that is a prefix
+// of the `key` and its value.
+func (t *ModelTrie) FindLongestMatchingPrefix(key string) (string, interface{}, bool) {
+	keys, ok := t.FindMatchingPrefix(key)
+	if !ok {
+		return "", nil, false
+	}
+	k := keys[len(keys)-1]
+	return k, t.m[k], true
+}
+
+// CopyPrefix copies the keys starting with `srcPrefix` to the same keys
+// relative to `dstPrefix` and returns the number of the keys copied.
+func (t *ModelTrie) CopyPrefix(srcPrefix, dstPrefix string) int {
+	keys := t.FindByPrefix(srcPrefix)
+	values := t.values(keys)
+	for i, key := range keys {
+		t.Add(dstPrefix+key[len(srcPrefix):], values[i])
+	}
+	return len(keys)
+}
+
+// keys returns the keys matched by `match` in order.
+func (t *ModelTrie) keys(match func(key string) bool) []string {
+	var keys []string
+	for key := range t.m {
+		if match(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (t *ModelTrie) values(keys []string) []interface{} {
+	var values []interface{}
+	for _, key := range keys {
+		values = append(values, t.m[key])
+	}
+	return values
+}
+
+func (t *ModelTrie) all(keys []string) map[string]interface{} {
+	m := make(map[string]interface{}, len(keys))
+	for _, key := range keys {
+		m[key] = t.m[key]
+	}
+	return m
+}
@@ -0,0 +1,131 @@
+package gtrie
+
+// defaultArenaBlockSize is the number of the nodes in a slab if WithArena is given zero or less.
+const defaultArenaBlockSize = 4096
+
+// arena carves the trie nodes out of large slabs so that a bulk load
+// does not allocate every node individually. The nodes removed are not freed
+// but left in the slabs as garbage until Compact rebuilds the trie.
+type arena struct {
+	size    int        // the number of the nodes in a slab
+	slab    []trieNode // the free nodes of the current slab
+	used    int        // the number of the nodes allocated
+	garbage int        // the number of the nodes removed
+	limit   int        // the number of the nodes reserved (Reserve)
+}
+
+func newArena(size int) *arena {
+	if size <= 0 {
+		size = defaultArenaBlockSize
+	}
+	return &arena{size: size}
+}
+
+// alloc returns a zeroed node from the current slab.
+func (a *arena) alloc() *trieNode {
+	if len(a.slab) == 0 {
+		a.slab = make([]trieNode, a.size)
+	}
+	n := &a.slab[0]
+	a.slab = a.slab[1:]
+	a.used++
+	return n
+}
+
+// newNode returns a zeroed node from the arena `a` or from the heap if `a` is nil.
+func (a *arena) newNode() *trieNode {
+	if a == nil {
+		return &trieNode{}
+	}
+	return a.alloc()
+}
+
+// release marks `n` nodes removed from the trie as garbage.
+func (a *arena) release(n int) {
+	if a != nil {
+		a.garbage += n
+	}
+}
+
+// clone copies the subtree of `n` into the arena under the `parent`.
+// The terminal nodes copied are recorded to `moved` if it is not nil.
+func (a *arena) clone(n, parent *trieNode, moved map[*trieNode]*trieNode) *trieNode {
+	c := a.alloc()
+	*c = *n
+	c.parent = parent
+	c.children = make(map[rune]*trieNode, len(n.children))
+	c.ext = n.ext.clone()
+	if c.ext != nil && c.ext.sorted != nil {
+		// the children cloned are put in order again.
+		*c.ext.sorted = (*c.ext.sorted)[:0]
+	}
+	for _, child := range n.children {
+		c.setChild(a.clone(child, c, moved))
+	}
+	if moved != nil && n.term {
+		moved[n] = c
+	}
+	return c
+}
+
+// WithArena makes the trie allocate the nodes from the slabs of `blockSize` nodes
+// (4096 if zero or less) to reduce the allocations and the heap fragmentation of a bulk load.
+// The children maps of the nodes are still allocated individually.
+// The nodes removed are kept in the slabs as garbage until Compact is called.
+func WithArena(blockSize int) Option {
+	return func(t *Trie) {
+		t.arena = newArena(blockSize)
+	}
+}
+
+// Compact rebuilds the trie into fresh slabs to free the garbage left by Remove
+// if the ratio of the garbage to the nodes allocated exceeds `ratio`.
+// It returns true if the trie is rebuilt. Compact does nothing and returns false
+// if the trie is not created with WithArena.
+func (t *Trie) Compact(ratio float64) bool {
+	if t == nil {
+		return false
+	}
+	t.lock()
+	defer t.unlock()
+	old := t.arena
+	if old == nil || old.garbage == 0 || float64(old.garbage)/float64(old.used) <= ratio {
+		return false
+	}
+	var (
+		moved map[*trieNode]*trieNode
+		terms []*trieNode
+	)
+	if t.order != nil || t.stamps != nil || t.prios != nil {
+		moved = make(map[*trieNode]*trieNode, t.Size())
+	}
+	if t.order != nil {
+		terms = t.order.nodes(t.Size())
+	}
+	a := newArena(old.size)
+	for _, c := range t.root.children {
+		t.root.setChild(a.clone(c, t.root, moved))
+	}
+	if t.order != nil {
+		t.order.reset()
+		for _, n := range terms {
+			t.order.pushBack(moved[n])
+		}
+	}
+	if t.stamps != nil {
+		stamps := make(map[*trieNode]stamp, len(t.stamps))
+		for n, s := range t.stamps {
+			stamps[moved[n]] = s
+		}
+		t.stamps = stamps
+	}
+	if t.prios != nil {
+		prios := make(map[*trieNode]int, len(t.prios))
+		for n, p := range t.prios {
+			prios[moved[n]] = p
+		}
+		t.prios = prios
+	}
+	t.arena = a
+	return true
+}
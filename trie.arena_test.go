@@ -0,0 +1,106 @@
+package gtrie
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestTrie_WithArena(t *testing.T) {
+	trie := New(WithArena(4), WithInsertionOrder(), WithIncrementalHash(nil))
+	want := newGNMITrie()
+	for _, key := range gnmiFixture {
+		trie.Add(key, true)
+	}
+	if !reflect.DeepEqual(trie.All(), want.All()) {
+		t.Errorf("All() = %v, want %v", trie.All(), want.All())
+	}
+	if New(WithArena(0)).Compact(0) {
+		t.Errorf("Compact() = true without garbage")
+	}
+
+	for _, key := range gnmiFixture[:8] {
+		trie.Remove(key)
+		want.Remove(key)
+	}
+	trie.Add("/interfaces/interface[name=1/3]", 3)
+	want.Add("/interfaces/interface[name=1/3]", 3)
+	order := trie.KeysInOrder()
+	hash, _ := trie.IncrementalHash()
+	if trie.Compact(1) {
+		t.Errorf("Compact(1) = true, want false")
+	}
+	if !trie.Compact(0.1) {
+		t.Fatalf("Compact(0.1) = false, want true")
+	}
+	if trie.arena.garbage != 0 {
+		t.Errorf("Compact() leaves garbage %d", trie.arena.garbage)
+	}
+	if !reflect.DeepEqual(trie.All(), want.All()) {
+		t.Errorf("All() after Compact = %v, want %v", trie.All(), want.All())
+	}
+	if got := trie.KeysInOrder(); !reflect.DeepEqual(got, order) {
+		t.Errorf("KeysInOrder() after Compact = %v, want %v", got, order)
+	}
+	if got, _ := trie.IncrementalHash(); got != hash || got != trie.Hash(nil) {
+		t.Errorf("IncrementalHash() after Compact = %x, want %x", got, hash)
+	}
+	if v, ok := trie.Find("/interfaces/interface[name=1/3]"); !ok || v != 3 {
+		t.Errorf("Find() after Compact = %v, %v", v, ok)
+	}
+	for _, key := range trie.Keys() {
+		trie.Remove(key)
+	}
+	if trie.Size() != 0 || len(trie.root.children) != 0 {
+		t.Errorf("Remove() after Compact leaves %d keys", trie.Size())
+	}
+	trie.Add("/a", 1)
+	trie.Clear()
+	if trie.arena.used != 0 {
+		t.Errorf("Clear() leaves the arena used %d", trie.arena.used)
+	}
+
+	if New().Compact(0) {
+		t.Errorf("Compact() = true without arena")
+	}
+}
+
+func benchmarkBuildTree(b *testing.B, opts ...Option) {
+	var words []string
+	for i := 0; i < 100000; i++ {
+		words = append(words, fmt.Sprintf("/key/%x/%d", i*7919, i))
+	}
+	var (
+		trie  *Trie
+		pause time.Duration
+		ms    runtime.MemStats
+	)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		trie = New(opts...)
+		for _, w := range words {
+			trie.Add(w, nil)
+		}
+		b.StopTimer()
+		start := time.Now()
+		runtime.GC()
+		pause += time.Since(start)
+		b.StartTimer()
+	}
+	b.StopTimer()
+	runtime.ReadMemStats(&ms)
+	b.ReportMetric(float64(pause.Nanoseconds())/float64(b.N), "gc-ns/op")
+	b.ReportMetric(float64(ms.HeapAlloc), "heap-bytes")
+	runtime.KeepAlive(trie)
+}
+
+func BenchmarkBuildTreeDefault(b *testing.B) {
+	benchmarkBuildTree(b)
+}
+
+func BenchmarkBuildTreeArena(b *testing.B) {
+	benchmarkBuildTree(b, WithArena(0))
+}
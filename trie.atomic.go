@@ -0,0 +1,101 @@
+package gtrie
+
+// WithAtomicReads makes the trie persistent so that Find, HasPrefix,
+// FindLongestMatchingPrefix and the prefix and fuzzy searches (FindByPrefix*,
+// FindByFuzzy*) read an immutable root loaded once without any lock.
+// The mutations copy the nodes on the changed path instead of modifying them
+// in place and then publish the new root atomically. The writers still
+// serialize among themselves and with the other methods by the lock.
+//
+// The mutations are more expensive for the copies and a single reader can be
+// slower for the copied nodes spread over the heap, so the mode is for
+// the read-mostly workloads scaling over many cores. WithArena has no effect
+// in the mode and Instrument must be called before the trie is read concurrently.
+func WithAtomicReads() Option {
+	return func(t *Trie) {
+		t.atomicReads = true
+	}
+}
+
+// readRoot returns the root for a read. The read lock is taken
+// unless the trie is in the atomic read mode; release it by readDone.
+func (t *Trie) readRoot() *trieNode {
+	if t.atomicReads {
+		return t.published.Load()
+	}
+	t.rlock()
+	return t.root
+}
+
+// readDone releases the read lock taken by readRoot.
+func (t *Trie) readDone() {
+	if !t.atomicReads {
+		t.runlock()
+	}
+}
+
+// unlock publishes the root modified under the write lock and releases the lock.
+// The mutations traced under the lock are delivered to the tracer after that.
+func (t *Trie) unlock() {
+	if t.atomicReads && t.published.Load() != t.root {
+		t.published.Store(t.root)
+		// the nodes copied so far are published and must not be modified anymore.
+		t.gen++
+	}
+	if t.cache != nil {
+		t.cache.invalidate()
+	}
+	if t.tracer != nil && len(t.tracer.pending) > 0 {
+		t.tracer.deliver(&t.mu)
+		return
+	}
+	t.mu.Unlock()
+}
+
+// copyNode returns a copy of the published node `n` under the `parent`
+// that can be modified until the next publish.
+func (t *Trie) copyNode(n, parent *trieNode) *trieNode {
+	c := &trieNode{}
+	*c = *n
+	c.gen = t.gen
+	c.parent = parent
+	c.children = make(map[rune]*trieNode, len(n.children))
+	for r, child := range n.children {
+		c.children[r] = child
+	}
+	// the published order and masks must not be modified in place.
+	c.ext = n.ext.clone()
+	return c
+}
+
+// writableRoot returns the root that can be modified in place.
+func (t *Trie) writableRoot() *trieNode {
+	if t.atomicReads && t.root.gen != t.gen {
+		t.root = t.copyNode(t.root, nil)
+	}
+	return t.root
+}
+
+// writable returns the child `n` of the writable node `parent`
+// that can be modified in place, replacing it by a copy if it is published.
+func (t *Trie) writable(parent, n *trieNode) *trieNode {
+	if !t.atomicReads || n.gen == t.gen {
+		return n
+	}
+	c := t.copyNode(n, parent)
+	parent.setChild(c)
+	return c
+}
+
+// writablePath returns the writable node reachable from the root by `key`.
+func (t *Trie) writablePath(key string) *trieNode {
+	node := t.writableRoot()
+	for _, r := range key {
+		n, ok := node.children[r]
+		if !ok {
+			return nil
+		}
+		node = t.writable(node, n)
+	}
+	return node
+}
@@ -0,0 +1,156 @@
+package gtrie
+
+import (
+	"reflect"
+	"runtime"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestTrie_WithAtomicReads(t *testing.T) {
+	trie := New(WithAtomicReads())
+	want := New()
+	for _, key := range gnmiFixture {
+		trie.Add(key, len(key))
+		want.Add(key, len(key))
+	}
+	check := func(step string) {
+		t.Helper()
+		for _, prefix := range []string{"", "/interfaces/interface[name=1/2]", "/none"} {
+			if got, want := trie.FindByPrefixAll(prefix), want.FindByPrefixAll(prefix); !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: FindByPrefixAll(%q) = %v, want %v", step, prefix, got, want)
+			}
+		}
+		if got, want := trie.FindByFuzzyAll("1/3]count"), want.FindByFuzzyAll("1/3]count"); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: FindByFuzzyAll() = %v, want %v", step, got, want)
+		}
+		for _, key := range gnmiFixture {
+			v1, ok1 := trie.Find(key)
+			v2, ok2 := want.Find(key)
+			if v1 != v2 || ok1 != ok2 {
+				t.Errorf("%s: Find(%q) = %v, %v, want %v, %v", step, key, v1, ok1, v2, ok2)
+			}
+		}
+		if trie.Size() != want.Size() {
+			t.Errorf("%s: Size() = %d, want %d", step, trie.Size(), want.Size())
+		}
+	}
+	check("Add")
+
+	// a root published before is never modified by the later mutations.
+	before := trie.published.Load()
+	keys := sortedKeys(collect(before))
+	for _, key := range gnmiFixture[:8] {
+		trie.Remove(key)
+		want.Remove(key)
+	}
+	trie.Add("/interfaces/interface[name=1/3]", 0)
+	want.Add("/interfaces/interface[name=1/3]", 0)
+	check("Remove")
+	if got := sortedKeys(collect(before)); !reflect.DeepEqual(got, keys) {
+		t.Errorf("the published root is modified: %v, want %v", got, keys)
+	}
+	if n := findNode(before, "/interfaces/interface[name=1/3]").children[nul]; n.value == 0 {
+		t.Errorf("the published value is modified")
+	}
+
+	err := trie.Txn(func(tx *Txn) error {
+		tx.Add("/a", 1)
+		tx.Add("/a/b", 2)
+		tx.Remove("/a")
+		tx.RemoveByPrefix("/interfaces/interface[name=1/3]/state/")
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want.Add("/a/b", 2)
+	for _, key := range want.FindByPrefix("/interfaces/interface[name=1/3]/state/") {
+		want.Remove(key)
+	}
+	check("Txn")
+	if _, ok := trie.Find("/a/b"); !ok {
+		t.Errorf("Find() after Txn fails")
+	}
+
+	trie.Shrink()
+	check("Shrink")
+	trie.Clear()
+	want.Clear()
+	check("Clear")
+	if got := collect(before); len(got) != len(keys) {
+		t.Errorf("the published root is cleared")
+	}
+}
+
+func TestTrie_WithAtomicReads_Concurrent(t *testing.T) {
+	trie := New(WithAtomicReads())
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				trie.Find("/key/10")
+				trie.FindByPrefix("/key/1")
+				trie.FindByFuzzy("k1")
+			}
+		}()
+	}
+	for i := 0; i < 1000; i++ {
+		trie.Add("/key/"+strconv.Itoa(i), i)
+		if i%3 == 0 {
+			trie.Remove("/key/" + strconv.Itoa(i/2))
+		}
+	}
+	close(stop)
+	wg.Wait()
+	if got := len(trie.FindByPrefix("/key/")); got != trie.Size() {
+		t.Errorf("FindByPrefix() = %d keys, want %d", got, trie.Size())
+	}
+}
+
+func BenchmarkFindReadModes(b *testing.B) {
+	keys := make([]string, 0, 100000)
+	for i := 0; i < cap(keys); i++ {
+		keys = append(keys, "/key/"+strconv.Itoa(i))
+	}
+	modes := []struct {
+		name string
+		opts []Option
+	}{
+		{"RWMutex", nil},
+		{"AtomicReads", []Option{WithAtomicReads()}},
+	}
+	for _, mode := range modes {
+		trie := New(mode.opts...)
+		for i, key := range keys {
+			trie.Add(key, i)
+		}
+		// collect the garbage of the copies not to measure it.
+		runtime.GC()
+		for _, goroutines := range []int{1, 8, 32} {
+			b.Run(mode.name+"/"+strconv.Itoa(goroutines), func(b *testing.B) {
+				var wg sync.WaitGroup
+				per := b.N/goroutines + 1
+				for g := 0; g < goroutines; g++ {
+					wg.Add(1)
+					go func(g int) {
+						defer wg.Done()
+						for i := 0; i < per; i++ {
+							trie.Find(keys[(g*per+i)%len(keys)])
+						}
+					}(g)
+				}
+				wg.Wait()
+			})
+		}
+	}
+}
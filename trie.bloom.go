@@ -0,0 +1,140 @@
+package gtrie
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// ErrInvalidBloom is returned by Bloom.UnmarshalBinary if the input is not
+// a Bloom marshaled by Bloom.MarshalBinary.
+var ErrInvalidBloom = errors.New("gtrie: invalid bloom filter")
+
+// bloomMagic and bloomVersion head the binary form of Bloom.
+const (
+	bloomMagic   = "GTBF"
+	bloomVersion = 1
+)
+
+// maxBloomHashes is the most hashes per key of a Bloom, which is of the false
+// positive rate of 2^-64. It bounds the cost of MayContain of a Bloom unmarshaled.
+const maxBloomHashes = 64
+
+// Bloom is a bloom filter of the keys of a trie exported by ExportBloom.
+// MayContain never returns false for a key of the trie when the filter
+// is exported, but may return true for a key not in the trie.
+// The filter is a snapshot; it is not updated by the mutations of the trie.
+// The keys are hashed by FNV-1a, so that the filter marshaled can be checked
+// by another process. A Bloom is safe for concurrent use of MayContain.
+type Bloom struct {
+	bits []uint64
+	m    uint64 // the number of the bits
+	k    uint32 // the number of the hashes per key
+}
+
+// ExportBloom builds a bloom filter of all the keys of the trie sized for
+// the false positive rate `fpRate` (e.g. 0.01) at the number of the keys.
+// The keys are put in their canonical form, so that the keys checked by
+// MayContain must be converted by the key transform of the trie if set.
+func (t *Trie) ExportBloom(fpRate float64) *Bloom {
+	if t == nil {
+		return new(Trie).ExportBloom(fpRate)
+	}
+	root := t.readRoot()
+	defer t.readDone()
+	terms := collectNodes(root)
+	b := newBloom(len(terms), fpRate)
+	for _, n := range terms {
+		b.add(t.canonical(n.key()))
+	}
+	return b
+}
+
+// newBloom returns an empty bloom filter for `n` keys at the false positive rate `p`.
+func newBloom(n int, p float64) *Bloom {
+	if p <= 0 || p >= 1 {
+		p = 0.01
+	}
+	m := uint64(64)
+	if n > 0 {
+		m = max(m, uint64(math.Ceil(-float64(n)*math.Log(p)/(math.Ln2*math.Ln2))))
+	}
+	m = (m + 63) / 64 * 64
+	k := uint32(1)
+	if n > 0 {
+		k = min(max(k, uint32(math.Round(float64(m)/float64(n)*math.Ln2))), maxBloomHashes)
+	}
+	return &Bloom{bits: make([]uint64, m/64), m: m, k: k}
+}
+
+// hashes returns the two hashes of the key combined into the k hashes
+// by the double hashing.
+func (b *Bloom) hashes(key string) (uint64, uint64) {
+	// FNV-1a inlined not to allocate the hash and the bytes of the key.
+	h1 := uint64(14695981039346656037)
+	for i := 0; i < len(key); i++ {
+		h1 ^= uint64(key[i])
+		h1 *= 1099511628211
+	}
+	// the second hash is odd not to repeat the bits for the even m.
+	h2 := (h1>>33 | h1<<31) | 1
+	return h1, h2
+}
+
+func (b *Bloom) add(key string) {
+	h1, h2 := b.hashes(key)
+	for i := uint64(0); i < uint64(b.k); i++ {
+		bit := (h1 + i*h2) % b.m
+		b.bits[bit/64] |= 1 << (bit % 64)
+	}
+}
+
+// MayContain returns false if the `key` was not in the trie when the filter
+// was exported, and true if it was, or may be at the false positive rate.
+func (b *Bloom) MayContain(key string) bool {
+	h1, h2 := b.hashes(key)
+	for i := uint64(0); i < uint64(b.k); i++ {
+		bit := (h1 + i*h2) % b.m
+		if b.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. The form is the magic,
+// the version, the number of the hashes, the number of the bits and the bits
+// in little-endian.
+func (b *Bloom) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 0, len(bloomMagic)+2+4+8+len(b.bits)*8)
+	data = append(data, bloomMagic...)
+	data = binary.LittleEndian.AppendUint16(data, bloomVersion)
+	data = binary.LittleEndian.AppendUint32(data, b.k)
+	data = binary.LittleEndian.AppendUint64(data, b.m)
+	for _, w := range b.bits {
+		data = binary.LittleEndian.AppendUint64(data, w)
+	}
+	return data, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+// It returns ErrInvalidBloom if the `data` is not in the form of MarshalBinary.
+func (b *Bloom) UnmarshalBinary(data []byte) error {
+	const header = len(bloomMagic) + 2 + 4 + 8
+	if len(data) < header || string(data[:len(bloomMagic)]) != bloomMagic ||
+		binary.LittleEndian.Uint16(data[len(bloomMagic):]) != bloomVersion {
+		return ErrInvalidBloom
+	}
+	k := binary.LittleEndian.Uint32(data[len(bloomMagic)+2:])
+	m := binary.LittleEndian.Uint64(data[len(bloomMagic)+6:])
+	data = data[header:]
+	if k == 0 || k > maxBloomHashes || m == 0 || m%64 != 0 || uint64(len(data)) != m/8 {
+		return ErrInvalidBloom
+	}
+	bits := make([]uint64, m/64)
+	for i := range bits {
+		bits[i] = binary.LittleEndian.Uint64(data[i*8:])
+	}
+	b.bits, b.m, b.k = bits, m, k
+	return nil
+}
@@ -0,0 +1,86 @@
+package gtrie
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTrie_ExportBloom(t *testing.T) {
+	trie := New()
+	for i := 0; i < 10000; i++ {
+		trie.Add(fmt.Sprintf("/interfaces/interface[name=eth%d]/state/mtu", i), i)
+	}
+	for _, rate := range []float64{0.1, 0.01, 0.001} {
+		b := trie.ExportBloom(rate)
+		for _, key := range trie.Keys() {
+			if !b.MayContain(key) {
+				t.Fatalf("MayContain(%q) = false for a key of the trie", key)
+			}
+		}
+		fp := 0
+		const absent = 20000
+		for i := 0; i < absent; i++ {
+			if b.MayContain(fmt.Sprintf("/interfaces/interface[name=eth%d]/state/mtu", 10000+i)) {
+				fp++
+			}
+		}
+		if got := float64(fp) / absent; got > rate*2 {
+			t.Errorf("false positive rate = %.4f, want about %.4f", got, rate)
+		}
+	}
+}
+
+func TestTrie_ExportBloomTransform(t *testing.T) {
+	trie := New(WithKeyTransform(strings.ToLower))
+	trie.Add("/A/B", 1)
+	b := trie.ExportBloom(0.01)
+	if !b.MayContain("/a/b") {
+		t.Errorf("MayContain() = false for the canonical key")
+	}
+	empty := New().ExportBloom(0.01)
+	if empty.MayContain("/a/b") {
+		t.Errorf("MayContain() of an empty filter = true")
+	}
+}
+
+func TestBloom_MarshalBinary(t *testing.T) {
+	trie := newGNMITrie()
+	src := trie.ExportBloom(0.01)
+	data, err := src.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary() error = %v", err)
+	}
+	var b Bloom
+	if err := b.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary() error = %v", err)
+	}
+	for _, key := range trie.Keys() {
+		if !b.MayContain(key) {
+			t.Errorf("MayContain(%q) = false after UnmarshalBinary", key)
+		}
+	}
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("/x%d", i)
+		if b.MayContain(key) != src.MayContain(key) {
+			t.Errorf("MayContain(%q) differs after UnmarshalBinary", key)
+		}
+	}
+	// a filter of too many hashes per key, e.g. 2^31 taking seconds per MayContain.
+	manyHashes := binary.LittleEndian.AppendUint64(binary.LittleEndian.AppendUint32([]byte("GTBF\x01\x00"), 1<<31), 64)
+	manyHashes = append(manyHashes, make([]byte, 8)...)
+	for _, data := range [][]byte{nil, []byte("GTBF"), data[:len(data)-1], append([]byte("XXXX"), data[4:]...), manyHashes} {
+		if err := b.UnmarshalBinary(data); err != ErrInvalidBloom {
+			t.Errorf("UnmarshalBinary() of %d bytes error = %v, want %v", len(data), err, ErrInvalidBloom)
+		}
+	}
+	// the filters of the tiny rates are exported with the hashes bounded.
+	tiny := trie.ExportBloom(1e-300)
+	if data, err = tiny.MarshalBinary(); err == nil {
+		err = b.UnmarshalBinary(data)
+	}
+	if tiny.k != maxBloomHashes || err != nil {
+		t.Errorf("ExportBloom(1e-300) = %d hashes, UnmarshalBinary() error = %v", tiny.k, err)
+	}
+}
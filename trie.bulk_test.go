@@ -0,0 +1,127 @@
+package gtrie
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// checkSameNodes checks that the subtrees of the nodes `a` and `b`
+// of the different tries have the same nodes.
+func checkSameNodes(t *testing.T, a, b *trieNode) {
+	t.Helper()
+	if a.rval != b.rval || a.term != b.term || a.path != b.path || a.depth != b.depth ||
+		a.value != b.value || a.mask != b.mask || a.termCount != b.termCount || len(a.children) != len(b.children) {
+		t.Fatalf("node %q (depth %d) = %+v, want %+v", a.rval, a.depth, *a, *b)
+	}
+	for r, c := range a.children {
+		d, ok := b.children[r]
+		if !ok {
+			t.Fatalf("node %q (depth %d) has the child %q not added", a.rval, a.depth, r)
+		}
+		if c.parent != a {
+			t.Fatalf("the parent of %q (depth %d) is not set", r, c.depth)
+		}
+		checkSameNodes(t, c, d)
+	}
+}
+
+func TestNewFromSortedKeys(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	keys := []string{"", "a", "a", "ab", "abc", "abd", "b", "가", "가나", "나"}
+	for i := 0; i < 1000; i++ {
+		b := make([]rune, rng.Intn(8))
+		for j := range b {
+			b[j] = []rune("ab/1가")[rng.Intn(5)]
+		}
+		keys = append(keys, string(b))
+	}
+	sort.Strings(keys)
+	values := make([]interface{}, len(keys))
+	want := New()
+	for i, key := range keys {
+		values[i] = i
+		want.Add(key, i)
+	}
+	trie, err := NewFromSortedKeys(keys, values)
+	if err != nil {
+		t.Fatalf("NewFromSortedKeys() error = %v", err)
+	}
+	if trie.Size() != want.Size() {
+		t.Errorf("Size() = %d, want %d", trie.Size(), want.Size())
+	}
+	checkSameNodes(t, trie.root, want.root)
+	for _, q := range []string{"ab", "1/", "가"} {
+		if got, w := sortedKeys(trie.FindByFuzzy(q)), sortedKeys(want.FindByFuzzy(q)); strings.Join(got, ",") != strings.Join(w, ",") {
+			t.Errorf("FindByFuzzy(%q) = %d keys, want %d keys", q, len(got), len(w))
+		}
+	}
+	// the trie built is as mutable as the trie of Add.
+	for _, key := range keys[:500] {
+		trie.Remove(key)
+		want.Remove(key)
+	}
+	checkSameNodes(t, trie.root, want.root)
+
+	empty, err := NewFromSortedKeys(nil, nil)
+	if err != nil || empty.Size() != 0 {
+		t.Errorf("NewFromSortedKeys(nil) = %d keys, %v", empty.Size(), err)
+	}
+}
+
+func TestNewFromSortedKeysError(t *testing.T) {
+	tests := []struct {
+		keys   []string
+		values []interface{}
+		want   error
+	}{
+		{[]string{"a", "b"}, []interface{}{1}, ErrValuesMismatch},
+		{[]string{"b", "a"}, []interface{}{1, 2}, ErrUnsortedKeys},
+		{[]string{"ab", "a"}, []interface{}{1, 2}, ErrUnsortedKeys},
+		{[]string{"", "가", "b"}, []interface{}{1, 2, 3}, ErrUnsortedKeys},
+	}
+	for _, tt := range tests {
+		if trie, err := NewFromSortedKeys(tt.keys, tt.values); !errors.Is(err, tt.want) || trie != nil {
+			t.Errorf("NewFromSortedKeys(%q) = %v, %v, want %v", tt.keys, trie, err, tt.want)
+		}
+	}
+}
+
+// sortedDictKeys returns the words of the dictionary in order, or the paths
+// of the counters of 100k subinterfaces if there is no dictionary.
+func sortedDictKeys() []string {
+	var keys []string
+	if data, err := os.ReadFile("/usr/share/dict/words"); err == nil {
+		keys = strings.Fields(string(data))
+	} else {
+		for i := 0; i < 100000; i++ {
+			keys = append(keys, fmt.Sprintf("/interfaces/interface[name=eth%d]/subinterfaces/subinterface[index=%d]/state/counters/in-octets", i/100, i%100))
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func BenchmarkNewFromSortedKeys(b *testing.B) {
+	keys := sortedDictKeys()
+	values := make([]interface{}, len(keys))
+	b.Run("Add", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			trie := New()
+			for j, key := range keys {
+				trie.Add(key, values[j])
+			}
+		}
+	})
+	b.Run("NewFromSortedKeys", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := NewFromSortedKeys(keys, values); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,74 @@
+package gtrie
+
+// WithValueSizer maintains the sum of the sizes of the values under each node,
+// as the number of the keys, by `size` returning the size of a value in bytes,
+// e.g. len of a []byte payload. The size of a value must not change while
+// the value is in the trie. The sums are adjusted by the difference of the sizes
+// on the overwrites, so that BytesByPrefix returns the sum in O(len(prefix)).
+func WithValueSizer(size func(v interface{}) int) Option {
+	return func(t *Trie) {
+		t.sizer = size
+		t.root.extend().sized = true
+	}
+}
+
+// WithMaxBytes rejects the values with ErrTooManyBytes if the sum of the sizes
+// of all the values of the trie would exceed `n` bytes by the value added,
+// including the value replacing the value of an existing key by its growth.
+// The values shrinking or not growing are still added over the limit.
+// It takes effect with WithValueSizer.
+func WithMaxBytes(n int) Option {
+	return func(t *Trie) {
+		t.limit().maxBytes = n
+	}
+}
+
+// BytesByPrefix returns the sum of the sizes of the values of the keys starting
+// with `prefix` by WithValueSizer. It returns 0 without WithValueSizer.
+func (t *Trie) BytesByPrefix(prefix string) int {
+	if t == nil {
+		return 0
+	}
+	root := t.readRoot()
+	defer t.readDone()
+	return findNode(root, t.canonical(prefix)).bytes()
+}
+
+// bytes returns the sum of the sizes of the values under the node,
+// or 0 if the node is nil or the sizes are not maintained.
+func (n *trieNode) bytes() int {
+	if n == nil || n.ext == nil {
+		return 0
+	}
+	return n.ext.bytes
+}
+
+// addBytes adds `delta` to the sums of the sizes of the node and its ancestors.
+func addBytes(n *trieNode, delta int) {
+	if delta == 0 {
+		return
+	}
+	for ; n != nil; n = n.parent {
+		if n.ext != nil && n.ext.sized {
+			n.ext.bytes += delta
+		}
+	}
+}
+
+// valueSize returns the size of the value of the terminal node, or 0 if nil.
+func (t *Trie) valueSize(n *trieNode) int {
+	if n == nil {
+		return 0
+	}
+	return t.sizer(n.value)
+}
+
+// checkBytes checks the `value` replacing the terminal node `old` (nil if new)
+// against WithMaxBytes.
+func (t *Trie) checkBytes(old *trieNode, value interface{}) error {
+	delta := t.sizer(value) - t.valueSize(old)
+	if delta > 0 && t.root.bytes()+delta > t.limits.maxBytes {
+		return ErrTooManyBytes
+	}
+	return nil
+}
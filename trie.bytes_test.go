@@ -0,0 +1,146 @@
+package gtrie
+
+import (
+	"errors"
+	"testing"
+)
+
+// payloadSize is the size of the []byte payloads and 0 of the others.
+func payloadSize(v interface{}) int {
+	b, _ := v.([]byte)
+	return len(b)
+}
+
+// checkBytes checks the sums of the sizes of the values under the nodes.
+func checkBytes(t *testing.T, node *trieNode) {
+	t.Helper()
+	walkNodes(node, func(n *trieNode) {
+		if n.rval == nul {
+			return
+		}
+		want := 0
+		for _, term := range collectNodes(n) {
+			want += payloadSize(term.value)
+		}
+		if n.bytes() != want {
+			t.Errorf("bytes of %q (depth %d) = %d, want %d", n.rval, n.depth, n.bytes(), want)
+		}
+	})
+}
+
+func TestTrie_BytesByPrefix(t *testing.T) {
+	for name, opts := range map[string][]Option{
+		"plain":  nil,
+		"sorted": {WithSortedChildren()},
+		"atomic": {WithAtomicReads()},
+	} {
+		trie := New(append(opts, WithValueSizer(payloadSize))...)
+		trie.Add("/interfaces/interface[name=1/1]", make([]byte, 10))
+		trie.Add("/interfaces/interface[name=1/2]", make([]byte, 20))
+		trie.Add("/interfaces", make([]byte, 1))
+		trie.Add("/qos/queue", make([]byte, 100))
+		trie.Add("/qos/none", "not a payload")
+		tests := []struct {
+			prefix string
+			want   int
+		}{
+			{"", 131},
+			{"/interfaces", 31},
+			{"/interfaces/", 30},
+			{"/interfaces/interface[name=1/2]", 20},
+			{"/qos", 100},
+			{"/system", 0},
+		}
+		for _, tt := range tests {
+			if got := trie.BytesByPrefix(tt.prefix); got != tt.want {
+				t.Errorf("%s: BytesByPrefix(%q) = %d, want %d", name, tt.prefix, got, tt.want)
+			}
+		}
+		// the overwrites grow and shrink the sums by the difference.
+		trie.Add("/interfaces/interface[name=1/2]", make([]byte, 50))
+		if got := trie.BytesByPrefix("/interfaces"); got != 61 {
+			t.Errorf("%s: BytesByPrefix() after the growth = %d, want 61", name, got)
+		}
+		trie.Add("/qos/queue", make([]byte, 5))
+		if got := trie.BytesByPrefix(""); got != 66 {
+			t.Errorf("%s: BytesByPrefix() after the shrink = %d, want 66", name, got)
+		}
+		trie.Remove("/interfaces/interface[name=1/1]")
+		trie.Remove("/none")
+		if got := trie.BytesByPrefix("/interfaces/"); got != 50 {
+			t.Errorf("%s: BytesByPrefix() after the removal = %d, want 50", name, got)
+		}
+		checkBytes(t, trie.root)
+		trie.Clear()
+		trie.Add("/a", make([]byte, 3))
+		if got := trie.BytesByPrefix(""); got != 3 {
+			t.Errorf("%s: BytesByPrefix() after Clear = %d, want 3", name, got)
+		}
+	}
+	if got := newGNMITrie().BytesByPrefix(""); got != 0 {
+		t.Errorf("BytesByPrefix() without WithValueSizer = %d, want 0", got)
+	}
+}
+
+func TestTrie_BytesByPrefixMutations(t *testing.T) {
+	trie := New(WithValueSizer(payloadSize))
+	for i, key := range []string{"/a", "/a/x", "/a/x/1", "/a/x/2", "/a/y", "/b/3", "/c"} {
+		trie.Add(key, make([]byte, i+1))
+	}
+	if _, err := trie.MovePrefix("/a/x", "/b/x"); err != nil {
+		t.Fatalf("MovePrefix() error = %v", err)
+	}
+	checkBytes(t, trie.root)
+	if _, err := trie.MovePrefix("/b/", "/a/"); err != nil {
+		t.Fatalf("MovePrefix() error = %v", err)
+	}
+	checkBytes(t, trie.root)
+	other := New()
+	other.Add("/a/3", make([]byte, 100))
+	other.Add("/d/e", make([]byte, 7))
+	trie.Merge(other)
+	checkBytes(t, trie.root)
+	trie.RemoveSoft("/d/e")
+	checkBytes(t, trie.root)
+	trie.Undelete("/d/e")
+	checkBytes(t, trie.root)
+	// the value of 6 bytes moved to "/a/3" is replaced by the merge.
+	if got, want := trie.BytesByPrefix(""), 1+2+3+4+5+7+100+7; got != want {
+		t.Errorf("BytesByPrefix() = %d, want %d", got, want)
+	}
+}
+
+func TestTrie_WithMaxBytes(t *testing.T) {
+	var rejected []string
+	trie := New(WithValueSizer(payloadSize), WithMaxBytes(100), WithOnReject(func(key string, err error) {
+		rejected = append(rejected, key)
+	}))
+	if err := trie.AddE("/a", make([]byte, 60)); err != nil {
+		t.Fatalf("AddE() error = %v", err)
+	}
+	if err := trie.AddE("/b", make([]byte, 41)); !errors.Is(err, ErrTooManyBytes) {
+		t.Errorf("AddE() over the quota error = %v, want %v", err, ErrTooManyBytes)
+	}
+	if err := trie.AddE("/b", make([]byte, 40)); err != nil {
+		t.Errorf("AddE() up to the quota error = %v", err)
+	}
+	// the overwrite is checked by its growth.
+	if err := trie.AddE("/a", make([]byte, 61)); !errors.Is(err, ErrTooManyBytes) {
+		t.Errorf("AddE() of the growth over the quota error = %v, want %v", err, ErrTooManyBytes)
+	}
+	if v, _ := trie.Find("/a"); len(v.([]byte)) != 60 {
+		t.Errorf("Find() after the rejection = %d bytes, want 60", len(v.([]byte)))
+	}
+	if err := trie.AddE("/a", make([]byte, 10)); err != nil {
+		t.Errorf("AddE() of the shrink error = %v", err)
+	}
+	if err := trie.AddE("/c", make([]byte, 50)); err != nil {
+		t.Errorf("AddE() after the shrink error = %v", err)
+	}
+	if got := trie.BytesByPrefix(""); got != 100 || trie.Size() != 3 {
+		t.Errorf("BytesByPrefix() = %d, Size() = %d, want 100, 3", got, trie.Size())
+	}
+	if len(rejected) != 2 || rejected[0] != "/b" || rejected[1] != "/a" {
+		t.Errorf("the keys rejected = %v, want [/b /a]", rejected)
+	}
+}
@@ -0,0 +1,133 @@
+package gtrie
+
+import (
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// cached queries of queryKey.
+const (
+	queryPrefix uint8 = iota
+	queryPrefixAll
+)
+
+// queryKey is the key of a cached result, the query and its canonical prefix.
+type queryKey struct {
+	method uint8
+	prefix string
+}
+
+// queryCache memoizes the results of the prefix queries (WithQueryCache).
+// The results are of the generation `gen` of the keys, which is advanced
+// by any write; a result computed while the generation is advanced is not
+// cached, since it may be of the keys before the write.
+type queryCache struct {
+	gen atomic.Uint64
+	// copy returns the copies of the results cached (CopyResults).
+	copy    bool
+	max     int
+	mu      sync.Mutex
+	entries map[queryKey]interface{}
+}
+
+// QueryCacheOption configures the query cache of WithQueryCache.
+type QueryCacheOption func(c *queryCache)
+
+// CopyResults returns a copy of the cached result to each caller, so that
+// the caller can modify it, at the cost of the copy per hit.
+func CopyResults() QueryCacheOption {
+	return func(c *queryCache) {
+		c.copy = true
+	}
+}
+
+// WithQueryCache memoizes the results of FindByPrefix, FindByPrefixAll and Keys
+// by their prefixes up to `maxEntries` results, for the read-mostly tries
+// queried by the same prefixes repeatedly. An arbitrary result is evicted for
+// a new one if the cache is full. Any write, e.g. Add, Remove and Clear,
+// invalidates all the results cached.
+//
+// A cache hit returns the slice or the map cached as it is, shared by all
+// the callers of the same query until it is invalidated, so the results,
+// including those of PrefixSearch, Search and SearchAll by SearchByPrefix,
+// must not be modified unless CopyResults is set. The values in the results
+// are shared anyway as the results of the queries not cached.
+func WithQueryCache(maxEntries int, opts ...QueryCacheOption) Option {
+	return func(t *Trie) {
+		if maxEntries <= 0 {
+			t.cache = nil
+			return
+		}
+		c := &queryCache{max: maxEntries, entries: make(map[queryKey]interface{})}
+		for _, opt := range opts {
+			opt(c)
+		}
+		t.cache = c
+	}
+}
+
+// get returns the result cached for the key and true, or the generation
+// the result to be computed for the key is of and false.
+func (c *queryCache) get(k queryKey) (interface{}, uint64, bool) {
+	gen := c.gen.Load()
+	c.mu.Lock()
+	v, ok := c.entries[k]
+	c.mu.Unlock()
+	return v, gen, ok
+}
+
+// put caches the result `v` computed for the key at the generation `gen`
+// unless the generation is advanced.
+func (c *queryCache) put(k queryKey, v interface{}, gen uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.gen.Load() != gen {
+		return
+	}
+	if _, ok := c.entries[k]; !ok && len(c.entries) >= c.max {
+		for old := range c.entries {
+			delete(c.entries, old)
+			break
+		}
+	}
+	c.entries[k] = v
+}
+
+// invalidate drops all the results cached. It must be called after the write
+// is visible to the readers, i.e. after the root is published.
+func (c *queryCache) invalidate() {
+	c.mu.Lock()
+	c.gen.Add(1)
+	clear(c.entries)
+	c.mu.Unlock()
+}
+
+// cachedPrefix returns FindByPrefix of the prefix from the cache.
+func (t *Trie) cachedPrefix(prefix string) []string {
+	k := queryKey{method: queryPrefix, prefix: t.canonical(prefix)}
+	v, gen, ok := t.cache.get(k)
+	if !ok {
+		v = t.findByPrefix(prefix)
+		t.cache.put(k, v, gen)
+	}
+	if t.cache.copy {
+		return slices.Clone(v.([]string))
+	}
+	return v.([]string)
+}
+
+// cachedPrefixAll returns FindByPrefixAll of the prefix from the cache.
+func (t *Trie) cachedPrefixAll(prefix string) map[string]interface{} {
+	k := queryKey{method: queryPrefixAll, prefix: t.canonical(prefix)}
+	v, gen, ok := t.cache.get(k)
+	if !ok {
+		v = t.findByPrefixAll(prefix)
+		t.cache.put(k, v, gen)
+	}
+	if t.cache.copy {
+		return maps.Clone(v.(map[string]interface{}))
+	}
+	return v.(map[string]interface{})
+}
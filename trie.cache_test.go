@@ -0,0 +1,186 @@
+package gtrie
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestTrie_QueryCache(t *testing.T) {
+	trie := New(WithQueryCache(8))
+	trie.Add("/a/1", 1)
+	trie.Add("/a/2", 2)
+	trie.Add("/b/1", 3)
+
+	keys := trie.FindByPrefix("/a")
+	sort.Strings(keys)
+	if !reflect.DeepEqual(keys, []string{"/a/1", "/a/2"}) {
+		t.Fatalf("FindByPrefix() = %v", keys)
+	}
+	// the hits return the same slice and map cached.
+	if again := trie.FindByPrefix("/a"); &again[0] != &keys[0] {
+		t.Errorf("FindByPrefix() not cached")
+	}
+	all := trie.FindByPrefixAll("/a")
+	if again := trie.FindByPrefixAll("/a"); reflect.ValueOf(again).Pointer() != reflect.ValueOf(all).Pointer() {
+		t.Errorf("FindByPrefixAll() not cached")
+	}
+	if k := trie.Keys(); len(k) != 3 || &trie.Keys()[0] != &k[0] {
+		t.Errorf("Keys() = %v, not cached", k)
+	}
+	// the results missed are cached as well.
+	if keys := trie.FindByPrefix("/c"); keys != nil {
+		t.Errorf("FindByPrefix(/c) = %v, want nil", keys)
+	}
+
+	mutations := []func(){
+		func() { trie.Add("/a/3", 4) },
+		func() { trie.Add("/a/3", 5) },
+		func() { trie.Remove("/a/1") },
+		func() { trie.Clear() },
+	}
+	for i, mutate := range mutations {
+		before := trie.FindByPrefix("/a")
+		beforeAll := trie.FindByPrefixAll("/a")
+		mutate()
+		keys := trie.FindByPrefix("/a")
+		sort.Strings(keys)
+		want := collectKeys(trie, "/a")
+		if !reflect.DeepEqual(keys, want) {
+			t.Errorf("mutation %d: FindByPrefix() = %v, want %v", i, keys, want)
+		}
+		if len(before) > 0 && len(keys) > 0 && &keys[0] == &before[0] {
+			t.Errorf("mutation %d: FindByPrefix() not invalidated", i)
+		}
+		if all := trie.FindByPrefixAll("/a"); len(all) > 0 && reflect.ValueOf(all).Pointer() == reflect.ValueOf(beforeAll).Pointer() {
+			t.Errorf("mutation %d: FindByPrefixAll() not invalidated", i)
+		}
+	}
+	if len(trie.FindByPrefix("/a")) != 0 || len(trie.FindByPrefixAll("")) != 0 {
+		t.Errorf("FindByPrefix() after Clear() = %v", trie.FindByPrefix("/a"))
+	}
+}
+
+// collectKeys returns the sorted keys under the prefix walked without the cache.
+func collectKeys(trie *Trie, prefix string) []string {
+	var keys []string
+	for key := range trie.IterByPrefixDesc(prefix) {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestTrie_QueryCacheEviction(t *testing.T) {
+	trie := New(WithQueryCache(2))
+	for i := 0; i < 5; i++ {
+		trie.Add(fmt.Sprintf("/%d", i), i)
+	}
+	for i := 0; i < 5; i++ {
+		if keys := trie.FindByPrefix(fmt.Sprintf("/%d", i)); len(keys) != 1 {
+			t.Errorf("FindByPrefix(/%d) = %v", i, keys)
+		}
+	}
+	if n := len(trie.cache.entries); n != 2 {
+		t.Errorf("%d results cached, want 2", n)
+	}
+	if trie := New(WithQueryCache(0)); trie.cache != nil {
+		t.Errorf("WithQueryCache(0) caches the results")
+	}
+}
+
+func TestTrie_QueryCacheCopyResults(t *testing.T) {
+	trie := New(WithQueryCache(8, CopyResults()))
+	trie.Add("/a/1", 1)
+	keys := trie.FindByPrefix("/a")
+	keys[0] = "modified"
+	all := trie.FindByPrefixAll("/a")
+	all["/a/1"] = "modified"
+	if keys := trie.FindByPrefix("/a"); keys[0] != "/a/1" {
+		t.Errorf("FindByPrefix() = %v, want the copy of [/a/1]", keys)
+	}
+	if all := trie.FindByPrefixAll("/a"); all["/a/1"] != 1 {
+		t.Errorf("FindByPrefixAll() = %v, want the copy of map[/a/1:1]", all)
+	}
+}
+
+func TestTrie_QueryCacheConcurrent(t *testing.T) {
+	for _, opts := range [][]Option{
+		{WithQueryCache(4)},
+		{WithQueryCache(4), WithAtomicReads()},
+		{WithQueryCache(4), WithPrefixLocks('/')},
+	} {
+		trie := New(opts...)
+		const writes = 200
+		var wg sync.WaitGroup
+		done := make(chan struct{})
+		for r := 0; r < 4; r++ {
+			wg.Add(1)
+			go func(r int) {
+				defer wg.Done()
+				for {
+					select {
+					case <-done:
+						return
+					default:
+					}
+					prefix := fmt.Sprintf("/%d", r%2)
+					for _, key := range trie.FindByPrefix(prefix) {
+						if key[:2] != prefix {
+							t.Errorf("FindByPrefix(%s) has %q", prefix, key)
+						}
+					}
+					for key, v := range trie.FindByPrefixAll(prefix) {
+						if v == nil || key[:2] != prefix {
+							t.Errorf("FindByPrefixAll(%s) has %q: %v", prefix, key, v)
+						}
+					}
+				}
+			}(r)
+		}
+		for i := 0; i < writes; i++ {
+			trie.Add(fmt.Sprintf("/%d/%d", i%2, i), i)
+			if i%3 == 0 {
+				trie.Remove(fmt.Sprintf("/%d/%d", i%2, i))
+			}
+		}
+		close(done)
+		wg.Wait()
+		// the results after the writes returned are of all the writes.
+		for p := 0; p < 2; p++ {
+			prefix := fmt.Sprintf("/%d", p)
+			keys := trie.FindByPrefix(prefix)
+			sort.Strings(keys)
+			if want := collectKeys(trie, prefix); !reflect.DeepEqual(keys, want) {
+				t.Errorf("%d options: FindByPrefix(%s) = %d keys, want %d", len(opts), prefix, len(keys), len(want))
+			}
+		}
+	}
+}
+
+func TestTrie_QueryCacheWriteKeys(t *testing.T) {
+	trie := New(WithQueryCache(8))
+	var want strings.Builder
+	for i := 0; i < 20; i++ {
+		trie.Add(fmt.Sprintf("/%c", 't'-i), true)
+		fmt.Fprintf(&want, "/%c\n", 'a'+i)
+	}
+	// the keys cached are in the order of the walk, not sorted.
+	cached := slices.Clone(trie.FindByPrefix("/"))
+	var buf bytes.Buffer
+	if n, err := trie.WriteKeys(&buf, "/"); n != 20 || err != nil {
+		t.Fatalf("WriteKeys() = %d, %v", n, err)
+	}
+	if buf.String() != want.String() {
+		t.Errorf("WriteKeys() wrote %q", buf.String())
+	}
+	// the keys sorted by WriteKeys are not the keys cached.
+	if keys := trie.FindByPrefix("/"); !reflect.DeepEqual(keys, cached) {
+		t.Errorf("FindByPrefix() after WriteKeys() = %v, want %v", keys, cached)
+	}
+}
@@ -0,0 +1,102 @@
+package gtrie
+
+import "unicode/utf8"
+
+// CollectByPrefix calls `fn` for each key starting with `prefix` and its value
+// inside the walk of the trie and returns the results of `fn` accepted,
+// skipping the keys for which `fn` returns false. The result is allocated once
+// for all the keys under `prefix`, so that the keys and values are transformed
+// without the intermediate map of FindByPrefixAll. It is a function, not a method,
+// since a method cannot have a type parameter. `fn` is called under the read lock
+// and must not modify the trie.
+func CollectByPrefix[T any](t *Trie, prefix string, fn func(key string, v interface{}) (T, bool)) []T {
+	root := t.readRoot()
+	defer t.readDone()
+	node := findNode(root, t.canonical(prefix))
+	if node == nil {
+		return nil
+	}
+	return appendCollected(make([]T, 0, node.termCount), node, fn)
+}
+
+// CollectByFuzzy calls `fn` for each key matched by the fuzzy search of FindByFuzzy
+// and returns the results accepted like CollectByPrefix.
+func CollectByFuzzy[T any](t *Trie, key string, fn func(key string, v interface{}) (T, bool)) []T {
+	root := t.readRoot()
+	defer t.readDone()
+	partial := t.runes(key)
+	if len(partial) == 0 {
+		return appendCollected(make([]T, 0, root.termCount), root, fn)
+	}
+	// the subtrees matched are disjoint, so that their term counts
+	// sum up to the number of the keys matched.
+	var matched []*trieNode
+	count := 0
+	potential := []potentialSubtree{{node: root, idx: 0}}
+	for l := len(potential); l > 0; l = len(potential) {
+		p := potential[l-1]
+		potential = potential[:l-1]
+		if fuzzyPruned(p.node, partial[p.idx:]) {
+			continue
+		}
+		if p.node.rval == partial[p.idx] {
+			p.idx++
+			if p.idx == len(partial) {
+				matched = append(matched, p.node)
+				count += p.node.termCount
+				continue
+			}
+		}
+		for _, c := range p.node.children {
+			potential = append(potential, potentialSubtree{node: c, idx: p.idx})
+		}
+	}
+	if count == 0 {
+		return nil
+	}
+	out := make([]T, 0, count)
+	for _, n := range matched {
+		out = appendCollected(out, n, fn)
+	}
+	return out
+}
+
+// CollectMatchingPrefix calls `fn` for each key that is a prefix of the input `key`
+// from the shortest and returns the results accepted like CollectByPrefix.
+func CollectMatchingPrefix[T any](t *Trie, key string, fn func(key string, v interface{}) (T, bool)) []T {
+	root := t.readRoot()
+	defer t.readDone()
+	key = t.canonical(key)
+	var out []T
+	node := root
+	for i := 0; i < len(key); {
+		r, size := utf8.DecodeRuneInString(key[i:])
+		i += size
+		n, ok := node.children[r]
+		if !ok {
+			break
+		}
+		if term, ok := n.children[nul]; ok && term.term {
+			if v, ok := fn(term.key(), term.value); ok {
+				if out == nil {
+					// the keys matched are bounded by the runes left.
+					out = make([]T, 0, 1+utf8.RuneCountInString(key[i:]))
+				}
+				out = append(out, v)
+			}
+		}
+		node = n
+	}
+	return out
+}
+
+// appendCollected appends the results of `fn` accepted for all the terminal
+// nodes under the node to `dst`.
+func appendCollected[T any](dst []T, node *trieNode, fn func(key string, v interface{}) (T, bool)) []T {
+	walkTerms(node, func(n *trieNode) {
+		if v, ok := fn(n.key(), n.value); ok {
+			dst = append(dst, v)
+		}
+	})
+	return dst
+}
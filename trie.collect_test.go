@@ -0,0 +1,99 @@
+package gtrie
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestCollect(t *testing.T) {
+	trie := New()
+	for i, key := range gnmiFixture {
+		trie.Add(key, i)
+	}
+	type entry struct {
+		key   string
+		value int
+	}
+	// the keys of the even values are accepted.
+	even := func(key string, v interface{}) (entry, bool) {
+		return entry{key, v.(int)}, v.(int)%2 == 0
+	}
+	check := func(name string, got []entry, want map[string]interface{}) {
+		t.Helper()
+		m := map[string]interface{}{}
+		for _, e := range got {
+			m[e.key] = e.value
+		}
+		evens := map[string]interface{}{}
+		for key, v := range want {
+			if v.(int)%2 == 0 {
+				evens[key] = v
+			}
+		}
+		if len(got) != len(m) || !reflect.DeepEqual(m, evens) {
+			t.Errorf("%s = %v, want %v", name, got, want)
+		}
+	}
+	for _, key := range []string{"/interfaces/interface[name=1/2]", "/interfaces/interface[name=1/3]/state/", "", "/none"} {
+		check(fmt.Sprintf("CollectByPrefix(%q)", key), CollectByPrefix(trie, key, even), trie.FindByPrefixAll(key))
+	}
+	for _, key := range []string{"/interfaces/interface/state", "1/3]en", "", "/none"} {
+		check(fmt.Sprintf("CollectByFuzzy(%q)", key), CollectByFuzzy(trie, key, even), trie.FindByFuzzyAll(key))
+	}
+	for _, key := range []string{"/interfaces/interface[name=1/3]/state/counters/x", "/interfaces/interface[name=1/1]", "", "/none"} {
+		check(fmt.Sprintf("CollectMatchingPrefix(%q)", key), CollectMatchingPrefix(trie, key, even), trie.FindMatchingPrefixAll(key))
+	}
+
+	got := CollectMatchingPrefix(trie, "/interfaces/interface[name=1/2]/state", func(key string, v interface{}) (string, bool) {
+		return key, true
+	})
+	want := []string{"/interfaces", "/interfaces/interface", "/interfaces/interface[name=1/2]", "/interfaces/interface[name=1/2]/state"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CollectMatchingPrefix() = %v, want %v from the shortest", got, want)
+	}
+	keys := CollectByPrefix(trie, "/interfaces/interface[name=1/2]/state/", func(key string, v interface{}) (string, bool) {
+		return strings.TrimPrefix(key, "/interfaces/interface[name=1/2]/state/"), true
+	})
+	sort.Strings(keys)
+	if want := []string{"admin-status", "counters", "enabled", "oper-status"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("CollectByPrefix() = %v, want %v", keys, want)
+	}
+}
+
+type benchEntry struct {
+	key   string
+	value int
+}
+
+func newCollectBenchTrie() *Trie {
+	trie := New()
+	for i := 0; i < 100000; i++ {
+		trie.Add(fmt.Sprintf("/routes/%d/next-hop", i), i)
+	}
+	return trie
+}
+
+func BenchmarkCollectByPrefix(b *testing.B) {
+	trie := newCollectBenchTrie()
+	b.Run("FindByPrefixAll", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			m := trie.FindByPrefixAll("/routes/")
+			out := make([]benchEntry, 0, len(m))
+			for key, v := range m {
+				out = append(out, benchEntry{key, v.(int)})
+			}
+		}
+	})
+	b.Run("CollectByPrefix", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			CollectByPrefix(trie, "/routes/", func(key string, v interface{}) (benchEntry, bool) {
+				return benchEntry{key, v.(int)}, true
+			})
+		}
+	})
+}
@@ -0,0 +1,126 @@
+package gtrie
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ValueMismatch is a key present in both of the tries compared with different values.
+type ValueMismatch struct {
+	Key  string
+	A, B interface{}
+}
+
+// CompareReport is the difference of two tries reported by Compare.
+// The keys are in lexicographic order.
+type CompareReport struct {
+	// MissingInA is the keys present only in the trie `b`.
+	MissingInA []string
+	// MissingInB is the keys present only in the trie `a`.
+	MissingInB []string
+	// ValueMismatch is the keys present in both with different values.
+	ValueMismatch []ValueMismatch
+}
+
+// Equal reports whether the tries compared have the same keys and values.
+func (r *CompareReport) Equal() bool {
+	return len(r.MissingInA) == 0 && len(r.MissingInB) == 0 && len(r.ValueMismatch) == 0
+}
+
+// String returns the difference a line per key, e.g.
+//
+//	-/a/b (missing in b)
+//	+/a/c (missing in a)
+//	~/a/d: a=1 b=2
+//
+// It returns "" if the tries are equal.
+func (r *CompareReport) String() string {
+	var b strings.Builder
+	for _, key := range r.MissingInB {
+		fmt.Fprintf(&b, "-%s (missing in b)\n", key)
+	}
+	for _, key := range r.MissingInA {
+		fmt.Fprintf(&b, "+%s (missing in a)\n", key)
+	}
+	for _, m := range r.ValueMismatch {
+		fmt.Fprintf(&b, "~%s: a=%v b=%v\n", m.Key, m.A, m.B)
+	}
+	return b.String()
+}
+
+// CompareOption configures Compare.
+type CompareOption func(o *compareOptions)
+
+type compareOptions struct {
+	prefix string
+	equal  func(a, b interface{}) bool
+}
+
+// ComparePrefix restricts Compare to the keys starting with `prefix`.
+func ComparePrefix(prefix string) CompareOption {
+	return func(o *compareOptions) {
+		o.prefix = prefix
+	}
+}
+
+// CompareValues compares the values by `equal` instead of reflect.DeepEqual.
+func CompareValues(equal func(a, b interface{}) bool) CompareOption {
+	return func(o *compareOptions) {
+		o.equal = equal
+	}
+}
+
+// Compare returns the difference of the keys and values of the tries `a` and `b`,
+// e.g. for the readable failures of the tests:
+//
+//	if r := gtrie.Compare(got, want); !r.Equal() {
+//		t.Errorf("the trie differs:\n%s", r)
+//	}
+//
+// A nil trie is compared as an empty trie. The keys are compared as stored;
+// the prefix of ComparePrefix is converted by the key transform of each trie.
+// Both tries are read-locked in the address order during the comparison.
+func Compare(a, b *Trie, opts ...CompareOption) *CompareReport {
+	o := compareOptions{equal: reflect.DeepEqual}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if a == nil {
+		a = &Trie{}
+	}
+	if b == nil {
+		b = &Trie{}
+	}
+	defer rlockBoth(a, b)()
+	collect := func(t *Trie) map[string]interface{} {
+		node := findNode(t.root, t.canonical(o.prefix))
+		if node == nil {
+			return nil
+		}
+		return nodeMap(collectNodes(node))
+	}
+	am, bm := collect(a), collect(b)
+	r := &CompareReport{}
+	for key, av := range am {
+		bv, ok := bm[key]
+		switch {
+		case !ok:
+			r.MissingInB = append(r.MissingInB, key)
+		case !o.equal(av, bv):
+			r.ValueMismatch = append(r.ValueMismatch, ValueMismatch{Key: key, A: av, B: bv})
+		}
+	}
+	for key := range bm {
+		if _, ok := am[key]; !ok {
+			r.MissingInA = append(r.MissingInA, key)
+		}
+	}
+	sort.Strings(r.MissingInA)
+	sort.Strings(r.MissingInB)
+	sort.Slice(r.ValueMismatch, func(i, j int) bool {
+		return r.ValueMismatch[i].Key < r.ValueMismatch[j].Key
+	})
+	return r
+}
@@ -0,0 +1,107 @@
+package gtrie
+
+import (
+	"math/rand"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/neoul/gtrie/gtrietest"
+)
+
+func TestCompare(t *testing.T) {
+	a := newTrieOf(map[string]interface{}{"/a": 1, "/a/b": 2, "/a/c": 3, "/x": 4})
+	b := newTrieOf(map[string]interface{}{"/a": 1, "/a/b": 20, "/a/d": 4, "/y": 5})
+	r := Compare(a, b)
+	want := &CompareReport{
+		MissingInA:    []string{"/a/d", "/y"},
+		MissingInB:    []string{"/a/c", "/x"},
+		ValueMismatch: []ValueMismatch{{Key: "/a/b", A: 2, B: 20}},
+	}
+	if !reflect.DeepEqual(r, want) || r.Equal() {
+		t.Errorf("Compare() = %+v, want %+v", r, want)
+	}
+	if got, want := r.String(), "-/a/c (missing in b)\n-/x (missing in b)\n+/a/d (missing in a)\n+/y (missing in a)\n~/a/b: a=2 b=20\n"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	r = Compare(a, b, ComparePrefix("/a/"), CompareValues(func(a, b interface{}) bool { return true }))
+	if !reflect.DeepEqual(r.MissingInA, []string{"/a/d"}) || !reflect.DeepEqual(r.MissingInB, []string{"/a/c"}) || len(r.ValueMismatch) != 0 {
+		t.Errorf("Compare() with the options = %+v", r)
+	}
+	if r := Compare(a, a); !r.Equal() || r.String() != "" {
+		t.Errorf("Compare() of the same trie = %+v", r)
+	}
+	if r := Compare(nil, a); len(r.MissingInA) != 4 {
+		t.Errorf("Compare(nil) = %+v", r)
+	}
+	if r := Compare(nil, nil); !r.Equal() {
+		t.Errorf("Compare(nil, nil) = %+v", r)
+	}
+}
+
+// firstElement returns the first path element of the key with the delimiters.
+func firstElement(key string) string {
+	if i := strings.IndexByte(key[1:], '/'); i >= 0 {
+		return key[:i+2]
+	}
+	return key + "/"
+}
+
+func reversed(keys []string) []string {
+	keys = slices.Clone(keys)
+	slices.Reverse(keys)
+	return keys
+}
+
+// TestTrie_RandomOps applies random mutations to the tries of the options
+// and the model, and compares them by the lookups and the node invariants.
+func TestTrie_RandomOps(t *testing.T) {
+	keys := gtrietest.GeneratePathKeys(80, 4, 3, 1)
+	for name, opts := range map[string][]Option{
+		"plain":    nil,
+		"atomic":   {WithAtomicReads()},
+		"arena":    {WithArena(16)},
+		"sorted":   {WithSortedChildren()},
+		"interned": {WithSegmentInterning('/')},
+		"ordered":  {WithInsertionOrder()},
+	} {
+		r := rand.New(rand.NewSource(1))
+		trie, model := New(opts...), gtrietest.NewModelTrie()
+		gtrietest.LoadKeys(trie, keys[:20], -1)
+		gtrietest.LoadKeys(model, keys[:20], -1)
+		for i := 0; i < 3000; i++ {
+			switch op := r.Intn(10); {
+			case op < 6:
+				k := keys[r.Intn(len(keys))]
+				trie.Add(k, i)
+				model.Add(k, i)
+			case op < 9:
+				k := keys[r.Intn(len(keys))]
+				trie.Remove(k)
+				model.Remove(k)
+			default:
+				src, dst := firstElement(keys[r.Intn(len(keys))]), firstElement(keys[r.Intn(len(keys))])
+				trie.CopyPrefix(src, dst)
+				model.CopyPrefix(src, dst)
+			}
+			if i%100 == 0 {
+				trie.Compact(0.1)
+			}
+		}
+		if report := Compare(trie, newTrieOf(model.All())); !report.Equal() {
+			t.Errorf("%s: the trie differs from the model:\n%s", name, report)
+		}
+		for _, key := range keys[:20] {
+			prefix := firstElement(key)
+			if got, want := trie.FindByPrefixDesc(prefix), model.FindByPrefix(prefix); !reflect.DeepEqual(got, reversed(want)) {
+				t.Errorf("%s: FindByPrefixDesc(%q) = %v, want %v", name, prefix, got, want)
+			}
+			if got, want := trie.FindByFuzzyAll(prefix), model.FindByFuzzyAll(prefix); len(got)+len(want) > 0 && !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: FindByFuzzyAll(%q) = %v, want %v", name, prefix, got, want)
+			}
+		}
+		checkNodes(t, trie.root, !trie.atomicReads)
+	}
+}
@@ -0,0 +1,53 @@
+package gtrie
+
+// The methods in this file keep the method names of derekparker/trie
+// (https://godoc.org/github.com/derekparker/trie) for drop-in migration.
+// Keys(prefix ...string) and All(prefix ...string) also accept the prefix
+// argument of derekparker/trie.
+
+// PrefixSearch returns all the keys starting with `pre`.
+// It is the same as FindByPrefix.
+func (t *Trie) PrefixSearch(pre string) []string {
+	return t.FindByPrefix(pre)
+}
+
+// FuzzySearch performs a fuzzy search against the keys in the trie.
+// It is the same as FindByFuzzy.
+func (t *Trie) FuzzySearch(partial string) []string {
+	return t.FindByFuzzy(partial)
+}
+
+// FindLongestMatchedKey returns the longest matched prefix key of the input `key`.
+// It is the same as FindLongestMatchingPrefix without the value.
+func (t *Trie) FindLongestMatchedKey(key string) (string, bool) {
+	k, _, ok := t.FindLongestMatchingPrefix(key)
+	return k, ok
+}
+
+// FindMatchedKey returns all the matched prefix keys of the input `key`.
+// It is the same as FindMatchingPrefix.
+func (t *Trie) FindMatchedKey(key string) ([]string, bool) {
+	return t.FindMatchingPrefix(key)
+}
+
+// The methods below are the names of the former gtrie/ wrapper package
+// that delegated to github.com/neoul/trie. They are provided by the root Trie
+// so that the wrapper is no longer needed.
+
+// FindLongestMatched returns the longest matched prefix key of the input `key`
+// and its value. It is the same as FindLongestMatchingPrefix.
+func (t *Trie) FindLongestMatched(key string) (string, interface{}, bool) {
+	return t.FindLongestMatchingPrefix(key)
+}
+
+// FindMatched returns all the matched prefix keys of the input `key`.
+// It is the same as FindMatchingPrefix.
+func (t *Trie) FindMatched(key string) ([]string, bool) {
+	return t.FindMatchingPrefix(key)
+}
+
+// HasKeysWithPrefix returns true if any of the keys in the trie starts with `key`.
+// It is the same as HasPrefix.
+func (t *Trie) HasKeysWithPrefix(key string) bool {
+	return t.HasPrefix(key)
+}
@@ -0,0 +1,69 @@
+package gtrie
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTrie_Compat(t *testing.T) {
+	trie := newGNMITrie()
+	prefix := "/interfaces/interface[name=1/2]"
+	key := "/interfaces/interface[name=1/2]/state/unknown"
+
+	if got, want := sortedKeys(trie.Keys(prefix)), sortedKeys(trie.FindByPrefix(prefix)); !reflect.DeepEqual(got, want) {
+		t.Errorf("Keys(%q) = %v, want %v", prefix, got, want)
+	}
+	if got, want := sortedKeys(trie.Keys()), sortedKeys(trie.FindByPrefix("")); !reflect.DeepEqual(got, want) {
+		t.Errorf("Keys() = %v, want %v", got, want)
+	}
+	if got, want := sortedKeys(trie.PrefixSearch(prefix)), sortedKeys(trie.FindByPrefix(prefix)); !reflect.DeepEqual(got, want) {
+		t.Errorf("PrefixSearch(%q) = %v, want %v", prefix, got, want)
+	}
+	if got, want := trie.FuzzySearch("1/3state"), trie.FindByFuzzy("1/3state"); len(got) == 0 || !reflect.DeepEqual(sortedKeys(got), sortedKeys(want)) {
+		t.Errorf("FuzzySearch() = %v, want %v", got, want)
+	}
+	if got, ok := trie.FindLongestMatchedKey(key); !ok || got != "/interfaces/interface[name=1/2]/state" {
+		t.Errorf("FindLongestMatchedKey(%q) = %q, %v", key, got, ok)
+	}
+	if got, ok := trie.FindLongestMatchedKey("/none"); ok || got != "" {
+		t.Errorf("FindLongestMatchedKey() = %q, %v, want \"\", false", got, ok)
+	}
+	got, ok := trie.FindMatchedKey(key)
+	want, _ := trie.FindMatchingPrefix(key)
+	if !ok || !reflect.DeepEqual(got, want) {
+		t.Errorf("FindMatchedKey(%q) = %v, %v, want %v", key, got, ok, want)
+	}
+	if got, want := trie.All(prefix), trie.FindByPrefixAll(prefix); !reflect.DeepEqual(got, want) {
+		t.Errorf("All(%q) = %v, want %v", prefix, got, want)
+	}
+	if got := trie.All(); len(got) != trie.Size() {
+		t.Errorf("All() = %d entries, want %d", len(got), trie.Size())
+	}
+	if got := trie.All("/none"); got != nil {
+		t.Errorf("All(\"/none\") = %v, want nil", got)
+	}
+}
+
+func TestTrie_WrapperCompat(t *testing.T) {
+	trie := newGNMITrie()
+	for _, key := range []string{
+		"/interfaces/interface[name=1/3]/state/absss",
+		"/interfaces/interface/state",
+		"/interfaces",
+		"/none",
+	} {
+		k1, v1, ok1 := trie.FindLongestMatched(key)
+		k2, v2, ok2 := trie.FindLongestMatchingPrefix(key)
+		if k1 != k2 || v1 != v2 || ok1 != ok2 {
+			t.Errorf("FindLongestMatched(%q) = %q, %v, %v, want %q, %v, %v", key, k1, v1, ok1, k2, v2, ok2)
+		}
+		m1, ok1 := trie.FindMatched(key)
+		m2, ok2 := trie.FindMatchingPrefix(key)
+		if !reflect.DeepEqual(m1, m2) || ok1 != ok2 {
+			t.Errorf("FindMatched(%q) = %v, %v, want %v, %v", key, m1, ok1, m2, ok2)
+		}
+		if trie.HasKeysWithPrefix(key) != trie.HasPrefix(key) {
+			t.Errorf("HasKeysWithPrefix(%q) != HasPrefix(%q)", key, key)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package gtrie
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// concurrentKeys returns the keys of the writer `w`, which are prefixes of
+// each other and share the prefixes with the keys of the other writers.
+func concurrentKeys(w, n int) []string {
+	keys := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		keys = append(keys, fmt.Sprintf("/if/%d/w%d%s", i%5, w, strings.Repeat("/x", i/5)))
+	}
+	return keys
+}
+
+// generations records the values written by the writers. Every value written
+// is a unique generation; the keys of a writer are written only by the writer,
+// so that the generations of a key increase.
+type generations struct {
+	next   atomic.Int64
+	issued sync.Map // generation -> key
+	// stale is the greatest generation of each key not valid any more, i.e.
+	// replaced or removed by a write returned.
+	stale sync.Map // key -> *atomic.Int64
+}
+
+func (g *generations) issue(key string) int64 {
+	gen := g.next.Add(1)
+	g.issued.Store(gen, key)
+	return gen
+}
+
+func (g *generations) floor(key string) *atomic.Int64 {
+	v, _ := g.stale.LoadOrStore(key, new(atomic.Int64))
+	return v.(*atomic.Int64)
+}
+
+// check reports the error if `v` found for the `key` was not written to the key
+// or was already stale at `floor` loaded before the read.
+func (g *generations) check(key string, v interface{}, floor int64) error {
+	gen, ok := v.(int64)
+	if !ok {
+		return fmt.Errorf("%q has the value %v not written", key, v)
+	}
+	if k, ok := g.issued.Load(gen); !ok || k != key {
+		return fmt.Errorf("%q has the generation %d written to %v", key, gen, k)
+	}
+	if gen <= floor {
+		return fmt.Errorf("%q has the generation %d stale at %d", key, gen, floor)
+	}
+	return nil
+}
+
+func TestTrie_Concurrent(t *testing.T) {
+	const (
+		writers = 4
+		readers = 4
+		ops     = 300
+		reads   = 200
+	)
+	modes := map[string][]Option{
+		"plain":      nil,
+		"atomic":     {WithAtomicReads()},
+		"timestamps": {WithTimestamps(), WithSortedChildren(), WithInsertionOrder()},
+		"prefix":     {WithPrefixLocks('/'), WithSortedChildren()},
+	}
+	for name, opts := range modes {
+		t.Run(name, func(t *testing.T) {
+			trie := New(opts...)
+			gens := &generations{}
+			var (
+				wg      sync.WaitGroup
+				done    atomic.Bool
+				errs    = make(chan error, writers+readers)
+				writing sync.WaitGroup
+			)
+			report := func(err error) {
+				select {
+				case errs <- err:
+				default:
+				}
+			}
+			for w := 0; w < writers; w++ {
+				writing.Add(1)
+				go func(w int) {
+					defer writing.Done()
+					keys := concurrentKeys(w, 20)
+					for i := 0; i < ops; i++ {
+						key := keys[(i*7)%len(keys)]
+						floor := gens.floor(key)
+						switch i % 4 {
+						case 3:
+							if v := trie.Remove(key); v != nil {
+								floor.Store(v.(int64))
+							}
+						case 2:
+							// the keys shared by the writers are checked by their keys only.
+							trie.Add(fmt.Sprintf("/shared/%d", i%10), gens.issue(fmt.Sprintf("/shared/%d", i%10)))
+							fallthrough
+						default:
+							gen := gens.issue(key)
+							trie.Add(key, gen)
+							floor.Store(gen - 1)
+						}
+					}
+				}(w)
+			}
+			for r := 0; r < readers; r++ {
+				wg.Add(1)
+				go func(r int) {
+					defer wg.Done()
+					keys := concurrentKeys(r%writers, 20)
+					for i := 0; i < reads || !done.Load(); i++ {
+						// yield to the writers waiting for the lock on a single CPU.
+						runtime.Gosched()
+						key := keys[i%len(keys)]
+						floor := gens.floor(key).Load()
+						if v, ok := trie.Find(key); ok {
+							if err := gens.check(key, v, floor); err != nil {
+								report(err)
+								return
+							}
+						}
+						for k, v := range trie.FindByPrefixAll("/if/" + key[4:5]) {
+							if err := gens.check(k, v, 0); err != nil {
+								report(err)
+								return
+							}
+						}
+						if k, v, ok := trie.FindLongestMatchingPrefix(key + "/y"); ok {
+							if err := gens.check(k, v, 0); err != nil || !strings.HasPrefix(key+"/y", k) {
+								report(fmt.Errorf("FindLongestMatchingPrefix(%q) = %q: %v", key+"/y", k, err))
+								return
+							}
+						}
+						if i%8 != 0 {
+							continue
+						}
+						// the walks over the whole trie are mixed in less often.
+						for _, k := range trie.FindByFuzzy("w" + key[7:8]) {
+							if !strings.Contains(k, "w") {
+								report(fmt.Errorf("FindByFuzzy() = %q", k))
+								return
+							}
+						}
+						trie.FindRelativeAll(key)
+						trie.FindRelativeValues(key)
+						trie.FindMatchingPrefixAll(key)
+						trie.Search("/if/", SearchByPrefix, Offset(1), MaxResults(3))
+						trie.SearchValues("x", SearchApproximate, Sorted(), MaxResults(2))
+						trie.CountWhere("/if/", func(string, interface{}) bool { return true })
+						trie.NextPage(NewResumeToken("/shared/", MaxResults(2)))
+						trie.Successor(key)
+						trie.GroupByValue("/shared/", nil)
+						trie.FindWithMeta(key)
+						trie.Size()
+					}
+				}(r)
+			}
+			writing.Wait()
+			done.Store(true)
+			wg.Wait()
+			close(errs)
+			for err := range errs {
+				t.Error(err)
+			}
+			if got := len(trie.Keys()); got != trie.Size() {
+				t.Errorf("Keys() = %d keys, want Size() %d", got, trie.Size())
+			}
+			checkNodes(t, trie.root, name != "atomic")
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package gtrie
+
+import (
+	"context"
+	"sort"
+)
+
+// cancelCheckInterval is the number of the nodes visited between
+// the checks of the context cancellation.
+const cancelCheckInterval = 1024
+
+// canceler checks the cancellation of a context periodically
+// while the trie is traversed. A nil canceler is never canceled.
+type canceler struct {
+	ctx     context.Context
+	visited int
+	err     error
+}
+
+func newCanceler(ctx context.Context) *canceler {
+	return &canceler{ctx: ctx}
+}
+
+// canceled returns true if the context is canceled.
+// The context is checked at the first call and every cancelCheckInterval calls.
+func (c *canceler) canceled() bool {
+	if c == nil {
+		return false
+	}
+	if c.err != nil {
+		return true
+	}
+	if c.visited%cancelCheckInterval == 0 {
+		c.err = c.ctx.Err()
+	}
+	c.visited++
+	return c.err != nil
+}
+
+// FindByPrefixCtx is FindByPrefix that can be canceled by `ctx`.
+// If `ctx` is done during the search, the keys found so far are returned with ctx.Err().
+func (t *Trie) FindByPrefixCtx(ctx context.Context, prefix string) ([]string, error) {
+	if t == nil {
+		return nil, nil
+	}
+	t.rlock()
+	defer t.runlock()
+	c := newCanceler(ctx)
+	if c.canceled() {
+		return nil, c.err
+	}
+	node := findNode(t.root, t.canonical(prefix))
+	if node == nil {
+		return nil, nil
+	}
+	return nodeKeys(collectNodesCtx(node, c)), c.err
+}
+
+// FindByFuzzyCtx is FindByFuzzy that can be canceled by `ctx`.
+// If `ctx` is done during the search, the keys found so far are returned with ctx.Err().
+func (t *Trie) FindByFuzzyCtx(ctx context.Context, key string) ([]string, error) {
+	if t == nil {
+		return nil, nil
+	}
+	t.rlock()
+	defer t.runlock()
+	c := newCanceler(ctx)
+	if c.canceled() {
+		return nil, c.err
+	}
+	keys := nodeKeys(fuzzycollectNodesCtx(t.root, t.runes(key), false, c))
+	sort.Sort(byKeys(keys))
+	return keys, c.err
+}
+
+// FindRelativeAllCtx is FindRelativeAll that can be canceled by `ctx`.
+// If `ctx` is done during the search, the keys and values found so far are returned with ctx.Err().
+func (t *Trie) FindRelativeAllCtx(ctx context.Context, key string) (map[string]interface{}, error) {
+	if t == nil {
+		return nil, nil
+	}
+	t.rlock()
+	defer t.runlock()
+	c := newCanceler(ctx)
+	if c.canceled() {
+		return nil, c.err
+	}
+	key = t.canonical(key)
+	runes := []rune(key)
+	var terms []*trieNode
+	if node := findNode(t.root, key); node != nil {
+		terms = collectNodesCtx(node, c)
+	}
+	if !c.canceled() {
+		terms = append(terms, matchingprefixcollect(t.root, runes, false)...)
+	}
+	if !c.canceled() {
+		terms = append(terms, fuzzycollectNodesCtx(t.root, runes, false, c)...)
+	}
+	return nodeMap(terms), c.err
+}
@@ -0,0 +1,106 @@
+package gtrie
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// countdownContext is a context that is canceled after Err() is called `n` times.
+type countdownContext struct {
+	context.Context
+	mu sync.Mutex
+	n  int
+}
+
+func (c *countdownContext) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.n <= 0 {
+		return context.Canceled
+	}
+	c.n--
+	return nil
+}
+
+func newLargeTrie(n int) *Trie {
+	trie := New()
+	for i := 0; i < n; i++ {
+		trie.Add("/key/"+strconv.Itoa(i), i)
+	}
+	return trie
+}
+
+func TestTrie_CtxCanceled(t *testing.T) {
+	trie := newLargeTrie(100)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if keys, err := trie.FindByPrefixCtx(ctx, "/key"); len(keys) != 0 || !errors.Is(err, context.Canceled) {
+		t.Errorf("FindByPrefixCtx() = %d keys, %v", len(keys), err)
+	}
+	if keys, err := trie.FindByFuzzyCtx(ctx, "k1"); len(keys) != 0 || !errors.Is(err, context.Canceled) {
+		t.Errorf("FindByFuzzyCtx() = %d keys, %v", len(keys), err)
+	}
+	if m, err := trie.FindRelativeAllCtx(ctx, "/key/1"); len(m) != 0 || !errors.Is(err, context.Canceled) {
+		t.Errorf("FindRelativeAllCtx() = %d keys, %v", len(m), err)
+	}
+}
+
+func TestTrie_CtxMidTraversal(t *testing.T) {
+	trie := newLargeTrie(10000)
+	ctx := &countdownContext{Context: context.Background(), n: 2}
+	keys, err := trie.FindByPrefixCtx(ctx, "/key")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("FindByPrefixCtx() error = %v, want %v", err, context.Canceled)
+	}
+	if len(keys) == 0 || len(keys) >= trie.Size() {
+		t.Errorf("FindByPrefixCtx() = %d keys, want a partial result", len(keys))
+	}
+
+	ctx = &countdownContext{Context: context.Background(), n: 2}
+	keys, err = trie.FindByFuzzyCtx(ctx, "k")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("FindByFuzzyCtx() error = %v, want %v", err, context.Canceled)
+	}
+	if len(keys) == 0 || len(keys) >= trie.Size() {
+		t.Errorf("FindByFuzzyCtx() = %d keys, want a partial result", len(keys))
+	}
+
+	ctx = &countdownContext{Context: context.Background(), n: 2}
+	m, err := trie.FindRelativeAllCtx(ctx, "/key/")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("FindRelativeAllCtx() error = %v, want %v", err, context.Canceled)
+	}
+	if len(m) == 0 || len(m) >= trie.Size() {
+		t.Errorf("FindRelativeAllCtx() = %d keys, want a partial result", len(m))
+	}
+}
+
+func TestTrie_CtxDeadline(t *testing.T) {
+	trie := newLargeTrie(10000)
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	if _, err := trie.FindByPrefixCtx(ctx, ""); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("FindByPrefixCtx() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	keys, err := trie.FindByPrefixCtx(ctx, "/key/1")
+	if err != nil {
+		t.Fatalf("FindByPrefixCtx() error = %v", err)
+	}
+	if want := trie.FindByPrefix("/key/1"); len(keys) != len(want) {
+		t.Errorf("FindByPrefixCtx() = %d keys, want %d", len(keys), len(want))
+	}
+	m, err := trie.FindRelativeAllCtx(ctx, "/key/1")
+	if err != nil {
+		t.Fatalf("FindRelativeAllCtx() error = %v", err)
+	}
+	if want := trie.FindRelativeAll("/key/1"); len(m) != len(want) {
+		t.Errorf("FindRelativeAllCtx() = %d keys, want %d", len(m), len(want))
+	}
+}
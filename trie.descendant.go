@@ -0,0 +1,105 @@
+package gtrie
+
+import (
+	"time"
+	"unicode/utf8"
+)
+
+// FindNearestDescendant finds the shortest key starting with `prefix`, i.e.
+// the nearest terminal under the node of `prefix` including the `prefix` itself,
+// and returns the key and its value. The keys of the same length are broken
+// by the lexicographic order. It is the inverse of FindLongestMatchingPrefix,
+// e.g. for the first concrete path under a subtree.
+func (t *Trie) FindNearestDescendant(prefix string) (string, interface{}, bool) {
+	if t == nil {
+		return "", nil, false
+	}
+	root := t.readRoot()
+	defer t.readDone()
+	node := findNode(root, t.canonical(prefix))
+	if node == nil || node.termCount <= 0 {
+		return "", nil, false
+	}
+	// the nodes are visited level by level; the first level having
+	// terminals has the shortest keys.
+	level := []*trieNode{node}
+	for len(level) > 0 {
+		var best *trieNode
+		var next []*trieNode
+		for _, n := range level {
+			for r, c := range n.children {
+				if r != nul {
+					if c.termCount > 0 {
+						next = append(next, c)
+					}
+					continue
+				}
+				if c.term && (best == nil || c.key() < best.key()) {
+					best = c
+				}
+			}
+		}
+		if best != nil {
+			return best.key(), best.value, true
+		}
+		level = next
+	}
+	return "", nil, false
+}
+
+// FindDescendants finds all the keys starting with `prefix` except the `prefix`
+// itself, i.e. the strict descendants of `prefix`. It is FindByPrefix
+// skipping the key equal to `prefix` if stored.
+func (t *Trie) FindDescendants(prefix string) []string {
+	if t == nil {
+		return nil
+	}
+	root := t.readRoot()
+	defer t.readDone()
+	if t.metrics != nil {
+		defer t.observe(MetricSearchPrefix, time.Now())
+	}
+	node := findNode(root, t.canonical(prefix))
+	if node == nil {
+		return nil
+	}
+	var keys []string
+	for r, c := range node.children {
+		// the end of the `prefix` is the `prefix` itself.
+		if r != nul {
+			keys = appendKeys(keys, c)
+		}
+	}
+	return keys
+}
+
+// FindAncestors finds all the keys that are a prefix of `key` except the `key`
+// itself, i.e. the strict ancestors of `key`. It is FindMatchingPrefix
+// skipping the key equal to `key` if stored. The keys are returned from the shortest.
+func (t *Trie) FindAncestors(key string) []string {
+	if t == nil {
+		return nil
+	}
+	root := t.readRoot()
+	defer t.readDone()
+	if t.metrics != nil {
+		defer t.observe(MetricSearchMatchingPrefix, time.Now())
+	}
+	var keys []string
+	key = t.canonical(key)
+	node := root
+	for i := 0; i < len(key); {
+		r, size := utf8.DecodeRuneInString(key[i:])
+		i += size
+		n, ok := node.children[r]
+		// the terminal at the last rune is the `key` itself.
+		if !ok || i == len(key) {
+			break
+		}
+		if term, ok := n.children[nul]; ok && term.term {
+			keys = append(keys, term.key())
+		}
+		node = n
+	}
+	return keys
+}
@@ -0,0 +1,103 @@
+package gtrie
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTrie_FindNearestDescendant(t *testing.T) {
+	trie := newGNMITrie()
+	tests := []struct {
+		prefix string
+		want   string
+		ok     bool
+	}{
+		{"/interfaces/interface[name=1/3]", "/interfaces/interface[name=1/3]", true},
+		{"/interfaces/interface[name=1/", "/interfaces/interface[name=1/2]", true},
+		{"/interfaces/interface[name=1/1]", "/interfaces/interface[name=1/1]/state/enabled", true},
+		{"/interfaces/interface[name=1/2]/state/", "/interfaces/interface[name=1/2]/state/enabled", true},
+		{"", "/interfaces", true},
+		{"/none", "", false},
+	}
+	for _, tt := range tests {
+		key, v, ok := trie.FindNearestDescendant(tt.prefix)
+		if key != tt.want || ok != tt.ok {
+			t.Errorf("FindNearestDescendant(%q) = %q, %v, want %q, %v", tt.prefix, key, ok, tt.want, tt.ok)
+		}
+		if want, _ := trie.Find(tt.want); ok && v != want {
+			t.Errorf("FindNearestDescendant(%q) = %v, want the value %v", tt.prefix, v, want)
+		}
+	}
+
+	trie.Remove("/interfaces/interface[name=1/3]")
+	if key, _, _ := trie.FindNearestDescendant("/interfaces/interface[name=1/3]"); key != "/interfaces/interface[name=1/3]/state" {
+		t.Errorf("FindNearestDescendant() of the key removed = %q, want its state child", key)
+	}
+	trie.Add("", "root")
+	if key, v, ok := trie.FindNearestDescendant(""); key != "" || v != "root" || !ok {
+		t.Errorf("FindNearestDescendant() of the empty key = %q, %v, %v", key, v, ok)
+	}
+}
+
+func TestTrie_FindDescendants(t *testing.T) {
+	trie := newGNMITrie()
+	tests := []struct {
+		prefix string
+		want   []string
+	}{
+		// the prefix stored is excluded.
+		{"/interfaces/interface[name=1/2]/state", []string{
+			"/interfaces/interface[name=1/2]/state/admin-status",
+			"/interfaces/interface[name=1/2]/state/counters",
+			"/interfaces/interface[name=1/2]/state/enabled",
+			"/interfaces/interface[name=1/2]/state/oper-status",
+		}},
+		// the prefix not stored is the same as FindByPrefix.
+		{"/interfaces/interface[name=1/1]", []string{"/interfaces/interface[name=1/1]/state/enabled"}},
+		{"/interfaces/interface[name=1/2]/state/counters", nil},
+		{"/none", nil},
+	}
+	for _, tt := range tests {
+		if got := sortedKeys(trie.FindDescendants(tt.prefix)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("FindDescendants(%q) = %v, want %v", tt.prefix, got, tt.want)
+		}
+	}
+	if got, want := len(trie.FindDescendants("")), trie.Size(); got != want {
+		t.Errorf("FindDescendants() of the empty prefix = %d keys, want %d", got, want)
+	}
+	trie.Add("", true)
+	if got, want := len(trie.FindDescendants("")), trie.Size()-1; got != want {
+		t.Errorf("FindDescendants() of the empty prefix stored = %d keys, want %d", got, want)
+	}
+}
+
+func TestTrie_FindAncestors(t *testing.T) {
+	trie := newGNMITrie()
+	tests := []struct {
+		key  string
+		want []string
+	}{
+		// the key stored is excluded.
+		{"/interfaces/interface[name=1/2]/state", []string{
+			"/interfaces",
+			"/interfaces/interface",
+			"/interfaces/interface[name=1/2]",
+		}},
+		// the key not stored is the same as FindMatchingPrefix.
+		{"/interfaces/interface[name=1/1]/state", []string{"/interfaces", "/interfaces/interface"}},
+		{"/interfaces", nil},
+		{"/none", nil},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := trie.FindAncestors(tt.key); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("FindAncestors(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+		if _, ok := trie.Find(tt.key); !ok {
+			want, _ := trie.FindMatchingPrefix(tt.key)
+			if got := trie.FindAncestors(tt.key); !reflect.DeepEqual(got, want) {
+				t.Errorf("FindAncestors(%q) = %v, want FindMatchingPrefix() %v", tt.key, got, want)
+			}
+		}
+	}
+}
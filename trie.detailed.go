@@ -0,0 +1,79 @@
+package gtrie
+
+import "sort"
+
+// SearchResult is a key found by SearchDetailed with the search that found it.
+type SearchResult struct {
+	Key   string
+	Value interface{}
+	// Source is the search that found the key. For SearchAllRelativeKey,
+	// it is the first of SearchByPrefix, SearchMatcingPrefix and
+	// SearchApproximate that found the key.
+	Source SearchType
+	// Sources is the sub-searches of SearchAllRelativeKey that found the key,
+	// or zero for the other searches.
+	Sources RelativeSource
+	// MatchLen is the number of the leading runes of the key matched to
+	// the input by SearchExactly, SearchByPrefix, SearchLongestMatchingPrefix
+	// and SearchMatcingPrefix, i.e. the runes of the input for a key starting
+	// with it and the runes of the key for a prefix of the input. It is zero
+	// for the other searches. The runes are of the canonical forms with
+	// a key transform.
+	MatchLen int
+}
+
+// SearchDetailed finds all matching keys according to stype (SearchType) as
+// SearchAll and returns them in lexicographic order, each with the search that
+// found it. The keys of SearchAllRelativeKey are labeled with all the sub-searches
+// that found them as FindRelativeResults instead of merged into a map.
+// It returns nil for an unsupported stype.
+func (t *Trie) SearchDetailed(key string, stype SearchType) []SearchResult {
+	if t == nil {
+		return nil
+	}
+	t.rlock()
+	defer t.runlock()
+	runes := t.runes(key)
+	var results []SearchResult
+	if stype == SearchAllRelativeKey {
+		found := relativecollect(t.root, runes)
+		results = make([]SearchResult, 0, len(found))
+		for n, src := range found {
+			r := SearchResult{Key: n.key(), Value: n.value, Source: src.first(), Sources: src}
+			if src&(RelativeByPrefix|RelativeMatchingPrefix) != 0 {
+				r.MatchLen = min(len(runes), n.depth-1)
+			}
+			results = append(results, r)
+		}
+	} else {
+		nodes, err := t.searchNodes(key, stype, &searchOptions{})
+		if err != nil {
+			return nil
+		}
+		results = make([]SearchResult, 0, len(nodes))
+		for _, n := range nodes {
+			r := SearchResult{Key: n.key(), Value: n.value, Source: stype}
+			switch stype {
+			case SearchExactly, SearchByPrefix, SearchLongestMatchingPrefix, SearchMatcingPrefix:
+				r.MatchLen = min(len(runes), n.depth-1)
+			}
+			results = append(results, r)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Key < results[j].Key
+	})
+	return results
+}
+
+// first returns the SearchType of the first sub-search in `s`.
+func (s RelativeSource) first() SearchType {
+	switch {
+	case s.Has(RelativeByPrefix):
+		return SearchByPrefix
+	case s.Has(RelativeMatchingPrefix):
+		return SearchMatcingPrefix
+	default:
+		return SearchApproximate
+	}
+}
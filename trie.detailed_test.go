@@ -0,0 +1,98 @@
+package gtrie
+
+import (
+	"testing"
+)
+
+func TestTrie_SearchDetailed(t *testing.T) {
+	const (
+		p = RelativeByPrefix
+		m = RelativeMatchingPrefix
+		a = RelativeApproximate
+	)
+	trie := newGNMITrie()
+	query := "/interfaces/interface[name=1/2]"
+	tests := []struct {
+		key   string
+		stype SearchType
+		want  map[string]SearchResult
+	}{
+		{
+			key:   query,
+			stype: SearchAllRelativeKey,
+			want: map[string]SearchResult{
+				"/interfaces":                          {Source: SearchMatcingPrefix, Sources: m, MatchLen: len("/interfaces")},
+				"/interfaces/interface":                {Source: SearchMatcingPrefix, Sources: m, MatchLen: len("/interfaces/interface")},
+				query:                                  {Source: SearchByPrefix, Sources: p | m | a, MatchLen: len(query)},
+				query + "/state/counters":              {Source: SearchByPrefix, Sources: p | a, MatchLen: len(query)},
+				"/interfaces/interface/state/counters": {},
+			},
+		},
+		{
+			key:   "/interfaces/interface/state",
+			stype: SearchAllRelativeKey,
+			want: map[string]SearchResult{
+				"/interfaces/interface":                         {Source: SearchMatcingPrefix, Sources: m, MatchLen: len("/interfaces/interface")},
+				"/interfaces/interface/state/counters":          {Source: SearchByPrefix, Sources: p | a, MatchLen: len("/interfaces/interface/state")},
+				"/interfaces/interface[name=1/1]/state/enabled": {Source: SearchApproximate, Sources: a},
+			},
+		},
+		{
+			key:   query + "/state/oper",
+			stype: SearchLongestMatchingPrefix,
+			want: map[string]SearchResult{
+				query + "/state": {Source: SearchLongestMatchingPrefix, MatchLen: len(query + "/state")},
+			},
+		},
+		{
+			key:   query + "/state/",
+			stype: SearchByPrefix,
+			want: map[string]SearchResult{
+				query + "/state/enabled": {Source: SearchByPrefix, MatchLen: len(query + "/state/")},
+				query + "/state":         {},
+			},
+		},
+		{
+			key:   "1/3counters",
+			stype: SearchApproximate,
+			want: map[string]SearchResult{
+				"/interfaces/interface[name=1/3]/state/counters": {Source: SearchApproximate},
+				"/interfaces/interface[name=1/2]/state/counters": {},
+			},
+		},
+	}
+	for _, tt := range tests {
+		results := trie.SearchDetailed(tt.key, tt.stype)
+		all := trie.SearchAll(tt.key, tt.stype)
+		if len(results) != len(all) {
+			t.Errorf("SearchDetailed(%q, %s) = %d results, want %d of SearchAll", tt.key, tt.stype, len(results), len(all))
+		}
+		got := make(map[string]SearchResult, len(results))
+		for i, r := range results {
+			if i > 0 && results[i-1].Key >= r.Key {
+				t.Errorf("SearchDetailed(%q, %s) not sorted at %q", tt.key, tt.stype, r.Key)
+			}
+			if _, ok := all[r.Key]; !ok || r.Value != true {
+				t.Errorf("SearchDetailed(%q, %s) has %q: %v not in SearchAll", tt.key, tt.stype, r.Key, r.Value)
+			}
+			got[r.Key] = r
+		}
+		for key, want := range tt.want {
+			r, ok := got[key]
+			if want == (SearchResult{}) {
+				// the zero result marks the key not found.
+				if ok {
+					t.Errorf("SearchDetailed(%q, %s) has %q", tt.key, tt.stype, key)
+				}
+				continue
+			}
+			want.Key, want.Value = key, true
+			if !ok || r != want {
+				t.Errorf("SearchDetailed(%q, %s)[%q] = %+v, want %+v", tt.key, tt.stype, key, r, want)
+			}
+		}
+	}
+	if results := trie.SearchDetailed(query, SearchType(100)); results != nil {
+		t.Errorf("SearchDetailed(unknown) = %v, want nil", results)
+	}
+}
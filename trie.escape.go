@@ -0,0 +1,98 @@
+package gtrie
+
+import "strings"
+
+// keyEscape escapes the delimiter '/', the brackets of the key predicates
+// and itself in the segments joined by JoinKey.
+const keyEscape = '\\'
+
+// needsEscape reports whether the byte is escaped by EscapeSegment.
+func needsEscape(c byte) bool {
+	return c == keyEscape || c == '/' || c == '[' || c == ']'
+}
+
+// EscapeSegment returns the segment `s` with '\', '/', '[' and ']' escaped
+// by '\', so that the segment can be a component of a key delimited by '/'
+// without being split by the segment-aware APIs, e.g. "1/2" becomes "1\/2".
+// It returns `s` as it is if nothing is escaped.
+func EscapeSegment(s string) string {
+	n := 0
+	for i := 0; i < len(s); i++ {
+		if needsEscape(s[i]) {
+			n++
+		}
+	}
+	if n == 0 {
+		return s
+	}
+	var b strings.Builder
+	b.Grow(len(s) + n)
+	for i := 0; i < len(s); i++ {
+		if needsEscape(s[i]) {
+			b.WriteByte(keyEscape)
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// UnescapeSegment returns the segment `s` escaped by EscapeSegment unescaped.
+// Any byte escaped by '\' is taken as it is and a trailing '\' is kept.
+func UnescapeSegment(s string) string {
+	i := strings.IndexByte(s, keyEscape)
+	if i < 0 {
+		return s
+	}
+	var b strings.Builder
+	b.Grow(len(s))
+	b.WriteString(s[:i])
+	for ; i < len(s); i++ {
+		if s[i] == keyEscape && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// JoinKey returns the key of the `segments` escaped by EscapeSegment, each
+// following '/', e.g. JoinKey("a", "1/2") is "/a/1\/2". JoinKey() is ""
+// and JoinKey("") is "/", so that SplitKey(JoinKey(segments...)) returns
+// the `segments` for any strings.
+func JoinKey(segments ...string) string {
+	n := len(segments)
+	for _, s := range segments {
+		n += len(s)
+	}
+	var b strings.Builder
+	b.Grow(n)
+	for _, s := range segments {
+		b.WriteByte('/')
+		b.WriteString(EscapeSegment(s))
+	}
+	return b.String()
+}
+
+// SplitKey splits the `key` by the unescaped '/' after the leading one, if any,
+// and returns the segments unescaped by UnescapeSegment. It is the inverse of
+// JoinKey; the key "" has no segment and "/" has one empty segment.
+// Unlike MarshalTreeJSON, it does not regard the key predicates; the '/'
+// in a segment must be escaped.
+func SplitKey(key string) []string {
+	if key == "" {
+		return nil
+	}
+	key = strings.TrimPrefix(key, "/")
+	var segs []string
+	start := 0
+	for i := 0; i < len(key); i++ {
+		switch key[i] {
+		case keyEscape:
+			i++
+		case '/':
+			segs = append(segs, UnescapeSegment(key[start:i]))
+			start = i + 1
+		}
+	}
+	return append(segs, UnescapeSegment(key[start:]))
+}
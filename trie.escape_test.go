@@ -0,0 +1,114 @@
+package gtrie
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+)
+
+func TestEscapeSegment(t *testing.T) {
+	tests := []struct {
+		seg, want string
+	}{
+		{"", ""},
+		{"abc", "abc"},
+		{"1/2", `1\/2`},
+		{`a\b`, `a\\b`},
+		{"name[k=1]", `name\[k=1\]`},
+		{"/", `\/`},
+		{"ü/\xff", "ü\\/\xff"},
+	}
+	for _, tt := range tests {
+		if got := EscapeSegment(tt.seg); got != tt.want {
+			t.Errorf("EscapeSegment(%q) = %q, want %q", tt.seg, got, tt.want)
+		}
+		if got := UnescapeSegment(tt.want); got != tt.seg {
+			t.Errorf("UnescapeSegment(%q) = %q, want %q", tt.want, got, tt.seg)
+		}
+	}
+	if got := UnescapeSegment(`a\x\`); got != `ax\` {
+		t.Errorf("UnescapeSegment() of the trailing escape = %q", got)
+	}
+}
+
+func TestJoinKey(t *testing.T) {
+	tests := []struct {
+		segs []string
+		key  string
+	}{
+		{nil, ""},
+		{[]string{""}, "/"},
+		{[]string{"", ""}, "//"},
+		{[]string{"a", "1/2"}, `/a/1\/2`},
+		{[]string{"/", "\\"}, `/\//\\`},
+		{[]string{"a", "", "b"}, "/a//b"},
+	}
+	for _, tt := range tests {
+		if got := JoinKey(tt.segs...); got != tt.key {
+			t.Errorf("JoinKey(%q) = %q, want %q", tt.segs, got, tt.key)
+		}
+		if got := SplitKey(tt.key); !reflect.DeepEqual(got, tt.segs) {
+			t.Errorf("SplitKey(%q) = %q, want %q", tt.key, got, tt.segs)
+		}
+	}
+	if got := SplitKey("a/b"); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("SplitKey() without the leading delimiter = %q", got)
+	}
+}
+
+// splitJoined reports whether SplitKey(JoinKey(segs...)) returns the segments.
+func splitJoined(segs []string) bool {
+	got := SplitKey(JoinKey(segs...))
+	if len(segs) == 0 {
+		return got == nil
+	}
+	return reflect.DeepEqual(got, segs)
+}
+
+func TestJoinKeyProperty(t *testing.T) {
+	if err := quick.Check(splitJoined, nil); err != nil {
+		t.Error(err)
+	}
+	// the segments made of the escaped bytes mostly, which quick rarely generates.
+	parts := []string{"", "/", `\`, "[", "]", "a", "ü", "\xff"}
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 10000; i++ {
+		segs := make([]string, rng.Intn(5))
+		for j := range segs {
+			for k := rng.Intn(4); k > 0; k-- {
+				segs[j] += parts[rng.Intn(len(parts))]
+			}
+		}
+		if !splitJoined(segs) {
+			t.Fatalf("SplitKey(JoinKey(%q)) = %q", segs, SplitKey(JoinKey(segs...)))
+		}
+	}
+}
+
+func TestJoinKeySegments(t *testing.T) {
+	trie := New()
+	keys := []string{JoinKey("a", "1/2", "x"), JoinKey("a", "1/2"), JoinKey("a", "b[c]")}
+	for _, key := range keys {
+		trie.Add(key, key)
+	}
+	groups := trie.GroupByPrefixSegment("/a", '/')
+	want := map[string][]string{
+		`/1\/2`:   {`/a/1\/2`, `/a/1\/2/x`},
+		`/b\[c\]`: {`/a/b\[c\]`},
+	}
+	if !reflect.DeepEqual(groups, want) {
+		t.Errorf("GroupByPrefixSegment() = %q, want %q", groups, want)
+	}
+	data, err := trie.MarshalTreeJSON('/')
+	if err != nil {
+		t.Fatalf("MarshalTreeJSON() error = %v", err)
+	}
+	restored := New()
+	if err := restored.UnmarshalTreeJSON(data, '/'); err != nil {
+		t.Fatalf("UnmarshalTreeJSON() error = %v", err)
+	}
+	if !reflect.DeepEqual(restored.All(), trie.All()) {
+		t.Errorf("UnmarshalTreeJSON() = %v, want %v", restored.All(), trie.All())
+	}
+}
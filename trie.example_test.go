@@ -0,0 +1,157 @@
+package gtrie_test
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/neoul/gtrie"
+)
+
+// newExampleTrie returns the trie of the gNMI paths used by the examples.
+func newExampleTrie() *gtrie.Trie {
+	trie := gtrie.New()
+	for _, key := range []string{
+		"/interfaces",
+		"/interfaces/interface",
+		"/interfaces/interface[name=1/2]",
+		"/interfaces/interface[name=1/2]/state",
+		"/interfaces/interface[name=1/2]/state/oper-status",
+		"/interfaces/interface[name=1/2]/state/enabled",
+		"/interfaces/interface[name=1/1]/state/enabled",
+		"/interfaces/interface[name=1/2]/state/admin-status",
+		"/interfaces/interface[name=1/2]/state/counters",
+		"/interfaces/interface[name=1/3]",
+		"/interfaces/interface[name=1/3]/state",
+		"/interfaces/interface[name=1/3]/state/oper-status",
+		"/interfaces/interface[name=1/3]/state/enabled",
+		"/interfaces/interface[name=1/3]/state/admin-status",
+		"/interfaces/interface[name=1/3]/state/counters",
+		"/interfaces/interface/state/counters",
+	} {
+		trie.Add(key, true)
+	}
+	return trie
+}
+
+func ExampleTrie_Find() {
+	trie := newExampleTrie()
+	fmt.Println(trie.Find("/interfaces/interface[name=1/3]"))
+	fmt.Println(trie.Find("/interfaces/interface[name=1/4]"))
+	// Output:
+	// true true
+	// <nil> false
+}
+
+func ExampleTrie_FindByPrefix() {
+	trie := newExampleTrie()
+	// the keys are found in no particular order.
+	keys := trie.FindByPrefix("/interfaces/interface[name=1/2]/state")
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Println(key)
+	}
+	// Output:
+	// /interfaces/interface[name=1/2]/state
+	// /interfaces/interface[name=1/2]/state/admin-status
+	// /interfaces/interface[name=1/2]/state/counters
+	// /interfaces/interface[name=1/2]/state/enabled
+	// /interfaces/interface[name=1/2]/state/oper-status
+}
+
+func ExampleTrie_FindByFuzzy() {
+	trie := newExampleTrie()
+	// the keys having the input as a subsequence.
+	keys := trie.FindByFuzzy("/interfaces/interface/state/enabled")
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Println(key)
+	}
+	// Output:
+	// /interfaces/interface[name=1/1]/state/enabled
+	// /interfaces/interface[name=1/2]/state/enabled
+	// /interfaces/interface[name=1/3]/state/enabled
+}
+
+func ExampleTrie_FindLongestMatchingPrefix() {
+	trie := newExampleTrie()
+	fmt.Println(trie.FindLongestMatchingPrefix("/interfaces/interface[name=1/3]/state/absss"))
+	// Output:
+	// /interfaces/interface[name=1/3]/state true true
+}
+
+func ExampleTrie_FindMatchingPrefix() {
+	trie := newExampleTrie()
+	// the keys are found from the shortest.
+	keys, ok := trie.FindMatchingPrefix("/interfaces/interface[name=1/3]/state/absss")
+	fmt.Println(ok)
+	for _, key := range keys {
+		fmt.Println(key)
+	}
+	// Output:
+	// true
+	// /interfaces
+	// /interfaces/interface
+	// /interfaces/interface[name=1/3]
+	// /interfaces/interface[name=1/3]/state
+}
+
+func ExampleTrie_FindRelativeAll() {
+	trie := newExampleTrie()
+	trie.Remove("/interfaces")
+	trie.Remove("/interfaces/interface")
+	// FindRelativeAll = FindByPrefix + FindByFuzzy + FindMatchingPrefix
+	m := trie.FindRelativeAll("/interfaces/interface/state")
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Println(key)
+	}
+	// Output:
+	// /interfaces/interface/state/counters
+	// /interfaces/interface[name=1/1]/state/enabled
+	// /interfaces/interface[name=1/2]/state
+	// /interfaces/interface[name=1/2]/state/admin-status
+	// /interfaces/interface[name=1/2]/state/counters
+	// /interfaces/interface[name=1/2]/state/enabled
+	// /interfaces/interface[name=1/2]/state/oper-status
+	// /interfaces/interface[name=1/3]/state
+	// /interfaces/interface[name=1/3]/state/admin-status
+	// /interfaces/interface[name=1/3]/state/counters
+	// /interfaces/interface[name=1/3]/state/enabled
+	// /interfaces/interface[name=1/3]/state/oper-status
+}
+
+func ExampleTrie_SearchWithOptions() {
+	trie := newExampleTrie()
+	keys, err := trie.SearchWithOptions("/interfaces/interface[name=1/3]/state/", gtrie.SearchByPrefix, gtrie.Sorted(), gtrie.MaxResults(2))
+	fmt.Println(keys, err)
+	// Output:
+	// [/interfaces/interface[name=1/3]/state/admin-status /interfaces/interface[name=1/3]/state/counters] <nil>
+}
+
+// The trie is safe for the concurrent use by multiple goroutines.
+func ExampleNew_concurrent() {
+	trie := gtrie.New()
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 10; i++ {
+				key := fmt.Sprintf("/interfaces/interface[name=%d/%d]", w, i)
+				trie.Add(key, i)
+				trie.FindByPrefix("/interfaces/")
+				if i%2 == 1 {
+					trie.Remove(key)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	fmt.Println(trie.Size(), len(trie.FindByPrefix("/interfaces/interface[name=0/")))
+	// Output: 20 5
+}
@@ -0,0 +1,58 @@
+package gtrie
+
+// FindExcludingPrefix returns all the keys except the keys starting with
+// any of the `exclude` prefixes. The excluded subtrees are never visited.
+func (t *Trie) FindExcludingPrefix(exclude ...string) []string {
+	if t == nil {
+		return nil
+	}
+	t.rlock()
+	defer t.runlock()
+	return nodeKeys(t.excludecollect(exclude))
+}
+
+// FindExcludingPrefixAll returns all the keys and values except the keys
+// starting with any of the `exclude` prefixes.
+func (t *Trie) FindExcludingPrefixAll(exclude ...string) map[string]interface{} {
+	if t == nil {
+		return nil
+	}
+	t.rlock()
+	defer t.runlock()
+	return nodeMap(t.excludecollect(exclude))
+}
+
+// excludecollect returns all the terminal nodes except the nodes under
+// the boundary nodes of the `exclude` prefixes.
+func (t *Trie) excludecollect(exclude []string) []*trieNode {
+	skip := make(map[*trieNode]struct{}, len(exclude))
+	for _, prefix := range exclude {
+		if node := findNode(t.root, t.canonical(prefix)); node != nil {
+			skip[node] = struct{}{}
+		}
+	}
+	if len(skip) == 0 {
+		return collectNodes(t.root)
+	}
+	var (
+		n *trieNode
+		i int
+	)
+	terms := make([]*trieNode, 0, t.root.termCount)
+	nodes := []*trieNode{t.root}
+	for l := len(nodes); l != 0; l = len(nodes) {
+		i = l - 1
+		n = nodes[i]
+		nodes = nodes[:i]
+		if _, ok := skip[n]; ok {
+			continue
+		}
+		for _, child := range n.children {
+			nodes = append(nodes, child)
+		}
+		if n.term {
+			terms = append(terms, n)
+		}
+	}
+	return terms
+}
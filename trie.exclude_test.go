@@ -0,0 +1,55 @@
+package gtrie
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTrie_FindExcludingPrefix(t *testing.T) {
+	trie := newGNMITrie()
+	all := sortedKeys(trie.Keys())
+	filter := func(exclude ...string) []string {
+		var keys []string
+	next:
+		for _, key := range all {
+			for _, prefix := range exclude {
+				if strings.HasPrefix(key, prefix) {
+					continue next
+				}
+			}
+			keys = append(keys, key)
+		}
+		return keys
+	}
+	tests := []struct {
+		name    string
+		exclude []string
+	}{
+		{"none", nil},
+		{"unmatched", []string{"/none"}},
+		{"single", []string{"/interfaces/interface[name=1/2]"}},
+		{"multiple", []string{"/interfaces/interface[name=1/2]", "/interfaces/interface[name=1/3]/"}},
+		{"nested", []string{"/interfaces/interface[name=1/", "/interfaces/interface[name=1/2]/state"}},
+		{"partial", []string{"/interfaces/interface[name=1/2]/st"}},
+		{"everything", []string{""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := filter(tt.exclude...)
+			got := sortedKeys(trie.FindExcludingPrefix(tt.exclude...))
+			if len(got) != len(want) || (len(want) > 0 && !reflect.DeepEqual(got, want)) {
+				t.Errorf("FindExcludingPrefix(%q) = %v, want %v", tt.exclude, got, want)
+			}
+			all := trie.FindExcludingPrefixAll(tt.exclude...)
+			if len(all) != len(want) {
+				t.Errorf("FindExcludingPrefixAll(%q) = %v, want %v", tt.exclude, all, want)
+			}
+			for _, key := range want {
+				if _, ok := all[key]; !ok {
+					t.Errorf("FindExcludingPrefixAll(%q) misses %s", tt.exclude, key)
+				}
+			}
+		})
+	}
+}
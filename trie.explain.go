@@ -0,0 +1,284 @@
+package gtrie
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Explanation is the report of Explain on whether a key of the trie is found
+// by a search and why. The rune indices are of the canonical forms of
+// the query and the candidate with a key transform.
+type Explanation struct {
+	Type      SearchType
+	Query     string
+	Candidate string
+	// Stored reports whether the candidate is a key of the trie.
+	Stored bool
+	// Matched reports whether the search of the query finds the candidate.
+	Matched bool
+	// Index is the rune index the match is decided at, or -1 if not applicable:
+	// the first rune of the query not matched by SearchExactly, SearchByPrefix,
+	// SearchMatcingPrefix and SearchLongestMatchingPrefix (the length of
+	// the shorter if one is a prefix of the other), the first rune of the query
+	// not found in order by SearchApproximate and the last rune of the query
+	// not matched from the end by SearchSuffix.
+	Index int
+	// Walked is the number of the runes of the query walked down the trie
+	// by SearchMatcingPrefix and SearchLongestMatchingPrefix before it stopped.
+	Walked int
+	// Positions are the rune indices of the candidate matched to the runes of
+	// the query in order by SearchApproximate, or nil if not matched.
+	Positions []int
+	// Winner is the longer key found by SearchLongestMatchingPrefix instead of the candidate.
+	Winner string
+	// Reason is why the candidate is (not) matched in words.
+	Reason string
+}
+
+// String returns the explanation in a line, e.g.
+//
+//	by-prefix "/a/b" -> "/a/c/d": not matched: the candidate has 'c' for 'b' at rune 3
+func (e Explanation) String() string {
+	result := "matched"
+	if !e.Matched {
+		result = "not matched"
+	}
+	return fmt.Sprintf("%s %q -> %q: %s: %s", e.Type, e.Query, e.Candidate, result, e.Reason)
+}
+
+// Explain reports whether the search of the `query` by `stype` finds the `candidate`,
+// as Search without the search options, and why, e.g. where the candidate diverges
+// from the query or which longer prefix wins, for debugging the keys not found.
+// The candidate is matched only if it is a key of the trie; the reason of
+// the candidate not stored is given as if it were.
+func (t *Trie) Explain(stype SearchType, query, candidate string) Explanation {
+	if t == nil {
+		return new(Trie).Explain(stype, query, candidate)
+	}
+	root := t.readRoot()
+	defer t.readDone()
+	e := t.explain(root, stype, query, candidate)
+	if !e.Stored {
+		e.Matched = false
+		e.Reason = "the candidate is not a key of the trie; if it were, " + e.Reason
+	}
+	return e
+}
+
+// explain is Explain of the trie read from the `root` already,
+// but the candidate not stored is matched as if it were.
+func (t *Trie) explain(root *trieNode, stype SearchType, query, candidate string) Explanation {
+	ccand := t.canonical(candidate)
+	term := findTerm(root, ccand)
+	e := Explanation{Type: stype, Query: query, Candidate: candidate, Stored: term != nil, Index: -1}
+	q, c := t.runes(query), []rune(ccand)
+	d := divergence(q, c)
+	switch stype {
+	case SearchExactly:
+		e.Matched, e.Index = d == len(q) && d == len(c), d
+		e.Reason = explainPrefix(q, c, d, e.Matched, "the candidate is the query")
+	case SearchByPrefix:
+		e.Matched, e.Index = d == len(q), d
+		e.Reason = explainPrefix(q, c, d, e.Matched, "the candidate starts with the query")
+	case SearchMatcingPrefix:
+		e.Matched, e.Index, e.Walked = d == len(c) && d > 0, d, walked(root, q)
+		e.Reason = fmt.Sprintf("%s; the walk of the query stopped at rune %d",
+			explainPrefix(q, c, d, e.Matched, "the candidate is a prefix of the query"), e.Walked)
+		if len(c) == 0 {
+			e.Reason = emptyPrefix
+		}
+	case SearchLongestMatchingPrefix:
+		e.Index, e.Walked = d, walked(root, q)
+		e.Reason = explainPrefix(q, c, d, d == len(c) && d > 0, "the candidate is a prefix of the query")
+		if d == len(c) && d > 0 {
+			// the candidate wins unless a longer prefix is found.
+			found, _ := longestprefix(root, string(q))
+			e.Matched = found == nil || found.depth-1 <= len(c)
+			if !e.Matched {
+				e.Winner = found.key()
+				e.Reason = fmt.Sprintf("%s, but the longer prefix %q wins", e.Reason, e.Winner)
+			}
+		}
+		e.Reason = fmt.Sprintf("%s; the walk of the query stopped at rune %d", e.Reason, e.Walked)
+		if len(c) == 0 {
+			e.Reason = emptyPrefix
+		}
+	case SearchApproximate:
+		e.Positions, e.Index = subsequence(q, c)
+		e.Matched = e.Index < 0
+		if e.Matched {
+			e.Reason = fmt.Sprintf("the runes of the query are at %v of the candidate", e.Positions)
+		} else {
+			e.Reason = fmt.Sprintf("the rune %d (%q) of the query is not found in order in the candidate", e.Index, q[e.Index])
+		}
+	case SearchAllRelativeKey:
+		var matched, all []string
+		for _, sub := range []SearchType{SearchByPrefix, SearchMatcingPrefix, SearchApproximate} {
+			s := t.explain(root, sub, query, candidate)
+			reason := fmt.Sprintf("%s: %s", sub, s.Reason)
+			if s.Matched {
+				matched = append(matched, reason)
+			}
+			all = append(all, reason)
+			if sub == SearchApproximate {
+				e.Positions = s.Positions
+			}
+		}
+		e.Matched = len(matched) > 0
+		if e.Matched {
+			all = matched
+		}
+		e.Reason = strings.Join(all, "; ")
+	case SearchSuffix:
+		s := 0
+		for s < len(q) && s < len(c) && q[len(q)-1-s] == c[len(c)-1-s] {
+			s++
+		}
+		e.Matched = s == len(q)
+		switch {
+		case e.Matched:
+			e.Reason = "the candidate ends with the query"
+		case s == len(c):
+			e.Index = len(q) - 1 - s
+			e.Reason = fmt.Sprintf("the candidate is shorter than the query of %d runes", len(q))
+		default:
+			e.Index = len(q) - 1 - s
+			e.Reason = fmt.Sprintf("the candidate has %q for %q at rune %d from the end", c[len(c)-1-s], q[e.Index], s)
+		}
+	case SearchWildcard:
+		var reached int
+		e.Matched, reached = wildcardMatch(parseWildcard(string(q)), c)
+		if e.Matched {
+			e.Reason = "the candidate matches the pattern"
+		} else {
+			e.Reason = fmt.Sprintf("the longest match of a part of the pattern ends at rune %d of the candidate", reached)
+		}
+	default:
+		k, ok := stype.distance()
+		if !ok {
+			e.Reason = ErrUnknownSearchType.Error()
+			return e
+		}
+		dist := editDistance(q, c)
+		e.Matched = dist <= k
+		if e.Matched {
+			e.Reason = fmt.Sprintf("the edit distance %d is within %d", dist, k)
+		} else {
+			e.Reason = fmt.Sprintf("the edit distance %d exceeds %d", dist, k)
+		}
+	}
+	return e
+}
+
+// emptyPrefix is the reason of the empty key not found as a prefix of the query.
+const emptyPrefix = "the empty key is not matched as a prefix"
+
+// divergence returns the number of the leading runes `q` and `c` have in common.
+func divergence(q, c []rune) int {
+	d := 0
+	for d < len(q) && d < len(c) && q[d] == c[d] {
+		d++
+	}
+	return d
+}
+
+// explainPrefix returns `same` if matched, or where the candidate `c`
+// diverges from the query `q` after their common prefix of `d` runes.
+func explainPrefix(q, c []rune, d int, matched bool, same string) string {
+	switch {
+	case matched:
+		return same
+	case d < len(q) && d < len(c):
+		return fmt.Sprintf("the candidate has %q for %q at rune %d", c[d], q[d], d)
+	case d == len(q) && d == len(c):
+		return "the candidate is the query"
+	case d == len(q):
+		return fmt.Sprintf("the candidate is longer than the query of %d runes", len(q))
+	default:
+		return fmt.Sprintf("the candidate ends at rune %d before the end of the query", d)
+	}
+}
+
+// walked returns the number of the leading runes of `q` on a path of the trie.
+func walked(root *trieNode, q []rune) int {
+	w := 0
+	for node := root; node != nil && w < len(q); w++ {
+		if node = node.children[q[w]]; node == nil {
+			break
+		}
+	}
+	return w
+}
+
+// subsequence matches the runes of `q` in order to the earliest runes of `c`
+// as the fuzzy search and returns the positions in `c` and -1, or nil and
+// the index of the first rune of `q` not matched.
+func subsequence(q, c []rune) ([]int, int) {
+	positions := make([]int, 0, len(q))
+	j := 0
+	for i := range q {
+		for j < len(c) && c[j] != q[i] {
+			j++
+		}
+		if j == len(c) {
+			return nil, i
+		}
+		positions = append(positions, j)
+		j++
+	}
+	return positions, -1
+}
+
+// wildcardMatch reports whether the runes `c` match the wildcard `tokens`
+// and returns the largest number of the leading runes of `c` matched by
+// a leading part of the tokens.
+func wildcardMatch(tokens []wildcardToken, c []rune) (bool, int) {
+	// states[j] reports whether the tokens so far match c[:j].
+	states := make([]bool, len(c)+1)
+	states[0] = true
+	reached := 0
+	for _, tok := range tokens {
+		next := make([]bool, len(c)+1)
+		for j := range states {
+			if !states[j] {
+				continue
+			}
+			switch {
+			case tok.star:
+				for k := j; k <= len(c); k++ {
+					next[k] = true
+				}
+			case j < len(c) && (tok.any || tok.r == c[j]):
+				next[j+1] = true
+			}
+		}
+		states = next
+		for j := len(c); j > reached; j-- {
+			if states[j] {
+				reached = j
+				break
+			}
+		}
+	}
+	return states[len(c)], reached
+}
+
+// editDistance returns the Levenshtein distance of the runes as distancecollect.
+func editDistance(q, c []rune) int {
+	row := make([]int, len(q)+1)
+	for i := range row {
+		row[i] = i
+	}
+	for _, r := range c {
+		prev := row[0]
+		row[0]++
+		for i := 1; i < len(row); i++ {
+			cost := 1
+			if q[i-1] == r {
+				cost = 0
+			}
+			prev, row[i] = row[i], min(row[i-1]+1, row[i]+1, prev+cost)
+		}
+	}
+	return row[len(q)]
+}
@@ -0,0 +1,98 @@
+package gtrie
+
+import (
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTrie_Explain(t *testing.T) {
+	trie := New()
+	for _, key := range []string{"/a", "/a/b", "/a/b/c", "/a/c/d", "/x/y"} {
+		trie.Add(key, nil)
+	}
+	tests := []struct {
+		stype     SearchType
+		query     string
+		candidate string
+		matched   bool
+		index     int
+		reason    string
+	}{
+		{SearchExactly, "/a/b", "/a/b", true, 4, "the candidate is the query"},
+		{SearchExactly, "/a/b", "/a/b/c", false, 4, "longer than the query of 4 runes"},
+		{SearchByPrefix, "/a/b", "/a/c/d", false, 3, "the candidate has 'c' for 'b' at rune 3"},
+		{SearchByPrefix, "/a/b", "/a", false, 2, "the candidate ends at rune 2"},
+		{SearchByPrefix, "/a/b", "/a/b/c", true, 4, "starts with the query"},
+		{SearchMatcingPrefix, "/a/b/z", "/a/b", true, 4, "the walk of the query stopped at rune 5"},
+		{SearchMatcingPrefix, "/a/b/z", "/a/b/c", false, 5, "the candidate has 'c' for 'z' at rune 5"},
+		{SearchLongestMatchingPrefix, "/a/b/c/d", "/a/b", false, 4, `the longer prefix "/a/b/c" wins`},
+		{SearchLongestMatchingPrefix, "/a/b/c/d", "/a/b/c", true, 6, "the walk of the query stopped at rune 6"},
+		{SearchApproximate, "acd", "/a/c/d", true, -1, "at [1 3 5] of the candidate"},
+		{SearchApproximate, "adc", "/a/c/d", false, 2, "the rune 2 ('c') of the query is not found"},
+		{SearchAllRelativeKey, "/a/b", "/a", true, -1, "matching-prefix: the candidate is a prefix of the query"},
+		{SearchAllRelativeKey, "yx", "/x/y", false, -1, "approximate: the rune 1 ('x')"},
+		{SearchSuffix, "c/d", "/a/c/d", true, -1, "ends with the query"},
+		{SearchSuffix, "b/d", "/a/c/d", false, 0, "the candidate has 'c' for 'b' at rune 2 from the end"},
+		{SearchWildcard, "/a/*/d", "/a/c/d", true, -1, "matches the pattern"},
+		{SearchWildcard, "/x/?z", "/x/y", false, -1, "ends at rune 4 of the candidate"},
+		{SearchWithinDistance(1), "/a/bc", "/a/b/c", true, -1, "the edit distance 1 is within 1"},
+		{SearchWithinDistance(1), "/x", "/x/y", false, -1, "the edit distance 2 exceeds 1"},
+		{SearchByPrefix, "/a", "/a/z", false, 2, "the candidate is not a key of the trie; if it were, the candidate starts"},
+		{SearchType(100), "/a", "/a", false, -1, ErrUnknownSearchType.Error()},
+	}
+	for _, tt := range tests {
+		e := trie.Explain(tt.stype, tt.query, tt.candidate)
+		if e.Matched != tt.matched || e.Index != tt.index || !strings.Contains(e.Reason, tt.reason) {
+			t.Errorf("Explain(%s, %q, %q) = %v, %d, %q, want %v, %d, %q", tt.stype, tt.query, tt.candidate,
+				e.Matched, e.Index, e.Reason, tt.matched, tt.index, tt.reason)
+		}
+	}
+	e := trie.Explain(SearchByPrefix, "/a/b", "/a/c/d")
+	if want := `by-prefix "/a/b" -> "/a/c/d": not matched: the candidate has 'c' for 'b' at rune 3`; e.String() != want {
+		t.Errorf("String() = %q, want %q", e.String(), want)
+	}
+}
+
+func TestTrie_ExplainSearch(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	word := func() string {
+		b := make([]byte, rng.Intn(6))
+		for i := range b {
+			b[i] = "ab/"[rng.Intn(3)]
+		}
+		return string(b)
+	}
+	trie := New(WithKeyTransform(strings.ToLower))
+	var keys []string
+	for i := 0; i < 60; i++ {
+		key := word()
+		trie.Add(key, nil)
+		keys = append(keys, key)
+	}
+	stypes := []SearchType{SearchExactly, SearchByPrefix, SearchLongestMatchingPrefix, SearchMatcingPrefix,
+		SearchApproximate, SearchAllRelativeKey, SearchSuffix, SearchWildcard, SearchWithinDistance(1)}
+	for i := 0; i < 200; i++ {
+		query := word()
+		if i%2 == 0 {
+			query = strings.ToUpper(query)
+		}
+		if i%3 == 0 {
+			query = strings.ReplaceAll(query, "b", "?")
+		}
+		for _, stype := range stypes {
+			found := trie.Search(query, stype)
+			for i := range found {
+				// SearchExactly returns the query found.
+				found[i] = strings.ToLower(found[i])
+			}
+			for _, key := range keys {
+				e := trie.Explain(stype, query, key)
+				if want := slices.Contains(found, key); e.Matched != want {
+					t.Fatalf("Explain(%s, %q, %q) = %v, want %v: %s", stype, query, key, e.Matched, want, e)
+				}
+			}
+		}
+	}
+}
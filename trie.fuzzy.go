@@ -0,0 +1,157 @@
+package gtrie
+
+import "sort"
+
+// FuzzyMatch is a key found by fuzzy search with the positions
+// of the runes matched to the query.
+type FuzzyMatch struct {
+	Key   string
+	Value interface{}
+	// Positions are the rune indices in the Key matched to the runes of the query in order.
+	Positions []int
+}
+
+// FindByFuzzyMatches performs a fuzzy search like FindByFuzzy
+// and returns the keys found with the positions of the matched runes,
+// e.g. for highlighting the search hits. The matches are sorted by
+// the key length and then lexicographically.
+func (t *Trie) FindByFuzzyMatches(partial string) []FuzzyMatch {
+	if t == nil {
+		return nil
+	}
+	t.rlock()
+	defer t.runlock()
+	matches := fuzzymatchcollect(t.root, t.runes(partial))
+	sort.Slice(matches, func(i, j int) bool {
+		if len(matches[i].Key) != len(matches[j].Key) {
+			return len(matches[i].Key) < len(matches[j].Key)
+		}
+		return matches[i].Key < matches[j].Key
+	})
+	return matches
+}
+
+// potentialMatch is a potentialSubtree carrying the positions matched so far.
+type potentialMatch struct {
+	potentialSubtree
+	positions []int
+}
+
+// fuzzymatchcollect is fuzzycollect tracking the rune positions
+// of the partial matched on the way to each subtree.
+func fuzzymatchcollect(node *trieNode, partial []rune) []FuzzyMatch {
+	var matches []FuzzyMatch
+	if len(partial) == 0 {
+		for _, n := range collectNodes(node) {
+			matches = append(matches, FuzzyMatch{Key: n.key(), Value: n.value, Positions: []int{}})
+		}
+		return matches
+	}
+
+	var (
+		i int
+		p potentialMatch
+	)
+
+	potential := []potentialMatch{{potentialSubtree: potentialSubtree{node: node, idx: 0}}}
+	for l := len(potential); l > 0; l = len(potential) {
+		i = l - 1
+		p = potential[i]
+		potential = potential[:i]
+		if fuzzyPruned(p.node, partial[p.idx:]) {
+			continue
+		}
+
+		if p.node.rval == partial[p.idx] {
+			p.idx++
+			positions := make([]int, len(p.positions), len(p.positions)+1)
+			copy(positions, p.positions)
+			p.positions = append(positions, p.node.depth-1)
+			if p.idx == len(partial) {
+				for _, n := range collectNodes(p.node) {
+					matches = append(matches, FuzzyMatch{Key: n.key(), Value: n.value, Positions: p.positions})
+				}
+				continue
+			}
+		}
+
+		for _, c := range p.node.children {
+			potential = append(potential, potentialMatch{
+				potentialSubtree: potentialSubtree{node: c, idx: p.idx},
+				positions:        p.positions,
+			})
+		}
+	}
+	return matches
+}
+
+// FindByFuzzyPrefix returns all the keys whose prefix of the rune length of
+// the input `prefix` is within the edit distance `maxDist` from `prefix`,
+// e.g. for the autocompletion tolerating the typos of the prefix typed.
+// An edit is an insertion, a deletion, a substitution or a transposition
+// of two adjacent runes. The keys shorter than `prefix` are not found.
+// FindByFuzzyPrefix with `maxDist` 0 is FindByPrefix.
+func (t *Trie) FindByFuzzyPrefix(prefix string, maxDist int) []string {
+	if t == nil {
+		return nil
+	}
+	t.rlock()
+	defer t.runlock()
+	return nodeKeys(fuzzyprefixcollect(t.root, t.runes(prefix), maxDist))
+}
+
+// fuzzyprefixcollect returns all the terminal nodes under the subtrees
+// at the depth of `prefix` whose path is within the distance `k` from `prefix`.
+// Each node on the walk holds a row of the edit distance matrix (of the optimal
+// string alignment) and the subtree is pruned as soon as no entry of the row
+// is within `k`. The walk switches to collectNodes at the depth of `prefix`.
+func fuzzyprefixcollect(node *trieNode, prefix []rune, k int) []*trieNode {
+	if k < 0 {
+		return nil
+	}
+	if len(prefix) == 0 {
+		return collectNodes(node)
+	}
+	var terms []*trieNode
+	row := make([]int, len(prefix)+1)
+	for i := range row {
+		row[i] = i
+	}
+	var walk func(n *trieNode, prev, prev2 []int, prevRune rune)
+	walk = func(n *trieNode, prev, prev2 []int, prevRune rune) {
+		cur := make([]int, len(prev))
+		cur[0] = prev[0] + 1
+		least := cur[0]
+		for i := 1; i < len(cur); i++ {
+			cost := 1
+			if prefix[i-1] == n.rval {
+				cost = 0
+			}
+			cur[i] = min(cur[i-1]+1, prev[i]+1, prev[i-1]+cost)
+			if prev2 != nil && i > 1 && prefix[i-1] == prevRune && prefix[i-2] == n.rval {
+				cur[i] = min(cur[i], prev2[i-2]+1)
+			}
+			least = min(least, cur[i])
+		}
+		if least > k {
+			return
+		}
+		if cur[0] == len(prefix) {
+			if cur[len(prefix)] <= k {
+				terms = append(terms, collectNodes(n)...)
+			}
+			return
+		}
+		for r, c := range n.children {
+			if r != nul {
+				walk(c, cur, prev, n.rval)
+			}
+		}
+	}
+	for r, c := range node.children {
+		if r != nul {
+			walk(c, row, nil, nul)
+		}
+	}
+	return terms
+}
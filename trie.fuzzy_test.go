@@ -0,0 +1,95 @@
+package gtrie
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTrie_FindByFuzzyMatches(t *testing.T) {
+	setup := []string{
+		"foosball",
+		"football",
+		"bmerica",
+		"ked",
+		"kedlock",
+		"frosty",
+		"bfrza",
+		"foo/bart/baz.go",
+	}
+	trie := New()
+	for i, key := range setup {
+		trie.Add(key, i)
+	}
+	for _, partial := range []string{"fsb", "footbal", "football", "fs", "oos", "kl", "ft", "fy", "fz", "a", "", "zzz"} {
+		matches := trie.FindByFuzzyMatches(partial)
+		keys := make([]string, 0, len(matches))
+		query := []rune(partial)
+		for _, m := range matches {
+			keys = append(keys, m.Key)
+			if v, _ := trie.Find(m.Key); v != m.Value {
+				t.Errorf("FindByFuzzyMatches(%q) value of %q = %v, want %v", partial, m.Key, m.Value, v)
+			}
+			if len(m.Positions) != len(query) {
+				t.Fatalf("FindByFuzzyMatches(%q) positions of %q = %v", partial, m.Key, m.Positions)
+			}
+			key := []rune(m.Key)
+			for i, pos := range m.Positions {
+				if i > 0 && pos <= m.Positions[i-1] {
+					t.Errorf("FindByFuzzyMatches(%q) positions of %q are not increasing: %v", partial, m.Key, m.Positions)
+				}
+				if key[pos] != query[i] {
+					t.Errorf("FindByFuzzyMatches(%q) position %d of %q is %q, want %q", partial, pos, m.Key, key[pos], query[i])
+				}
+			}
+		}
+		if want := sortedKeys(trie.FindByFuzzy(partial)); len(keys) != len(want) || (len(want) > 0 && !reflect.DeepEqual(sortedKeys(keys), want)) {
+			t.Errorf("FindByFuzzyMatches(%q) = %v, want %v", partial, keys, want)
+		}
+	}
+
+	want := []FuzzyMatch{
+		{Key: "bfrza", Value: 6, Positions: []int{1, 3}},
+		{Key: "foo/bart/baz.go", Value: 7, Positions: []int{0, 11}},
+	}
+	if got := trie.FindByFuzzyMatches("fz"); !reflect.DeepEqual(got, want) {
+		t.Errorf("FindByFuzzyMatches(\"fz\") = %v, want %v", got, want)
+	}
+}
+
+func TestTrie_FindByFuzzyPrefix(t *testing.T) {
+	trie := newGNMITrie()
+	trie.Add("/interfaces-state", true)
+	trie.Add("/", true)
+	for _, prefix := range []string{"", "/", "/interfaces", "/interfaces/interface[name=1/", "/interfaces/interface[name=1/2]/state", "/x"} {
+		got, want := sortedKeys(trie.FindByFuzzyPrefix(prefix, 0)), sortedKeys(trie.FindByPrefix(prefix))
+		if len(got) != 0 || len(want) != 0 {
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("FindByFuzzyPrefix(%q, 0) = %v, want %v", prefix, got, want)
+			}
+		}
+	}
+	interfaces := sortedKeys(trie.FindByPrefix("/interfaces/int"))
+	tests := []struct {
+		prefix  string
+		maxDist int
+		want    []string
+	}{
+		// transposition
+		{"/interfaecs/int", 1, interfaces},
+		{"/interfaecs/int", 0, nil},
+		// substitution
+		{"/interfaxes/int", 1, interfaces},
+		// deletion and insertion
+		{"/intrfaces/int", 1, nil},
+		{"/intrfaces/int", 2, interfaces},
+		{"/interfaces-i", 1, append([]string{"/interfaces-state"}, sortedKeys(trie.FindByPrefix("/interfaces/i"))...)},
+		{"/x", 1, sortedKeys(trie.FindByPrefix("/"))[1:]},
+		{"/interfaces/interface/state/counters/x", 2, nil},
+		{"/a", -1, nil},
+	}
+	for _, tt := range tests {
+		if got := sortedKeys(trie.FindByFuzzyPrefix(tt.prefix, tt.maxDist)); !reflect.DeepEqual(got, tt.want) && (len(got) != 0 || len(tt.want) != 0) {
+			t.Errorf("FindByFuzzyPrefix(%q, %d) = %v, want %v", tt.prefix, tt.maxDist, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package gtrie
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// WithGNMIPathNormalization makes the trie canonicalize the keys as gNMI paths
+// by NormalizeGNMIPath, so that the paths of the same element having the list keys
+// in a different order or with extra whitespace are regarded as the same key.
+// It is applied after the key transform given by WithKeyTransform if any.
+func WithGNMIPathNormalization() Option {
+	return func(t *Trie) {
+		if prev := t.transform; prev != nil {
+			t.transform = func(key string) string {
+				return NormalizeGNMIPath(prev(key))
+			}
+			return
+		}
+		t.transform = NormalizeGNMIPath
+	}
+}
+
+// gnmiKey is a key predicate [name=value] of a gNMI path element.
+type gnmiKey struct {
+	name  string
+	value string
+}
+
+// NormalizeGNMIPath returns the canonical form of the gNMI path string.
+// The key predicates of each path element are sorted by the key name and
+// the whitespace around the element names, key names and values is removed,
+// e.g. "/a/b[ y=2 ][x=1]/c" becomes "/a/b[x=1][y=2]/c".
+// A value can contain '=', '/' and the escaped '\]' and '\\'; the escapes are kept as they are.
+// The path is returned unchanged if a predicate is not terminated by ']'
+// or followed by other than a predicate, '/' or whitespace.
+// The keys built by PathKey are canonical unless their names or values
+// have the leading or trailing whitespace.
+func NormalizeGNMIPath(path string) string {
+	var (
+		b    strings.Builder
+		keys []gnmiKey
+	)
+	b.Grow(len(path))
+	err := scanGNMIPath(path, func(i int, name string, preds []string) {
+		if i > 0 {
+			b.WriteByte('/')
+		}
+		b.WriteString(strings.TrimSpace(name))
+		keys = keys[:0]
+		for _, pred := range preds {
+			name, value, _ := cutPredicate(pred)
+			keys = append(keys, gnmiKey{name: strings.TrimSpace(name), value: strings.TrimSpace(value)})
+		}
+		sort.SliceStable(keys, func(a, b int) bool {
+			return keys[a].name < keys[b].name
+		})
+		for _, k := range keys {
+			b.WriteByte('[')
+			b.WriteString(k.name)
+			b.WriteByte('=')
+			b.WriteString(k.value)
+			b.WriteByte(']')
+		}
+	})
+	if err != nil {
+		return path
+	}
+	return b.String()
+}
+
+// scanGNMIPath splits the gNMI path string by '/' out of the key predicates and
+// the escapes, e.g. "/a[k=1/2]/b" into "", "a[k=1/2]" and "b", and calls `fn`
+// with the index, the name and the predicates (without the brackets) of each
+// element as they are in the path, i.e. escaped and not trimmed. The predicates
+// are only valid during the call. The whitespace between and after the
+// predicates is skipped. It returns the error of a predicate not terminated
+// by ']' or followed by other than a predicate, '/' or whitespace, after
+// `fn` is called with the elements before.
+func scanGNMIPath(path string, fn func(i int, name string, preds []string)) error {
+	var preds []string
+	for i, elem := 0, 0; ; elem++ {
+		// the element name
+		j := i
+		for ; j < len(path) && path[j] != '[' && path[j] != '/'; j++ {
+			if path[j] == keyEscape {
+				j++
+			}
+		}
+		j = min(j, len(path))
+		name := path[i:j]
+		i = j
+		// the key predicates
+		preds = preds[:0]
+		for i < len(path) && path[i] == '[' {
+			end := predicateEnd(path, i+1)
+			if end < 0 {
+				return fmt.Errorf("predicate not terminated at %d", i)
+			}
+			preds = append(preds, path[i+1:end])
+			i = end + 1
+			// the whitespace between and after the predicates
+			for i < len(path) && path[i] == ' ' {
+				i++
+			}
+		}
+		if i < len(path) && path[i] != '/' {
+			return fmt.Errorf("unexpected %q at %d", path[i], i)
+		}
+		fn(elem, name, preds)
+		if i >= len(path) {
+			return nil
+		}
+		i++
+	}
+}
+
+// cutPredicate slices the key predicate (without the brackets) around
+// the first '=' not escaped into the key name and value.
+// The found result reports whether '=' appears in the predicate.
+func cutPredicate(pred string) (name, value string, found bool) {
+	for i := 0; i < len(pred); i++ {
+		switch pred[i] {
+		case keyEscape:
+			i++
+		case '=':
+			return pred[:i], pred[i+1:], true
+		}
+	}
+	return pred, "", false
+}
+
+// predicateEnd returns the index of the ']' closing the predicate starting at `i`
+// skipping the escaped runes, or -1 if the predicate is not terminated.
+func predicateEnd(path string, i int) int {
+	for ; i < len(path); i++ {
+		switch path[i] {
+		case '\\':
+			i++
+		case ']':
+			return i
+		}
+	}
+	return -1
+}
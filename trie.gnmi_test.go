@@ -0,0 +1,62 @@
+package gtrie
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeGNMIPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/interfaces/interface[name=1/2]/state", "/interfaces/interface[name=1/2]/state"},
+		{"/a/b[y=2][x=1]/c", "/a/b[x=1][y=2]/c"},
+		{"/a/b[ y = 2 ] [x=1] / c ", "/a/b[x=1][y=2]/c"},
+		{"/a[k=v=w][b=1]", "/a[b=1][k=v=w]"},
+		{`/a[z=x\]y][a=\\]/b`, `/a[a=\\][z=x\]y]/b`},
+		{"/a[z=1][a=1]/b[z=2][a=2]", "/a[a=1][z=1]/b[a=2][z=2]"},
+		{"/a[k=1", "/a[k=1"},
+		{"/a[ k=1 ]b/c[ x=1 ]", "/a[ k=1 ]b/c[ x=1 ]"},
+		{`/a\/b[k\=1=2]`, `/a\/b[k\=1=2]`},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeGNMIPath(tt.path); got != tt.want {
+			t.Errorf("NormalizeGNMIPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestTrie_WithGNMIPathNormalization(t *testing.T) {
+	trie := New(WithGNMIPathNormalization())
+	trie.Add("/network-instances/network-instance[name=default]/protocols/protocol[identifier=BGP][name=bgp]/state", 1)
+	trie.Add("/lldp/interfaces/interface[name=eth0]/neighbors/neighbor[id=a\\]b][chassis=x=y]", 2)
+
+	tests := []struct {
+		key  string
+		want interface{}
+	}{
+		{"/network-instances/network-instance[name=default]/protocols/protocol[name=bgp][identifier=BGP]/state", 1},
+		{"/network-instances/network-instance[ name=default ]/protocols/protocol[name=bgp] [identifier=BGP]/state", 1},
+		{"/lldp/interfaces/interface[name=eth0]/neighbors/neighbor[chassis=x=y][id=a\\]b]", 2},
+	}
+	for _, tt := range tests {
+		if v, ok := trie.Find(tt.key); !ok || v != tt.want {
+			t.Errorf("Find(%q) = %v, %v, want %v", tt.key, v, ok, tt.want)
+		}
+	}
+	trie.Add("/network-instances/network-instance[name=default]/protocols/protocol[name=bgp][identifier=BGP]/state", 3)
+	if trie.Size() != 2 {
+		t.Errorf("Size() = %d, want 2", trie.Size())
+	}
+	if keys := trie.FindByPrefix("/network-instances/network-instance[name=default]/protocols/protocol[name=bgp][identifier=BGP]"); len(keys) != 1 {
+		t.Errorf("FindByPrefix() = %v, want 1 key", keys)
+	}
+
+	folded := New(WithKeyTransform(strings.ToLower), WithGNMIPathNormalization())
+	folded.Add("/A[Y=1][X=2]", 1)
+	if v, ok := folded.Find("/a[x=2][y=1]"); !ok || v != 1 {
+		t.Errorf("Find() with the composed transform = %v, %v, want 1", v, ok)
+	}
+}
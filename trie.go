@@ -8,163 +8,494 @@
 package gtrie
 
 import (
+	"cmp"
+	"errors"
+	"slices"
 	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+	"unsafe"
 )
 
 // trieNode for the node structure of the R-Way Trie
 type trieNode struct {
 	rval      rune
-	path      string
 	term      bool
+	path      string
 	depth     int
 	value     interface{}
 	mask      uint64
 	parent    *trieNode
 	children  map[rune]*trieNode
 	termCount int
+	// link is the position in the insertion order (WithInsertionOrder).
+	link *orderLink
+	// gen is the generation of the trie (WithAtomicReads) in which the node is created.
+	// The node can be modified in place only in the same generation.
+	gen uint64
+	// segments is the dictionary (WithSegmentInterning) the path of
+	// the terminal node is encoded against. The path is the key if it is nil.
+	segments *segmentDict
+	// ext is the state maintained by WithSortedChildren, WithWideMask,
+	// WithFuzzyCounting and WithValueSizer.
+	// It is nil without the options; a pointer keeps the node
+	// in the 112-byte size class.
+	ext *nodeExt
 }
 
 // Trie for R-Way Trie
+//
+// The zero value of Trie is an empty trie ready to use without any option,
+// so that a Trie can be embedded in a struct literal. The root is created
+// on the first use. As the other types having a lock, a Trie must not be
+// copied after the first use.
+//
+// A nil *Trie is an empty trie that cannot be changed: the searches find
+// nothing, the mutations returning an error return ErrNilTrie and the others
+// are ignored, e.g. Add and Clear, or report nothing changed, e.g. AddChanged.
+// The methods building a new result from the keys, e.g. Compile and StreamTo,
+// build that of an empty trie.
 type Trie struct {
 	mu   sync.RWMutex
 	root *trieNode
-	size int
+	// rootOnce creates the root of the zero value.
+	rootOnce sync.Once
+	// size is only updated under the write lock,
+	// but it can be read without any lock.
+	size      atomic.Int64
+	digest    *digest
+	transform KeyTransform
+	metrics   MetricsSink
+	order     *order
+	arena     *arena
+	childCap  int
+	// published is the root read without lock in the atomic read mode.
+	// The root of the generation `gen` is published by unlock.
+	atomicReads bool
+	published   atomic.Pointer[trieNode]
+	gen         uint64
+	// loads tracks the in-flight FindOrLoad calls per key.
+	loadMu sync.Mutex
+	loads  map[string]*loadCall
+	// patterns is the root of the pattern trie built by AddPattern.
+	patterns *patternNode
+	// segments interns the segments of the keys (WithSegmentInterning).
+	segments *segmentDict
+	// limits rejects the keys exceeding them if set (WithMaxKeys and so on).
+	limits *limits
+	// sorted maintains the children of the nodes in rune order (WithSortedChildren).
+	sorted bool
+	// wide maintains the wide masks of the nodes (WithWideMask).
+	wide bool
+	// counting maintains the counts of the runes of the nodes (WithFuzzyCounting).
+	counting bool
+	// stamps is the timestamps of the terminal nodes (WithTimestamps).
+	stamps map[*trieNode]stamp
+	clock  func() time.Time
+	// prios is the priorities of the terminal nodes (AddWithPriority).
+	prios map[*trieNode]int
+	// tombs is the keys removed by RemoveSoft by their canonical keys.
+	tombs map[string]tombstone
+	// plocks is the locks of the partitions of the keys (WithPrefixLocks).
+	plocks *prefixLocks
+	// sizer is the size of the values maintained per node (WithValueSizer).
+	sizer func(v interface{}) int
+	// keyLess is the order of the keys of the ordered operations (WithKeyLess).
+	keyLess func(a, b string) bool
+	// unchanged reports whether the value added is the same as the old (WithSkipUnchanged).
+	unchanged func(old, new interface{}) bool
+	// ids is the keys interned by Intern indexed by their IDs minus one.
+	ids []string
+	// tracer is the hook of the mutations installed by SetTracer.
+	tracer *tracer
+	// expected is the nodes hinted by WithExpectedKeys, reserved by New.
+	expected int
+	// reserved is the nodes reserved for the keys to be added (Reserve)
+	// allocated until its limit.
+	reserved *arena
+	// cache is the results of the prefix queries cached (WithQueryCache).
+	cache *queryCache
 }
 
-// byKeys for fuzzy search
+// Option configures a Trie created by New.
+type Option func(t *Trie)
+
+// byKeys sorts the keys by length and then lexicographically for fuzzy search
 type byKeys []string
 
-func (a byKeys) Len() int           { return len(a) }
-func (a byKeys) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
-func (a byKeys) Less(i, j int) bool { return len(a[i]) < len(a[j]) }
+func (a byKeys) Len() int      { return len(a) }
+func (a byKeys) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a byKeys) Less(i, j int) bool {
+	if len(a[i]) != len(a[j]) {
+		return len(a[i]) < len(a[j])
+	}
+	return a[i] < a[j]
+}
 
 const nul = 0x0
 
 // New creates a new Trie with an initialized root trieNode.
-func New() *Trie {
-	return &Trie{
+func New(opts ...Option) *Trie {
+	t := &Trie{
 		root: &trieNode{children: make(map[rune]*trieNode), depth: 0},
-		size: 0,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	if t.atomicReads {
+		t.arena = nil
+		t.plocks = nil
+		t.gen = 1
+		t.published.Store(t.root)
+	} else if t.expected > 0 {
+		t.reserve(t.expected)
+	}
+	return t
+}
+
+// init creates the root of the zero value of the trie.
+func (t *Trie) init() {
+	t.rootOnce.Do(func() {
+		if t.root == nil {
+			t.root = &trieNode{children: make(map[rune]*trieNode)}
+		}
+	})
+}
+
+// lock takes the write lock of the trie.
+func (t *Trie) lock() {
+	t.init()
+	t.mu.Lock()
+	if t.plocks != nil {
+		t.plocks.fix(t.root)
+	}
+}
+
+// rlock takes the read lock of the trie, which is released by runlock.
+// It waits for the writers of all the partitions (WithPrefixLocks).
+func (t *Trie) rlock() {
+	t.init()
+	t.mu.RLock()
+	if t.plocks != nil {
+		t.plocks.rlockAll(t.root)
 	}
 }
 
 // Size returns the number of nodes inserted to the trie.
+// It is safe to call Size concurrently with the mutations without lock contention.
 func (t *Trie) Size() int {
-	return t.size
+	if t == nil {
+		return 0
+	}
+	return int(t.size.Load())
 }
 
+// The errors of the mutations returned by AddE and RemoveE.
+// ErrFrozen and ErrJournal are reserved for a read-only mode and a journal
+// of the mutations, which are not implemented yet.
+var (
+	// ErrFrozen is returned by the mutations of a trie that does not accept them anymore.
+	ErrFrozen = errors.New("gtrie: trie frozen")
+	// ErrJournal is returned by the mutations failing to record them to a journal.
+	ErrJournal = errors.New("gtrie: journal write failed")
+	// ErrKeyTooLong is returned if a key is longer than WithMaxKeyLength.
+	ErrKeyTooLong = errors.New("gtrie: key too long")
+	// ErrTrieFull is returned if a new key is added to the trie having WithMaxKeys keys.
+	ErrTrieFull = errors.New("gtrie: trie full")
+	// ErrTooManyChildren is returned if a new key makes a node have
+	// more children than WithMaxChildren.
+	ErrTooManyChildren = errors.New("gtrie: too many children")
+	// ErrTooManyBytes is returned if a value makes the sizes of the values
+	// of the trie exceed WithMaxBytes.
+	ErrTooManyBytes = errors.New("gtrie: too many bytes")
+	// ErrNilTrie is returned by the mutations of a nil *Trie.
+	ErrNilTrie = errors.New("gtrie: nil trie")
+)
+
 // Add adds a key to the Trie, including a value. The value
 // is stored as `interface{}` and must be type cast by the caller.
 // Upon the Add(), the old value added with the same key is removed from the trie.
+// Add is AddE ignoring the error: the key rejected by the limits of the trie
+// (e.g. WithMaxKeys) is not added and only reported to the hook of WithOnReject.
 func (t *Trie) Add(key string, value interface{}) {
-	t.mu.Lock()
-	defer t.mu.Unlock()
+	_ = t.AddE(key, value)
+}
+
+// AddE adds a key and value like Add, but returns the error if the key is
+// not added, e.g. ErrKeyTooLong, ErrTrieFull or ErrTooManyChildren
+// for the limits of the trie.
+func (t *Trie) AddE(key string, value interface{}) error {
+	if t == nil {
+		return ErrNilTrie
+	}
+	_, err := t.addE(key, value)
+	return err
+}
+
+// addE is AddE returning true if the key is added new.
+func (t *Trie) addE(key string, value interface{}) (bool, error) {
+	if t.plocks != nil {
+		if added, ok := t.addPartition(key, value); ok {
+			return added, nil
+		}
+	}
+	t.lock()
+	size := t.Size()
+	err := t.add(key, value)
+	added := t.Size() > size
+	t.unlock()
+	if err != nil {
+		t.reject(key, err)
+	}
+	return added, err
+}
+
+// add adds the key and value under the write lock.
+// It returns the error if the key is rejected by the limits of the trie.
+func (t *Trie) add(key string, value interface{}) error {
+	_, err := t.addChanged(key, value)
+	return err
+}
+
+// addChanged is add returning false without the error
+// if the value is unchanged by WithSkipUnchanged.
+func (t *Trie) addChanged(key string, value interface{}) (bool, error) {
+	var old *trieNode
 	cnt := 1
-	runes := []rune(key)
+	if t.limits != nil {
+		if err := t.limits.checkLength(key); err != nil {
+			return false, err
+		}
+	}
+	ckey := t.canonical(key)
 	// check the node exists
-	if node := findNode(t.root, runes); node != nil {
+	if node := findNode(t.root, ckey); node != nil {
 		if node, ok := node.children[nul]; ok && node.term {
+			old = node
 			cnt = 0
 		}
 	}
+	if old != nil && t.unchanged != nil && t.unchanged(old.value, value) {
+		return false, nil
+	}
+	if old == nil && t.limits != nil {
+		if err := t.limits.checkNew(t.root, t.Size(), ckey); err != nil {
+			return false, err
+		}
+	}
+	if t.limits != nil && t.limits.maxBytes > 0 && t.sizer != nil {
+		if err := t.checkBytes(old, value); err != nil {
+			return false, err
+		}
+	}
+	runes := []rune(ckey)
 
-	t.size = t.size + cnt
+	t.size.Add(int64(cnt))
+	var wides []wideMask
+	if t.wide {
+		wides = wideSuffixes(runes)
+	}
+	var counts []runeCounts
+	if t.counting {
+		counts = countSuffixes(runes)
+	}
 	bitmask := maskruneslice(runes)
-	node := t.root
+	node := t.writableRoot()
 	node.mask |= bitmask
 	node.termCount = node.termCount + cnt
+	if wides != nil {
+		node.ext.wide.or(&wides[0])
+	}
+	if counts != nil {
+		node.ext.counts.max(&counts[0])
+	}
 	for i := range runes {
 		r := runes[i]
 		bitmask = maskruneslice(runes[i:])
 		if n, ok := node.children[r]; ok {
-			node = n
+			node = t.writable(node, n)
 			node.mask |= bitmask
 		} else {
-			node = node.newChild(r, "", bitmask, nil, false)
+			node = node.newChild(t.nodeArena(), t.childCap, r, "", bitmask, nil, false)
+			node.gen = t.gen
 		}
 		node.termCount = node.termCount + cnt
+		if wides != nil {
+			node.ext.wide.or(&wides[i])
+		}
+		if counts != nil {
+			node.ext.counts.max(&counts[i])
+		}
+	}
+	t.newTerm(node, old, key, value)
+	return true, nil
+}
+
+// newTerm adds the terminal node of the `key` and `value` under the writable
+// `parent`, replacing the terminal node `old` if not nil. The size and
+// the term counts of the trie are not updated.
+func (t *Trie) newTerm(parent, old *trieNode, key string, value interface{}) *trieNode {
+	path := key
+	if t.segments != nil {
+		path = t.segments.intern(key)
+	}
+	node := parent.newChild(t.nodeArena(), 0, nul, path, 0, value, true)
+	node.gen = t.gen
+	node.segments = t.segments
+	if t.tombs != nil {
+		// the key added, also by Merge, is revived if soft-removed.
+		delete(t.tombs, t.canonical(key))
+	}
+	if t.digest != nil {
+		t.digest.replace(old, node)
+	}
+	if t.sizer != nil {
+		addBytes(parent, t.valueSize(node)-t.valueSize(old))
+	}
+	if t.order != nil {
+		t.order.replace(old, node)
+	}
+	if t.stamps != nil {
+		t.stamp(old, node)
+	}
+	if old != nil && t.prios != nil {
+		delete(t.prios, old)
+	}
+	if old != nil && t.slabbed() {
+		// the slab must not keep the value replaced.
+		t.retire(old)
+		t.arena.release(1)
 	}
-	node = node.newChild(nul, key, 0, value, true)
+	if t.metrics != nil {
+		t.metrics.IncCounter(MetricAdd)
+	}
+	if t.tracer != nil {
+		t.trace(TraceAdd, key, "")
+	}
+	return node
 }
 
 // Find finds the value of the key matching to the input `key` exactly.
 func (t *Trie) Find(key string) (interface{}, bool) {
-	t.mu.RLock()
-	defer t.mu.RUnlock()
-	node := findNode(t.root, []rune(key))
-	if node == nil {
+	if t == nil {
 		return nil, false
 	}
-
-	node, ok := node.children[nul]
-	if !ok || !node.term {
+	if t.plocks != nil {
+		if value, found, ok := t.findPartition(key); ok {
+			return value, found
+		}
+	}
+	root := t.readRoot()
+	defer t.readDone()
+	node := findNode(root, t.canonical(key))
+	if node != nil {
+		node = node.children[nul]
+	}
+	if node == nil || !node.term {
+		if t.metrics != nil {
+			t.metrics.IncCounter(MetricFindMiss)
+		}
 		return nil, false
 	}
+	if t.metrics != nil {
+		t.metrics.IncCounter(MetricFindHit)
+	}
 	return node.value, true
 }
 
 // Remove removes the `key` from the trie and return the value,
 // ensuring that all bitmasks up to root are appropriately recalculated.
 func (t *Trie) Remove(key string) interface{} {
-	t.mu.Lock()
-	defer t.mu.Unlock()
+	value, _ := t.RemoveE(key)
+	return value
+}
+
+// RemoveE removes the key like Remove, but returns the error if the key
+// cannot be removed. The value is nil without error if the key does not exist.
+func (t *Trie) RemoveE(key string) (interface{}, error) {
+	if t == nil {
+		return nil, ErrNilTrie
+	}
+	if t.plocks != nil {
+		if value, ok := t.removePartition(key); ok {
+			return value, nil
+		}
+	}
+	t.lock()
+	defer t.unlock()
+	value, _ := t.remove(key)
+	return value, nil
+}
+
+// remove removes the key under the write lock.
+// It returns the value removed and true if the key existed.
+func (t *Trie) remove(key string) (interface{}, bool) {
 	var (
-		i     int
-		r     rune
 		value interface{}
-		rs    = []rune(key)
-		node  = findNode(t.root, []rune(key))
+		ckey  = t.canonical(key)
+		node  = findNode(t.root, ckey)
 	)
 	if node == nil {
-		return nil
+		return nil, false
 	}
 	target, ok := node.children[nul]
 	if !ok || !target.term {
-		return nil
+		return nil, false
+	}
+	if t.atomicReads {
+		node = t.writablePath(ckey)
 	}
 	value = target.value
-	target.children = nil
-	target.parent = nil
-	target.value = nil
-	t.size--
+	if t.digest != nil {
+		t.digest.replace(target, nil)
+	}
+	if t.sizer != nil {
+		addBytes(node, -t.valueSize(target))
+	}
+	if t.order != nil {
+		t.order.unlink(target)
+	}
+	delete(t.stamps, target)
+	delete(t.prios, target)
+	if t.metrics != nil {
+		t.metrics.IncCounter(MetricRemove)
+	}
+	if t.tracer != nil {
+		t.trace(TraceRemove, key, "")
+	}
+	t.size.Add(-1)
+	t.arena.release(1)
 	node.removeChild(nul)
+	t.retire(target)
+	// changed is the deepest node left on the path, whose children are changed,
+	// and lost is the mask of its child removed; none if only the terminal node.
+	changed, lost := node, uint64(0)
 	for node.parent != nil {
 		node.termCount--
 		parent := node.parent
 		if len(node.children) <= 0 {
-			i++
-			r = rs[len(rs)-i]
-			parent.removeChild(r)
-			node.parent = nil
-			node.value = nil
-			node.children = nil
+			parent.removeChild(node.rval)
+			t.arena.release(1)
+			t.retire(node)
+			changed, lost = parent, node.mask
 		}
-		// fmt.Printf("key %s, parent.rval %c n.rval %c r %c\n", target.path, n.parent.rval, n.rval, r)
 		node = parent
 	}
 	node.termCount--
-	updateMask(node)
-	return value
+	updateMask(changed, lost)
+	return value, true
 }
 
 // Clear removes all the keys and values of the trie.
 func (t *Trie) Clear() {
-	t.mu.Lock()
-	node := t.root
-	for r, c := range node.children {
-		delete(node.children, r)
-		removeAll(c)
-	}
-	node.rval = 0
-	node.path = ""
-	node.term = false
-	node.depth = 0
-	node.value = nil
-	node.mask = uint64(0)
-	node.parent = nil
-	node.termCount = 0
-	t.mu.Unlock()
+	if t == nil {
+		return
+	}
+	t.lock()
+	t.clear()
+	t.unlock()
 
 	// keys := t.FindByPrefix("")
 	// for i := range keys {
@@ -172,36 +503,138 @@ func (t *Trie) Clear() {
 	// }
 }
 
+// Drain removes all the keys and values of the trie like Clear and returns
+// the keys and values removed, e.g. to release the resources held by the values.
+// The keys are collected and removed under the same write lock, so that
+// every key added before is either returned or left to the next Drain.
+func (t *Trie) Drain() map[string]interface{} {
+	if t == nil {
+		return nil
+	}
+	t.lock()
+	m := collectAll(t.root)
+	t.clear()
+	t.unlock()
+	return m
+}
+
+// ClearFunc removes all the keys and values of the trie like Drain and calls
+// `fn` with each key and value removed in lexicographic order of the keys
+// after the write lock is released, so that `fn` may use the trie.
+func (t *Trie) ClearFunc(fn func(key string, v interface{})) {
+	if t == nil {
+		return
+	}
+	t.lock()
+	kvs := nodeKVs(collectNodes(t.root))
+	t.clear()
+	t.unlock()
+	for _, kv := range kvs {
+		fn(kv.Key, kv.Value)
+	}
+}
+
+// clear removes all the keys and values under the write lock.
+func (t *Trie) clear() {
+	if t.atomicReads {
+		t.root = &trieNode{children: make(map[rune]*trieNode), gen: t.gen, ext: t.root.ext.empty()}
+	} else {
+		// the children are detached at once, not removed one by one.
+		node := t.root
+		for _, c := range node.children {
+			t.retire(c)
+		}
+		node.children = make(map[rune]*trieNode)
+		node.rval = 0
+		node.path = ""
+		node.term = false
+		node.depth = 0
+		node.value = nil
+		node.mask = uint64(0)
+		node.parent = nil
+		node.termCount = 0
+		node.ext = node.ext.empty()
+	}
+	t.size.Store(0)
+	if t.digest != nil {
+		t.digest.reset()
+	}
+	clear(t.stamps)
+	clear(t.prios)
+	clear(t.tombs)
+	if t.order != nil {
+		t.order.reset()
+	}
+	if t.arena != nil {
+		t.arena = newArena(t.arena.size)
+	}
+	t.reserved = nil
+	if t.tracer != nil {
+		t.trace(TraceClear, "", "")
+	}
+}
+
 // FindByFuzzy performs a fuzzy search (Approximate string matching) against the keys in the trie.
 func (t *Trie) FindByFuzzy(key string) []string {
-	t.mu.RLock()
-	defer t.mu.RUnlock()
-	keys := fuzzycollect(t.root, []rune(key))
+	if t == nil {
+		return nil
+	}
+	root := t.readRoot()
+	defer t.readDone()
+	if t.metrics != nil {
+		defer t.observe(MetricSearchFuzzy, time.Now())
+	}
+	keys := fuzzycollect(root, t.runes(key))
 	sort.Sort(byKeys(keys))
 	return keys
 }
 
 // FindByFuzzyValue performs a fuzzy search (Approximate string matching) against the keys in the trie.
 func (t *Trie) FindByFuzzyValue(key string) []interface{} {
-	t.mu.RLock()
-	defer t.mu.RUnlock()
-	values := fuzzycollectValues(t.root, []rune(key))
+	if t == nil {
+		return nil
+	}
+	root := t.readRoot()
+	defer t.readDone()
+	if t.metrics != nil {
+		defer t.observe(MetricSearchFuzzy, time.Now())
+	}
+	values := fuzzycollectValues(root, t.runes(key))
 	return values
 }
 
 // FindByFuzzyAll performs a fuzzy search (Approximate string matching) against the keys in the trie.
 func (t *Trie) FindByFuzzyAll(key string) map[string]interface{} {
-	t.mu.RLock()
-	defer t.mu.RUnlock()
-	return fuzzycollectAll(t.root, []rune(key))
+	if t == nil {
+		return nil
+	}
+	root := t.readRoot()
+	defer t.readDone()
+	if t.metrics != nil {
+		defer t.observe(MetricSearchFuzzy, time.Now())
+	}
+	return fuzzycollectAll(root, t.runes(key))
 }
 
 // FindByPrefix performs a prefix search against the keys in the trie.
 // It returns all the keys starting with `prefix` in the trie.
 func (t *Trie) FindByPrefix(prefix string) []string {
-	t.mu.RLock()
-	defer t.mu.RUnlock()
-	node := findNode(t.root, []rune(prefix))
+	if t == nil {
+		return nil
+	}
+	if t.cache != nil {
+		return t.cachedPrefix(prefix)
+	}
+	return t.findByPrefix(prefix)
+}
+
+func (t *Trie) findByPrefix(prefix string) []string {
+	root := t.readRoot()
+	defer t.readDone()
+	if t.metrics != nil {
+		defer t.observe(MetricSearchPrefix, time.Now())
+	}
+	node := findNode(root, t.canonical(prefix))
 	if node == nil {
 		return nil
 	}
@@ -210,9 +643,15 @@ func (t *Trie) FindByPrefix(prefix string) []string {
 
 // FindByPrefixValue returns all the values that have a key starting with `prefix`.
 func (t *Trie) FindByPrefixValue(prefix string) []interface{} {
-	t.mu.RLock()
-	defer t.mu.RUnlock()
-	node := findNode(t.root, []rune(prefix))
+	if t == nil {
+		return nil
+	}
+	root := t.readRoot()
+	defer t.readDone()
+	if t.metrics != nil {
+		defer t.observe(MetricSearchPrefix, time.Now())
+	}
+	node := findNode(root, t.canonical(prefix))
 	if node == nil {
 		return nil
 	}
@@ -221,9 +660,22 @@ func (t *Trie) FindByPrefixValue(prefix string) []interface{} {
 
 // FindByPrefixAll returns all the keys and values starting with `prefix`.
 func (t *Trie) FindByPrefixAll(prefix string) map[string]interface{} {
-	t.mu.RLock()
-	defer t.mu.RUnlock()
-	node := findNode(t.root, []rune(prefix))
+	if t == nil {
+		return nil
+	}
+	if t.cache != nil {
+		return t.cachedPrefixAll(prefix)
+	}
+	return t.findByPrefixAll(prefix)
+}
+
+func (t *Trie) findByPrefixAll(prefix string) map[string]interface{} {
+	root := t.readRoot()
+	defer t.readDone()
+	if t.metrics != nil {
+		defer t.observe(MetricSearchPrefix, time.Now())
+	}
+	node := findNode(root, t.canonical(prefix))
 	if node == nil {
 		return nil
 	}
@@ -232,34 +684,180 @@ func (t *Trie) FindByPrefixAll(prefix string) map[string]interface{} {
 
 // HasPrefix returns true if any of the keys in the trie starts with `prefix`.
 func (t *Trie) HasPrefix(prefix string) bool {
-	t.mu.RLock()
-	defer t.mu.RUnlock()
-	node := findNode(t.root, []rune(prefix))
+	if t == nil {
+		return false
+	}
+	root := t.readRoot()
+	defer t.readDone()
+	node := findNode(root, t.canonical(prefix))
 	return node != nil
 }
 
+// HasPrefixAny returns the first of the `prefixes` that any of the keys
+// in the trie starts with, and true if found.
+func (t *Trie) HasPrefixAny(prefixes []string) (string, bool) {
+	if t == nil {
+		return "", false
+	}
+	t.rlock()
+	defer t.runlock()
+	for _, prefix := range prefixes {
+		node := findNode(t.root, t.canonical(prefix))
+		if node != nil && node.termCount > 0 {
+			return prefix, true
+		}
+	}
+	return "", false
+}
+
+// OverlapsPrefix returns true if the trie and the `other` trie have
+// keys sharing a common prefix of `minDepth` runes or more.
+// Both tries are walked in lockstep down the shared runes.
+func (t *Trie) OverlapsPrefix(other *Trie, minDepth int) bool {
+	if t == nil {
+		return false
+	}
+	if other == nil {
+		return false
+	}
+	defer rlockBoth(t, other)()
+	return overlapcollect(t.root, other.root, minDepth)
+}
+
+// rlockBoth takes the read locks of the tries `a` and `b` and returns the function
+// releasing them. The tries are locked in the address order to avoid the deadlock
+// with the concurrent call of the same operation with `a` and `b` swapped.
+func rlockBoth(a, b *Trie) func() {
+	if a == b {
+		a.rlock()
+		return a.runlock
+	}
+	first, second := a, b
+	if uintptr(unsafe.Pointer(first)) > uintptr(unsafe.Pointer(second)) {
+		first, second = second, first
+	}
+	first.rlock()
+	second.rlock()
+	return func() {
+		second.runlock()
+		first.runlock()
+	}
+}
+
+// LongestCommonPrefix returns the longest string shared by all the keys starting with `prefix`.
+// It returns "" if no key starts with `prefix`.
+func (t *Trie) LongestCommonPrefix(prefix string) string {
+	if t == nil {
+		return ""
+	}
+	t.rlock()
+	defer t.runlock()
+	runes := t.runes(prefix)
+	node := findNode(t.root, string(runes))
+	if node == nil || node.termCount <= 0 {
+		return ""
+	}
+	// descend while the node has a single child that is not a terminal.
+	for len(node.children) == 1 {
+		var child *trieNode
+		for _, c := range node.children {
+			child = c
+		}
+		if child.rval == nul {
+			break
+		}
+		runes = append(runes, child.rval)
+		node = child
+	}
+	return string(runes)
+}
+
 // Keys returns all the keys.
-func (t *Trie) Keys() []string {
+// If `prefix` is given, it returns all the keys starting with the `prefix` like FindByPrefix.
+func (t *Trie) Keys(prefix ...string) []string {
+	if len(prefix) > 0 {
+		return t.FindByPrefix(prefix[0])
+	}
 	return t.FindByPrefix("")
 }
 
 // Values returns all the values.
 func (t *Trie) Values() []interface{} {
-	t.mu.RLock()
-	defer t.mu.RUnlock()
-	node := findNode(t.root, []rune(""))
+	if t == nil {
+		return nil
+	}
+	t.rlock()
+	defer t.runlock()
+	node := findNode(t.root, "")
 	if node == nil {
 		return nil
 	}
 	return collectValues(node)
 }
 
+// DistinctValues returns the distinct values of all the keys.
+// Two values are regarded as the same if `equal` returns true.
+// If `equal` is nil, the values are compared by == and must be comparable.
+func (t *Trie) DistinctValues(equal func(a, b interface{}) bool) []interface{} {
+	if t == nil {
+		return nil
+	}
+	t.rlock()
+	defer t.runlock()
+	var values []interface{}
+	seen := make(map[interface{}]struct{})
+	for _, n := range collectNodes(t.root) {
+		if equal == nil {
+			if _, ok := seen[n.value]; ok {
+				continue
+			}
+			seen[n.value] = struct{}{}
+			values = append(values, n.value)
+			continue
+		}
+		found := false
+		for i := range values {
+			if equal(values[i], n.value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			values = append(values, n.value)
+		}
+	}
+	return values
+}
+
+// ValuesWhere returns all the values of which the key and value satisfy `pred`.
+func (t *Trie) ValuesWhere(pred func(key string, v interface{}) bool) []interface{} {
+	if t == nil {
+		return nil
+	}
+	t.rlock()
+	defer t.runlock()
+	var values []interface{}
+	for _, n := range collectNodes(t.root) {
+		if pred(n.key(), n.value) {
+			values = append(values, n.value)
+		}
+	}
+	return values
+}
+
 // All returns a map for all matched keys and values.
-// All the key of the map starts with `prefix`.
-func (t *Trie) All() map[string]interface{} {
-	t.mu.RLock()
-	defer t.mu.RUnlock()
-	node := findNode(t.root, []rune(""))
+// If `prefix` is given, all the key of the map starts with the `prefix`.
+func (t *Trie) All(prefix ...string) map[string]interface{} {
+	if t == nil {
+		return nil
+	}
+	var pre string
+	if len(prefix) > 0 {
+		pre = prefix[0]
+	}
+	t.rlock()
+	defer t.runlock()
+	node := findNode(t.root, t.canonical(pre))
 	if node == nil {
 		return nil
 	}
@@ -270,40 +868,96 @@ func (t *Trie) All() map[string]interface{} {
 // from the trie and then returns the its key and inserted value.
 // the key found is the longest matched prefix of the input `key`.
 func (t *Trie) FindLongestMatchingPrefix(key string) (string, interface{}, bool) {
-	t.mu.RLock()
-	defer t.mu.RUnlock()
+	if t == nil {
+		return "", nil, false
+	}
+	root := t.readRoot()
+	defer t.readDone()
+	if t.metrics != nil {
+		defer t.observe(MetricSearchLongestPrefix, time.Now())
+	}
+	found, _ := longestprefix(root, t.canonical(key))
+	if found == nil {
+		return "", nil, false
+	}
+	return found.key(), found.value, true
+}
+
+// FindLongestMatchingPrefixSplit finds the longest matching prefix of the `key`
+// like FindLongestMatchingPrefix, but splits the input `key` into the prefix matched
+// and the remaining suffix so that prefix+suffix is the `key` exactly.
+// The split is made at the byte offset of the match, never in the middle of a rune.
+// With a key transform, the prefix is the shortest prefix of the `key` converted to
+// the canonical prefix matched; if there is none, ok is false and the suffix is the `key`.
+func (t *Trie) FindLongestMatchingPrefixSplit(key string) (prefix, suffix string, value interface{}, ok bool) {
+	if t == nil {
+		return "", key, nil, false
+	}
+	root := t.readRoot()
+	defer t.readDone()
+	if t.metrics != nil {
+		defer t.observe(MetricSearchLongestPrefix, time.Now())
+	}
+	ckey := t.canonical(key)
+	found, end := longestprefix(root, ckey)
+	if found == nil {
+		return "", key, nil, false
+	}
+	if t.transform == nil {
+		return key[:end], key[end:], found.value, true
+	}
+	for i := range key {
+		if t.transform(key[:i]) == ckey[:end] {
+			return key[:i], key[i:], found.value, true
+		}
+	}
+	if end == len(ckey) {
+		return key, "", found.value, true
+	}
+	return "", key, nil, false
+}
+
+// longestprefix returns the terminal node of the longest key that is a prefix of `key`
+// and the byte length of the prefix.
+func longestprefix(node *trieNode, key string) (*trieNode, int) {
 	var found *trieNode
-	node := t.root
+	var end int
 	if node == nil {
-		return "", nil, false
+		return nil, 0
 	}
-	for _, r := range []rune(key) {
+	for i := 0; i < len(key); {
+		r, size := utf8.DecodeRuneInString(key[i:])
 		n, ok := node.children[r]
 		if !ok {
 			break
 		}
+		i += size
 		t, ok := n.children[nul]
 		if ok && t.term {
 			found = t
+			end = i
 		}
 		node = n
 	}
-	if found == nil {
-		return "", nil, false
-	}
-	return found.path, found.value, true
+	return found, end
 }
 
 // FindMatchingPrefix finds all the matching prefixes against to the input `key`.
 // The keys returned are the prefixes of the input `key`.
 func (t *Trie) FindMatchingPrefix(key string) ([]string, bool) {
-	t.mu.RLock()
-	defer t.mu.RUnlock()
+	if t == nil {
+		return nil, false
+	}
+	t.rlock()
+	defer t.runlock()
+	if t.metrics != nil {
+		defer t.observe(MetricSearchMatchingPrefix, time.Now())
+	}
 	nodes, ok := t.findPrefixMatchNodes(key)
 	if ok {
 		keys := make([]string, 0, len(nodes))
 		for _, n := range nodes {
-			keys = append(keys, n.path)
+			keys = append(keys, n.key())
 		}
 		return keys, true
 	}
@@ -313,8 +967,14 @@ func (t *Trie) FindMatchingPrefix(key string) ([]string, bool) {
 // FindMatchingPrefixValue finds all the matched prefix keys against to the input `key`.
 // The values of the matched keys are returned.
 func (t *Trie) FindMatchingPrefixValue(key string) []interface{} {
-	t.mu.RLock()
-	defer t.mu.RUnlock()
+	if t == nil {
+		return nil
+	}
+	t.rlock()
+	defer t.runlock()
+	if t.metrics != nil {
+		defer t.observe(MetricSearchMatchingPrefix, time.Now())
+	}
 	nodes, ok := t.findPrefixMatchNodes(key)
 	if ok {
 		vals := make([]interface{}, 0, len(nodes))
@@ -329,33 +989,77 @@ func (t *Trie) FindMatchingPrefixValue(key string) []interface{} {
 // FindMatchingPrefixAll finds all the matched prefix keys and the values against to
 // the input `key`. The keys returned are the prefixes of the input `key`.
 func (t *Trie) FindMatchingPrefixAll(key string) map[string]interface{} {
-	t.mu.RLock()
-	defer t.mu.RUnlock()
+	if t == nil {
+		return nil
+	}
+	t.rlock()
+	defer t.runlock()
+	if t.metrics != nil {
+		defer t.observe(MetricSearchMatchingPrefix, time.Now())
+	}
 	m := make(map[string]interface{})
 	nodes, ok := t.findPrefixMatchNodes(key)
 	if ok {
 		for _, n := range nodes {
-			m[n.path] = n.value
+			m[n.key()] = n.value
 		}
 	}
 	return m
 }
 
+// PrefixHit is a key matched as a prefix of the input key of FindMatchingPrefixOrdered.
+type PrefixHit struct {
+	Key   string
+	Value interface{}
+	// Depth is the length of the prefix in runes (of the canonical form with a key transform).
+	Depth int
+}
+
+// FindMatchingPrefixOrdered finds all the matched prefix keys against to the input `key`
+// and returns them with the values and the depths ordered from the shortest
+// to the longest prefix, so that the most specific prefix is the last.
+func (t *Trie) FindMatchingPrefixOrdered(key string) []PrefixHit {
+	if t == nil {
+		return nil
+	}
+	t.rlock()
+	defer t.runlock()
+	if t.metrics != nil {
+		defer t.observe(MetricSearchMatchingPrefix, time.Now())
+	}
+	nodes, ok := t.findPrefixMatchNodes(key)
+	if !ok {
+		return nil
+	}
+	hits := make([]PrefixHit, 0, len(nodes))
+	for _, n := range nodes {
+		// the terminal node is a child of the node of the last rune.
+		hits = append(hits, PrefixHit{Key: n.key(), Value: n.value, Depth: n.depth - 1})
+	}
+	return hits
+}
+
 // FindAll finds all relative prefix keys against to the input `key` and
 // all matched keys that starts with the input `key` in the trie.
 // It returns the result of (FindByPrefixAll() + FindMatchingPrefixAll())
 func (t *Trie) FindAll(key string) map[string]interface{} {
-	t.mu.RLock()
-	defer t.mu.RUnlock()
+	if t == nil {
+		return nil
+	}
+	t.rlock()
+	defer t.runlock()
+	if t.metrics != nil {
+		defer t.observe(MetricSearchMatchingPrefix, time.Now())
+	}
 	m := make(map[string]interface{})
-	node := findNode(t.root, []rune(key))
+	node := findNode(t.root, t.canonical(key))
 	if node != nil {
 		m = collectAll(node)
 	}
 	nodes, ok := t.findPrefixMatchNodes(key)
 	if ok {
 		for _, n := range nodes {
-			m[n.path] = n.value
+			m[n.key()] = n.value
 		}
 	}
 	return m
@@ -369,11 +1073,14 @@ func (t *Trie) findPrefixMatchNodes(key string) ([]*trieNode, bool) {
 	if node == nil {
 		return nil, false
 	}
-	if t.size <= 0 {
+	size := t.size.Load()
+	if size <= 0 {
 		return nil, false
 	}
-	nodes := make([]*trieNode, 0, t.size)
-	for _, r := range []rune(key) {
+	// the keys matched are bounded by the runes of the key.
+	key = t.canonical(key)
+	nodes := make([]*trieNode, 0, min(size, int64(utf8.RuneCountInString(key))))
+	for _, r := range key {
 		n, ok := node.children[r]
 		if !ok {
 			break
@@ -392,78 +1099,110 @@ func (t *Trie) findPrefixMatchNodes(key string) ([]*trieNode, bool) {
 }
 
 // Creates and returns a pointer to a new child for the node.
-func (n *trieNode) newChild(rval rune, path string, bitmask uint64, value interface{}, term bool) *trieNode {
-	node := &trieNode{
+// The child is allocated from the arena `a` if it is not nil
+// and its children map is created with the capacity `capacity`.
+func (n *trieNode) newChild(a *arena, capacity int, rval rune, path string, bitmask uint64, value interface{}, term bool) *trieNode {
+	node := a.newNode()
+	*node = trieNode{
 		rval:     rval,
 		path:     path,
 		mask:     bitmask,
 		term:     term,
 		value:    value,
 		parent:   n,
-		children: make(map[rune]*trieNode),
+		children: make(map[rune]*trieNode, capacity),
 		depth:    n.depth + 1,
 	}
-	n.children[node.rval] = node
+	if rval != nul {
+		node.ext = n.ext.empty()
+	}
+	n.setChild(node)
 	n.mask |= bitmask
 	return node
 }
 
-// removeChild removes the child
+// setChild adds the child `c` or replaces the child of the same rune with it.
+func (n *trieNode) setChild(c *trieNode) {
+	n.children[c.rval] = c
+	sorted := n.ordered()
+	if sorted == nil {
+		return
+	}
+	i, found := slices.BinarySearchFunc(*sorted, c.rval, func(e *trieNode, r rune) int {
+		return cmp.Compare(e.rval, r)
+	})
+	if found {
+		(*sorted)[i] = c
+		return
+	}
+	*sorted = slices.Insert(*sorted, i, c)
+}
+
+// removeChild removes the child.
+// The masks are not updated; call updateMask on the node after the removals.
 func (n *trieNode) removeChild(r rune) {
 	delete(n.children, r)
-	updateMask(n.parent)
-	// for nd := n.parent; nd != nil; nd = nd.parent {
-	// 	nd.mask ^= nd.mask
-	// 	nd.mask |= uint64(1) << uint64(nd.rval-'a')
-	// 	for _, c := range nd.children {
-	// 		nd.mask |= c.mask
-	// 	}
-	// }
+	sorted := n.ordered()
+	if sorted == nil {
+		return
+	}
+	i, found := slices.BinarySearchFunc(*sorted, r, func(e *trieNode, r rune) int {
+		return cmp.Compare(e.rval, r)
+	})
+	if found {
+		// slices.Delete zeroes the last element not to keep the child removed.
+		*sorted = slices.Delete(*sorted, i, i+1)
+	}
 }
 
-// updateMask updates n.mask
-func updateMask(node *trieNode) {
+// updateMask updates the masks of the node and its ancestors bottom-up
+// after the bits `lost` may be gone from the masks of the children of the node,
+// e.g. the mask of a child removed. Only the bits of `lost` are looked up in
+// the children (uncovered), and the bits cleared from a node are the only bits
+// its parent may lose. It stops at the first node whose masks are unchanged
+// since the masks above it are not affected.
+func updateMask(node *trieNode, lost uint64) {
 	for ; node != nil; node = node.parent {
-		node.mask ^= node.mask
-		node.mask |= uint64(1) << uint64(node.rval-'a')
-		for _, c := range node.children {
-			node.mask |= c.mask
+		// the wide mask and the counts may change without the 64-bit mask.
+		wide, counts := updateWide(node), updateCounts(node)
+		lost = uncovered(node, lost)
+		if !wide && !counts && lost == 0 {
+			return
 		}
+		node.mask &^= lost
 	}
 }
 
-func removeAll(node *trieNode) {
-	for r, c := range node.children {
-		delete(node.children, r)
-		removeAll(c)
+// uncovered returns the bits of `lost` in the mask of the node that are
+// neither the bit of its own rune nor in the masks of its children.
+// The children are looked up only until all the bits are found.
+func uncovered(n *trieNode, lost uint64) uint64 {
+	lost &= n.mask &^ (uint64(1) << uint64(n.rval-'a'))
+	for _, c := range n.children {
+		if lost == 0 {
+			break
+		}
+		lost &^= c.mask
 	}
-	node.parent = nil
-	node.children = nil
-	node.value = nil
+	return lost
 }
 
-func findNode(node *trieNode, runes []rune) *trieNode {
-	if node == nil {
-		return nil
-	}
-
-	if len(runes) == 0 {
-		return node
-	}
-
-	n, ok := node.children[runes[0]]
-	if !ok {
-		return nil
-	}
-
-	var nrunes []rune
-	if len(runes) > 1 {
-		nrunes = runes[1:]
-	} else {
-		nrunes = runes[0:0]
+// findNode finds the node reachable from the node by the runes of `key`.
+// The runes are decoded from `key` in place without any allocation;
+// the ASCII bytes take the fast path.
+func findNode(node *trieNode, key string) *trieNode {
+	for i := 0; node != nil && i < len(key); {
+		r := rune(key[i])
+		if r < utf8.RuneSelf {
+			i++
+		} else {
+			var size int
+			r, size = utf8.DecodeRuneInString(key[i:])
+			i += size
+		}
+		node = node.children[r]
 	}
-
-	return findNode(n, nrunes)
+	return node
 }
 
 func maskruneslice(rs []rune) uint64 {
@@ -475,70 +1214,18 @@ func maskruneslice(rs []rune) uint64 {
 }
 
 func collect(node *trieNode) []string {
-	var (
-		n *trieNode
-		i int
-	)
-	keys := make([]string, 0, node.termCount)
-	nodes := make([]*trieNode, 1, len(node.children)+1)
-	nodes[0] = node
-	for l := len(nodes); l != 0; l = len(nodes) {
-		i = l - 1
-		n = nodes[i]
-		nodes = nodes[:i]
-		for _, c := range n.children {
-			nodes = append(nodes, c)
-		}
-		if n.term {
-			word := n.path
-			keys = append(keys, word)
-		}
-	}
-	return keys
+	return appendKeys(make([]string, 0, node.termCount), node)
 }
 
 func collectValues(node *trieNode) []interface{} {
-	var (
-		n *trieNode
-		i int
-	)
-	values := make([]interface{}, 0, node.termCount)
-	nodes := make([]*trieNode, 1, len(node.children)+1)
-	nodes[0] = node
-	for l := len(nodes); l != 0; l = len(nodes) {
-		i = l - 1
-		n = nodes[i]
-		nodes = nodes[:i]
-		for _, c := range n.children {
-			nodes = append(nodes, c)
-		}
-		if n.term {
-			values = append(values, n.value)
-		}
-	}
-	return values
+	return appendValues(make([]interface{}, 0, node.termCount), node)
 }
 
 func collectAll(node *trieNode) map[string]interface{} {
-	var (
-		n *trieNode
-		i int
-	)
-	m := make(map[string]interface{})
-	nodes := make([]*trieNode, 1, len(node.children)+1)
-	nodes[0] = node
-	for l := len(nodes); l != 0; l = len(nodes) {
-		i = l - 1
-		n = nodes[i]
-		nodes = nodes[:i]
-		for _, c := range n.children {
-			nodes = append(nodes, c)
-		}
-		if n.term {
-			word := n.path
-			m[word] = n.value
-		}
-	}
+	m := make(map[string]interface{}, node.termCount)
+	walkTerms(node, func(n *trieNode) {
+		m[n.key()] = n.value
+	})
 	return m
 }
 
@@ -553,7 +1240,6 @@ func fuzzycollect(node *trieNode, partial []rune) []string {
 	}
 
 	var (
-		m    uint64
 		i    int
 		p    potentialSubtree
 		keys []string
@@ -564,8 +1250,7 @@ func fuzzycollect(node *trieNode, partial []rune) []string {
 		i = l - 1
 		p = potential[i]
 		potential = potential[:i]
-		m = maskruneslice(partial[p.idx:])
-		if (p.node.mask & m) != m {
+		if fuzzyPruned(p.node, partial[p.idx:]) {
 			continue
 		}
 
@@ -590,7 +1275,6 @@ func fuzzycollectValues(node *trieNode, partial []rune) []interface{} {
 	}
 
 	var (
-		m      uint64
 		i      int
 		p      potentialSubtree
 		values []interface{}
@@ -601,8 +1285,7 @@ func fuzzycollectValues(node *trieNode, partial []rune) []interface{} {
 		i = l - 1
 		p = potential[i]
 		potential = potential[:i]
-		m = maskruneslice(partial[p.idx:])
-		if (p.node.mask & m) != m {
+		if fuzzyPruned(p.node, partial[p.idx:]) {
 			continue
 		}
 
@@ -627,7 +1310,6 @@ func fuzzycollectAll(node *trieNode, partial []rune) map[string]interface{} {
 	}
 
 	var (
-		m      uint64
 		i      int
 		p      potentialSubtree
 		values map[string]interface{} = make(map[string]interface{})
@@ -638,8 +1320,7 @@ func fuzzycollectAll(node *trieNode, partial []rune) map[string]interface{} {
 		i = l - 1
 		p = potential[i]
 		potential = potential[:i]
-		m = maskruneslice(partial[p.idx:])
-		if (p.node.mask & m) != m {
+		if fuzzyPruned(p.node, partial[p.idx:]) {
 			continue
 		}
 
@@ -659,3 +1340,38 @@ func fuzzycollectAll(node *trieNode, partial []rune) map[string]interface{} {
 	}
 	return values
 }
+
+// overlapcollect returns true if the nodes `a` and `b` of the different tries
+// share a path of `minDepth` runes or more leading to the terminals of both.
+func overlapcollect(a, b *trieNode, minDepth int) bool {
+	type pair struct{ a, b *trieNode }
+	if a.termCount <= 0 || b.termCount <= 0 {
+		return false
+	}
+	nodes := []pair{{a, b}}
+	for l := len(nodes); l != 0; l = len(nodes) {
+		p := nodes[l-1]
+		nodes = nodes[:l-1]
+		if p.a.depth >= minDepth {
+			return true
+		}
+		// walk the smaller children map.
+		small, large := p.a, p.b
+		if len(small.children) > len(large.children) {
+			small, large = large, small
+		}
+		for r, c := range small.children {
+			if r == nul {
+				continue
+			}
+			if o, ok := large.children[r]; ok {
+				if small == p.a {
+					nodes = append(nodes, pair{c, o})
+				} else {
+					nodes = append(nodes, pair{o, c})
+				}
+			}
+		}
+	}
+	return false
+}
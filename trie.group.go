@@ -0,0 +1,127 @@
+package gtrie
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// GroupByPrefixSegment partitions the keys starting with `prefix` by their
+// segment next to the prefix, i.e. the part of the key after the prefix up to
+// the end of the segment starting at the first delimiter `delim`, and returns
+// the sorted keys of each group. The segment ends at the next delimiter, but the
+// delimiters in the key predicates (e.g. "[name=1/2]") and the delimiters
+// escaped by EscapeSegment do not end it, and
+// the segment starting with '[' ends at the end of its predicate.
+// For example, GroupByPrefixSegment("/interfaces/interface", '[') groups
+// the keys by the interfaces, e.g. "[name=1/2]", and
+// GroupByPrefixSegment("/interfaces", '/') by "/interface[name=1/2]".
+// The keys having no delimiter after the prefix are grouped by "".
+// The subtree of the prefix is walked once.
+func (t *Trie) GroupByPrefixSegment(prefix string, delim rune) map[string][]string {
+	if t == nil {
+		return nil
+	}
+	root := t.readRoot()
+	defer t.readDone()
+	prefix = t.canonical(prefix)
+	node := findNode(root, prefix)
+	if node == nil {
+		return nil
+	}
+	groups := make(map[string][]string)
+	walkTerms(node, func(n *trieNode) {
+		key := n.key()
+		rest := t.canonical(key)[len(prefix):]
+		group := ""
+		if end := segmentEnd(rest, delim); end >= 0 {
+			group = rest[:end]
+		}
+		groups[group] = append(groups[group], key)
+	})
+	for _, keys := range groups {
+		sort.Strings(keys)
+	}
+	return groups
+}
+
+// segmentEnd returns the end of the segment of `rest` starting at the first
+// delimiter `delim`, or -1 if `rest` has no delimiter out of the key predicates.
+func segmentEnd(rest string, delim rune) int {
+	d := string(delim)
+	start := -1
+	for i := 0; i < len(rest); {
+		if rest[i] == keyEscape && delim != keyEscape {
+			i += 2
+			continue
+		}
+		if strings.HasPrefix(rest[i:], d) {
+			if start >= 0 {
+				return i
+			}
+			start = i
+			if delim == '[' {
+				if end := predicateEnd(rest, i+1); end >= 0 {
+					return end + 1
+				}
+				return len(rest)
+			}
+			i += len(d)
+			continue
+		}
+		if rest[i] == '[' {
+			if end := predicateEnd(rest, i+1); end >= 0 {
+				i = end + 1
+				continue
+			}
+		}
+		i++
+	}
+	if start < 0 {
+		return -1
+	}
+	return len(rest)
+}
+
+// GroupByValue partitions the keys starting with `prefix` by their values
+// identified by `keyOf`, e.g. the subscriber ID of the value, and returns
+// the sorted keys of each value. The values are identified by fmt.Sprint
+// if `keyOf` is nil. The subtree of the prefix is walked once and the keys
+// of all the groups share a slice sized by the number of the keys;
+// the group slices are capped not to overwrite each other if appended.
+func (t *Trie) GroupByValue(prefix string, keyOf func(v interface{}) string) map[string][]string {
+	if t == nil {
+		return nil
+	}
+	if keyOf == nil {
+		keyOf = func(v interface{}) string { return fmt.Sprint(v) }
+	}
+	root := t.readRoot()
+	defer t.readDone()
+	node := findNode(root, t.canonical(prefix))
+	if node == nil {
+		return nil
+	}
+	terms := collectNodes(node)
+	names := make([]string, len(terms))
+	counts := make(map[string]int)
+	for i, n := range terms {
+		names[i] = keyOf(n.value)
+		counts[names[i]]++
+	}
+	// the groups are carved from one slice at the offsets of their counts.
+	keys := make([]string, len(terms))
+	groups := make(map[string][]string, len(counts))
+	offset := 0
+	for name, count := range counts {
+		groups[name] = keys[offset : offset : offset+count]
+		offset += count
+	}
+	for i, n := range terms {
+		groups[names[i]] = append(groups[names[i]], n.key())
+	}
+	for _, keys := range groups {
+		sort.Strings(keys)
+	}
+	return groups
+}
@@ -0,0 +1,116 @@
+package gtrie
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestTrie_GroupByPrefixSegment(t *testing.T) {
+	trie := newGNMITrie()
+	got := trie.GroupByPrefixSegment("/interfaces/interface", '[')
+	want := map[string][]string{
+		"":           {"/interfaces/interface", "/interfaces/interface/state/counters"},
+		"[name=1/1]": {"/interfaces/interface[name=1/1]/state/enabled"},
+		"[name=1/2]": {
+			"/interfaces/interface[name=1/2]",
+			"/interfaces/interface[name=1/2]/state",
+			"/interfaces/interface[name=1/2]/state/admin-status",
+			"/interfaces/interface[name=1/2]/state/counters",
+			"/interfaces/interface[name=1/2]/state/enabled",
+			"/interfaces/interface[name=1/2]/state/oper-status",
+		},
+		"[name=1/3]": {
+			"/interfaces/interface[name=1/3]",
+			"/interfaces/interface[name=1/3]/state",
+			"/interfaces/interface[name=1/3]/state/admin-status",
+			"/interfaces/interface[name=1/3]/state/counters",
+			"/interfaces/interface[name=1/3]/state/enabled",
+			"/interfaces/interface[name=1/3]/state/oper-status",
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GroupByPrefixSegment('[') = %v, want %v", got, want)
+	}
+
+	got = trie.GroupByPrefixSegment("/interfaces", '/')
+	if keys := got["/interface[name=1/2]"]; len(keys) != 6 {
+		t.Errorf("GroupByPrefixSegment('/') of [name=1/2] = %v", keys)
+	}
+	if keys := got["/interface"]; !reflect.DeepEqual(keys, []string{"/interfaces/interface", "/interfaces/interface/state/counters"}) {
+		t.Errorf("GroupByPrefixSegment('/') of /interface = %v", keys)
+	}
+	if len(got) != 5 || !reflect.DeepEqual(got[""], []string{"/interfaces"}) {
+		t.Errorf("GroupByPrefixSegment('/') = %v", got)
+	}
+
+	got = trie.GroupByPrefixSegment("/interfaces/interface[name=1/2]/state", '/')
+	if len(got) != 5 || len(got["/enabled"]) != 1 {
+		t.Errorf("GroupByPrefixSegment() of the leaves = %v", got)
+	}
+	if got := trie.GroupByPrefixSegment("/none", '/'); got != nil {
+		t.Errorf("GroupByPrefixSegment() of no key = %v", got)
+	}
+}
+
+func TestSegmentEnd(t *testing.T) {
+	tests := []struct {
+		rest  string
+		delim rune
+		want  int
+	}{
+		{"/a/b", '/', 2},
+		{"/a[k=1/2]/b", '/', 9},
+		{`/a\/b/c`, '/', 5},
+		{"x/a", '/', 3},
+		{"abc", '/', -1},
+		{"[k=1/2]/b[c]", '[', 7},
+		{"face[k=1]/x", '[', 9},
+		{"[k=1", '[', 4},
+		{"ü/aüb", 'ü', 4},
+	}
+	for _, tt := range tests {
+		if got := segmentEnd(tt.rest, tt.delim); got != tt.want {
+			t.Errorf("segmentEnd(%q, %q) = %d, want %d", tt.rest, tt.delim, got, tt.want)
+		}
+	}
+}
+
+func TestTrie_GroupByValue(t *testing.T) {
+	type subscriber struct{ id string }
+	subs := []*subscriber{{"s0"}, {"s1"}, {"s2"}}
+	trie := New()
+	want := map[string][]string{}
+	for i := 0; i < 300; i++ {
+		key := fmt.Sprintf("/subs/%03d", i)
+		sub := subs[i%len(subs)]
+		trie.Add(key, sub)
+		want[sub.id] = append(want[sub.id], key)
+	}
+	trie.Add("/other", subs[0])
+	byID := func(v interface{}) string { return v.(*subscriber).id }
+	got := trie.GroupByValue("/subs/", byID)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GroupByValue() = %v, want %v", got, want)
+	}
+	// the groups sharing a slice do not overwrite each other.
+	for id := range got {
+		got[id] = append(got[id], "/x")
+	}
+	for id, keys := range got {
+		if len(keys) != 101 || keys[99] != want[id][99] {
+			t.Errorf("group %s is overwritten: %v", id, keys)
+		}
+	}
+	if got := trie.GroupByValue("/none", byID); got != nil {
+		t.Errorf("GroupByValue() of no key = %v, want nil", got)
+	}
+
+	trie = New()
+	trie.Add("/a", 1)
+	trie.Add("/b", "1")
+	trie.Add("/c", 2)
+	if got := trie.GroupByValue("", nil); !reflect.DeepEqual(got, map[string][]string{"1": {"/a", "/b"}, "2": {"/c"}}) {
+		t.Errorf("GroupByValue() by fmt.Sprint = %v", got)
+	}
+}
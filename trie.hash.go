@@ -0,0 +1,106 @@
+package gtrie
+
+import (
+	"fmt"
+	"hash"
+	"hash/fnv"
+)
+
+// HashOption configures the hash of the trie contents.
+type HashOption func(d *digest)
+
+// HashValueWith sets the function converting a value to the bytes hashed.
+// The values are hashed by fmt.Sprint by default.
+func HashValueWith(fn func(v interface{}) []byte) HashOption {
+	return func(d *digest) {
+		d.value = fn
+	}
+}
+
+// digest is an order-independent hash of the key and value pairs.
+// The hash of each pair is combined by XOR so that the contribution
+// of a pair can be removed by XORing its hash again.
+type digest struct {
+	new   func() hash.Hash64
+	value func(v interface{}) []byte
+	sum   uint64
+}
+
+func newDigest(h func() hash.Hash64, opts ...HashOption) *digest {
+	d := &digest{new: h, value: hashValue}
+	if d.new == nil {
+		d.new = fnv.New64a
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// hashValue is the default value conversion of the digest.
+func hashValue(v interface{}) []byte {
+	return []byte(fmt.Sprint(v))
+}
+
+// entry returns the hash of a key and value pair.
+func (d *digest) entry(key string, v interface{}) uint64 {
+	h := d.new()
+	h.Write([]byte(key))
+	// the separator keeps ("ab", "c") and ("a", "bc") apart.
+	h.Write([]byte{0})
+	h.Write(d.value(v))
+	return h.Sum64()
+}
+
+// replace removes the contribution of the terminal node `old`
+// and adds the contribution of the terminal node `new`. Both can be nil.
+func (d *digest) replace(old, new *trieNode) {
+	if old != nil {
+		d.sum ^= d.entry(old.key(), old.value)
+	}
+	if new != nil {
+		d.sum ^= d.entry(new.key(), new.value)
+	}
+}
+
+func (d *digest) reset() {
+	d.sum = 0
+}
+
+// WithIncrementalHash maintains the hash of the trie contents on every Add and Remove
+// so that IncrementalHash returns it in O(1). `h` and `opts` are the same as of Hash.
+func WithIncrementalHash(h func() hash.Hash64, opts ...HashOption) Option {
+	return func(t *Trie) {
+		t.digest = newDigest(h, opts...)
+	}
+}
+
+// Hash returns an order-independent digest of all the key and value pairs in the trie.
+// The pairs are hashed by `h` (FNV-1a if nil) and combined by XOR,
+// so the tries having the same contents have the same hash regardless of the insertion order.
+func (t *Trie) Hash(h func() hash.Hash64, opts ...HashOption) uint64 {
+	if t == nil {
+		return new(Trie).Hash(h, opts...)
+	}
+	d := newDigest(h, opts...)
+	t.rlock()
+	defer t.runlock()
+	for _, n := range collectNodes(t.root) {
+		d.replace(nil, n)
+	}
+	return d.sum
+}
+
+// IncrementalHash returns the hash maintained by WithIncrementalHash.
+// It returns false if the trie is not created with WithIncrementalHash.
+func (t *Trie) IncrementalHash() (uint64, bool) {
+	if t == nil {
+		return 0, false
+	}
+	t.rlock()
+	defer t.runlock()
+	if t.digest == nil {
+		return 0, false
+	}
+	return t.digest.sum, true
+}
@@ -0,0 +1,96 @@
+package gtrie
+
+import (
+	"hash"
+	"hash/crc64"
+	"testing"
+)
+
+func TestTrie_Hash(t *testing.T) {
+	a := New()
+	b := New()
+	for _, key := range gnmiFixture {
+		a.Add(key, len(key))
+	}
+	for i := len(gnmiFixture) - 1; i >= 0; i-- {
+		b.Add(gnmiFixture[i], len(gnmiFixture[i]))
+	}
+	if a.Hash(nil) != b.Hash(nil) {
+		t.Errorf("Hash() of the same contents differs: %x, %x", a.Hash(nil), b.Hash(nil))
+	}
+	crc := func() hash.Hash64 { return crc64.New(crc64.MakeTable(crc64.ECMA)) }
+	if a.Hash(crc) != b.Hash(crc) {
+		t.Errorf("Hash(crc64) of the same contents differs")
+	}
+	if New().Hash(nil) != 0 {
+		t.Errorf("Hash() of an empty trie is not zero")
+	}
+
+	base := a.Hash(nil)
+	a.Add("/interfaces", 100)
+	if a.Hash(nil) == base {
+		t.Errorf("Hash() is not changed by a value change")
+	}
+	a.Add("/interfaces", len("/interfaces"))
+	if a.Hash(nil) != base {
+		t.Errorf("Hash() is not restored")
+	}
+	a.Add("/interfaces/x", 0)
+	if a.Hash(nil) == base {
+		t.Errorf("Hash() is not changed by a new key")
+	}
+	a.Remove("/interfaces/x")
+	if a.Hash(nil) != base {
+		t.Errorf("Hash() is not restored by Remove")
+	}
+	a.Remove("/interfaces")
+	if a.Hash(nil) == base {
+		t.Errorf("Hash() is not changed by Remove")
+	}
+
+	// values hashed by the value function
+	ignore := HashValueWith(func(v interface{}) []byte { return nil })
+	c := New()
+	c.Add("/interfaces", "different value")
+	d := New()
+	d.Add("/interfaces", 1)
+	if c.Hash(nil, ignore) != d.Hash(nil, ignore) {
+		t.Errorf("Hash() with HashValueWith() hashes the values")
+	}
+	if c.Hash(nil) == d.Hash(nil) {
+		t.Errorf("Hash() ignores the values")
+	}
+}
+
+func TestTrie_IncrementalHash(t *testing.T) {
+	if _, ok := New().IncrementalHash(); ok {
+		t.Errorf("IncrementalHash() is enabled by default")
+	}
+	trie := New(WithIncrementalHash(nil))
+	check := func(step string) {
+		t.Helper()
+		if got, ok := trie.IncrementalHash(); !ok || got != trie.Hash(nil) {
+			t.Errorf("%s: IncrementalHash() = %x, %v, want %x", step, got, ok, trie.Hash(nil))
+		}
+	}
+	check("empty")
+	for i, key := range gnmiFixture {
+		trie.Add(key, i)
+		check("add " + key)
+	}
+	for i, key := range gnmiFixture {
+		if i%3 == 0 {
+			trie.Remove(key)
+			check("remove " + key)
+		}
+	}
+	trie.Remove("/none")
+	check("remove a missing key")
+	trie.Add("/interfaces/interface", "overwritten")
+	check("overwrite")
+	trie.Clear()
+	check("clear")
+	if got, _ := trie.IncrementalHash(); got != 0 {
+		t.Errorf("IncrementalHash() after Clear() = %x", got)
+	}
+}
@@ -0,0 +1,73 @@
+package gtrie
+
+// Intern returns the ID of the `key` interned, assigning the next ID from 1
+// and adding the key with the ID of uint64 as its value if the key is not
+// interned yet. The ID maps back to the key by KeyByID.
+// The IDs are never recycled: the ID of a key removed, or replaced by Add
+// with another value, becomes invalid, and the key interned again gets a new ID.
+// The key added by Add is interned by replacing its value with a new ID.
+// It returns 0 if the key is rejected by the limits of the trie.
+// The keys are kept for KeyByID as given, so that the keys sharing
+// the strings with the nodes cost no more than the slice of the IDs;
+// the trie with WithSegmentInterning keeps the key strings apart.
+func (t *Trie) Intern(key string) uint64 {
+	if t == nil {
+		return 0
+	}
+	t.rlock()
+	id, ok := t.internedID(key)
+	t.runlock()
+	if ok {
+		return id
+	}
+	t.lock()
+	// the key may be interned by another while the lock is not held.
+	if id, ok := t.internedID(key); ok {
+		t.unlock()
+		return id
+	}
+	id = uint64(len(t.ids)) + 1
+	err := t.add(key, id)
+	if err == nil {
+		t.ids = append(t.ids, key)
+	}
+	t.unlock()
+	if err != nil {
+		t.reject(key, err)
+		return 0
+	}
+	return id
+}
+
+// KeyByID returns the key interned with the `id` by Intern.
+// It returns false if the ID is not assigned or no longer valid.
+func (t *Trie) KeyByID(id uint64) (string, bool) {
+	if t == nil {
+		return "", false
+	}
+	// the read lock is taken even in the atomic read mode for the IDs.
+	t.rlock()
+	defer t.runlock()
+	if id == 0 || id > uint64(len(t.ids)) {
+		return "", false
+	}
+	key := t.ids[id-1]
+	if n := findTerm(t.root, t.canonical(key)); n == nil || n.value != id {
+		return "", false
+	}
+	return key, true
+}
+
+// internedID returns the ID of the key if it is interned and still valid.
+func (t *Trie) internedID(key string) (uint64, bool) {
+	ckey := t.canonical(key)
+	n := findTerm(t.root, ckey)
+	if n == nil {
+		return 0, false
+	}
+	id, ok := n.value.(uint64)
+	if !ok || id == 0 || id > uint64(len(t.ids)) || t.canonical(t.ids[id-1]) != ckey {
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,109 @@
+package gtrie
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestTrie_Intern(t *testing.T) {
+	trie := New()
+	a, b := trie.Intern("/interfaces/interface[name=1/1]"), trie.Intern("/interfaces/interface[name=1/2]")
+	if a != 1 || b != 2 {
+		t.Fatalf("Intern() = %d, %d, want 1, 2", a, b)
+	}
+	if id := trie.Intern("/interfaces/interface[name=1/1]"); id != a {
+		t.Errorf("Intern() again = %d, want %d", id, a)
+	}
+	if key, ok := trie.KeyByID(b); !ok || key != "/interfaces/interface[name=1/2]" {
+		t.Errorf("KeyByID(%d) = %q, %v", b, key, ok)
+	}
+	if v, _ := trie.Find("/interfaces/interface[name=1/1]"); v != a {
+		t.Errorf("Find() = %v, want the ID %d", v, a)
+	}
+	for _, id := range []uint64{0, 3} {
+		if key, ok := trie.KeyByID(id); ok {
+			t.Errorf("KeyByID(%d) = %q, true", id, key)
+		}
+	}
+
+	// the IDs are not recycled.
+	trie.Remove("/interfaces/interface[name=1/1]")
+	if key, ok := trie.KeyByID(a); ok {
+		t.Errorf("KeyByID() of the key removed = %q, true", key)
+	}
+	if id := trie.Intern("/interfaces/interface[name=1/1]"); id != 3 {
+		t.Errorf("Intern() of the key removed = %d, want 3", id)
+	}
+	trie.Add("/interfaces/interface[name=1/2]", "up")
+	if _, ok := trie.KeyByID(b); ok {
+		t.Errorf("KeyByID() of the key replaced is valid")
+	}
+	if id := trie.Intern("/interfaces/interface[name=1/2]"); id != 4 {
+		t.Errorf("Intern() of the key replaced = %d, want 4", id)
+	}
+	trie.Clear()
+	if id := trie.Intern("/a"); id != 5 {
+		t.Errorf("Intern() after Clear() = %d, want 5", id)
+	}
+}
+
+func TestTrie_InternOptions(t *testing.T) {
+	var rejected []string
+	trie := New(WithKeyTransform(strings.ToLower), WithMaxKeys(1), WithOnReject(func(key string, err error) {
+		rejected = append(rejected, key)
+	}))
+	if id := trie.Intern("/A"); id != 1 {
+		t.Errorf("Intern() = %d, want 1", id)
+	}
+	if id := trie.Intern("/a"); id != 1 {
+		t.Errorf("Intern() of the same canonical key = %d, want 1", id)
+	}
+	if key, ok := trie.KeyByID(1); !ok || key != "/A" {
+		t.Errorf("KeyByID() = %q, %v, want the key as interned", key, ok)
+	}
+	if id := trie.Intern("/b"); id != 0 || len(rejected) != 1 {
+		t.Errorf("Intern() over the limit = %d with rejected %v", id, rejected)
+	}
+	if id := trie.Intern("/c"); id != 0 || len(rejected) != 2 {
+		t.Errorf("Intern() over the limit = %d, want the ID not consumed", id)
+	}
+}
+
+func TestTrie_InternConcurrent(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithAtomicReads()}} {
+		trie := New(opts...)
+		const n = 16
+		ids := make([][]uint64, n)
+		var wg sync.WaitGroup
+		for g := 0; g < n; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+				for _, key := range gnmiFixture {
+					ids[g] = append(ids[g], trie.Intern(key))
+				}
+			}(g)
+		}
+		wg.Wait()
+		for g := 1; g < n; g++ {
+			for i, id := range ids[g] {
+				if id != ids[0][i] {
+					t.Fatalf("Intern(%q) = %d and %d", gnmiFixture[i], ids[0][i], id)
+				}
+			}
+		}
+		for i, id := range ids[0] {
+			if key, ok := trie.KeyByID(id); !ok || key != gnmiFixture[i] {
+				t.Errorf("KeyByID(%d) = %q, %v, want %q", id, key, ok, gnmiFixture[i])
+			}
+		}
+		unique := New()
+		for _, key := range gnmiFixture {
+			unique.Add(key, nil)
+		}
+		if trie.Size() != unique.Size() || len(trie.ids) != unique.Size() {
+			t.Errorf("Size() = %d with %d IDs, want %d", trie.Size(), len(trie.ids), unique.Size())
+		}
+	}
+}
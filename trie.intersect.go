@@ -0,0 +1,103 @@
+package gtrie
+
+// Intersect returns a new trie having the keys present in both of the trie and
+// the `other` with the values of the trie. The tries are walked in lockstep
+// down the shared runes, so the subtrees present in only one of them are skipped.
+// Both tries are read-locked in the address order during the walk.
+// The keys are compared by the canonical form of the key transform.
+func (t *Trie) Intersect(other *Trie) *Trie {
+	if t == nil {
+		return New()
+	}
+	result := t.derive()
+	if other == nil {
+		return result
+	}
+	defer rlockBoth(t, other)()
+	for _, n := range intersectcollect(t.root, other.root) {
+		result.add(n.key(), n.value)
+	}
+	return result
+}
+
+// Subtract returns a new trie having the keys of the trie not present in
+// the `other` with the values of the trie. Like Intersect, the tries are walked
+// in lockstep and the subtrees absent from the `other` are copied wholesale.
+// Both tries are read-locked in the address order during the walk.
+func (t *Trie) Subtract(other *Trie) *Trie {
+	if t == nil {
+		return New()
+	}
+	result := t.derive()
+	if other == nil {
+		t.rlock()
+		defer t.runlock()
+		for _, n := range collectNodes(t.root) {
+			result.add(n.key(), n.value)
+		}
+		return result
+	}
+	defer rlockBoth(t, other)()
+	for _, n := range subtractcollect(t.root, other.root) {
+		result.add(n.key(), n.value)
+	}
+	return result
+}
+
+// derive returns a new empty trie having the same key transform as the trie.
+func (t *Trie) derive() *Trie {
+	if t.transform != nil {
+		return New(WithKeyTransform(t.transform))
+	}
+	return New()
+}
+
+// intersectcollect returns the terminal nodes under `a` whose key is also under `b`.
+func intersectcollect(a, b *trieNode) []*trieNode {
+	type pair struct{ a, b *trieNode }
+	var terms []*trieNode
+	nodes := []pair{{a, b}}
+	for l := len(nodes); l != 0; l = len(nodes) {
+		p := nodes[l-1]
+		nodes = nodes[:l-1]
+		for r, c := range p.a.children {
+			o, ok := p.b.children[r]
+			if !ok {
+				continue
+			}
+			if r == nul {
+				if c.term && o.term {
+					terms = append(terms, c)
+				}
+				continue
+			}
+			nodes = append(nodes, pair{c, o})
+		}
+	}
+	return terms
+}
+
+// subtractcollect returns the terminal nodes under `a` whose key is not under `b`.
+func subtractcollect(a, b *trieNode) []*trieNode {
+	type pair struct{ a, b *trieNode }
+	var terms []*trieNode
+	nodes := []pair{{a, b}}
+	for l := len(nodes); l != 0; l = len(nodes) {
+		p := nodes[l-1]
+		nodes = nodes[:l-1]
+		for r, c := range p.a.children {
+			o, ok := p.b.children[r]
+			switch {
+			case !ok:
+				terms = append(terms, collectNodes(c)...)
+			case r == nul:
+				if c.term && !o.term {
+					terms = append(terms, c)
+				}
+			default:
+				nodes = append(nodes, pair{c, o})
+			}
+		}
+	}
+	return terms
+}
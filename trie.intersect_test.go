@@ -0,0 +1,76 @@
+package gtrie
+
+import (
+	"reflect"
+	"testing"
+)
+
+func newTrieOf(m map[string]interface{}) *Trie {
+	trie := New()
+	for k, v := range m {
+		trie.Add(k, v)
+	}
+	return trie
+}
+
+func TestTrie_IntersectSubtract(t *testing.T) {
+	tests := []struct {
+		name      string
+		a, b      map[string]interface{}
+		intersect map[string]interface{}
+		subtract  map[string]interface{}
+	}{
+		{
+			name:      "Subset",
+			a:         map[string]interface{}{"/a": 1, "/a/b": 2, "/a/c": 3, "/d": 4},
+			b:         map[string]interface{}{"/a/b": 2, "/d": 4},
+			intersect: map[string]interface{}{"/a/b": 2, "/d": 4},
+			subtract:  map[string]interface{}{"/a": 1, "/a/c": 3},
+		},
+		{
+			name:      "Disjoint",
+			a:         map[string]interface{}{"/a": 1, "/a/b": 2},
+			b:         map[string]interface{}{"/b": 1, "/a/c": 2, "/a/b/c": 3},
+			intersect: map[string]interface{}{},
+			subtract:  map[string]interface{}{"/a": 1, "/a/b": 2},
+		},
+		{
+			name:      "ValuesDiffer",
+			a:         map[string]interface{}{"/a": 1, "/a/b": 2, "/c": 3},
+			b:         map[string]interface{}{"/a": 10, "/a/b": 20, "/d": 30},
+			intersect: map[string]interface{}{"/a": 1, "/a/b": 2},
+			subtract:  map[string]interface{}{"/c": 3},
+		},
+	}
+	for _, tt := range tests {
+		a, b := newTrieOf(tt.a), newTrieOf(tt.b)
+		i := a.Intersect(b)
+		if got := i.All(); !reflect.DeepEqual(got, tt.intersect) {
+			t.Errorf("%s: Intersect() = %v, want %v", tt.name, got, tt.intersect)
+		}
+		if i.Size() != len(tt.intersect) {
+			t.Errorf("%s: Intersect().Size() = %d, want %d", tt.name, i.Size(), len(tt.intersect))
+		}
+		s := a.Subtract(b)
+		if got := s.All(); !reflect.DeepEqual(got, tt.subtract) {
+			t.Errorf("%s: Subtract() = %v, want %v", tt.name, got, tt.subtract)
+		}
+		if s.Size() != len(tt.subtract) {
+			t.Errorf("%s: Subtract().Size() = %d, want %d", tt.name, s.Size(), len(tt.subtract))
+		}
+	}
+
+	trie := newGNMITrie()
+	if got, want := trie.Intersect(trie).All(), trie.All(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Intersect(self) = %v, want %v", got, want)
+	}
+	if got := trie.Subtract(trie).Size(); got != 0 {
+		t.Errorf("Subtract(self).Size() = %d, want 0", got)
+	}
+	if got, want := trie.Subtract(nil).All(), trie.All(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Subtract(nil) = %v, want %v", got, want)
+	}
+	if got := trie.Intersect(nil).Size(); got != 0 {
+		t.Errorf("Intersect(nil).Size() = %d, want 0", got)
+	}
+}
@@ -0,0 +1,116 @@
+package gtrie
+
+import (
+	"sync"
+	"time"
+)
+
+// stackPool holds the node stacks of the trie traversals
+// so that a traversal does not allocate its stack per call.
+var stackPool = sync.Pool{
+	New: func() interface{} {
+		s := make([]*trieNode, 0, 64)
+		return &s
+	},
+}
+
+// walkTerms calls `fn` for all the terminal nodes under the node
+// using a node stack taken from stackPool.
+func walkTerms(node *trieNode, fn func(n *trieNode)) {
+	sp := stackPool.Get().(*[]*trieNode)
+	nodes := append((*sp)[:0], node)
+	for l := len(nodes); l != 0; l = len(nodes) {
+		n := nodes[l-1]
+		// clear the slot not to keep the node alive in the pool.
+		nodes[l-1] = nil
+		nodes = nodes[:l-1]
+		for _, c := range n.children {
+			nodes = append(nodes, c)
+		}
+		if n.term {
+			fn(n)
+		}
+	}
+	*sp = nodes
+	stackPool.Put(sp)
+}
+
+// appendKeys appends the keys of all the terminal nodes under the node to `dst`.
+func appendKeys(dst []string, node *trieNode) []string {
+	walkTerms(node, func(n *trieNode) {
+		dst = append(dst, n.key())
+	})
+	return dst
+}
+
+// appendValues appends the values of all the terminal nodes under the node to `dst`.
+func appendValues(dst []interface{}, node *trieNode) []interface{} {
+	walkTerms(node, func(n *trieNode) {
+		dst = append(dst, n.value)
+	})
+	return dst
+}
+
+// FindByPrefixInto appends all the keys starting with `prefix` to `dst`
+// and returns the extended slice like strconv.AppendInt.
+// It does not allocate if `dst` has enough capacity.
+func (t *Trie) FindByPrefixInto(prefix string, dst []string) []string {
+	if t == nil {
+		return dst
+	}
+	t.rlock()
+	defer t.runlock()
+	if t.metrics != nil {
+		defer t.observe(MetricSearchPrefix, time.Now())
+	}
+	node := findNode(t.root, t.canonical(prefix))
+	if node == nil {
+		return dst
+	}
+	return appendKeys(dst, node)
+}
+
+// FindByPrefixValueInto appends all the values that have a key starting with `prefix`
+// to `dst` and returns the extended slice.
+// It does not allocate if `dst` has enough capacity.
+func (t *Trie) FindByPrefixValueInto(prefix string, dst []interface{}) []interface{} {
+	if t == nil {
+		return dst
+	}
+	t.rlock()
+	defer t.runlock()
+	if t.metrics != nil {
+		defer t.observe(MetricSearchPrefix, time.Now())
+	}
+	node := findNode(t.root, t.canonical(prefix))
+	if node == nil {
+		return dst
+	}
+	return appendValues(dst, node)
+}
+
+// FindMatchingPrefixInto appends all the keys that are a prefix of the input `key`
+// to `dst` in order from the shortest and returns the extended slice.
+// It does not allocate if `dst` has enough capacity.
+func (t *Trie) FindMatchingPrefixInto(key string, dst []string) []string {
+	if t == nil {
+		return dst
+	}
+	t.rlock()
+	defer t.runlock()
+	if t.metrics != nil {
+		defer t.observe(MetricSearchMatchingPrefix, time.Now())
+	}
+	node := t.root
+	for _, r := range t.canonical(key) {
+		n, ok := node.children[r]
+		if !ok {
+			break
+		}
+		if c, ok := n.children[nul]; ok && c.term {
+			dst = append(dst, c.key())
+		}
+		node = n
+	}
+	return dst
+}
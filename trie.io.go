@@ -0,0 +1,63 @@
+package gtrie
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+)
+
+// ErrNewlineInKey is returned by WriteKeys if a key contains a line break
+// that cannot be represented in the line-oriented format.
+var ErrNewlineInKey = errors.New("gtrie: key contains a line break")
+
+// WriteKeys writes all the keys starting with `prefix` to `w`,
+// one key per line in lexicographic order. It returns the number of the keys written.
+// The keys containing '\n' or '\r' are rejected with ErrNewlineInKey
+// before anything is written.
+func (t *Trie) WriteKeys(w io.Writer, prefix string) (int, error) {
+	if t == nil {
+		return 0, nil
+	}
+	// the keys are sorted in place, so they are not of the query cache.
+	keys := t.findByPrefix(prefix)
+	for _, key := range keys {
+		if strings.ContainsAny(key, "\r\n") {
+			return 0, ErrNewlineInKey
+		}
+	}
+	sort.Strings(keys)
+	bw := bufio.NewWriter(w)
+	for i, key := range keys {
+		if _, err := bw.WriteString(key); err != nil {
+			return i, err
+		}
+		if err := bw.WriteByte('\n'); err != nil {
+			return i, err
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return 0, err
+	}
+	return len(keys), nil
+}
+
+// ReadKeys adds each non-empty line read from `r` as a key with the `value`.
+// It returns the number of the keys added.
+func (t *Trie) ReadKeys(r io.Reader, value interface{}) (int, error) {
+	if t == nil {
+		return 0, ErrNilTrie
+	}
+	var n int
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		key := scanner.Text()
+		if key == "" {
+			continue
+		}
+		t.Add(key, value)
+		n++
+	}
+	return n, scanner.Err()
+}
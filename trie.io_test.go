@@ -0,0 +1,68 @@
+package gtrie
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTrie_WriteKeys(t *testing.T) {
+	trie := newGNMITrie()
+	var buf bytes.Buffer
+	n, err := trie.WriteKeys(&buf, "/interfaces/interface[name=1/3]")
+	if err != nil {
+		t.Fatalf("WriteKeys() error = %v", err)
+	}
+	want := "/interfaces/interface[name=1/3]\n" +
+		"/interfaces/interface[name=1/3]/state\n" +
+		"/interfaces/interface[name=1/3]/state/admin-status\n" +
+		"/interfaces/interface[name=1/3]/state/counters\n" +
+		"/interfaces/interface[name=1/3]/state/enabled\n" +
+		"/interfaces/interface[name=1/3]/state/oper-status\n"
+	if n != 6 || buf.String() != want {
+		t.Errorf("WriteKeys() = %d, %q, want 6, %q", n, buf.String(), want)
+	}
+
+	trie.Add("/bad\nkey", nil)
+	buf.Reset()
+	if n, err := trie.WriteKeys(&buf, ""); !errors.Is(err, ErrNewlineInKey) || n != 0 || buf.Len() != 0 {
+		t.Errorf("WriteKeys() = %d, %v, %q, want 0, %v", n, err, buf.String(), ErrNewlineInKey)
+	}
+}
+
+func TestTrie_ReadKeys(t *testing.T) {
+	trie := New()
+	n, err := trie.ReadKeys(strings.NewReader("foo\n\nbar\r\nbaz"), 1)
+	if err != nil || n != 3 {
+		t.Fatalf("ReadKeys() = %d, %v, want 3", n, err)
+	}
+	if got, want := sortedKeys(trie.Keys()), []string{"bar", "baz", "foo"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Keys() = %v, want %v", got, want)
+	}
+	if v, ok := trie.Find("bar"); !ok || v != 1 {
+		t.Errorf("Find() = %v, %v, want 1", v, ok)
+	}
+}
+
+func TestTrie_WriteReadKeysRoundTrip(t *testing.T) {
+	src := newGNMITrie()
+	addFromFile(src, "fixtures/test.txt")
+	var buf bytes.Buffer
+	written, err := src.WriteKeys(&buf, "")
+	if err != nil {
+		t.Fatalf("WriteKeys() error = %v", err)
+	}
+	dst := New()
+	read, err := dst.ReadKeys(&buf, true)
+	if err != nil {
+		t.Fatalf("ReadKeys() error = %v", err)
+	}
+	if written != src.Size() || read != written || dst.Size() != src.Size() {
+		t.Errorf("written %d, read %d, size %d, want %d", written, read, dst.Size(), src.Size())
+	}
+	if got, want := sortedKeys(dst.Keys()), sortedKeys(src.Keys()); !reflect.DeepEqual(got, want) {
+		t.Errorf("Keys() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,161 @@
+package gtrie
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrMissingKey is the error of a JSON line without the "key" field.
+var ErrMissingKey = errors.New("gtrie: missing key")
+
+// JSONLineError is returned by ReadJSONLines and NewFromJSONLines for a line
+// that is not a JSON object of a key and a value, or whose key is rejected by
+// the limits of the trie. `Line` is the line number from 1 and `Err` is
+// the error of encoding/json, ErrMissingKey or the error of AddE.
+type JSONLineError struct {
+	Line int
+	Err  error
+}
+
+func (e *JSONLineError) Error() string {
+	return fmt.Sprintf("gtrie: json line %d: %v", e.Line, e.Err)
+}
+
+func (e *JSONLineError) Unwrap() error {
+	return e.Err
+}
+
+// jsonLine is the JSON object of a line of the JSON lines.
+type jsonLine struct {
+	Key   *string     `json:"key"`
+	Value interface{} `json:"value"`
+}
+
+// jsonLinesOptions is the options of ReadJSONLines and NewFromJSONLines.
+type jsonLinesOptions struct {
+	trie []Option
+	skip func(err *JSONLineError)
+}
+
+// JSONLinesOption configures the reading of the JSON lines.
+type JSONLinesOption func(o *jsonLinesOptions)
+
+// SkipBadLines skips the lines that are not the JSON objects of a key and
+// a value, and the keys rejected by the limits, instead of failing the reading. `fn` is called with the error of each
+// line skipped, e.g. to log it or count it; it can be nil.
+func SkipBadLines(fn func(err *JSONLineError)) JSONLinesOption {
+	return func(o *jsonLinesOptions) {
+		if fn == nil {
+			fn = func(*JSONLineError) {}
+		}
+		o.skip = fn
+	}
+}
+
+// WithTrieOptions creates the trie of NewFromJSONLines with `opts`.
+// ReadJSONLines ignores it.
+func WithTrieOptions(opts ...Option) JSONLinesOption {
+	return func(o *jsonLinesOptions) {
+		o.trie = append(o.trie, opts...)
+	}
+}
+
+// NewFromJSONLines returns the trie of the keys and values read from `r`
+// by ReadJSONLines. It returns nil and the error if a line is malformed
+// unless skipped by SkipBadLines, or the reading fails.
+func NewFromJSONLines(r io.Reader, opts ...JSONLinesOption) (*Trie, error) {
+	var o jsonLinesOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	t := New(o.trie...)
+	if _, err := t.ReadJSONLines(r, opts...); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// ReadJSONLines adds the keys and values of the JSON lines read from `r`,
+// one JSON object {"key": "...", "value": ...} per line as WriteJSONLines writes.
+// The blank lines are skipped. The values are decoded by encoding/json into
+// interface{}, e.g. a number into float64. It returns the number of the keys
+// added new, not counting the keys whose values are replaced. A malformed line
+// is returned as *JSONLineError unless skipped by SkipBadLines; the keys of
+// the lines before it are added. So is a key rejected by the limits of the trie
+// (e.g. ErrTrieFull), whose error is the `Err` of the JSONLineError.
+func (t *Trie) ReadJSONLines(r io.Reader, opts ...JSONLinesOption) (int, error) {
+	if t == nil {
+		return 0, ErrNilTrie
+	}
+	var o jsonLinesOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	var n int
+	br := bufio.NewReader(r)
+	for line := 1; ; line++ {
+		// ReadBytes does not limit the length of the lines as bufio.Scanner does.
+		data, err := br.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return n, err
+		}
+		if data = bytes.TrimSpace(data); len(data) > 0 {
+			var kv jsonLine
+			lerr := json.Unmarshal(data, &kv)
+			if lerr == nil && kv.Key == nil {
+				lerr = ErrMissingKey
+			}
+			if lerr == nil {
+				var added bool
+				if added, lerr = t.addE(*kv.Key, kv.Value); added {
+					n++
+				}
+			}
+			switch {
+			case lerr == nil:
+			case o.skip != nil:
+				o.skip(&JSONLineError{Line: line, Err: lerr})
+			default:
+				return n, &JSONLineError{Line: line, Err: lerr}
+			}
+		}
+		if err == io.EOF {
+			return n, nil
+		}
+	}
+}
+
+// WriteJSONLines writes the keys starting with `prefix` and their values to `w`
+// as the JSON lines of ReadJSONLines in lexicographic order of the keys,
+// or the order of WithKeyLess, so that the exports of the same contents are
+// the same. The values are encoded by encoding/json; the error of a value
+// is returned with its key, after the lines of the keys before it are written.
+func (t *Trie) WriteJSONLines(w io.Writer, prefix string) error {
+	if t == nil {
+		return nil
+	}
+	var kvs []KV
+	root := t.readRoot()
+	if node := findNode(root, t.canonical(prefix)); node != nil {
+		t.walkKeyOrder(node, false, func(n *trieNode) bool {
+			kvs = append(kvs, KV{Key: n.key(), Value: n.value})
+			return true
+		})
+	}
+	t.readDone()
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	// the keys of the gNMI paths are written as they are, e.g. "a<b".
+	enc.SetEscapeHTML(false)
+	for _, kv := range kvs {
+		if err := enc.Encode(jsonLine{Key: &kv.Key, Value: kv.Value}); err != nil {
+			bw.Flush()
+			return fmt.Errorf("gtrie: value of %q: %w", kv.Key, err)
+		}
+	}
+	return bw.Flush()
+}
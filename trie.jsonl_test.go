@@ -0,0 +1,156 @@
+package gtrie
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTrie_WriteJSONLines(t *testing.T) {
+	trie := New()
+	for key, value := range map[string]interface{}{
+		"/a/2":  2.5,
+		"/a/1":  map[string]interface{}{"up": true},
+		"/a":    "<a>",
+		"/b":    nil,
+		"/a/10": []interface{}{"x"},
+	} {
+		trie.Add(key, value)
+	}
+	var buf bytes.Buffer
+	if err := trie.WriteJSONLines(&buf, "/a"); err != nil {
+		t.Fatalf("WriteJSONLines() error = %v", err)
+	}
+	want := `{"key":"/a","value":"<a>"}
+{"key":"/a/1","value":{"up":true}}
+{"key":"/a/10","value":["x"]}
+{"key":"/a/2","value":2.5}
+`
+	if buf.String() != want {
+		t.Errorf("WriteJSONLines() = %s, want %s", buf.String(), want)
+	}
+	buf.Reset()
+	if err := trie.WriteJSONLines(&buf, "/none"); err != nil || buf.Len() != 0 {
+		t.Errorf("WriteJSONLines() of no key = %q, %v", buf.String(), err)
+	}
+
+	trie.Add("/a/3", func() {})
+	buf.Reset()
+	if err := trie.WriteJSONLines(&buf, "/a"); err == nil || !strings.Contains(err.Error(), `"/a/3"`) || strings.Count(buf.String(), "\n") != 4 {
+		t.Errorf("WriteJSONLines() of a bad value = %q, %v", buf.String(), err)
+	}
+}
+
+func TestTrie_JSONLinesRoundTrip(t *testing.T) {
+	src := New()
+	for i, key := range gnmiFixture {
+		src.Add(key, map[string]interface{}{"index": float64(i), "key": key})
+	}
+	src.Add("", "root")
+	src.Add("/line\nbreak", "quoted")
+	var buf bytes.Buffer
+	if err := src.WriteJSONLines(&buf, ""); err != nil {
+		t.Fatalf("WriteJSONLines() error = %v", err)
+	}
+	exported := buf.String()
+	dst, err := NewFromJSONLines(&buf, WithTrieOptions(WithSortedChildren()))
+	if err != nil {
+		t.Fatalf("NewFromJSONLines() error = %v", err)
+	}
+	if !reflect.DeepEqual(dst.FindByPrefixAll(""), src.FindByPrefixAll("")) {
+		t.Errorf("NewFromJSONLines() = %v, want %v", dst.FindByPrefixAll(""), src.FindByPrefixAll(""))
+	}
+	// the exports of the same contents are the same.
+	buf.Reset()
+	if err := dst.WriteJSONLines(&buf, ""); err != nil || buf.String() != exported {
+		t.Errorf("WriteJSONLines() of the trie read = %s, %v, want %s", buf.String(), err, exported)
+	}
+}
+
+func TestTrie_ReadJSONLines(t *testing.T) {
+	input := "{\"key\":\"/a\",\"value\":1}\n" +
+		"\n" +
+		"  {\"key\":\"/b\"}  \r\n" +
+		"{\"key\":\"/c\",\"value\":\n" +
+		"{\"value\":true}\n" +
+		"[\"/d\"]\n" +
+		"{\"key\":\"/e\",\"value\":[1,2]}"
+	if trie, err := NewFromJSONLines(strings.NewReader(input)); trie != nil || err == nil {
+		t.Fatalf("NewFromJSONLines() of the bad lines = %v, %v", trie, err)
+	}
+
+	trie := New()
+	n, err := trie.ReadJSONLines(strings.NewReader(input))
+	var lerr *JSONLineError
+	if n != 2 || !errors.As(err, &lerr) || lerr.Line != 4 || !strings.Contains(err.Error(), "line 4") {
+		t.Fatalf("ReadJSONLines() = %d, %v, want 2 and the error of line 4", n, err)
+	}
+	if got := sortedKeys(trie.Keys()); !reflect.DeepEqual(got, []string{"/a", "/b"}) {
+		t.Errorf("Keys() = %v, want the keys before the bad line", got)
+	}
+
+	var skipped []int
+	trie, err = NewFromJSONLines(strings.NewReader(input), SkipBadLines(func(err *JSONLineError) {
+		skipped = append(skipped, err.Line)
+		if err.Line == 5 && !errors.Is(err, ErrMissingKey) {
+			t.Errorf("the error of line 5 = %v, want %v", err, ErrMissingKey)
+		}
+	}))
+	if err != nil || !reflect.DeepEqual(skipped, []int{4, 5, 6}) {
+		t.Fatalf("NewFromJSONLines() skipping = %v, %v, want the lines 4, 5 and 6 skipped", skipped, err)
+	}
+	want := map[string]interface{}{"/a": 1.0, "/b": nil, "/e": []interface{}{1.0, 2.0}}
+	if got := trie.FindByPrefixAll(""); !reflect.DeepEqual(got, want) {
+		t.Errorf("FindByPrefixAll() = %v, want %v", got, want)
+	}
+	if _, err := New().ReadJSONLines(strings.NewReader("{}"), SkipBadLines(nil)); err != nil {
+		t.Errorf("ReadJSONLines() skipping with nil = %v", err)
+	}
+
+	// the lines longer than the limit of bufio.Scanner.
+	long := strings.Repeat("x", 1<<17)
+	trie = New()
+	if n, err := trie.ReadJSONLines(strings.NewReader(`{"key":"/long","value":"` + long + `"}`)); n != 1 || err != nil {
+		t.Errorf("ReadJSONLines() of a long line = %d, %v", n, err)
+	}
+}
+
+func TestTrie_ReadJSONLinesLimits(t *testing.T) {
+	input := "{\"key\":\"/a\",\"value\":1}\n" +
+		"{\"key\":\"/a\",\"value\":2}\n" +
+		"{\"key\":\"/b\",\"value\":3}\n" +
+		"{\"key\":\"/c\",\"value\":4}\n" +
+		"{\"key\":\"/b\",\"value\":5}\n"
+	var rejected []string
+	trie := New(WithMaxKeys(2), WithOnReject(func(key string, err error) {
+		rejected = append(rejected, key)
+	}))
+	// the key replaced is not counted and the key rejected fails the reading.
+	n, err := trie.ReadJSONLines(strings.NewReader(input))
+	var lerr *JSONLineError
+	if n != 2 || !errors.As(err, &lerr) || lerr.Line != 4 || !errors.Is(err, ErrTrieFull) {
+		t.Fatalf("ReadJSONLines() = %d, %v, want 2 and %v of line 4", n, err, ErrTrieFull)
+	}
+	if !reflect.DeepEqual(rejected, []string{"/c"}) {
+		t.Errorf("the keys rejected = %v, want [/c]", rejected)
+	}
+
+	var skipped []int
+	trie, err = NewFromJSONLines(strings.NewReader(input), WithTrieOptions(WithMaxKeys(2)), SkipBadLines(func(err *JSONLineError) {
+		skipped = append(skipped, err.Line)
+	}))
+	if err != nil || !reflect.DeepEqual(skipped, []int{4}) {
+		t.Fatalf("NewFromJSONLines() skipping = %v, %v, want the line 4 skipped", skipped, err)
+	}
+	want := map[string]interface{}{"/a": 2.0, "/b": 5.0}
+	if got := trie.FindByPrefixAll(""); !reflect.DeepEqual(got, want) {
+		t.Errorf("FindByPrefixAll() = %v, want %v", got, want)
+	}
+	// "/a/1" is added new under the lock of the partition of "/a".
+	trie = New(WithPrefixLocks('/'))
+	if n, err := trie.ReadJSONLines(strings.NewReader(input + "{\"key\":\"/a/1\"}\n")); n != 4 || err != nil {
+		t.Errorf("ReadJSONLines() with the prefix locks = %d, %v, want 4", n, err)
+	}
+}
@@ -0,0 +1,93 @@
+package gtrie
+
+import "sort"
+
+// KV is a key and value pair stored in the trie.
+type KV struct {
+	Key   string
+	Value interface{}
+}
+
+// byKV sorts KV pairs by key
+type byKV []KV
+
+func (a byKV) Len() int           { return len(a) }
+func (a byKV) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a byKV) Less(i, j int) bool { return a[i].Key < a[j].Key }
+
+// FindByPrefixKV returns all the keys and values starting with `prefix`
+// in lexicographic order of the keys.
+func (t *Trie) FindByPrefixKV(prefix string) []KV {
+	if t == nil {
+		return nil
+	}
+	t.rlock()
+	defer t.runlock()
+	node := findNode(t.root, t.canonical(prefix))
+	if node == nil {
+		return nil
+	}
+	return nodeKVs(collectNodes(node))
+}
+
+// FindMatchingPrefixKV returns all the matched prefix keys and values against to
+// the input `key` in lexicographic order, which is from the shortest to the longest prefix.
+func (t *Trie) FindMatchingPrefixKV(key string) []KV {
+	if t == nil {
+		return nil
+	}
+	t.rlock()
+	defer t.runlock()
+	return nodeKVs(matchingprefixcollect(t.root, t.runes(key), false))
+}
+
+// FindByFuzzyKV performs a fuzzy search against the keys in the trie
+// and returns the keys and values found in lexicographic order.
+func (t *Trie) FindByFuzzyKV(key string) []KV {
+	if t == nil {
+		return nil
+	}
+	t.rlock()
+	defer t.runlock()
+	return nodeKVs(fuzzycollectNodes(t.root, t.runes(key), false))
+}
+
+// FindRelativeKV returns all the relative keys and values of the input `key`
+// (FindByPrefix + FindMatchingPrefix + FindByFuzzy) in lexicographic order.
+func (t *Trie) FindRelativeKV(key string) []KV {
+	if t == nil {
+		return nil
+	}
+	t.rlock()
+	defer t.runlock()
+	nodes, _ := t.searchNodes(key, SearchAllRelativeKey, &searchOptions{})
+	return nodeKVs(nodes)
+}
+
+// SearchKV finds all matching keys and values according to stype (SearchType)
+// and returns them in lexicographic order of the keys.
+func (t *Trie) SearchKV(key string, stype SearchType) []KV {
+	if t == nil {
+		return nil
+	}
+	t.rlock()
+	defer t.runlock()
+	nodes, err := t.searchNodes(key, stype, &searchOptions{})
+	if err != nil {
+		return nil
+	}
+	return nodeKVs(nodes)
+}
+
+// nodeKVs returns the keys and values of the terminal nodes sorted by key.
+func nodeKVs(nodes []*trieNode) []KV {
+	kvs := make([]KV, 0, len(nodes))
+	for _, n := range nodes {
+		kvs = append(kvs, KV{Key: n.key(), Value: n.value})
+	}
+	sort.Sort(byKV(kvs))
+	return kvs
+}
+
+// noKVs is the iterator of no key returned by the iterators of a nil *Trie.
+func noKVs(yield func(string, interface{}) bool) {}
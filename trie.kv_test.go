@@ -0,0 +1,65 @@
+package gtrie
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func kvsToMap(kvs []KV) map[string]interface{} {
+	m := make(map[string]interface{}, len(kvs))
+	for _, kv := range kvs {
+		m[kv.Key] = kv.Value
+	}
+	return m
+}
+
+func TestTrie_KV(t *testing.T) {
+	trie := New()
+	for i, key := range gnmiFixture {
+		trie.Add(key, i)
+	}
+	key := "/interfaces/interface[name=1/2]/state"
+	tests := []struct {
+		name string
+		kvs  []KV
+		want map[string]interface{}
+	}{
+		{"FindByPrefixKV", trie.FindByPrefixKV("/interfaces/interface[name=1/"), trie.FindByPrefixAll("/interfaces/interface[name=1/")},
+		{"FindMatchingPrefixKV", trie.FindMatchingPrefixKV(key), trie.FindMatchingPrefixAll(key)},
+		{"FindByFuzzyKV", trie.FindByFuzzyKV("1/3counters"), trie.FindByFuzzyAll("1/3counters")},
+		{"FindRelativeKV", trie.FindRelativeKV(key), trie.FindRelativeAll(key)},
+		{"SearchKV", trie.SearchKV(key, SearchByPrefix), trie.SearchAll(key, SearchByPrefix)},
+		{"SearchKVExactly", trie.SearchKV(key, SearchExactly), trie.SearchAll(key, SearchExactly)},
+		{"SearchKVLongestMatchingPrefix", trie.SearchKV(key+"/x", SearchLongestMatchingPrefix), trie.SearchAll(key+"/x", SearchLongestMatchingPrefix)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if len(tt.kvs) == 0 {
+				t.Fatalf("%s returns nothing", tt.name)
+			}
+			if !sort.SliceIsSorted(tt.kvs, func(i, j int) bool { return tt.kvs[i].Key < tt.kvs[j].Key }) {
+				t.Errorf("%s is not sorted: %v", tt.name, tt.kvs)
+			}
+			if got := kvsToMap(tt.kvs); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+
+	want := []KV{
+		{"/interfaces", 0},
+		{"/interfaces/interface", 1},
+		{"/interfaces/interface[name=1/2]", 2},
+		{"/interfaces/interface[name=1/2]/state", 3},
+	}
+	if got := trie.FindMatchingPrefixKV(key); !reflect.DeepEqual(got, want) {
+		t.Errorf("FindMatchingPrefixKV() = %v, want %v", got, want)
+	}
+	if got := trie.FindByPrefixKV("/none"); got != nil {
+		t.Errorf("FindByPrefixKV() = %v, want nil", got)
+	}
+	if got := trie.SearchKV(key, SearchType(42)); got != nil {
+		t.Errorf("SearchKV() = %v, want nil", got)
+	}
+}
@@ -0,0 +1,146 @@
+package gtrie
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// WithKeyLess orders the keys by `less` instead of lexicographic order in the
+// ordered operations: the sorted and paged results of SearchWithOptions and
+// Search (OrderBy(Lexicographic), the default order), FindByPrefixDesc,
+// IterByPrefixDesc, the sorted FindByPrefixWhere, NextPage, Successor,
+// Predecessor and the ties of SuggestCorrections. `less` must be a strict
+// total order of the keys given as they are added, e.g. NaturalLess.
+// The nodes of the trie are still in rune order, so that these operations
+// collect all the keys matched and sort them instead of walking them in order:
+// the cost is O(n log n) of the n keys matched, or O(n log k) for k keys
+// selected by MaxResults (and Offset) or a page of NextPage.
+func WithKeyLess(less func(a, b string) bool) Option {
+	return func(t *Trie) {
+		t.keyLess = less
+	}
+}
+
+// lessKey reports whether the key `a` is less than `b` in the order of the trie.
+func (t *Trie) lessKey(a, b string) bool {
+	if t.keyLess != nil {
+		return t.keyLess(a, b)
+	}
+	return a < b
+}
+
+// NaturalLess reports whether `a` is less than `b` in the natural order,
+// which compares the runs of the decimal digits by their numeric values,
+// e.g. "/interface[name=1/2]" is less than "/interface[name=1/10]".
+// The other bytes are compared as they are. The strings equal in the natural
+// order but not the same (e.g. "01" and "1") are ordered lexicographically.
+func NaturalLess(a, b string) bool {
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		ca, cb := a[i], b[j]
+		if !isDigit(ca) || !isDigit(cb) {
+			if ca != cb {
+				return ca < cb
+			}
+			i++
+			j++
+			continue
+		}
+		// skip the leading zeros and compare the runs by their lengths first.
+		si, sj := i, j
+		for si < len(a) && a[si] == '0' {
+			si++
+		}
+		for sj < len(b) && b[sj] == '0' {
+			sj++
+		}
+		ei, ej := si, sj
+		for ei < len(a) && isDigit(a[ei]) {
+			ei++
+		}
+		for ej < len(b) && isDigit(b[ej]) {
+			ej++
+		}
+		if ei-si != ej-sj {
+			return ei-si < ej-sj
+		}
+		if na, nb := a[si:ei], b[sj:ej]; na != nb {
+			return na < nb
+		}
+		i, j = ei, ej
+	}
+	if len(a)-i != len(b)-j {
+		return len(a)-i < len(b)-j
+	}
+	return a < b
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// selectNodes returns the first `k` terminal nodes in the order of `less`
+// (the last ones from the greatest if desc) in the order. All the nodes are
+// sorted if `k` is zero or less or not less than the nodes. The nodes are
+// selected by a heap of `k` nodes, so that only the nodes selected are sorted.
+func selectNodes(nodes []*trieNode, k int, less func(a, b string) bool, desc bool) []*trieNode {
+	before := func(a, b *trieNode) bool {
+		if desc {
+			return less(b.key(), a.key())
+		}
+		return less(a.key(), b.key())
+	}
+	if k <= 0 || k >= len(nodes) {
+		sort.SliceStable(nodes, func(i, j int) bool {
+			return before(nodes[i], nodes[j])
+		})
+		return nodes
+	}
+	h := &nodeHeap{before: before, nodes: make([]*trieNode, 0, k)}
+	for _, n := range nodes {
+		if len(h.nodes) < k {
+			heap.Push(h, n)
+		} else if before(n, h.nodes[0]) {
+			h.nodes[0] = n
+			heap.Fix(h, 0)
+		}
+	}
+	// pop the last selected first.
+	selected := make([]*trieNode, len(h.nodes))
+	for i := len(selected) - 1; i >= 0; i-- {
+		selected[i] = heap.Pop(h).(*trieNode)
+	}
+	return selected
+}
+
+// nodeHeap is the heap of the nodes selected with the last of them on the top.
+type nodeHeap struct {
+	before func(a, b *trieNode) bool
+	nodes  []*trieNode
+}
+
+func (h *nodeHeap) Len() int           { return len(h.nodes) }
+func (h *nodeHeap) Less(i, j int) bool { return h.before(h.nodes[j], h.nodes[i]) }
+func (h *nodeHeap) Swap(i, j int)      { h.nodes[i], h.nodes[j] = h.nodes[j], h.nodes[i] }
+func (h *nodeHeap) Push(x any)         { h.nodes = append(h.nodes, x.(*trieNode)) }
+func (h *nodeHeap) Pop() any {
+	n := h.nodes[len(h.nodes)-1]
+	h.nodes = h.nodes[:len(h.nodes)-1]
+	return n
+}
+
+// walkKeyOrder calls `fn` with the terminal nodes under the node in the order
+// of the trie (descending if `desc` is true) until `fn` returns false.
+// The nodes are walked in order for lexicographic order; otherwise
+// they are collected and sorted by the order of WithKeyLess.
+func (t *Trie) walkKeyOrder(node *trieNode, desc bool, fn func(n *trieNode) bool) {
+	if t.keyLess == nil {
+		walkSorted(node, desc, fn)
+		return
+	}
+	for _, n := range selectNodes(collectNodes(node), 0, t.keyLess, desc) {
+		if !fn(n) {
+			return
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package gtrie
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"testing"
+
+	"github.com/neoul/gtrie/gtrietest"
+)
+
+func TestNaturalLess(t *testing.T) {
+	ordered := []string{
+		"",
+		"/interface",
+		"/interface[name=1/1]",
+		"/interface[name=1/1]/state",
+		"/interface[name=1/2]",
+		"/interface[name=1/9]",
+		"/interface[name=1/10]",
+		"/interface[name=1/10]/state",
+		"/interface[name=1/100]",
+		"/interface[name=2/01]",
+		"/interface[name=2/1]",
+		"/interface[name=2/1a]",
+		"/interface[name=eth0]",
+		"/interface[name=eth2]",
+		"/interface[name=eth10]",
+	}
+	for i, a := range ordered {
+		for j, b := range ordered {
+			if got := NaturalLess(a, b); got != (i < j) {
+				t.Errorf("NaturalLess(%q, %q) = %v, want %v", a, b, got, i < j)
+			}
+		}
+	}
+}
+
+func TestSelectNodes(t *testing.T) {
+	trie, model := New(), gtrietest.NewModelTrie()
+	keys := gtrietest.GeneratePathKeys(200, 4, 12, 1)
+	gtrietest.LoadKeys(trie, keys, nil)
+	gtrietest.LoadKeys(model, keys, nil)
+	all := model.Keys()
+	sort.Slice(all, func(i, j int) bool { return NaturalLess(all[i], all[j]) })
+	for _, desc := range []bool{false, true} {
+		want := slices.Clone(all)
+		if desc {
+			slices.Reverse(want)
+		}
+		for _, k := range []int{0, 1, 7, len(all) - 1, len(all), len(all) + 1} {
+			got := nodeKeys(selectNodes(collectNodes(trie.root), k, NaturalLess, desc))
+			n := len(all)
+			if k > 0 && k < n {
+				n = k
+			}
+			if !reflect.DeepEqual(got, want[:n]) {
+				t.Errorf("selectNodes(%d, desc %v) = %v, want %v", k, desc, got, want[:n])
+			}
+		}
+	}
+}
+
+func TestTrie_WithKeyLess(t *testing.T) {
+	trie := New(WithKeyLess(NaturalLess))
+	var want []string
+	for i := 1; i <= 12; i++ {
+		key := fmt.Sprintf("/interface[name=1/%d]", i)
+		trie.Add(key, i)
+		want = append(want, key)
+	}
+	desc := slices.Clone(want)
+	slices.Reverse(desc)
+
+	if got, _ := trie.SearchWithOptions("/interface", SearchByPrefix, Sorted()); !reflect.DeepEqual(got, want) {
+		t.Errorf("SearchWithOptions(Sorted) = %v, want %v", got, want)
+	}
+	if got := trie.Search("/interface", SearchByPrefix, Offset(8), MaxResults(3)); !reflect.DeepEqual(got, want[8:11]) {
+		t.Errorf("Search(Offset, MaxResults) = %v, want %v", got, want[8:11])
+	}
+	if got := trie.Search("/interface", SearchByPrefix, Descending(), MaxResults(2)); !reflect.DeepEqual(got, desc[:2]) {
+		t.Errorf("Search(Descending, MaxResults) = %v, want %v", got, desc[:2])
+	}
+	if got := trie.Search("e", SearchApproximate, OrderBy(ByLength), MaxResults(1)); !reflect.DeepEqual(got, want[:1]) {
+		t.Errorf("Search(OrderBy(ByLength)) = %v, want %v", got, want[:1])
+	}
+	if got := trie.FindByPrefixDesc("/interface"); !reflect.DeepEqual(got, desc) {
+		t.Errorf("FindByPrefixDesc() = %v, want %v", got, desc)
+	}
+	var iterated []string
+	for key := range trie.IterByPrefixDesc("/interface") {
+		iterated = append(iterated, key)
+	}
+	if !reflect.DeepEqual(iterated, desc) {
+		t.Errorf("IterByPrefixDesc() = %v, want %v", iterated, desc)
+	}
+	if got := pageAll(t, trie, NewResumeToken("/interface", MaxResults(5)), func(cursor string) {
+		trie.Remove(cursor)
+	}); !reflect.DeepEqual(got, want) {
+		t.Errorf("NextPage() = %v, want %v", got, want)
+	}
+	trie.Add(want[4], 5)
+	if key, _, ok := trie.Successor(want[1]); !ok || key != want[2] {
+		t.Errorf("Successor(%q) = %q, %v, want %q", want[1], key, ok, want[2])
+	}
+	if key, _, ok := trie.Predecessor("/interface[name=1/3]/x"); !ok || key != want[2] {
+		t.Errorf("Predecessor() = %q, %v, want %q", key, ok, want[2])
+	}
+	got := trie.FindByPrefixWhere("/interface", func(key string, v interface{}) bool {
+		return v.(int)%2 == 0
+	}, Sorted(), MaxResults(3))
+	if keys := sortedKeys(keysOf(got)); !reflect.DeepEqual(keys, []string{want[1], want[3], want[5]}) {
+		t.Errorf("FindByPrefixWhere(Sorted, MaxResults) = %v", keys)
+	}
+
+	// the ties of the distance, the common prefix and the length.
+	trie = New(WithKeyLess(NaturalLess))
+	trie.Add("a10", nil)
+	trie.Add("a9b", nil)
+	if got := trie.SuggestCorrections("a", 2, 0); !reflect.DeepEqual(got, []string{"a9b", "a10"}) {
+		t.Errorf("SuggestCorrections() = %v", got)
+	}
+}
@@ -0,0 +1,32 @@
+package gtrie
+
+// The lifecycle of the trie nodes
+//
+// A node is live while it is reachable from the root of the trie. The nodes
+// are only modified under the write lock and only read under the read lock,
+// except in the atomic read mode, where the nodes of the published root are
+// read without any lock and are never modified; the writer copies them into
+// the current generation first (see writable).
+//
+// A node is detached when it is removed from the children of its parent.
+// The readers that reached the node before, e.g. an iteration of the published
+// root in the atomic read mode or a node kept by the caller of a traversal,
+// may still iterate its children. So the children map of a detached node is
+// never written nor dropped; a subtree is detached by removing its root from
+// the live parent only, and is left to the garbage collector as it is.
+// The children map of a live node is written only under the write lock and
+// never while it is published.
+
+// retire drops the links of the detached node `n` that would keep the trie
+// or the value alive, leaving its children as they are. A node published in
+// the atomic read mode is left untouched since it may be still read without lock.
+// The value is dropped only for the slabs of the nodes, which keep the node alive.
+func (t *Trie) retire(n *trieNode) {
+	if n.gen != t.gen {
+		return
+	}
+	n.parent = nil
+	if t.slabbed() {
+		n.value = nil
+	}
+}
@@ -0,0 +1,132 @@
+package gtrie
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestTrie_DetachedNodes(t *testing.T) {
+	options := map[string][]Option{
+		"plain":  nil,
+		"arena":  {WithArena(16)},
+		"sorted": {WithSortedChildren()},
+	}
+	for oname, opts := range options {
+		trie := New(opts...)
+		for _, key := range []string{"/a/x/1", "/a/x/2", "/a/y", "/b"} {
+			trie.Add(key, key)
+		}
+		// the nodes kept by a reader before the detachment.
+		node := findNode(trie.root, "/a/x/")
+		first := trie.root.children['/']
+		term := findNode(trie.root, "/b").children[nul]
+		trie.Remove("/b")
+		if term.parent != nil {
+			t.Errorf("%s: the terminal removed keeps the parent", oname)
+		}
+		if want := interface{}("/b"); trie.arena != nil {
+			if term.value != nil {
+				t.Errorf("%s: the terminal removed keeps the value in the arena", oname)
+			}
+		} else if term.value != want {
+			t.Errorf("%s: the value of the terminal removed = %v, want %v", oname, term.value, want)
+		}
+		trie.Clear()
+		// the children of the nodes detached are left as they are.
+		got := sortedKeys(collect(node))
+		if want := []string{"/a/x/1", "/a/x/2"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: the keys of the node detached = %v, want %v", oname, got, want)
+		}
+		if first.parent != nil {
+			t.Errorf("%s: the child of the root cleared is not unlinked", oname)
+		}
+		checkNodes(t, trie.root, true)
+	}
+}
+
+func TestTrie_DetachedNodesPublished(t *testing.T) {
+	trie := New(WithAtomicReads(), WithArena(16))
+	trie.Add("/a/x", "x")
+	root := trie.published.Load()
+	term := findNode(root, "/a/x").children[nul]
+	trie.Remove("/a/x")
+	trie.Clear()
+	// the nodes published are never modified.
+	if term.value != "x" || term.parent == nil {
+		t.Errorf("the terminal published is modified: %v, %p", term.value, term.parent)
+	}
+	if got := collect(root); !reflect.DeepEqual(got, []string{"/a/x"}) {
+		t.Errorf("the root published = %v", got)
+	}
+}
+
+// TestTrie_ConcurrentDetach is for the race detector; the readers traverse
+// the nodes while the writer detaches them.
+func TestTrie_ConcurrentDetach(t *testing.T) {
+	const rounds = 100
+	for _, atomicReads := range []bool{false, true} {
+		var opts []Option
+		if atomicReads {
+			opts = append(opts, WithAtomicReads())
+		}
+		trie := New(opts...)
+		var wg sync.WaitGroup
+		done := make(chan struct{})
+		readers := []func(){
+			func() { trie.FindByPrefix("/k/") },
+			func() { trie.FindByFuzzy("k1") },
+			func() { trie.KeysSnapshot("/k/") },
+			func() {
+				for range trie.IterSnapshot("/k/") {
+					trie.Remove("/k/0")
+				}
+			},
+			func() {
+				for range trie.IterByPrefixDesc("/k/") {
+				}
+			},
+		}
+		if atomicReads {
+			// the nodes published are traversed without lock.
+			readers = append(readers, func() {
+				if node := findNode(trie.published.Load(), "/k/"); node != nil {
+					collect(node)
+				}
+			})
+		}
+		for _, read := range readers {
+			wg.Add(1)
+			go func(read func()) {
+				defer wg.Done()
+				for {
+					select {
+					case <-done:
+						return
+					default:
+						read()
+					}
+				}
+			}(read)
+		}
+		for i := 0; i < rounds; i++ {
+			for j := 0; j < 10; j++ {
+				trie.Add(fmt.Sprintf("/k/%d", j), j)
+			}
+			for j := 0; j < 5; j++ {
+				trie.Remove(fmt.Sprintf("/k/%d", j))
+			}
+			if i%2 == 0 {
+				trie.Clear()
+			} else {
+				trie.Drain()
+			}
+		}
+		close(done)
+		wg.Wait()
+		if trie.Size() != 0 {
+			t.Errorf("atomic %v: Size() = %d, want 0", atomicReads, trie.Size())
+		}
+	}
+}
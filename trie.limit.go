@@ -0,0 +1,96 @@
+package gtrie
+
+// limits guards the trie against the keys blowing the memory.
+// A limit of zero or less is no limit.
+type limits struct {
+	maxKeyLength int
+	maxKeys      int
+	maxChildren  int
+	maxBytes     int
+	onReject     func(key string, err error)
+}
+
+// limit returns the limits of the trie creating them if not set.
+func (t *Trie) limit() *limits {
+	if t.limits == nil {
+		t.limits = &limits{}
+	}
+	return t.limits
+}
+
+// WithMaxKeyLength rejects the keys longer than `n` bytes with ErrKeyTooLong.
+// The length is checked against the key given before the key transform.
+func WithMaxKeyLength(n int) Option {
+	return func(t *Trie) {
+		t.limit().maxKeyLength = n
+	}
+}
+
+// WithMaxKeys rejects the new keys with ErrTrieFull if the trie has `n` keys.
+// The values of the existing keys can be still replaced.
+func WithMaxKeys(n int) Option {
+	return func(t *Trie) {
+		t.limit().maxKeys = n
+	}
+}
+
+// WithMaxChildren rejects the new keys with ErrTooManyChildren if a node
+// on the path of the key would have more than `n` children.
+// The end of a key is not counted as a child.
+func WithMaxChildren(n int) Option {
+	return func(t *Trie) {
+		t.limit().maxChildren = n
+	}
+}
+
+// WithOnReject sets the hook called with the key and the error whenever
+// a key is rejected by the limits, including the rejections returned by AddE.
+// It is called after the lock of the trie is released.
+func WithOnReject(fn func(key string, err error)) Option {
+	return func(t *Trie) {
+		t.limit().onReject = fn
+	}
+}
+
+// checkLength checks the length of the `key` before it is canonicalized.
+func (l *limits) checkLength(key string) error {
+	if l.maxKeyLength > 0 && len(key) > l.maxKeyLength {
+		return ErrKeyTooLong
+	}
+	return nil
+}
+
+// checkNew checks the new canonical key `ckey` against the size and the fanout
+// limits of the trie rooted at `root`.
+func (l *limits) checkNew(root *trieNode, size int, ckey string) error {
+	if l.maxKeys > 0 && size >= l.maxKeys {
+		return ErrTrieFull
+	}
+	if l.maxChildren <= 0 {
+		return nil
+	}
+	node := root
+	for _, r := range ckey {
+		c, ok := node.children[r]
+		if !ok {
+			// the nodes created below have a child only.
+			fanout := len(node.children)
+			if _, ok := node.children[nul]; ok {
+				fanout--
+			}
+			if fanout >= l.maxChildren {
+				return ErrTooManyChildren
+			}
+			return nil
+		}
+		node = c
+	}
+	return nil
+}
+
+// reject calls the hook of the rejection if set.
+func (t *Trie) reject(key string, err error) {
+	if t.limits != nil && t.limits.onReject != nil {
+		t.limits.onReject(key, err)
+	}
+}
@@ -0,0 +1,106 @@
+package gtrie
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTrie_WithMaxKeyLength(t *testing.T) {
+	trie := New(WithMaxKeyLength(8))
+	if err := trie.AddE("12345678", 1); err != nil {
+		t.Errorf("AddE() of a key of the max length error = %v", err)
+	}
+	if err := trie.AddE("123456789", 1); err != ErrKeyTooLong {
+		t.Errorf("AddE() of a long key error = %v, want %v", err, ErrKeyTooLong)
+	}
+	trie.Add(strings.Repeat("x", 1<<20), 1)
+	if trie.Size() != 1 {
+		t.Errorf("Size() = %d, want 1", trie.Size())
+	}
+}
+
+func TestTrie_WithMaxKeys(t *testing.T) {
+	trie := New(WithMaxKeys(2))
+	for _, key := range []string{"foo", "bar"} {
+		if err := trie.AddE(key, 1); err != nil {
+			t.Errorf("AddE(%q) error = %v", key, err)
+		}
+	}
+	if err := trie.AddE("baz", 1); err != ErrTrieFull {
+		t.Errorf("AddE() to a full trie error = %v, want %v", err, ErrTrieFull)
+	}
+	if err := trie.AddE("foo", 2); err != nil {
+		t.Errorf("AddE() replacing a value of a full trie error = %v", err)
+	}
+	if v, _ := trie.Find("foo"); v != 2 {
+		t.Errorf("Find() = %v, want 2", v)
+	}
+	if trie.TryAdd("baz", 1, time.Second) {
+		t.Errorf("TryAdd() to a full trie = true")
+	}
+	trie.Remove("bar")
+	if err := trie.AddE("baz", 1); err != nil {
+		t.Errorf("AddE() after Remove error = %v", err)
+	}
+	if got := sortedKeys(trie.Keys()); !reflect.DeepEqual(got, []string{"baz", "foo"}) {
+		t.Errorf("Keys() = %v", got)
+	}
+}
+
+func TestTrie_WithMaxChildren(t *testing.T) {
+	trie := New(WithMaxChildren(2))
+	for _, key := range []string{"/a", "/b", "/a/x", "/a/y", "/a"} {
+		if err := trie.AddE(key, 1); err != nil {
+			t.Errorf("AddE(%q) error = %v", key, err)
+		}
+	}
+	for _, key := range []string{"/c", "/a/z"} {
+		if err := trie.AddE(key, 1); err != ErrTooManyChildren {
+			t.Errorf("AddE(%q) error = %v, want %v", key, err, ErrTooManyChildren)
+		}
+	}
+	// the end of a key is not a child.
+	if err := trie.AddE("/", 1); err != nil {
+		t.Errorf("AddE(%q) error = %v", "/", err)
+	}
+	if trie.Size() != 5 {
+		t.Errorf("Size() = %d, want 5", trie.Size())
+	}
+}
+
+func TestTrie_WithOnReject(t *testing.T) {
+	type rejection struct {
+		key string
+		err error
+	}
+	var got []rejection
+	var trie *Trie
+	trie = New(WithMaxKeys(1), WithMaxKeyLength(4), WithOnReject(func(key string, err error) {
+		// the hook is called without the lock.
+		trie.Find(key)
+		got = append(got, rejection{key, err})
+	}))
+	trie.Add("foo", 1)
+	trie.Add("bar", 1)
+	trie.Add("foobar", 1)
+	trie.AddE("baz", 1)
+	err := trie.Txn(func(tx *Txn) error {
+		tx.Add("qux", 1)
+		tx.Remove("foo")
+		return nil
+	})
+	if !errors.Is(err, ErrTrieFull) {
+		t.Errorf("Txn() error = %v, want %v", err, ErrTrieFull)
+	}
+	want := []rejection{{"bar", ErrTrieFull}, {"foobar", ErrKeyTooLong}, {"baz", ErrTrieFull}, {"qux", ErrTrieFull}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("rejections = %v, want %v", got, want)
+	}
+	// the transaction rejected is not applied at all.
+	if _, ok := trie.Find("foo"); !ok || trie.Size() != 1 {
+		t.Errorf("Size() = %d, want 1 with foo", trie.Size())
+	}
+}
@@ -0,0 +1,56 @@
+package gtrie
+
+import "sync"
+
+// loadCall is an in-flight or completed FindOrLoad call.
+type loadCall struct {
+	wg    sync.WaitGroup
+	value interface{}
+	err   error
+}
+
+// FindOrLoad returns the value of the key if it exists in the trie.
+// Otherwise, it calls `load` to get the value, adds it to the trie and returns it.
+// The concurrent callers for the same missing key share a single call of `load`.
+// The error of `load` is returned to all of them and nothing is added to the trie.
+func (t *Trie) FindOrLoad(key string, load func(key string) (interface{}, error)) (interface{}, error) {
+	if t == nil {
+		return nil, ErrNilTrie
+	}
+	if value, ok := t.Find(key); ok {
+		return value, nil
+	}
+	k := string(t.runes(key))
+	t.loadMu.Lock()
+	if c, ok := t.loads[k]; ok {
+		t.loadMu.Unlock()
+		c.wg.Wait()
+		return c.value, c.err
+	}
+	// the key may be loaded while waiting for loadMu.
+	if value, ok := t.Find(key); ok {
+		t.loadMu.Unlock()
+		return value, nil
+	}
+	c := &loadCall{}
+	c.wg.Add(1)
+	if t.loads == nil {
+		t.loads = make(map[string]*loadCall)
+	}
+	t.loads[k] = c
+	t.loadMu.Unlock()
+
+	defer func() {
+		t.loadMu.Lock()
+		delete(t.loads, k)
+		t.loadMu.Unlock()
+		c.wg.Done()
+	}()
+	c.value, c.err = load(key)
+	if c.err != nil {
+		c.value = nil
+		return nil, c.err
+	}
+	t.Add(key, c.value)
+	return c.value, nil
+}
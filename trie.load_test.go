@@ -0,0 +1,63 @@
+package gtrie
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTrie_FindOrLoad(t *testing.T) {
+	trie := New()
+	trie.Add("cached", 1)
+	load := func(key string) (interface{}, error) {
+		t.Errorf("load(%q) is called for the cached key", key)
+		return nil, nil
+	}
+	if v, err := trie.FindOrLoad("cached", load); err != nil || v != 1 {
+		t.Errorf("FindOrLoad() = %v, %v, want 1, nil", v, err)
+	}
+
+	errLoad := errors.New("load failed")
+	if v, err := trie.FindOrLoad("missing", func(string) (interface{}, error) {
+		return 2, errLoad
+	}); err != errLoad || v != nil {
+		t.Errorf("FindOrLoad() = %v, %v, want nil, %v", v, err, errLoad)
+	}
+	if _, ok := trie.Find("missing"); ok {
+		t.Errorf("FindOrLoad() cached the failed load")
+	}
+	if v, err := trie.FindOrLoad("missing", func(key string) (interface{}, error) {
+		return key + "!", nil
+	}); err != nil || v != "missing!" {
+		t.Errorf("FindOrLoad() = %v, %v, want missing!, nil", v, err)
+	}
+	if v, ok := trie.Find("missing"); !ok || v != "missing!" {
+		t.Errorf("Find() = %v, %v, want missing!, true", v, ok)
+	}
+}
+
+func TestTrie_FindOrLoadConcurrent(t *testing.T) {
+	trie := New()
+	var calls atomic.Int32
+	load := func(key string) (interface{}, error) {
+		calls.Add(1)
+		time.Sleep(10 * time.Millisecond)
+		return "value", nil
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, err := trie.FindOrLoad("key", load); err != nil || v != "value" {
+				t.Errorf("FindOrLoad() = %v, %v, want value, nil", v, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := calls.Load(); n != 1 {
+		t.Errorf("load is called %d times, want 1", n)
+	}
+}
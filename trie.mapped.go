@@ -0,0 +1,278 @@
+package gtrie
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"unsafe"
+)
+
+// mappedMagic and mappedVersion head the file of WriteMapped.
+const (
+	mappedMagic   = "GTMM"
+	mappedVersion = 1
+)
+
+// The file of WriteMapped is laid out for OpenMapped to look up the keys
+// directly from the bytes mapped into memory:
+//
+//	header   magic "GTMM", version, states, values, span, reserved (uint32 each
+//	         but the magic) and the length of the string table (uint64); 32 bytes
+//	base     the base array of the double array; int32 per state
+//	check    the check array of the double array; int32 per state
+//	records  a fixed-size record of 16 bytes per value: kind, length (uint32)
+//	         and the offset into the string table or the scalar value (uint64)
+//	strings  the bytes of the values referred to by the records
+//
+// All the integers are little-endian regardless of the host, so that a file
+// can be shared across hosts; a big-endian host converts the arrays on open
+// instead of using the bytes mapped. The sections are aligned by their size.
+const (
+	mappedHeaderSize = 32
+	mappedRecordSize = 16
+)
+
+// The kinds of the values in the records. The values of the other types are
+// encoded by encoding/gob into the string table.
+const (
+	mappedNil uint32 = iota
+	mappedString
+	mappedBytes
+	mappedBool
+	mappedInt
+	mappedInt64
+	mappedUint64
+	mappedFloat64
+	mappedGob
+)
+
+// mappedFile is the file mapped by OpenMapped holding the values of a StaticTrie.
+type mappedFile struct {
+	data    []byte // the whole file
+	records []byte
+	strings []byte
+	values  int
+	unmap   func([]byte) error
+}
+
+// WriteMapped compiles the trie like Compile and writes it to the file
+// at `path` in the flat layout opened by OpenMapped. The file is written
+// into a temporary file and renamed to `path`, so that the file mapped by
+// the readers is never modified. The values of the types other than nil,
+// string, []byte, bool, int, int64, uint64 and float64 are encoded by
+// encoding/gob and must be registered by gob.Register.
+func (t *Trie) WriteMapped(path string) error {
+	if t == nil {
+		return new(Trie).WriteMapped(path)
+	}
+	data, err := t.Compile().marshalMapped()
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(data); err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+// marshalMapped returns the static trie in the layout of WriteMapped.
+func (s *StaticTrie) marshalMapped() ([]byte, error) {
+	var strs bytes.Buffer
+	records := make([]byte, 0, s.Size()*mappedRecordSize)
+	for i := 0; i < s.Size(); i++ {
+		kind, length, word := mappedNil, uint32(0), uint64(0)
+		switch v := s.valueAt(i).(type) {
+		case nil:
+		case string:
+			kind, length, word = mappedString, uint32(len(v)), uint64(strs.Len())
+			strs.WriteString(v)
+		case []byte:
+			kind, length, word = mappedBytes, uint32(len(v)), uint64(strs.Len())
+			strs.Write(v)
+		case bool:
+			kind = mappedBool
+			if v {
+				word = 1
+			}
+		case int:
+			kind, word = mappedInt, uint64(v)
+		case int64:
+			kind, word = mappedInt64, uint64(v)
+		case uint64:
+			kind, word = mappedUint64, v
+		case float64:
+			kind, word = mappedFloat64, math.Float64bits(v)
+		default:
+			start := strs.Len()
+			if err := gob.NewEncoder(&strs).Encode(&v); err != nil {
+				return nil, fmt.Errorf("gtrie: value %d: %w", i, err)
+			}
+			kind, length, word = mappedGob, uint32(strs.Len()-start), uint64(start)
+		}
+		records = binary.LittleEndian.AppendUint32(records, kind)
+		records = binary.LittleEndian.AppendUint32(records, length)
+		records = binary.LittleEndian.AppendUint64(records, word)
+	}
+	data := make([]byte, 0, mappedHeaderSize+8*len(s.base)+len(records)+strs.Len())
+	data = append(data, mappedMagic...)
+	for _, v := range []uint32{mappedVersion, uint32(len(s.base)), uint32(s.Size()), uint32(s.span), 0} {
+		data = binary.LittleEndian.AppendUint32(data, v)
+	}
+	data = binary.LittleEndian.AppendUint64(data, uint64(strs.Len()))
+	for _, array := range [][]int32{s.base, s.check} {
+		for _, v := range array {
+			data = binary.LittleEndian.AppendUint32(data, uint32(v))
+		}
+	}
+	data = append(data, records...)
+	data = append(data, strs.Bytes()...)
+	return data, nil
+}
+
+// OpenMapped opens the file written by WriteMapped as a StaticTrie looking up
+// the keys directly from the file mapped into memory without decoding it.
+// The values are decoded on each lookup. The lookups never read out of the
+// file, but a corrupted file may give wrong results; the values failing to
+// be decoded are returned as nil. It returns ErrInvalidStaticTrie if the file
+// is not in the layout. The StaticTrie must be closed by Close to unmap the file.
+// On the platforms without mmap, the file is read into memory instead.
+func OpenMapped(path string) (*StaticTrie, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() < mappedHeaderSize || info.Size() > math.MaxInt {
+		return nil, ErrInvalidStaticTrie
+	}
+	data, unmap, err := mapFile(f, int(info.Size()))
+	if err != nil {
+		return nil, err
+	}
+	s, err := openMapped(data)
+	if err != nil {
+		if unmap != nil {
+			unmap(data)
+		}
+		return nil, err
+	}
+	s.mapped.unmap = unmap
+	return s, nil
+}
+
+// openMapped returns the static trie on the bytes in the layout of WriteMapped.
+func openMapped(data []byte) (*StaticTrie, error) {
+	le := binary.LittleEndian
+	if string(data[:len(mappedMagic)]) != mappedMagic || le.Uint32(data[4:]) != mappedVersion {
+		return nil, ErrInvalidStaticTrie
+	}
+	states, values := uint64(le.Uint32(data[8:])), uint64(le.Uint32(data[12:]))
+	span, strs := int32(le.Uint32(data[16:])), le.Uint64(data[24:])
+	size := mappedHeaderSize + 8*states + mappedRecordSize*values
+	if span < 0 || span > 257 || strs > math.MaxInt64-size || uint64(len(data)) != size+strs {
+		return nil, ErrInvalidStaticTrie
+	}
+	arrays := data[mappedHeaderSize:]
+	records := arrays[8*states:]
+	s := &StaticTrie{
+		base:  int32s(arrays[:4*states]),
+		check: int32s(arrays[4*states : 8*states]),
+		span:  span,
+		mapped: &mappedFile{
+			data:    data,
+			records: records[:mappedRecordSize*values],
+			strings: records[mappedRecordSize*values:],
+			values:  int(values),
+		},
+	}
+	return s, nil
+}
+
+// int32s returns the little-endian int32s of `b` sharing the bytes if the host
+// is little-endian and `b` is aligned, or converting them otherwise.
+func int32s(b []byte) []int32 {
+	n := len(b) / 4
+	if n == 0 {
+		return nil
+	}
+	if binary.NativeEndian.Uint16([]byte{1, 0}) == 1 && uintptr(unsafe.Pointer(&b[0]))%4 == 0 {
+		return unsafe.Slice((*int32)(unsafe.Pointer(&b[0])), n)
+	}
+	a := make([]int32, n)
+	for i := range a {
+		a[i] = int32(binary.LittleEndian.Uint32(b[4*i:]))
+	}
+	return a
+}
+
+// value decodes the value of the index from its record.
+func (m *mappedFile) value(idx int) interface{} {
+	r := m.records[idx*mappedRecordSize:]
+	kind, length, word := binary.LittleEndian.Uint32(r), uint64(binary.LittleEndian.Uint32(r[4:])), binary.LittleEndian.Uint64(r[8:])
+	var b []byte
+	switch kind {
+	case mappedString, mappedBytes, mappedGob:
+		if word > uint64(len(m.strings)) || length > uint64(len(m.strings))-word {
+			return nil
+		}
+		b = m.strings[word : word+length]
+	}
+	switch kind {
+	case mappedString:
+		return string(b)
+	case mappedBytes:
+		return bytes.Clone(b)
+	case mappedBool:
+		return word != 0
+	case mappedInt:
+		return int(int64(word))
+	case mappedInt64:
+		return int64(word)
+	case mappedUint64:
+		return word
+	case mappedFloat64:
+		return math.Float64frombits(word)
+	case mappedGob:
+		var v interface{}
+		if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&v); err != nil {
+			return nil
+		}
+		return v
+	}
+	return nil
+}
+
+// Close unmaps the file of the static trie opened by OpenMapped. The static
+// trie must not be used after Close. It does nothing for the other static tries.
+func (s *StaticTrie) Close() error {
+	m := s.mapped
+	if m == nil {
+		return nil
+	}
+	s.base, s.check, s.mapped = nil, nil, &mappedFile{}
+	if m.unmap == nil {
+		return nil
+	}
+	return m.unmap(m.data)
+}
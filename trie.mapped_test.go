@@ -0,0 +1,186 @@
+package gtrie
+
+import (
+	"bytes"
+	"encoding/gob"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+type mappedValue struct {
+	Name string
+	MTU  int
+}
+
+func init() {
+	gob.Register(mappedValue{})
+}
+
+func TestTrie_WriteMapped(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gnmi.gtmm")
+	trie := newStaticFixture()
+	if err := trie.WriteMapped(path); err != nil {
+		t.Fatalf("WriteMapped() error = %v", err)
+	}
+	s, err := OpenMapped(path)
+	if err != nil {
+		t.Fatalf("OpenMapped() error = %v", err)
+	}
+	checkStatic(t, trie, s)
+	// the file is replaced, not modified, under the static trie mapped.
+	trie.Add("/added", 1)
+	if err := trie.WriteMapped(path); err != nil {
+		t.Fatalf("WriteMapped() over the file mapped error = %v", err)
+	}
+	checkStatic(t, newStaticFixture(), s)
+	if err := s.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	if _, ok := s.Find("/added"); ok || s.Size() != 0 {
+		t.Errorf("the static trie closed is not empty")
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 1 {
+		t.Errorf("WriteMapped() leaves the files %v", entries)
+	}
+
+	empty := filepath.Join(t.TempDir(), "empty.gtmm")
+	if err := New().WriteMapped(empty); err != nil {
+		t.Fatalf("WriteMapped() of an empty trie error = %v", err)
+	}
+	s, err = OpenMapped(empty)
+	if err != nil {
+		t.Fatalf("OpenMapped() of an empty trie error = %v", err)
+	}
+	defer s.Close()
+	if _, ok := s.Find(""); ok || s.Size() != 0 || len(s.FindByPrefix("")) != 0 {
+		t.Errorf("the static trie of an empty trie is not empty")
+	}
+}
+
+func TestTrie_WriteMappedValues(t *testing.T) {
+	values := map[string]interface{}{
+		"nil":     nil,
+		"string":  "eth0",
+		"empty":   "",
+		"bytes":   []byte{0, 1, 2},
+		"bool":    true,
+		"int":     -1500,
+		"int64":   int64(-1) << 40,
+		"uint64":  uint64(1) << 63,
+		"float64": 0.25,
+		"struct":  mappedValue{Name: "eth0", MTU: 9000},
+		"slice":   []string{"a", "b"},
+	}
+	trie := New()
+	for key, v := range values {
+		trie.Add(key, v)
+	}
+	path := filepath.Join(t.TempDir(), "values.gtmm")
+	if err := trie.WriteMapped(path); err != nil {
+		t.Fatalf("WriteMapped() error = %v", err)
+	}
+	s, err := OpenMapped(path)
+	if err != nil {
+		t.Fatalf("OpenMapped() error = %v", err)
+	}
+	defer s.Close()
+	for key, want := range values {
+		if got, ok := s.Find(key); !ok || !reflect.DeepEqual(got, want) {
+			t.Errorf("Find(%q) = %#v, %v, want %#v", key, got, ok, want)
+		}
+	}
+
+	trie.Add("unregistered", struct{ X int }{1})
+	if err := trie.WriteMapped(path); err == nil {
+		t.Errorf("WriteMapped() of an unregistered type error = nil")
+	}
+}
+
+func TestOpenMapped_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "gnmi.gtmm")
+	if err := newStaticFixture().WriteMapped(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	invalid := map[string][]byte{
+		"short":     data[:mappedHeaderSize-1],
+		"magic":     append([]byte("GTXX"), data[4:]...),
+		"truncated": data[:len(data)-1],
+		"longer":    append(append([]byte{}, data...), 0),
+	}
+	for name, b := range invalid {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, b, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if s, err := OpenMapped(p); err != ErrInvalidStaticTrie {
+			t.Errorf("OpenMapped() of the %s file = %v, %v, want %v", name, s, err, ErrInvalidStaticTrie)
+		}
+	}
+	if _, err := OpenMapped(filepath.Join(dir, "none")); !os.IsNotExist(err) {
+		t.Errorf("OpenMapped() of no file error = %v", err)
+	}
+
+	// the lookups of a corrupted file never panic.
+	keys := newStaticFixture().Keys()
+	for i := mappedHeaderSize; i < len(data); i += 7 {
+		b := append([]byte{}, data...)
+		b[i] ^= 0xa5
+		s, err := openMapped(b)
+		if err != nil {
+			continue
+		}
+		for _, key := range keys {
+			s.Find(key)
+			s.FindLongestMatchingPrefix(key + "/x")
+		}
+		s.FindByPrefix("")
+	}
+}
+
+func TestInt32s(t *testing.T) {
+	b := []byte{1, 0, 0, 0, 0xff, 0xff, 0xff, 0xff, 0}
+	for _, b := range [][]byte{b[:8], append([]byte{0}, b...)[1:9]} {
+		if got := int32s(b); !reflect.DeepEqual(got, []int32{1, -1}) {
+			t.Errorf("int32s(%v) = %v", b, got)
+		}
+	}
+}
+
+func BenchmarkOpenMapped(b *testing.B) {
+	trie := dictTrie(b)
+	path := filepath.Join(b.TempDir(), "dict.gtmm")
+	if err := trie.WriteMapped(path); err != nil {
+		b.Fatal(err)
+	}
+	keys := trie.Keys()
+	b.Run("mapped", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			s, err := OpenMapped(path)
+			if err != nil {
+				b.Fatal(err)
+			}
+			s.Find(keys[i%len(keys)])
+			s.Close()
+		}
+	})
+	var buf bytes.Buffer
+	if _, err := trie.Compile().WriteTo(&buf); err != nil {
+		b.Fatal(err)
+	}
+	b.Run("read", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			s, err := ReadStaticTrie(bytes.NewReader(buf.Bytes()))
+			if err != nil {
+				b.Fatal(err)
+			}
+			s.Find(keys[i%len(keys)])
+		}
+	})
+}
@@ -0,0 +1,115 @@
+package gtrie
+
+import "unicode/utf8"
+
+// Match is an occurrence of a key found in a text by Matcher.
+type Match struct {
+	Key    string
+	Value  interface{}
+	Offset int // byte offset of the canonical key in the text
+}
+
+// acNode is a node of the Aho-Corasick automaton.
+type acNode struct {
+	children map[rune]*acNode
+	fail     *acNode // the longest proper suffix of this node in the automaton
+	dict     *acNode // the nearest node on the fail chain having a key
+	term     *KV     // the key ending at this node
+	size     int     // the length of the canonical key ending at this node in bytes
+}
+
+// Matcher is an Aho-Corasick automaton built from the keys of a trie.
+// It finds all the occurrences of the keys in a text in a single pass.
+// A Matcher is a frozen copy of the trie, so the later changes of the trie
+// are not reflected, and it is safe for concurrent use.
+type Matcher struct {
+	root *acNode
+}
+
+// BuildMatcher builds an Aho-Corasick Matcher from the current keys and values of the trie.
+// The goto function of the automaton is copied from the trie nodes and
+// the failure links are computed breadth-first. The empty key is ignored.
+func (t *Trie) BuildMatcher() *Matcher {
+	if t == nil {
+		return new(Trie).BuildMatcher()
+	}
+	t.rlock()
+	root := copyACNode(t.root, 0)
+	t.runlock()
+	root.term = nil
+
+	queue := make([]*acNode, 0, len(root.children))
+	for _, c := range root.children {
+		c.fail = root
+		queue = append(queue, c)
+	}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		for r, c := range n.children {
+			f := n.fail
+			for f != nil {
+				if next, ok := f.children[r]; ok {
+					c.fail = next
+					break
+				}
+				f = f.fail
+			}
+			if f == nil {
+				c.fail = root
+			}
+			if c.fail.term != nil {
+				c.dict = c.fail
+			} else {
+				c.dict = c.fail.dict
+			}
+			queue = append(queue, c)
+		}
+	}
+	return &Matcher{root: root}
+}
+
+// copyACNode copies the trie node of the path of `size` bytes and its subtree
+// into automaton nodes.
+func copyACNode(node *trieNode, size int) *acNode {
+	n := &acNode{children: make(map[rune]*acNode, len(node.children)), size: size}
+	for r, c := range node.children {
+		if r == nul {
+			if c.term {
+				n.term = &KV{Key: c.key(), Value: c.value}
+			}
+			continue
+		}
+		n.children[r] = copyACNode(c, size+utf8.RuneLen(r))
+	}
+	return n
+}
+
+// FindAllIn returns all the occurrences of the keys in the `text`,
+// including the overlapped ones, in order of their end position.
+// With a key transform, the canonical keys are found in the `text` as it is,
+// and the matches have the original keys.
+// The occurrences ending at the same position are ordered from the longest key.
+func (m *Matcher) FindAllIn(text string) []Match {
+	var matches []Match
+	node := m.root
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		i += size
+		for node != m.root {
+			if _, ok := node.children[r]; ok {
+				break
+			}
+			node = node.fail
+		}
+		if next, ok := node.children[r]; ok {
+			node = next
+		}
+		for o := node; o != nil; o = o.dict {
+			if o.term != nil {
+				matches = append(matches, Match{Key: o.term.Key, Value: o.term.Value, Offset: i - o.size})
+			}
+		}
+	}
+	return matches
+}
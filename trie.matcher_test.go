@@ -0,0 +1,86 @@
+package gtrie
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMatcher_FindAllIn(t *testing.T) {
+	trie := New()
+	for i, key := range []string{"he", "she", "his", "hers"} {
+		trie.Add(key, i)
+	}
+	m := trie.BuildMatcher()
+	want := []Match{
+		{Key: "she", Value: 1, Offset: 1},
+		{Key: "he", Value: 0, Offset: 2},
+		{Key: "hers", Value: 3, Offset: 2},
+	}
+	if got := m.FindAllIn("ushers"); !reflect.DeepEqual(got, want) {
+		t.Errorf("FindAllIn() = %v, want %v", got, want)
+	}
+	if got := m.FindAllIn("nothing"); len(got) != 0 {
+		t.Errorf("FindAllIn() = %v, want nothing", got)
+	}
+
+	// the matcher is a frozen copy.
+	trie.Add("us", 4)
+	if got := m.FindAllIn("ushers"); !reflect.DeepEqual(got, want) {
+		t.Errorf("FindAllIn() after Add() = %v, want %v", got, want)
+	}
+}
+
+func TestMatcher_FindAllInUnicode(t *testing.T) {
+	trie := New()
+	for _, key := range []string{"苹果", "果", "沂水县", "café", "é"} {
+		trie.Add(key, true)
+	}
+	text := "苹果 沂水县 café"
+	m := trie.BuildMatcher()
+	got := m.FindAllIn(text)
+	want := []string{"苹果", "果", "沂水县", "café", "é"}
+	if len(got) != len(want) {
+		t.Fatalf("FindAllIn() = %v, want %v", got, want)
+	}
+	for i := range got {
+		if got[i].Key != want[i] {
+			t.Errorf("FindAllIn()[%d].Key = %q, want %q", i, got[i].Key, want[i])
+		}
+		if text[got[i].Offset:got[i].Offset+len(got[i].Key)] != got[i].Key {
+			t.Errorf("FindAllIn()[%d].Offset = %d does not locate %q", i, got[i].Offset, got[i].Key)
+		}
+	}
+}
+
+func TestMatcher_FindAllInOverlapping(t *testing.T) {
+	trie := New()
+	for _, key := range []string{"a", "aa", "aaa", ""} {
+		trie.Add(key, nil)
+	}
+	got := trie.BuildMatcher().FindAllIn("aaaa")
+	// "a" x4, "aa" x3, "aaa" x2
+	if len(got) != 9 {
+		t.Errorf("FindAllIn() = %v, want 9 matches", got)
+	}
+	for _, match := range got {
+		if !strings.HasPrefix("aaaa"[match.Offset:], match.Key) {
+			t.Errorf("FindAllIn() match %v is wrong", match)
+		}
+	}
+}
+
+func TestMatcher_FindAllInTransform(t *testing.T) {
+	trie := New(WithKeyTransform(FoldDiacritics()))
+	trie.Add("café", 1)
+	trie.Add("é", 2)
+	// the canonical keys, "cafe" and "e", are shorter than the original ones.
+	want := []Match{
+		{Key: "é", Value: 2, Offset: 2},
+		{Key: "café", Value: 1, Offset: 4},
+		{Key: "é", Value: 2, Offset: 7},
+	}
+	if got := trie.BuildMatcher().FindAllIn("the cafe"); !reflect.DeepEqual(got, want) {
+		t.Errorf("FindAllIn() = %v, want %v", got, want)
+	}
+}
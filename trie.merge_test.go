@@ -0,0 +1,111 @@
+package gtrie
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTrie_Merge(t *testing.T) {
+	a := map[string]interface{}{"": 0, "/a": 1, "/a/b": 2, "/a/c": 3, "/d": 4, "/x/y/z": 5}
+	b := map[string]interface{}{"/a": 10, "/a/b/c": 20, "/a/d": 30, "/e/f": 40, "/x/y": 50, "": 60}
+	want := map[string]interface{}{"": 60, "/a": 10, "/a/b": 2, "/a/b/c": 20, "/a/c": 3, "/a/d": 30, "/d": 4, "/e/f": 40, "/x/y": 50, "/x/y/z": 5}
+	for name, opts := range map[string][]Option{
+		"plain":       nil,
+		"atomic":      {WithAtomicReads()},
+		"arena":       {WithArena(4)},
+		"sorted":      {WithSortedChildren()},
+		"interned":    {WithSegmentInterning('/')},
+		"ordered":     {WithInsertionOrder(), WithIncrementalHash(nil)},
+		"transformed": {WithKeyTransform(strings.ToLower)},
+		"limited":     {WithMaxKeys(100)},
+	} {
+		dst := New(opts...)
+		for k, v := range a {
+			dst.Add(k, v)
+		}
+		src := newTrieOf(b)
+		if got := dst.Merge(src); got != 4 {
+			t.Errorf("%s: Merge() = %d, want 4", name, got)
+		}
+		if got := dst.All(); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: All() after Merge = %v, want %v", name, got, want)
+		}
+		if dst.Size() != len(want) {
+			t.Errorf("%s: Size() after Merge = %d, want %d", name, dst.Size(), len(want))
+		}
+		checkNodes(t, dst.root, !dst.atomicReads)
+		if got, ok := dst.IncrementalHash(); ok && got != dst.Hash(nil) {
+			t.Errorf("%s: IncrementalHash() = %x, want %x", name, got, dst.Hash(nil))
+		}
+		// no node of the other is shared.
+		nodes := make(map[*trieNode]bool)
+		walkNodes(src.root, func(n *trieNode) { nodes[n] = true })
+		walkNodes(dst.root, func(n *trieNode) {
+			if nodes[n] {
+				t.Errorf("%s: node %q (depth %d) is shared", name, n.rval, n.depth)
+			}
+		})
+		dst.Remove("/e/f")
+		if got := src.All(); !reflect.DeepEqual(got, b) {
+			t.Errorf("%s: the other after Merge = %v, want %v", name, got, b)
+		}
+	}
+
+	trie := newTrieOf(a)
+	if got := trie.Merge(trie); got != 0 {
+		t.Errorf("Merge() into itself = %d", got)
+	}
+	if got := trie.Merge(nil); got != 0 {
+		t.Errorf("Merge(nil) = %d", got)
+	}
+	var rejected []string
+	trie = New(WithMaxKeys(7), WithOnReject(func(key string, err error) {
+		rejected = append(rejected, key)
+	}))
+	for k, v := range a {
+		trie.Add(k, v)
+	}
+	if got := trie.Merge(newTrieOf(b)); got != 1 || len(rejected) != 3 {
+		t.Errorf("Merge() over the limit = %d with rejected %v", got, rejected)
+	}
+}
+
+// mergeFixture returns two tries of `n` keys, of which 90% are under
+// the prefixes absent from the other.
+func mergeFixture(n int) (*Trie, *Trie) {
+	a, b := New(), New()
+	for i := 0; i < n; i++ {
+		a.Add(fmt.Sprintf("/a%d/leaf/%d", i%1000, i), i)
+		if i%10 == 0 {
+			b.Add(fmt.Sprintf("/a%d/leaf/%d", i%1000, i+1), i)
+		} else {
+			b.Add(fmt.Sprintf("/b%d/leaf/%d", i%1000, i), i)
+		}
+	}
+	return a, b
+}
+
+func BenchmarkMerge(b *testing.B) {
+	const n = 500000
+	_, src := mergeFixture(n)
+	b.Run("merge", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			dst, _ := mergeFixture(n)
+			b.StartTimer()
+			dst.Merge(src)
+		}
+	})
+	b.Run("re-add", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			dst, _ := mergeFixture(n)
+			b.StartTimer()
+			for k, v := range src.All() {
+				dst.Add(k, v)
+			}
+		}
+	})
+}
@@ -0,0 +1,128 @@
+package gtrie
+
+import "time"
+
+// KeyMeta is the metadata of a key recorded by WithTimestamps.
+type KeyMeta struct {
+	// CreatedAt is when the key was added first.
+	CreatedAt time.Time
+	// UpdatedAt is when the value of the key was added last.
+	UpdatedAt time.Time
+}
+
+// stamp is the time of the creation and the update of a key in Unix nanoseconds.
+type stamp struct {
+	created, updated int64
+}
+
+// WithTimestamps records when each key is added and updated.
+// Adding the key again updates UpdatedAt, but keeps CreatedAt;
+// Remove discards both. The timestamps are kept in a map by the terminal
+// nodes, not in the nodes, so that the trie without the option does not grow.
+func WithTimestamps() Option {
+	return func(t *Trie) {
+		t.stamps = make(map[*trieNode]stamp)
+	}
+}
+
+// WithClock sets the clock of the timestamps of WithTimestamps
+// and the traces of SetTracer instead of time.Now.
+func WithClock(now func() time.Time) Option {
+	return func(t *Trie) {
+		t.clock = now
+	}
+}
+
+// now returns the current time of the clock of WithClock or time.Now.
+func (t *Trie) now() time.Time {
+	if t.clock != nil {
+		return t.clock()
+	}
+	return time.Now()
+}
+
+// stamp records the time of the terminal node `n` replacing the node `old` if not nil.
+func (t *Trie) stamp(old, n *trieNode) {
+	ns := t.now().UnixNano()
+	s := stamp{created: ns, updated: ns}
+	if old != nil {
+		if o, ok := t.stamps[old]; ok {
+			s.created = o.created
+		}
+		delete(t.stamps, old)
+	}
+	t.stamps[n] = s
+}
+
+func (s stamp) meta() KeyMeta {
+	return KeyMeta{CreatedAt: time.Unix(0, s.created), UpdatedAt: time.Unix(0, s.updated)}
+}
+
+// Meta returns the metadata of the `key`. It returns false if the key does not
+// exist or the trie is not created with WithTimestamps.
+func (t *Trie) Meta(key string) (KeyMeta, bool) {
+	if t == nil {
+		return KeyMeta{}, false
+	}
+	t.rlock()
+	defer t.runlock()
+	node := findTerm(t.root, t.canonical(key))
+	if node == nil {
+		return KeyMeta{}, false
+	}
+	s, ok := t.stamps[node]
+	if !ok {
+		return KeyMeta{}, false
+	}
+	return s.meta(), true
+}
+
+// FindWithMeta is Find returning the metadata of the key as well.
+// The metadata is zero if the trie is not created with WithTimestamps.
+func (t *Trie) FindWithMeta(key string) (interface{}, KeyMeta, bool) {
+	if t == nil {
+		return nil, KeyMeta{}, false
+	}
+	// the read lock is taken even in the atomic read mode for the map of the timestamps.
+	t.rlock()
+	defer t.runlock()
+	node := findTerm(t.root, t.canonical(key))
+	if node == nil {
+		return nil, KeyMeta{}, false
+	}
+	var meta KeyMeta
+	if s, ok := t.stamps[node]; ok {
+		meta = s.meta()
+	}
+	return node.value, meta, true
+}
+
+// findTerm returns the terminal node of the canonical `key` or nil if not found.
+func findTerm(root *trieNode, key string) *trieNode {
+	node := findNode(root, key)
+	if node != nil {
+		node = node.children[nul]
+	}
+	if node == nil || !node.term {
+		return nil
+	}
+	return node
+}
+
+// FindOlderThan returns all the keys updated before `cutoff`, e.g. for the jobs
+// sweeping the stale keys. It returns nil if the trie is not created with WithTimestamps.
+func (t *Trie) FindOlderThan(cutoff time.Time) []string {
+	if t == nil {
+		return nil
+	}
+	t.rlock()
+	defer t.runlock()
+	var keys []string
+	ns := cutoff.UnixNano()
+	for n, s := range t.stamps {
+		if s.updated < ns {
+			keys = append(keys, n.key())
+		}
+	}
+	return keys
+}
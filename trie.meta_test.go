@@ -0,0 +1,69 @@
+package gtrie
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeClock is a clock advanced by a second per reading.
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time {
+	c.now = c.now.Add(time.Second)
+	return c.now
+}
+
+func TestTrie_WithTimestamps(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for name, opts := range map[string][]Option{
+		"plain":  nil,
+		"atomic": {WithAtomicReads()},
+		"arena":  {WithArena(4)},
+	} {
+		clock := &fakeClock{now: base}
+		trie := New(append(opts, WithTimestamps(), WithClock(clock.Now))...)
+		trie.Add("/a", 1) // +1s
+		trie.Add("/b", 2) // +2s
+		trie.Add("/c", 3) // +3s
+		trie.Add("/a", 4) // +4s
+		meta, ok := trie.Meta("/a")
+		if !ok || !meta.CreatedAt.Equal(base.Add(time.Second)) || !meta.UpdatedAt.Equal(base.Add(4*time.Second)) {
+			t.Errorf("%s: Meta() = %v, %v", name, meta, ok)
+		}
+		trie.Remove("/b")
+		trie.Compact(0)
+		if _, ok := trie.Meta("/b"); ok {
+			t.Errorf("%s: Meta() of the key removed is found", name)
+		}
+		trie.Add("/b", 5) // +5s
+		v, meta, ok := trie.FindWithMeta("/b")
+		if !ok || v != 5 || !meta.CreatedAt.Equal(base.Add(5*time.Second)) {
+			t.Errorf("%s: FindWithMeta() = %v, %v, %v", name, v, meta, ok)
+		}
+		if got := sortedKeys(trie.FindOlderThan(base.Add(4 * time.Second))); !reflect.DeepEqual(got, []string{"/c"}) {
+			t.Errorf("%s: FindOlderThan() = %v", name, got)
+		}
+		if got := sortedKeys(trie.FindOlderThan(base.Add(time.Hour))); !reflect.DeepEqual(got, []string{"/a", "/b", "/c"}) {
+			t.Errorf("%s: FindOlderThan() = %v", name, got)
+		}
+		trie.Clear()
+		if got := trie.FindOlderThan(base.Add(time.Hour)); len(got) != 0 {
+			t.Errorf("%s: FindOlderThan() after Clear = %v", name, got)
+		}
+	}
+}
+
+func TestTrie_MetaWithoutTimestamps(t *testing.T) {
+	trie := New()
+	trie.Add("/a", 1)
+	if _, ok := trie.Meta("/a"); ok {
+		t.Errorf("Meta() without WithTimestamps is found")
+	}
+	if v, meta, ok := trie.FindWithMeta("/a"); v != 1 || !ok || meta != (KeyMeta{}) {
+		t.Errorf("FindWithMeta() without WithTimestamps = %v, %v, %v", v, meta, ok)
+	}
+	if got := trie.FindOlderThan(time.Now()); got != nil {
+		t.Errorf("FindOlderThan() without WithTimestamps = %v", got)
+	}
+}
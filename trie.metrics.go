@@ -0,0 +1,44 @@
+package gtrie
+
+import "time"
+
+// MetricsSink receives the metrics of the trie operations.
+// It can be backed by Prometheus, expvar or any other metrics system.
+// The methods are called while the trie is locked, so they must not block
+// and must not call the methods of the trie.
+type MetricsSink interface {
+	IncCounter(name string)
+	ObserveDuration(name string, d time.Duration)
+}
+
+// The metric names reported to MetricsSink.
+const (
+	MetricAdd      = "gtrie.add"       // counter of Add
+	MetricRemove   = "gtrie.remove"    // counter of the keys removed by Remove
+	MetricFindHit  = "gtrie.find.hit"  // counter of Find finding the key
+	MetricFindMiss = "gtrie.find.miss" // counter of Find not finding the key
+
+	MetricSearchPrefix         = "gtrie.search.prefix"          // duration of FindByPrefix*
+	MetricSearchFuzzy          = "gtrie.search.fuzzy"           // duration of FindByFuzzy*
+	MetricSearchMatchingPrefix = "gtrie.search.matching_prefix" // duration of FindMatchingPrefix* and FindAll
+	MetricSearchLongestPrefix  = "gtrie.search.longest_prefix"  // duration of FindLongestMatchingPrefix
+	MetricSearchRelative       = "gtrie.search.relative"        // duration of FindRelative*
+)
+
+// Instrument installs the MetricsSink `m` to the trie. The number of the keys
+// is not reported since it is available by Size() without any lock.
+// Instrument(nil) uninstalls the sink; the operations are not measured
+// at all (only a nil check) if no sink is installed.
+func (t *Trie) Instrument(m MetricsSink) {
+	if t == nil {
+		return
+	}
+	t.lock()
+	defer t.unlock()
+	t.metrics = m
+}
+
+// observe reports the duration since `start`. It must be called under the lock.
+func (t *Trie) observe(name string, start time.Time) {
+	t.metrics.ObserveDuration(name, time.Since(start))
+}
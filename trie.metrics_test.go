@@ -0,0 +1,82 @@
+package gtrie
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeSink struct {
+	mu        sync.Mutex
+	counters  map[string]int
+	durations map[string]int
+}
+
+func newFakeSink() *fakeSink {
+	return &fakeSink{counters: map[string]int{}, durations: map[string]int{}}
+}
+
+func (s *fakeSink) IncCounter(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counters[name]++
+}
+
+func (s *fakeSink) ObserveDuration(name string, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if d < 0 {
+		panic("negative duration")
+	}
+	s.durations[name]++
+}
+
+func TestTrie_Instrument(t *testing.T) {
+	trie := New()
+	trie.Add("/not/measured", nil)
+	sink := newFakeSink()
+	trie.Instrument(sink)
+
+	trie.Add("/interfaces", 1)
+	trie.Add("/interfaces/interface", 2)
+	trie.Add("/interfaces", 3)
+	trie.Find("/interfaces")
+	trie.Find("/interfaces/interface")
+	trie.Find("/interfaces/none")
+	trie.Remove("/interfaces")
+	trie.Remove("/interfaces")
+	trie.FindByPrefix("/interfaces")
+	trie.FindByPrefixAll("/interfaces")
+	trie.FindByFuzzy("ii")
+	trie.FindMatchingPrefix("/interfaces/interface/x")
+	trie.FindLongestMatchingPrefix("/interfaces/interface/x")
+	trie.FindRelativeAll("/interfaces")
+
+	wantCounters := map[string]int{
+		MetricAdd:      3,
+		MetricFindHit:  2,
+		MetricFindMiss: 1,
+		MetricRemove:   1,
+	}
+	if !reflect.DeepEqual(sink.counters, wantCounters) {
+		t.Errorf("counters = %v, want %v", sink.counters, wantCounters)
+	}
+	wantDurations := map[string]int{
+		MetricSearchPrefix:         2,
+		MetricSearchFuzzy:          1,
+		MetricSearchMatchingPrefix: 1,
+		MetricSearchLongestPrefix:  1,
+		MetricSearchRelative:       1,
+	}
+	if !reflect.DeepEqual(sink.durations, wantDurations) {
+		t.Errorf("durations = %v, want %v", sink.durations, wantDurations)
+	}
+
+	trie.Instrument(nil)
+	trie.Add("/not/measured/again", nil)
+	trie.Find("/not/measured/again")
+	if sink.counters[MetricAdd] != 3 || sink.counters[MetricFindHit] != 2 {
+		t.Errorf("counters changed after Instrument(nil): %v", sink.counters)
+	}
+}
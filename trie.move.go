@@ -0,0 +1,240 @@
+package gtrie
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	// ErrPrefixOverlap is returned by MovePrefix if the new prefix is under the old prefix.
+	ErrPrefixOverlap = errors.New("gtrie: new prefix under the old prefix")
+	// ErrKeyExists is returned by MovePrefix if a key moved collides with an existing key.
+	ErrKeyExists = errors.New("gtrie: key exists")
+)
+
+// MovePrefix renames the prefix `oldPrefix` of the keys to `newPrefix`
+// in one locked operation and returns the number of the keys moved.
+// It fails without any change with ErrPrefixOverlap if `newPrefix` starts
+// with `oldPrefix` (a move into itself), and with ErrKeyExists if any key moved
+// would replace a key that is not moved; the existing keys are never overwritten.
+// The subtree of `oldPrefix` is detached and grafted under `newPrefix`
+// keeping its nodes, values and insertion order. With a key transform or
+// in the atomic read mode, the keys are removed and added one by one instead;
+// then the keys moved are rebuilt from their canonical form.
+// The limits of the trie are not applied to the keys moved.
+func (t *Trie) MovePrefix(oldPrefix, newPrefix string) (int, error) {
+	if t == nil {
+		return 0, ErrNilTrie
+	}
+	t.lock()
+	defer t.unlock()
+	oldPrefix, newPrefix = t.canonical(oldPrefix), t.canonical(newPrefix)
+	if oldPrefix == newPrefix {
+		return 0, nil
+	}
+	if strings.HasPrefix(newPrefix, oldPrefix) {
+		return 0, ErrPrefixOverlap
+	}
+	node := findNode(t.root, oldPrefix)
+	if node == nil {
+		return 0, nil
+	}
+	terms := collectNodes(node)
+	keys := make([]string, len(terms))
+	for i, n := range terms {
+		keys[i] = newPrefix + t.canonical(n.key())[len(oldPrefix):]
+		// the keys under the old prefix are moved away together.
+		if strings.HasPrefix(keys[i], oldPrefix) {
+			continue
+		}
+		if n := findNode(t.root, keys[i]); n != nil {
+			if n, ok := n.children[nul]; ok && n.term {
+				return 0, ErrKeyExists
+			}
+		}
+	}
+	if t.tracer != nil {
+		t.trace(TraceMovePrefix, oldPrefix, newPrefix)
+	}
+	if t.transform != nil || t.atomicReads {
+		// the keys moved are not traced one by one.
+		limits, tracer := t.limits, t.tracer
+		t.limits, t.tracer = nil, nil
+		values := make([]interface{}, len(terms))
+		for i, n := range terms {
+			values[i], _ = t.remove(n.key())
+		}
+		for i, key := range keys {
+			_ = t.add(key, values[i])
+		}
+		t.limits, t.tracer = limits, tracer
+		return len(terms), nil
+	}
+	t.detach(node)
+	if t.digest != nil {
+		for _, n := range terms {
+			t.digest.replace(n, nil)
+		}
+	}
+	delta := utf8.RuneCountInString(newPrefix) - utf8.RuneCountInString(oldPrefix)
+	walkNodes(node, func(n *trieNode) {
+		n.depth += delta
+	})
+	for i, n := range terms {
+		n.path = keys[i]
+		if t.segments != nil {
+			n.path = t.segments.intern(keys[i])
+		}
+	}
+	if t.digest != nil {
+		for _, n := range terms {
+			t.digest.replace(nil, n)
+		}
+	}
+	t.graft(node, newPrefix)
+	return len(terms), nil
+}
+
+// detach unlinks the subtree of the node from its parent and removes
+// the ancestors left without any key. The node must not be the root.
+func (t *Trie) detach(node *trieNode) {
+	parent := node.parent
+	parent.removeChild(node.rval)
+	node.parent = nil
+	for n := parent; n != nil; n = n.parent {
+		n.termCount -= node.termCount
+	}
+	addBytes(parent, -node.bytes())
+	changed, lost := parent, node.mask
+	for n := parent; n.parent != nil && len(n.children) == 0; n = changed {
+		changed, lost = n.parent, n.mask
+		changed.removeChild(n.rval)
+		t.arena.release(1)
+		t.retire(n)
+	}
+	updateMask(changed, lost)
+}
+
+// graft links the subtree of the detached node under the `prefix`
+// creating the nodes missing on the path. The depths of the subtree
+// must be already fixed for the `prefix`.
+func (t *Trie) graft(node *trieNode, prefix string) {
+	runes := []rune(prefix)
+	if len(runes) == 0 {
+		t.merge(t.root, node)
+		return
+	}
+	parent := t.root
+	for _, r := range runes[:len(runes)-1] {
+		c, ok := parent.children[r]
+		if !ok {
+			c = parent.newChild(t.nodeArena(), t.childCap, r, "", 0, nil, false)
+		}
+		parent = c
+	}
+	for n := parent; n != nil; n = n.parent {
+		n.termCount += node.termCount
+	}
+	addBytes(parent, node.bytes())
+	node.rval = runes[len(runes)-1]
+	node.mask = nodeMask(node)
+	updateWide(node)
+	updateCounts(node)
+	if dst, ok := parent.children[node.rval]; ok {
+		t.merge(dst, node)
+	} else {
+		node.parent = parent
+		parent.setChild(node)
+	}
+	// the nodes created on the path have no mask yet; none can stop early.
+	for n := parent; n != nil; n = n.parent {
+		n.mask = nodeMask(n)
+		updateWide(n)
+		updateCounts(n)
+	}
+}
+
+// merge moves the children of `src` into `dst` at the same position.
+// The terminal nodes of both must not collide.
+func (t *Trie) merge(dst, src *trieNode) {
+	dst.termCount += src.termCount
+	if dst.ext != nil && dst.ext.sized {
+		dst.ext.bytes += src.bytes()
+	}
+	for r, c := range src.children {
+		if d, ok := dst.children[r]; ok {
+			t.merge(d, c)
+			continue
+		}
+		c.parent = dst
+		dst.setChild(c)
+	}
+	t.retire(src)
+	t.arena.release(1)
+	dst.mask = nodeMask(dst)
+	updateWide(dst)
+	updateCounts(dst)
+}
+
+// nodeMask returns the mask of the node from its own rune and
+// the masks of its children.
+func nodeMask(n *trieNode) uint64 {
+	mask := uint64(1) << uint64(n.rval-'a')
+	for _, c := range n.children {
+		mask |= c.mask
+	}
+	return mask
+}
+
+// walkNodes calls `fn` with all the nodes of the subtree of the node.
+func walkNodes(node *trieNode, fn func(n *trieNode)) {
+	nodes := []*trieNode{node}
+	for len(nodes) > 0 {
+		n := nodes[len(nodes)-1]
+		nodes = nodes[:len(nodes)-1]
+		fn(n)
+		for _, c := range n.children {
+			nodes = append(nodes, c)
+		}
+	}
+}
+
+// CopyPrefix copies the keys having the prefix `srcPrefix` to the same keys
+// relative to `dstPrefix` in one locked operation, sharing the values.
+// The existing keys are overwritten. The keys are collected before the copy,
+// so that `dstPrefix` can be under `srcPrefix`. It returns the number of
+// the keys created or overwritten; the keys rejected by the limits of the
+// trie are not counted and only reported to the hook of WithOnReject.
+func (t *Trie) CopyPrefix(srcPrefix, dstPrefix string) int {
+	if t == nil {
+		return 0
+	}
+	type rejection struct {
+		key string
+		err error
+	}
+	var rejected []rejection
+	count := 0
+	t.lock()
+	srcPrefix, dstPrefix = t.canonical(srcPrefix), t.canonical(dstPrefix)
+	if node := findNode(t.root, srcPrefix); node != nil {
+		terms := collectNodes(node)
+		kvs := make([]KV, len(terms))
+		for i, n := range terms {
+			kvs[i] = KV{Key: dstPrefix + t.canonical(n.key())[len(srcPrefix):], Value: n.value}
+		}
+		for _, kv := range kvs {
+			if err := t.add(kv.Key, kv.Value); err != nil {
+				rejected = append(rejected, rejection{kv.Key, err})
+				continue
+			}
+			count++
+		}
+	}
+	t.unlock()
+	for _, r := range rejected {
+		t.reject(r.key, r.err)
+	}
+	return count
+}
@@ -0,0 +1,169 @@
+package gtrie
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// checkNodes checks the depths, term counts and masks of the nodes under the node,
+// and their parents if `parents`; the atomic read mode does not keep them up to date.
+func checkNodes(t *testing.T, node *trieNode, parents bool) {
+	t.Helper()
+	checkMasks(t, node)
+	walkNodes(node, func(n *trieNode) {
+		count := 0
+		for r, c := range n.children {
+			if (parents && c.parent != n) || c.rval != r || c.depth != n.depth+1 {
+				t.Errorf("child %q of %q (depth %d) has parent %p, rune %q, depth %d", r, n.rval, n.depth, c.parent, c.rval, c.depth)
+			}
+			if r == nul {
+				count++
+			} else {
+				count += c.termCount
+			}
+		}
+		if n.termCount != count && n.rval != nul {
+			t.Errorf("termCount of %q (depth %d) = %d, want %d", n.rval, n.depth, n.termCount, count)
+		}
+		if n.parent != nil && len(n.children) == 0 && n.rval != nul {
+			t.Errorf("%q (depth %d) is left without children", n.rval, n.depth)
+		}
+	})
+}
+
+func TestTrie_MovePrefix(t *testing.T) {
+	keys := []string{"/a", "/a/x", "/a/x/1", "/a/x/2", "/a/y", "/b/3", "/c"}
+	tests := []struct {
+		name     string
+		old, new string
+		want     int
+		err      error
+		keys     []string
+	}{
+		{"rename", "/a/x", "/b/x", 3, nil, []string{"/a", "/a/y", "/b/3", "/b/x", "/b/x/1", "/b/x/2", "/c"}},
+		{"merge", "/a/x/", "/b/", 2, nil, []string{"/a", "/a/x", "/a/y", "/b/1", "/b/2", "/b/3", "/c"}},
+		{"partial rune", "/a/", "/z", 4, nil, []string{"/a", "/b/3", "/c", "/zx", "/zx/1", "/zx/2", "/zy"}},
+		{"to the ancestor", "/a/x/", "/a/", 2, nil, []string{"/a", "/a/1", "/a/2", "/a/x", "/a/y", "/b/3", "/c"}},
+		{"to the root", "/a/x/", "", 2, nil, []string{"/a", "/a/x", "/a/y", "/b/3", "/c", "1", "2"}},
+		{"whole", "/", "#", 7, nil, []string{"#a", "#a/x", "#a/x/1", "#a/x/2", "#a/y", "#b/3", "#c"}},
+		{"not found", "/d", "/e", 0, nil, keys},
+		{"same", "/a", "/a", 0, nil, keys},
+		{"descendant", "/a", "/a/x/z", 0, ErrPrefixOverlap, keys},
+		{"root into itself", "", "/r", 0, ErrPrefixOverlap, keys},
+		{"collision", "/a/y", "/a/x", 0, ErrKeyExists, keys},
+		{"collision of the prefix", "/a/x", "/c", 0, ErrKeyExists, keys},
+	}
+	options := map[string][]Option{
+		"plain":       nil,
+		"atomic":      {WithAtomicReads()},
+		"interned":    {WithSegmentInterning('/')},
+		"arena":       {WithArena(16)},
+		"transformed": {WithKeyTransform(strings.ToLower)},
+	}
+	for oname, opts := range options {
+		for _, tt := range tests {
+			trie := New(opts...)
+			for i, key := range keys {
+				trie.Add(key, i)
+			}
+			got, err := trie.MovePrefix(tt.old, tt.new)
+			if got != tt.want || err != tt.err {
+				t.Errorf("%s: %s: MovePrefix(%q, %q) = %d, %v, want %d, %v", oname, tt.name, tt.old, tt.new, got, err, tt.want, tt.err)
+			}
+			if keys := sortedKeys(trie.Keys()); !reflect.DeepEqual(keys, tt.keys) {
+				t.Errorf("%s: %s: Keys() = %v, want %v", oname, tt.name, keys, tt.keys)
+			}
+			if trie.Size() != len(tt.keys) {
+				t.Errorf("%s: %s: Size() = %d, want %d", oname, tt.name, trie.Size(), len(tt.keys))
+			}
+			checkNodes(t, trie.root, !trie.atomicReads)
+		}
+	}
+}
+
+func TestTrie_MovePrefixValues(t *testing.T) {
+	trie := New(WithInsertionOrder(), WithIncrementalHash(nil), WithSegmentInterning('/'))
+	for _, key := range []string{"/b/2", "/a/x/1", "/c", "/a/x/2", "/a/y"} {
+		trie.Add(key, key)
+	}
+	if n, err := trie.MovePrefix("/a/x", "/d/e/f"); n != 2 || err != nil {
+		t.Fatalf("MovePrefix() = %d, %v", n, err)
+	}
+	for _, key := range []string{"/a/x/1", "/a/x/2"} {
+		if v, ok := trie.Find("/d/e/f" + key[len("/a/x"):]); !ok || v != key {
+			t.Errorf("Find() of the key moved from %q = %v, %v", key, v, ok)
+		}
+		if _, ok := trie.Find(key); ok {
+			t.Errorf("Find(%q) is found after the move", key)
+		}
+	}
+	if got := trie.KeysInOrder(); !reflect.DeepEqual(got, []string{"/b/2", "/d/e/f/1", "/c", "/d/e/f/2", "/a/y"}) {
+		t.Errorf("KeysInOrder() = %v", got)
+	}
+	if got, _ := trie.IncrementalHash(); got != trie.Hash(nil) {
+		t.Errorf("IncrementalHash() = %x, want %x", got, trie.Hash(nil))
+	}
+	if got := trie.FindByPrefix("/d/e/"); len(got) != 2 {
+		t.Errorf("FindByPrefix() = %v", got)
+	}
+	if key, _, ok := trie.FindLongestMatchingPrefix("/d/e/f/1/2"); !ok || key != "/d/e/f/1" {
+		t.Errorf("FindLongestMatchingPrefix() = %q, %v", key, ok)
+	}
+}
+
+func TestTrie_CopyPrefix(t *testing.T) {
+	keys := []string{"/a", "/a/x", "/a/x/1", "/b/3"}
+	tests := []struct {
+		name     string
+		src, dst string
+		want     int
+		keys     []string
+	}{
+		{"copy", "/a/", "/c/", 2, []string{"/a", "/a/x", "/a/x/1", "/b/3", "/c/x", "/c/x/1"}},
+		{"overwrite", "/a/x", "/b/", 2, []string{"/a", "/a/x", "/a/x/1", "/b/", "/b//1", "/b/3"}},
+		{"into itself", "/a", "/a/x/", 3, []string{"/a", "/a/x", "/a/x/", "/a/x//x", "/a/x//x/1", "/a/x/1", "/b/3"}},
+		{"into itself at the root", "", "/a/", 4, []string{"/a", "/a//a", "/a//a/x", "/a//a/x/1", "/a//b/3", "/a/x", "/a/x/1", "/b/3"}},
+		{"same", "/a/x", "/a/x", 2, keys},
+		{"empty source", "/d", "/e", 0, keys},
+	}
+	for _, tt := range tests {
+		trie := New()
+		for _, key := range keys {
+			trie.Add(key, key)
+		}
+		if got := trie.CopyPrefix(tt.src, tt.dst); got != tt.want {
+			t.Errorf("%s: CopyPrefix(%q, %q) = %d, want %d", tt.name, tt.src, tt.dst, got, tt.want)
+		}
+		if got := sortedKeys(trie.Keys()); !reflect.DeepEqual(got, tt.keys) {
+			t.Errorf("%s: Keys() = %v, want %v", tt.name, got, tt.keys)
+		}
+		checkNodes(t, trie.root, true)
+	}
+}
+
+func TestTrie_CopyPrefixValues(t *testing.T) {
+	type config struct{ mtu int }
+	def := &config{mtu: 1500}
+	var rejected []string
+	trie := New(WithMaxKeys(5), WithOnReject(func(key string, err error) {
+		rejected = append(rejected, key)
+	}))
+	trie.Add("/default/mtu", def)
+	trie.Add("/default/enabled", true)
+	if got := trie.CopyPrefix("/default/", "/eth0/"); got != 2 {
+		t.Errorf("CopyPrefix() = %d, want 2", got)
+	}
+	v, _ := trie.Find("/eth0/mtu")
+	if v != def {
+		t.Errorf("Find() = %v, want the value shared", v)
+	}
+	def.mtu = 9000
+	if v.(*config).mtu != 9000 {
+		t.Errorf("the value copied is not shared")
+	}
+	// the trie has a room for one more key.
+	if got := trie.CopyPrefix("/default/", "/eth1/"); got != 1 || len(rejected) != 1 {
+		t.Errorf("CopyPrefix() over the limit = %d with rejected %v", got, rejected)
+	}
+}
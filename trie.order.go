@@ -0,0 +1,144 @@
+package gtrie
+
+import "iter"
+
+// OrderOption configures the insertion order maintained by WithInsertionOrder.
+type OrderOption func(o *order)
+
+// RefreshOnOverwrite moves an existing key to the end of the insertion order
+// when it is added again. By default, the key keeps its original position.
+func RefreshOnOverwrite() OrderOption {
+	return func(o *order) {
+		o.refresh = true
+	}
+}
+
+// orderLink is the intrusive link of a terminal node in the insertion order.
+// It is allocated only for the terminal nodes of the trie created with WithInsertionOrder.
+type orderLink struct {
+	prev, next *trieNode
+}
+
+// order is a doubly-linked list of the terminal nodes in the insertion order.
+type order struct {
+	head, tail *trieNode
+	refresh    bool
+}
+
+// pushBack links the terminal node `n` at the end of the list.
+func (o *order) pushBack(n *trieNode) {
+	n.link = &orderLink{prev: o.tail}
+	if o.tail != nil {
+		o.tail.link.next = n
+	} else {
+		o.head = n
+	}
+	o.tail = n
+}
+
+// unlink removes the terminal node `n` from the list.
+func (o *order) unlink(n *trieNode) {
+	if n == nil || n.link == nil {
+		return
+	}
+	if n.link.prev != nil {
+		n.link.prev.link.next = n.link.next
+	} else {
+		o.head = n.link.next
+	}
+	if n.link.next != nil {
+		n.link.next.link.prev = n.link.prev
+	} else {
+		o.tail = n.link.prev
+	}
+	n.link = nil
+}
+
+// replace puts the terminal node `new` to the list in place of the terminal node `old`
+// added with the same key. `old` can be nil if the key is added first.
+func (o *order) replace(old, new *trieNode) {
+	if old == nil || o.refresh {
+		o.unlink(old)
+		o.pushBack(new)
+		return
+	}
+	new.link = old.link
+	old.link = nil
+	if new.link.prev != nil {
+		new.link.prev.link.next = new
+	} else {
+		o.head = new
+	}
+	if new.link.next != nil {
+		new.link.next.link.prev = new
+	} else {
+		o.tail = new
+	}
+}
+
+func (o *order) reset() {
+	o.head = nil
+	o.tail = nil
+}
+
+// nodes returns the terminal nodes in the insertion order.
+func (o *order) nodes(size int) []*trieNode {
+	nodes := make([]*trieNode, 0, size)
+	for n := o.head; n != nil; n = n.link.next {
+		nodes = append(nodes, n)
+	}
+	return nodes
+}
+
+// WithInsertionOrder maintains the order in which the keys are added
+// so that KeysInOrder and IterInOrder return the keys in that order.
+// A key added again keeps its original position unless RefreshOnOverwrite is given.
+// The order costs two pointers per key; the trie created without
+// the option does not allocate them.
+func WithInsertionOrder(opts ...OrderOption) Option {
+	return func(t *Trie) {
+		t.order = &order{}
+		for _, opt := range opts {
+			opt(t.order)
+		}
+	}
+}
+
+// KeysInOrder returns all the keys in the order they were added.
+// It returns nil if the trie is not created with WithInsertionOrder.
+func (t *Trie) KeysInOrder() []string {
+	if t == nil {
+		return nil
+	}
+	t.rlock()
+	defer t.runlock()
+	if t.order == nil {
+		return nil
+	}
+	return nodeKeys(t.order.nodes(t.Size()))
+}
+
+// IterInOrder returns an iterator over all the keys and values in the order
+// the keys were added. Like IterSnapshot, the pairs are copied under the read lock
+// when the iteration starts and then yielded without holding any lock.
+// It yields nothing if the trie is not created with WithInsertionOrder.
+func (t *Trie) IterInOrder() iter.Seq2[string, interface{}] {
+	if t == nil {
+		return noKVs
+	}
+	return func(yield func(string, interface{}) bool) {
+		t.rlock()
+		var kvs []KV
+		if t.order != nil {
+			for _, n := range t.order.nodes(t.Size()) {
+				kvs = append(kvs, KV{Key: n.key(), Value: n.value})
+			}
+		}
+		t.runlock()
+		for _, kv := range kvs {
+			if !yield(kv.Key, kv.Value) {
+				return
+			}
+		}
+	}
+}
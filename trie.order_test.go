@@ -0,0 +1,106 @@
+package gtrie
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTrie_KeysInOrder(t *testing.T) {
+	trie := New(WithInsertionOrder())
+	for _, key := range []string{"/c", "/a", "/b/x", "/b"} {
+		trie.Add(key, key)
+	}
+	if got, want := trie.KeysInOrder(), []string{"/c", "/a", "/b/x", "/b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("KeysInOrder() = %v, want %v", got, want)
+	}
+
+	// overwrite keeps the position.
+	trie.Add("/a", 1)
+	if got, want := trie.KeysInOrder(), []string{"/c", "/a", "/b/x", "/b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("KeysInOrder() after overwrite = %v, want %v", got, want)
+	}
+	if v, _ := trie.Find("/a"); v != 1 {
+		t.Errorf("Find() = %v, want 1", v)
+	}
+	trie.Add("/c", 1)
+	trie.Add("/b", 1)
+	if got, want := trie.KeysInOrder(), []string{"/c", "/a", "/b/x", "/b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("KeysInOrder() after overwrite = %v, want %v", got, want)
+	}
+
+	// remove and re-add moves the key to the end.
+	trie.Remove("/a")
+	if got, want := trie.KeysInOrder(), []string{"/c", "/b/x", "/b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("KeysInOrder() after Remove = %v, want %v", got, want)
+	}
+	trie.Remove("/c")
+	trie.Remove("/b")
+	trie.Remove("/none")
+	if got, want := trie.KeysInOrder(), []string{"/b/x"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("KeysInOrder() after Remove = %v, want %v", got, want)
+	}
+	trie.Add("/a", 2)
+	trie.Add("/c", 2)
+	if got, want := trie.KeysInOrder(), []string{"/b/x", "/a", "/c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("KeysInOrder() after re-add = %v, want %v", got, want)
+	}
+
+	trie.Clear()
+	if got := trie.KeysInOrder(); len(got) != 0 {
+		t.Errorf("KeysInOrder() after Clear = %v, want empty", got)
+	}
+	trie.Add("/z", 0)
+	if got, want := trie.KeysInOrder(), []string{"/z"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("KeysInOrder() after Clear = %v, want %v", got, want)
+	}
+
+	if got := New().KeysInOrder(); got != nil {
+		t.Errorf("KeysInOrder() without the option = %v, want nil", got)
+	}
+}
+
+func TestTrie_KeysInOrder_Refresh(t *testing.T) {
+	trie := New(WithInsertionOrder(RefreshOnOverwrite()))
+	for _, key := range []string{"/a", "/b", "/c"} {
+		trie.Add(key, key)
+	}
+	trie.Add("/a", 1)
+	if got, want := trie.KeysInOrder(), []string{"/b", "/c", "/a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("KeysInOrder() = %v, want %v", got, want)
+	}
+	trie.Add("/a", 2)
+	trie.Add("/b", 2)
+	if got, want := trie.KeysInOrder(), []string{"/c", "/a", "/b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("KeysInOrder() = %v, want %v", got, want)
+	}
+	trie.Remove("/a")
+	if got, want := trie.KeysInOrder(), []string{"/c", "/b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("KeysInOrder() = %v, want %v", got, want)
+	}
+}
+
+func TestTrie_IterInOrder(t *testing.T) {
+	trie := New(WithInsertionOrder())
+	keys := []string{"/k/3", "/k/1", "/k/2"}
+	for i, key := range keys {
+		trie.Add(key, i)
+	}
+	var got []string
+	for k, v := range trie.IterInOrder() {
+		if keys[v.(int)] != k {
+			t.Errorf("IterInOrder() yields %s, %v", k, v)
+		}
+		got = append(got, k)
+		// mutations during the iteration must not deadlock.
+		trie.Add(k+"/x", nil)
+		if len(got) == 2 {
+			break
+		}
+	}
+	if want := keys[:2]; !reflect.DeepEqual(got, want) {
+		t.Errorf("IterInOrder() = %v, want %v", got, want)
+	}
+	for range New().IterInOrder() {
+		t.Errorf("IterInOrder() without the option yields")
+	}
+}
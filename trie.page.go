@@ -0,0 +1,257 @@
+package gtrie
+
+import "sort"
+
+// window collects a page of the terminal nodes visited in order:
+// it skips the first `offset` nodes and stops the walk at `max` nodes.
+type window struct {
+	offset int
+	max    int
+	terms  []*trieNode
+}
+
+// add collects the node unless skipped and returns false if the page is full.
+func (w *window) add(n *trieNode) bool {
+	if w.offset > 0 {
+		w.offset--
+		return true
+	}
+	w.terms = append(w.terms, n)
+	return w.max <= 0 || len(w.terms) < w.max
+}
+
+// paged reports whether the results are cut by MaxResults or Offset.
+func (o *searchOptions) paged() bool {
+	return o.max > 0 || o.offset > 0
+}
+
+// searchPage returns the terminal nodes matching to stype (SearchType) in the order
+// and the page of the search options under the read lock. The nodes are sorted
+// in lexicographic order if paged without an order, so that the pages are stable.
+// The page is cut inside the walk if the nodes can be walked in the order,
+// i.e. in lexicographic order without CaseFold, the key transform and
+// WithKeyLess, except for SearchAllRelativeKey merging three searches.
+// Otherwise all the nodes matched are sorted before cut; only the nodes
+// up to the end of the page are sorted in the order of WithKeyLess.
+func (t *Trie) searchPage(key string, stype SearchType, o *searchOptions) ([]*trieNode, error) {
+	if !stype.valid() {
+		return nil, ErrUnknownSearchType
+	}
+	ordered := o.sorted || o.paged()
+	if ordered && o.order == Lexicographic && !o.fold && t.transform == nil && t.keyLess == nil && stype != SearchAllRelativeKey {
+		w := &window{offset: o.offset, max: o.max}
+		t.walkSearch(key, stype, o, w.add)
+		return w.terms, nil
+	}
+	nodes, err := t.searchNodes(key, stype, o)
+	if err != nil || !ordered {
+		return nodes, err
+	}
+	if o.order == Lexicographic && t.keyLess != nil {
+		k := 0
+		if o.max > 0 {
+			k = o.offset + o.max
+		}
+		nodes = selectNodes(nodes, k, t.keyLess, o.desc)
+	} else {
+		sortNodes(nodes, o.order, o.desc)
+	}
+	if o.offset >= len(nodes) {
+		return nil, nil
+	}
+	nodes = nodes[o.offset:]
+	if o.max > 0 && len(nodes) > o.max {
+		nodes = nodes[:o.max]
+	}
+	return nodes, nil
+}
+
+// sortNodes sorts the terminal nodes by their keys in the order,
+// or in reverse if desc is true.
+func sortNodes(nodes []*trieNode, order SearchOrder, desc bool) {
+	keys := nodeKeys(nodes)
+	var s sort.Interface = nodesByKey{sort.StringSlice(keys), keys, nodes}
+	if order == ByLength {
+		s = nodesByKey{byKeys(keys), keys, nodes}
+	}
+	if desc {
+		s = sort.Reverse(s)
+	}
+	sort.Sort(s)
+}
+
+// nodesByKey sorts the nodes along with their keys by the order of the keys.
+type nodesByKey struct {
+	order sort.Interface
+	keys  []string
+	nodes []*trieNode
+}
+
+func (a nodesByKey) Len() int           { return len(a.nodes) }
+func (a nodesByKey) Less(i, j int) bool { return a.order.Less(i, j) }
+func (a nodesByKey) Swap(i, j int) {
+	a.keys[i], a.keys[j] = a.keys[j], a.keys[i]
+	a.nodes[i], a.nodes[j] = a.nodes[j], a.nodes[i]
+}
+
+// walkSearch calls `fn` with the terminal nodes matching to stype (SearchType)
+// in lexicographic order of the canonical keys (descending if o.desc) until
+// `fn` returns false. The CaseFold of the options is not applied.
+func (t *Trie) walkSearch(key string, stype SearchType, o *searchOptions, fn func(n *trieNode) bool) {
+	runes := t.runes(key)
+	switch stype {
+	case SearchExactly:
+		if n := findTerm(t.root, string(runes)); n != nil {
+			fn(n)
+		}
+	case SearchByPrefix:
+		if node := findNode(t.root, string(runes)); node != nil {
+			walkSorted(node, o.desc, fn)
+		}
+	case SearchLongestMatchingPrefix:
+		if terms := matchingprefixcollect(t.root, runes, false); len(terms) > 0 {
+			fn(terms[len(terms)-1])
+		}
+	case SearchMatcingPrefix:
+		// the prefixes of the key are in lexicographic order from the shortest.
+		terms := matchingprefixcollect(t.root, runes, false)
+		for i := range terms {
+			if o.desc {
+				i = len(terms) - 1 - i
+			}
+			if !fn(terms[i]) {
+				return
+			}
+		}
+	case SearchApproximate:
+		walkOrdered(t.root, 0, o.desc, func(c *trieNode, idx int) (int, bool) {
+			if idx == len(runes) {
+				return idx, true
+			}
+			if fuzzyPruned(c, runes[idx:]) {
+				return idx, false
+			}
+			if c.rval == runes[idx] {
+				idx++
+			}
+			return idx, true
+		}, func(idx int) bool {
+			return idx == len(runes)
+		}, fn)
+	case SearchSuffix:
+		m := maskruneslice(runes)
+		if (t.root.mask & m) != m {
+			return
+		}
+		walkSorted(t.root, o.desc, func(n *trieNode) bool {
+			return !hasSuffix(n, runes, false) || fn(n)
+		})
+	case SearchWildcard:
+		tokens := parseWildcard(string(runes))
+		walkOrdered(t.root, wildcardClosure(tokens, []int{0}), o.desc, func(c *trieNode, active []int) ([]int, bool) {
+			next := wildcardStep(tokens, active, c.rval, o.delim)
+			return next, len(next) > 0
+		}, func(active []int) bool {
+			return len(active) > 0 && active[len(active)-1] == len(tokens)
+		}, fn)
+	default:
+		k, _ := stype.distance()
+		row := make([]int, len(runes)+1)
+		for i := range row {
+			row[i] = i
+		}
+		walkOrdered(t.root, row, o.desc, func(c *trieNode, prev []int) ([]int, bool) {
+			cur := make([]int, len(prev))
+			cur[0] = prev[0] + 1
+			least := cur[0]
+			for i := 1; i < len(cur); i++ {
+				cost := 1
+				if runes[i-1] == c.rval {
+					cost = 0
+				}
+				cur[i] = min(cur[i-1]+1, prev[i]+1, prev[i-1]+cost)
+				least = min(least, cur[i])
+			}
+			return cur, least <= k
+		}, func(row []int) bool {
+			return row[len(row)-1] <= k
+		}, fn)
+	}
+}
+
+// walkOrdered calls `fn` with the terminal nodes under the node in lexicographic
+// order of the keys (descending if `desc` is true) until `fn` returns false.
+// Each node of the walk carries the state of the search: `next` returns the state
+// of the child `c` from the state of its parent, or false to prune the child,
+// and `match` reports whether the key of the node of the state is matched.
+func walkOrdered[S any](node *trieNode, state S, desc bool, next func(c *trieNode, s S) (S, bool), match func(s S) bool, fn func(n *trieNode) bool) {
+	type entry struct {
+		node  *trieNode
+		state S
+	}
+	entries := []entry{{node, state}}
+	for l := len(entries); l != 0; l = len(entries) {
+		e := entries[l-1]
+		entries = entries[:l-1]
+		if e.node.term {
+			// the terminal node carries the state of its parent.
+			if match(e.state) && !fn(e.node) {
+				return
+			}
+			continue
+		}
+		// push the children in the opposite order so that the first is popped first.
+		children := sortedChildren(e.node, !desc)
+		for _, c := range children {
+			if c.rval == nul {
+				entries = append(entries, entry{c, e.state})
+				continue
+			}
+			if s, ok := next(c, e.state); ok {
+				entries = append(entries, entry{c, s})
+			}
+		}
+	}
+}
+
+// wildcardStep returns the sorted indexes of the wildcard tokens active after
+// the rune `r` from the `active` ones, closed over the stars.
+func wildcardStep(tokens []wildcardToken, active []int, r rune, delim rune) []int {
+	var next []int
+	for _, idx := range active {
+		if idx == len(tokens) {
+			continue
+		}
+		switch tok := tokens[idx]; {
+		case tok.star:
+			if delim == nul || r != delim {
+				next = append(next, idx)
+			}
+		case tok.any:
+			if delim == nul || r != delim {
+				next = append(next, idx+1)
+			}
+		case tok.r == r:
+			next = append(next, idx+1)
+		}
+	}
+	return wildcardClosure(tokens, next)
+}
+
+// wildcardClosure adds the indexes following the stars of the `active`
+// indexes, which match the empty runes, and returns them sorted and unique.
+func wildcardClosure(tokens []wildcardToken, active []int) []int {
+	for i := 0; i < len(active); i++ {
+		if idx := active[i]; idx < len(tokens) && tokens[idx].star {
+			active = append(active, idx+1)
+		}
+	}
+	sort.Ints(active)
+	unique := active[:0]
+	for i, idx := range active {
+		if i == 0 || idx != active[i-1] {
+			unique = append(unique, idx)
+		}
+	}
+	return unique
+}
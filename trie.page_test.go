@@ -0,0 +1,129 @@
+package gtrie
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTrie_SearchPage(t *testing.T) {
+	searches := []struct {
+		key   string
+		stype SearchType
+		opts  []SearchOption
+	}{
+		{"/interfaces", SearchExactly, nil},
+		{"/interfaces/interface[", SearchByPrefix, nil},
+		{"/interfaces/interface[name=1/3]/state/x", SearchLongestMatchingPrefix, nil},
+		{"/interfaces/interface[name=1/3]/state/enabled", SearchMatcingPrefix, nil},
+		{"ied", SearchApproximate, nil},
+		{"", SearchApproximate, nil},
+		{"/interfaces/interface[name=1/2]/state", SearchAllRelativeKey, nil},
+		{"status", SearchSuffix, nil},
+		{"/interfaces/*/state/*", SearchWildcard, nil},
+		{"/interfaces/*/state/*", SearchWildcard, []SearchOption{SegmentDelim('/')}},
+		{"*e?", SearchWildcard, nil},
+		{"/interfaces/interface[name=1/3]/state", SearchWithinDistance(4), nil},
+		{"/interfaces/interface[", SearchByPrefix, []SearchOption{CaseFold()}},
+		{"/interfaces/interface[", SearchByPrefix, []SearchOption{OrderBy(ByLength)}},
+	}
+	for name, opts := range map[string][]Option{
+		"plain":  nil,
+		"sorted": {WithSortedChildren(), WithWideMask()},
+	} {
+		trie := New(opts...)
+		for _, key := range gnmiFixture {
+			trie.Add(key, key)
+		}
+		for _, s := range searches {
+			o := newSearchOptions(s.opts)
+			// the keys found without the options are sorted for the pages.
+			nodes, _ := trie.searchNodes(s.key, s.stype, o)
+			all := nodeKeys(nodes)
+			if o.order == ByLength {
+				sortNodes(nodes, ByLength, false)
+				all = nodeKeys(nodes)
+			} else {
+				slices.Sort(all)
+			}
+			if len(all) == 0 {
+				t.Fatalf("%s: %v(%q) finds no key", name, s.stype, s.key)
+			}
+			desc := slices.Clone(all)
+			slices.Reverse(desc)
+			for _, offset := range []int{0, 1, len(all) - 1, len(all), len(all) + 5} {
+				for _, max := range []int{0, 1, 3, len(all) + 10} {
+					for _, reverse := range []bool{false, true} {
+						want := all
+						popts := append(slices.Clone(s.opts), Offset(offset), MaxResults(max))
+						if reverse {
+							want = desc
+							popts = append(popts, Descending())
+						}
+						want = want[min(offset, len(want)):]
+						if max > 0 && len(want) > max {
+							want = want[:max]
+						}
+						got := trie.Search(s.key, s.stype, popts...)
+						// the keys are not sorted if not paged without an order.
+						unordered := offset == 0 && max == 0 && !reverse && o.order == Lexicographic
+						if unordered {
+							slices.Sort(got)
+						}
+						if len(got) != len(want) || (len(want) > 0 && !reflect.DeepEqual(got, want)) {
+							t.Errorf("%s: %v(%q) with Offset(%d), MaxResults(%d), Descending %v = %q, want %q",
+								name, s.stype, s.key, offset, max, reverse, got, want)
+						}
+						values := trie.SearchValues(s.key, s.stype, popts...)
+						for i := range values {
+							if unordered {
+								break
+							}
+							if i >= len(want) || values[i] != want[i] {
+								t.Errorf("%s: SearchValues() %v(%q) = %v, want %q", name, s.stype, s.key, values, want)
+								break
+							}
+						}
+						if m := trie.SearchAll(s.key, s.stype, popts...); len(m) != len(want) {
+							t.Errorf("%s: SearchAll() %v(%q) = %v, want %q", name, s.stype, s.key, m, want)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestTrie_SearchPageStops(t *testing.T) {
+	trie := New()
+	for _, key := range gnmiFixture {
+		trie.Add(key, key)
+	}
+	for _, stype := range []SearchType{SearchByPrefix, SearchApproximate, SearchSuffix, SearchWildcard, SearchWithinDistance(40)} {
+		key := map[SearchType]string{SearchByPrefix: "/", SearchApproximate: "/", SearchSuffix: "s", SearchWildcard: "*"}[stype]
+		visited := 0
+		trie.walkSearch(key, stype, &searchOptions{}, func(n *trieNode) bool {
+			visited++
+			return visited < 2
+		})
+		if visited != 2 {
+			t.Errorf("%v(%q) visits %d keys after the walk stopped at 2", stype, key, visited)
+		}
+	}
+	if got := trie.Search("/interfaces", SearchByPrefix, Offset(2), MaxResults(2)); !reflect.DeepEqual(got, []string{
+		"/interfaces/interface/state/counters",
+		"/interfaces/interface[name=1/1]/state/enabled",
+	}) {
+		t.Errorf("Search() of the second page = %q", got)
+	}
+	if got, err := trie.SearchWithOptions("/none", SearchByPrefix, Offset(1)); err != nil || len(got) != 0 {
+		t.Errorf("SearchWithOptions() of no key = %q, %v", got, err)
+	}
+	if _, err := trie.SearchWithOptions("/", SearchType(42), MaxResults(1)); err != ErrUnknownSearchType {
+		t.Errorf("SearchWithOptions() of the unknown type error = %v", err)
+	}
+	if got := trie.Search("/INTERFACES", SearchByPrefix, CaseFold(), Offset(1), MaxResults(1)); len(got) != 1 || !strings.HasPrefix(got[0], "/interfaces") {
+		t.Errorf("Search() with CaseFold = %q", got)
+	}
+}
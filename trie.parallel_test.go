@@ -0,0 +1,74 @@
+package gtrie
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestTrie_FindByPrefixAllParallel(t *testing.T) {
+	trie := New()
+	for i := 0; i < 20000; i++ {
+		trie.Add(fmt.Sprintf("/interfaces/interface[name=1/%d]/state", i), i)
+	}
+	trie.Add("/interfaces", -1)
+	trie.Add("/other", -2)
+	for _, prefix := range []string{"", "/interfaces", "/interfaces/interface[name=1/1", "/interfaces/interface[name=1/19999]"} {
+		want := trie.FindByPrefixAll(prefix)
+		for _, workers := range []int{0, 1, 2, 4, 7} {
+			if got := trie.FindByPrefixAllParallel(prefix, workers); !reflect.DeepEqual(got, want) {
+				t.Errorf("FindByPrefixAllParallel(%q, %d) = %d keys, want %d", prefix, workers, len(got), len(want))
+			}
+		}
+	}
+	if got := trie.FindByPrefixAllParallel("/none", 4); got != nil {
+		t.Errorf("FindByPrefixAllParallel() = %v, want nil", got)
+	}
+}
+
+func TestTrie_FindByPrefixAllParallelWithWriters(t *testing.T) {
+	trie := New()
+	for i := 0; i < 10000; i++ {
+		trie.Add("/a/"+strconv.Itoa(i), i)
+	}
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			trie.Add("/b/"+strconv.Itoa(i), i)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 10; i++ {
+			if got := trie.FindByPrefixAllParallel("/a/", 4); len(got) != 10000 {
+				t.Errorf("FindByPrefixAllParallel() = %d keys, want 10000", len(got))
+			}
+		}
+	}()
+	wg.Wait()
+}
+
+var (
+	parallelBenchOnce sync.Once
+	parallelBenchTrie *Trie
+)
+
+func BenchmarkFindByPrefixAllParallel(b *testing.B) {
+	parallelBenchOnce.Do(func() {
+		parallelBenchTrie = New()
+		for i := 0; i < 1000000; i++ {
+			parallelBenchTrie.Add("/subtree/"+strconv.Itoa(i), i)
+		}
+	})
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(strconv.Itoa(workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				parallelBenchTrie.FindByPrefixAllParallel("/subtree/", workers)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package gtrie
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrInvalidPathKey is returned by ParsePathKey if the key is not a gNMI path.
+var ErrInvalidPathKey = errors.New("gtrie: invalid path key")
+
+// PathElem is an element of a gNMI path, e.g. interface[name=eth0],
+// with the name and the key predicates by the key names.
+type PathElem struct {
+	Name string
+	// Keys is the values of the key predicates by the key names,
+	// or nil if the element has no predicate.
+	Keys map[string]string
+}
+
+// escapeWith returns `s` with '\' and the bytes of `special` escaped by '\'.
+func escapeWith(s, special string) string {
+	if !strings.ContainsAny(s, special) && strings.IndexByte(s, keyEscape) < 0 {
+		return s
+	}
+	var b strings.Builder
+	b.Grow(len(s) + 4)
+	for i := 0; i < len(s); i++ {
+		if s[i] == keyEscape || strings.IndexByte(special, s[i]) >= 0 {
+			b.WriteByte(keyEscape)
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// PathKey returns the key of the gNMI path of the `elems`, each following '/'
+// with its key predicates sorted by the key name, e.g. "/interfaces/interface[name=eth0]".
+// '\' escapes '/' and '[' in the element names, '=' and ']' in the key names,
+// ']' in the values and itself, so that ParsePathKey returns the `elems` for
+// any names and values. The key is canonical by NormalizeGNMIPath unless the
+// names or values have the leading or trailing whitespace.
+// PathKey() is "" and the element of the empty name is "/".
+func PathKey(elems ...PathElem) string {
+	var (
+		b    strings.Builder
+		keys []gnmiKey
+	)
+	for _, e := range elems {
+		b.WriteByte('/')
+		b.WriteString(escapeWith(e.Name, "/["))
+		keys = keys[:0]
+		for name, value := range e.Keys {
+			keys = append(keys, gnmiKey{name: escapeWith(name, "=]"), value: escapeWith(value, "]")})
+		}
+		// sorted by the escaped names as NormalizeGNMIPath.
+		sort.Slice(keys, func(i, j int) bool {
+			return keys[i].name < keys[j].name
+		})
+		for _, k := range keys {
+			b.WriteByte('[')
+			b.WriteString(k.name)
+			b.WriteByte('=')
+			b.WriteString(k.value)
+			b.WriteByte(']')
+		}
+	}
+	return b.String()
+}
+
+// ParsePathKey returns the elements of the gNMI path `key` built by PathKey
+// with the names and values unescaped. The key must start with '/' and
+// each predicate must have '='; the whitespace is kept as it is, but the
+// whitespace between and after the predicates. It returns nil for "" and
+// ErrInvalidPathKey for a malformed key, e.g. a predicate not terminated
+// by ']' or a key name repeated in an element.
+func ParsePathKey(key string) ([]PathElem, error) {
+	if key == "" {
+		return nil, nil
+	}
+	if key[0] != '/' {
+		return nil, fmt.Errorf("%w %q: no leading '/'", ErrInvalidPathKey, key)
+	}
+	var (
+		elems []PathElem
+		perr  error
+	)
+	err := scanGNMIPath(key, func(i int, name string, preds []string) {
+		if i == 0 {
+			// the empty element before the leading '/'.
+			return
+		}
+		e := PathElem{Name: UnescapeSegment(name)}
+		for _, pred := range preds {
+			k, v, found := cutPredicate(pred)
+			if !found {
+				perr = fmt.Errorf("%w %q: no '=' in [%s]", ErrInvalidPathKey, key, pred)
+				continue
+			}
+			if e.Keys == nil {
+				e.Keys = make(map[string]string, len(preds))
+			}
+			k = UnescapeSegment(k)
+			if _, ok := e.Keys[k]; ok {
+				perr = fmt.Errorf("%w %q: key %q repeated", ErrInvalidPathKey, key, k)
+			}
+			e.Keys[k] = UnescapeSegment(v)
+		}
+		elems = append(elems, e)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w %q: %v", ErrInvalidPathKey, key, err)
+	}
+	if perr != nil {
+		return nil, perr
+	}
+	return elems, nil
+}
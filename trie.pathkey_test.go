@@ -0,0 +1,87 @@
+package gtrie
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestPathKey(t *testing.T) {
+	tests := []struct {
+		elems []PathElem
+		want  string
+	}{
+		{nil, ""},
+		{[]PathElem{{}}, "/"},
+		{
+			[]PathElem{{Name: "interfaces"}, {Name: "interface", Keys: map[string]string{"name": "1/2"}}, {Name: "state"}},
+			"/interfaces/interface[name=1/2]/state",
+		},
+		{
+			[]PathElem{{Name: "protocol", Keys: map[string]string{"name": "bgp", "identifier": "BGP", "afi": "ipv4"}}},
+			"/protocol[afi=ipv4][identifier=BGP][name=bgp]",
+		},
+		{
+			[]PathElem{{Name: "neighbor", Keys: map[string]string{"id": `a]b\c`, "k=1": "x=y", "z]": "["}}},
+			`/neighbor[id=a\]b\\c][k\=1=x=y][z\]=[]`,
+		},
+		{[]PathElem{{Name: "a/b[c]"}, {Name: `\`}}, `/a\/b\[c]/\\`},
+		{[]PathElem{{Name: "a", Keys: map[string]string{}}, {}}, "/a/"},
+	}
+	for _, tt := range tests {
+		got := PathKey(tt.elems...)
+		if got != tt.want {
+			t.Errorf("PathKey(%v) = %q, want %q", tt.elems, got, tt.want)
+		}
+		elems, err := ParsePathKey(got)
+		if err != nil {
+			t.Errorf("ParsePathKey(%q) error = %v", got, err)
+			continue
+		}
+		for i := range tt.elems {
+			// no predicate is parsed into nil Keys.
+			if len(tt.elems[i].Keys) == 0 {
+				tt.elems[i].Keys = nil
+			}
+		}
+		if !reflect.DeepEqual(elems, tt.elems) {
+			t.Errorf("ParsePathKey(%q) = %v, want %v", got, elems, tt.elems)
+		}
+		// the keys without the whitespace around the names and values are canonical.
+		if n := NormalizeGNMIPath(got); n != got {
+			t.Errorf("NormalizeGNMIPath(%q) = %q", got, n)
+		}
+	}
+}
+
+func TestParsePathKey(t *testing.T) {
+	elems, err := ParsePathKey("/a[y=2] [x=1] /b[k= v ]")
+	want := []PathElem{{Name: "a", Keys: map[string]string{"x": "1", "y": "2"}}, {Name: "b", Keys: map[string]string{"k": " v "}}}
+	if err != nil || !reflect.DeepEqual(elems, want) {
+		t.Errorf("ParsePathKey() = %v, %v, want %v", elems, err, want)
+	}
+	for _, key := range []string{
+		"a/b",
+		"/a[k=1",
+		"/a[k]",
+		"/a[k=1]b",
+		"/a[k=1][k=2]",
+		`/a[k=1\]`,
+	} {
+		if elems, err := ParsePathKey(key); !errors.Is(err, ErrInvalidPathKey) {
+			t.Errorf("ParsePathKey(%q) = %v, %v, want %v", key, elems, err, ErrInvalidPathKey)
+		}
+	}
+}
+
+func TestTrie_PathKey(t *testing.T) {
+	trie := New(WithGNMIPathNormalization())
+	key := PathKey(PathElem{Name: "interfaces"}, PathElem{Name: "interface", Keys: map[string]string{"name": "eth0]1", "unit": "0"}})
+	trie.Add(key, 1)
+	if v, ok := trie.Find(`/interfaces/interface[ unit=0 ][name=eth0\]1]`); !ok || v != 1 {
+		t.Errorf("Find() = %v, %v, want 1", v, ok)
+	}
+	if keys := trie.FindByPrefix("/interfaces"); len(keys) != 1 || keys[0] != key {
+		t.Errorf("FindByPrefix() = %v, want [%s]", keys, key)
+	}
+}
@@ -0,0 +1,389 @@
+package gtrie
+
+import "unicode"
+
+// FindBySuffix performs a suffix search against the keys in the trie.
+// It returns all the keys ending with `suffix` in the trie.
+// With a key transform, the canonical keys end with the canonical `suffix`.
+func (t *Trie) FindBySuffix(suffix string) []string {
+	if t == nil {
+		return nil
+	}
+	t.rlock()
+	defer t.runlock()
+	return nodeKeys(suffixcollect(t.root, t.runes(suffix), false))
+}
+
+// FindBySuffixValue returns all the values that have a key ending with `suffix`.
+func (t *Trie) FindBySuffixValue(suffix string) []interface{} {
+	if t == nil {
+		return nil
+	}
+	t.rlock()
+	defer t.runlock()
+	return nodeValues(suffixcollect(t.root, t.runes(suffix), false))
+}
+
+// FindBySuffixAll returns all the keys and values ending with `suffix`.
+func (t *Trie) FindBySuffixAll(suffix string) map[string]interface{} {
+	if t == nil {
+		return nil
+	}
+	t.rlock()
+	defer t.runlock()
+	return nodeMap(suffixcollect(t.root, t.runes(suffix), false))
+}
+
+// FindByWildcard returns all the keys matching to the wildcard `pattern`.
+// '*' matches any sequence of runes (including the empty one) and
+// '?' matches exactly one rune. A backslash escapes the next rune
+// so that '\*' and '\?' match themselves. With a key transform,
+// the canonical keys are matched to the canonical `pattern`.
+func (t *Trie) FindByWildcard(pattern string) []string {
+	if t == nil {
+		return nil
+	}
+	t.rlock()
+	defer t.runlock()
+	return nodeKeys(wildcardcollect(t.root, parseWildcard(t.canonical(pattern)), nul, false))
+}
+
+// FindByWildcardValue returns all the values of the keys matching to the wildcard `pattern`.
+func (t *Trie) FindByWildcardValue(pattern string) []interface{} {
+	if t == nil {
+		return nil
+	}
+	t.rlock()
+	defer t.runlock()
+	return nodeValues(wildcardcollect(t.root, parseWildcard(t.canonical(pattern)), nul, false))
+}
+
+// FindByWildcardAll returns all the keys and values matching to the wildcard `pattern`.
+func (t *Trie) FindByWildcardAll(pattern string) map[string]interface{} {
+	if t == nil {
+		return nil
+	}
+	t.rlock()
+	defer t.runlock()
+	return nodeMap(wildcardcollect(t.root, parseWildcard(t.canonical(pattern)), nul, false))
+}
+
+// FindWithinDistance returns all the keys whose Levenshtein (edit) distance
+// to the input `key` is `k` or less.
+func (t *Trie) FindWithinDistance(key string, k int) []string {
+	if t == nil {
+		return nil
+	}
+	t.rlock()
+	defer t.runlock()
+	return nodeKeys(distancecollect(t.root, t.runes(key), k, false))
+}
+
+// FindWithinDistanceValue returns all the values of the keys
+// within the edit distance `k` from the input `key`.
+func (t *Trie) FindWithinDistanceValue(key string, k int) []interface{} {
+	if t == nil {
+		return nil
+	}
+	t.rlock()
+	defer t.runlock()
+	return nodeValues(distancecollect(t.root, t.runes(key), k, false))
+}
+
+// FindWithinDistanceAll returns all the keys and values
+// within the edit distance `k` from the input `key`.
+func (t *Trie) FindWithinDistanceAll(key string, k int) map[string]interface{} {
+	if t == nil {
+		return nil
+	}
+	t.rlock()
+	defer t.runlock()
+	return nodeMap(distancecollect(t.root, t.runes(key), k, false))
+}
+
+// runeEqual compares two runes exactly or,
+// if fold is true, under Unicode simple case folding.
+func runeEqual(a, b rune, fold bool) bool {
+	if a == b {
+		return true
+	}
+	if !fold {
+		return false
+	}
+	for f := unicode.SimpleFold(a); f != a; f = unicode.SimpleFold(f) {
+		if f == b {
+			return true
+		}
+	}
+	return false
+}
+
+// nodeKeys returns the keys of the terminal nodes.
+func nodeKeys(nodes []*trieNode) []string {
+	keys := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		keys = append(keys, n.key())
+	}
+	return keys
+}
+
+// nodeValues returns the values of the terminal nodes.
+func nodeValues(nodes []*trieNode) []interface{} {
+	values := make([]interface{}, 0, len(nodes))
+	for _, n := range nodes {
+		values = append(values, n.value)
+	}
+	return values
+}
+
+// nodeMap returns the keys and values of the terminal nodes.
+func nodeMap(nodes []*trieNode) map[string]interface{} {
+	m := make(map[string]interface{}, len(nodes))
+	for _, n := range nodes {
+		m[n.key()] = n.value
+	}
+	return m
+}
+
+// collectNodes returns all the terminal nodes under the node.
+func collectNodes(node *trieNode) []*trieNode {
+	return collectNodesCtx(node, nil)
+}
+
+// collectNodesCtx returns all the terminal nodes under the node.
+// The traversal stops with the partial result if the canceler `c` is canceled.
+func collectNodesCtx(node *trieNode, c *canceler) []*trieNode {
+	var (
+		n *trieNode
+		i int
+	)
+	terms := make([]*trieNode, 0, node.termCount)
+	nodes := make([]*trieNode, 1, len(node.children)+1)
+	nodes[0] = node
+	for l := len(nodes); l != 0; l = len(nodes) {
+		if c.canceled() {
+			break
+		}
+		i = l - 1
+		n = nodes[i]
+		nodes = nodes[:i]
+		for _, child := range n.children {
+			nodes = append(nodes, child)
+		}
+		if n.term {
+			terms = append(terms, n)
+		}
+	}
+	return terms
+}
+
+// findNodes finds all the nodes reachable from the node by `runes`.
+// Unlike findNode, more than one node can be found if fold is true.
+func findNodes(node *trieNode, runes []rune, fold bool) []*trieNode {
+	if !fold {
+		if n := findNode(node, string(runes)); n != nil {
+			return []*trieNode{n}
+		}
+		return nil
+	}
+	if node == nil {
+		return nil
+	}
+	nodes := []*trieNode{node}
+	for _, r := range runes {
+		next := make([]*trieNode, 0, len(nodes))
+		for _, n := range nodes {
+			for cr, c := range n.children {
+				if cr != nul && runeEqual(cr, r, true) {
+					next = append(next, c)
+				}
+			}
+		}
+		if len(next) == 0 {
+			return nil
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+// suffixcollect returns all the terminal nodes whose canonical key ends with `suffix`.
+func suffixcollect(node *trieNode, suffix []rune, fold bool) []*trieNode {
+	if node == nil {
+		return nil
+	}
+	if !fold {
+		m := maskruneslice(suffix)
+		if (node.mask & m) != m {
+			return nil
+		}
+	}
+	terms := collectNodes(node)
+	found := terms[:0]
+	for _, n := range terms {
+		if hasSuffix(n, suffix, fold) {
+			found = append(found, n)
+		}
+	}
+	return found
+}
+
+// hasSuffix reports whether the canonical key of the terminal node `n`,
+// i.e. the runes of its path from the root, ends with `suffix`.
+func hasSuffix(n *trieNode, suffix []rune, fold bool) bool {
+	if len(suffix) > n.depth-1 {
+		return false
+	}
+	for i, p := len(suffix)-1, n.parent; i >= 0; i, p = i-1, p.parent {
+		if !runeEqual(p.rval, suffix[i], fold) {
+			return false
+		}
+	}
+	return true
+}
+
+// wildcardToken is an element of a parsed wildcard pattern.
+type wildcardToken struct {
+	r    rune
+	any  bool // '?'
+	star bool // '*'
+}
+
+// parseWildcard parses the wildcard `pattern` into tokens.
+func parseWildcard(pattern string) []wildcardToken {
+	runes := []rune(pattern)
+	tokens := make([]wildcardToken, 0, len(runes))
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; r {
+		case '\\':
+			if i+1 < len(runes) {
+				i++
+				tokens = append(tokens, wildcardToken{r: runes[i]})
+			} else {
+				tokens = append(tokens, wildcardToken{r: r})
+			}
+		case '*':
+			// collapse the consecutive stars.
+			if l := len(tokens); l > 0 && tokens[l-1].star {
+				continue
+			}
+			tokens = append(tokens, wildcardToken{star: true})
+		case '?':
+			tokens = append(tokens, wildcardToken{any: true})
+		default:
+			tokens = append(tokens, wildcardToken{r: r})
+		}
+	}
+	return tokens
+}
+
+type wildcardState struct {
+	idx  int
+	node *trieNode
+}
+
+// wildcardcollect returns all the terminal nodes matching to the wildcard tokens.
+// If `delim` is not nul, '*' and '?' never match the `delim` rune.
+func wildcardcollect(node *trieNode, tokens []wildcardToken, delim rune, fold bool) []*trieNode {
+	if node == nil {
+		return nil
+	}
+	var (
+		i     int
+		s     wildcardState
+		terms []*trieNode
+	)
+	visited := make(map[wildcardState]struct{})
+	states := []wildcardState{wildcardState{node: node, idx: 0}}
+	for l := len(states); l > 0; l = len(states) {
+		i = l - 1
+		s = states[i]
+		states = states[:i]
+		if _, ok := visited[s]; ok {
+			continue
+		}
+		visited[s] = struct{}{}
+		if s.idx == len(tokens) {
+			if c, ok := s.node.children[nul]; ok && c.term {
+				terms = append(terms, c)
+			}
+			continue
+		}
+		tok := tokens[s.idx]
+		if tok.star {
+			states = append(states, wildcardState{node: s.node, idx: s.idx + 1})
+		}
+		for r, c := range s.node.children {
+			if r == nul {
+				continue
+			}
+			switch {
+			case tok.star:
+				if delim == nul || r != delim {
+					states = append(states, wildcardState{node: c, idx: s.idx})
+				}
+			case tok.any:
+				if delim == nul || r != delim {
+					states = append(states, wildcardState{node: c, idx: s.idx + 1})
+				}
+			default:
+				if runeEqual(r, tok.r, fold) {
+					states = append(states, wildcardState{node: c, idx: s.idx + 1})
+				}
+			}
+		}
+	}
+	return terms
+}
+
+// distancecollect returns all the terminal nodes whose key is within
+// the Levenshtein distance `k` from `key`. Each node on the walk holds
+// a row of the edit distance matrix and the subtree is pruned as soon as
+// no entry of the row is within `k`.
+func distancecollect(node *trieNode, key []rune, k int, fold bool) []*trieNode {
+	if node == nil || k < 0 {
+		return nil
+	}
+	var terms []*trieNode
+	row := make([]int, len(key)+1)
+	for i := range row {
+		row[i] = i
+	}
+	if c, ok := node.children[nul]; ok && c.term && row[len(key)] <= k {
+		terms = append(terms, c)
+	}
+	var walk func(n *trieNode, prev []int)
+	walk = func(n *trieNode, prev []int) {
+		cur := make([]int, len(prev))
+		cur[0] = prev[0] + 1
+		least := cur[0]
+		for i := 1; i < len(cur); i++ {
+			cost := 1
+			if runeEqual(key[i-1], n.rval, fold) {
+				cost = 0
+			}
+			cur[i] = min(cur[i-1]+1, prev[i]+1, prev[i-1]+cost)
+			if cur[i] < least {
+				least = cur[i]
+			}
+		}
+		if least > k {
+			return
+		}
+		for r, c := range n.children {
+			if r == nul {
+				if c.term && cur[len(key)] <= k {
+					terms = append(terms, c)
+				}
+				continue
+			}
+			walk(c, cur)
+		}
+	}
+	for r, c := range node.children {
+		if r != nul {
+			walk(c, row)
+		}
+	}
+	return terms
+}
@@ -0,0 +1,169 @@
+package gtrie
+
+import (
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// HasPrefixes returns whether any key starts with each of the `prefixes`
+// like HasPrefix for each of them. The prefixes are sorted and walked in one
+// pass under the read lock, so that the path shared by a prefix and the one
+// before is walked once, e.g. for the many prefixes under "/interfaces/".
+func (t *Trie) HasPrefixes(prefixes []string) map[string]bool {
+	if t == nil {
+		return make(map[string]bool)
+	}
+	result := make(map[string]bool, len(prefixes))
+	if len(prefixes) == 0 {
+		return result
+	}
+	ckeys := make([]string, len(prefixes))
+	order := make([]int, len(prefixes))
+	for i, prefix := range prefixes {
+		ckeys[i] = t.canonical(prefix)
+		order[i] = i
+	}
+	slices.SortFunc(order, func(i, j int) int { return strings.Compare(ckeys[i], ckeys[j]) })
+
+	root := t.readRoot()
+	defer t.readDone()
+	// path is the nodes walked by the prefix before; path[i] is reached
+	// at the byte offset `end` of the key.
+	type step struct {
+		node *trieNode
+		end  int
+		// exact is false for an invalid byte, whose rune depends on the bytes after it.
+		exact bool
+	}
+	path := []step{{node: root, exact: true}}
+	prev := ""
+	for _, i := range order {
+		key := ckeys[i]
+		common := 0
+		for common < len(key) && common < len(prev) && key[common] == prev[common] {
+			common++
+		}
+		for len(path) > 1 {
+			s := path[len(path)-1]
+			if s.end <= common && (s.exact || s.end+utf8.UTFMax-1 <= common) {
+				break
+			}
+			path = path[:len(path)-1]
+		}
+		s := path[len(path)-1]
+		node, pos := s.node, s.end
+		for node != nil && pos < len(key) {
+			r, size := rune(key[pos]), 1
+			if r >= utf8.RuneSelf {
+				r, size = utf8.DecodeRuneInString(key[pos:])
+			}
+			pos += size
+			node = node.children[r]
+			if node != nil {
+				path = append(path, step{node: node, end: pos, exact: r != utf8.RuneError || size > 1})
+			}
+		}
+		result[prefixes[i]] = node != nil
+		prev = key
+	}
+	return result
+}
+
+// FindByPrefixesAll finds all the keys starting with any of the `prefixes`
+// and their values under one read lock. The prefixes covered by another
+// (e.g. "/a/b" by "/a/") are dropped first, so that every subtree is walked
+// once and no key is found twice, unlike merging FindByPrefixAll per prefix.
+func (t *Trie) FindByPrefixesAll(prefixes []string) map[string]interface{} {
+	if t == nil {
+		return nil
+	}
+	root := t.readRoot()
+	defer t.readDone()
+	m := make(map[string]interface{})
+	for _, node := range prefixNodes(root, t.coveringPrefixes(prefixes)) {
+		walkTerms(node, func(n *trieNode) {
+			m[n.key()] = n.value
+		})
+	}
+	return m
+}
+
+// FindByPrefixes finds all the keys starting with any of the `prefixes`
+// like FindByPrefixesAll. Every key is returned once.
+func (t *Trie) FindByPrefixes(prefixes []string) []string {
+	if t == nil {
+		return nil
+	}
+	root := t.readRoot()
+	defer t.readDone()
+	nodes := prefixNodes(root, t.coveringPrefixes(prefixes))
+	count := 0
+	for _, node := range nodes {
+		count += node.termCount
+	}
+	keys := make([]string, 0, count)
+	for _, node := range nodes {
+		keys = appendKeys(keys, node)
+	}
+	return keys
+}
+
+// coveringPrefixes returns the canonical forms of the `prefixes` in order
+// without the prefixes duplicated or covered by another.
+func (t *Trie) coveringPrefixes(prefixes []string) []string {
+	ckeys := make([]string, len(prefixes))
+	for i, prefix := range prefixes {
+		ckeys[i] = t.canonical(prefix)
+	}
+	slices.Sort(ckeys)
+	// stack is the prefixes kept that are a prefix of the last one in bytes;
+	// a prefix covering another sorts before it.
+	var (
+		kept  []string
+		stack []string
+	)
+	for _, key := range ckeys {
+		for len(stack) > 0 && !strings.HasPrefix(key, stack[len(stack)-1]) {
+			stack = stack[:len(stack)-1]
+		}
+		covered := false
+		for _, s := range stack {
+			if runePrefix(key, s) {
+				covered = true
+				break
+			}
+		}
+		if covered {
+			continue
+		}
+		kept = append(kept, key)
+		stack = append(stack, key)
+	}
+	return kept
+}
+
+// runePrefix reports whether the runes of `prefix` are a prefix of the runes
+// of `key` as they are walked in the trie. A prefix in bytes ending with
+// an invalid byte may not be, e.g. "\xe2\x82" of "€".
+func runePrefix(key, prefix string) bool {
+	if !strings.HasPrefix(key, prefix) {
+		return false
+	}
+	if utf8.ValidString(prefix) {
+		return true
+	}
+	runes, krunes := []rune(prefix), []rune(key)
+	return len(runes) <= len(krunes) && slices.Equal(runes, krunes[:len(runes)])
+}
+
+// prefixNodes returns the nodes of the canonical `prefixes` found under the root.
+func prefixNodes(root *trieNode, prefixes []string) []*trieNode {
+	nodes := make([]*trieNode, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		if node := findNode(root, prefix); node != nil {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
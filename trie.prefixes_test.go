@@ -0,0 +1,105 @@
+package gtrie
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTrie_HasPrefixes(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithKeyTransform(strings.ToLower)}} {
+		trie := New(opts...)
+		for _, key := range append(gnmiFixture, "/ünïcode/ké", "/bad\xe2\x82/x") {
+			trie.Add(key, true)
+		}
+		prefixes := []string{
+			"", "/", "/interfaces", "/interfaces/", "/interfaces/interface[name=1/2]/state/enabled",
+			"/interfaces/interface[name=1/2]/state/enabledx", "/interfaces/interface[name=1/4]",
+			"/interfaces/interface[name=1/3]/state/counters", "/Interfaces/interface[name=1/1]",
+			"/interfaces/interface[name=1/1]/state/", "/interfaces/interface/", "/x", "/ü", "/ünïcode/k",
+			"/ünïcode/kë", "/ünïcode/ké", "/bad\xe2\x82", "/bad\xe2\x82\xac", "/bad\xe2", "/bad\xe2\x82/x",
+			"/interfaces", // duplicated
+		}
+		got := trie.HasPrefixes(prefixes)
+		if len(got) != len(prefixes)-1 {
+			t.Errorf("HasPrefixes() returns %d prefixes, want %d", len(got), len(prefixes)-1)
+		}
+		for _, prefix := range prefixes {
+			if want := trie.HasPrefix(prefix); got[prefix] != want {
+				t.Errorf("HasPrefixes()[%q] = %v, want %v", prefix, got[prefix], want)
+			}
+		}
+	}
+	if got := New().HasPrefixes(nil); len(got) != 0 {
+		t.Errorf("HasPrefixes(nil) = %v", got)
+	}
+}
+
+func BenchmarkHasPrefixes(b *testing.B) {
+	const neighbor = "/network-instances/network-instance[name=default]/protocols/protocol[identifier=BGP][name=bgp]/bgp/neighbors/neighbor[neighbor-address=10.0.%d.%d]"
+	trie := New()
+	for i := 0; i < 2000; i++ {
+		trie.Add(fmt.Sprintf(neighbor+"/afi-safis/afi-safi[afi-safi-name=IPV4_UNICAST]/state/prefixes/received", i/250, i%250), i)
+	}
+	prefixes := make([]string, 200)
+	for i := range prefixes {
+		// the prefixes share the long paths, most of which exist.
+		prefixes[i] = fmt.Sprintf(neighbor+"/afi-safis", i*7/250, i*7%250)
+	}
+	b.Run("loop", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			m := make(map[string]bool, len(prefixes))
+			for _, prefix := range prefixes {
+				m[prefix] = trie.HasPrefix(prefix)
+			}
+		}
+	})
+	b.Run("batch", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			trie.HasPrefixes(prefixes)
+		}
+	})
+}
+
+func TestTrie_FindByPrefixes(t *testing.T) {
+	trie := New()
+	for i, key := range append(gnmiFixture, "/€/x", "/\xe2\x82/y") {
+		trie.Add(key, i)
+	}
+	tests := []struct {
+		name     string
+		prefixes []string
+	}{
+		{"nested", []string{"/interfaces/interface[name=1/2]/state/", "/interfaces/interface[name=1/2]", "/interfaces/interface[name=1/3]/state/counters"}},
+		{"duplicated", []string{"/interfaces/interface[name=1/3]", "/interfaces/interface[name=1/3]", "/interfaces/interface/"}},
+		{"not found", []string{"/none", "/interfaces/interface[name=1/1]", "/interfaces/interface[name=1/4]"}},
+		{"all", []string{"/interfaces/interface[name=1/2]", ""}},
+		{"invalid byte", []string{"/\xe2\x82", "/€"}},
+		{"none", nil},
+	}
+	for _, tt := range tests {
+		want := map[string]interface{}{}
+		for _, prefix := range tt.prefixes {
+			for key, v := range trie.FindByPrefixAll(prefix) {
+				want[key] = v
+			}
+		}
+		if got := trie.FindByPrefixesAll(tt.prefixes); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: FindByPrefixesAll(%q) = %v, want %v", tt.name, tt.prefixes, got, want)
+		}
+		keys := trie.FindByPrefixes(tt.prefixes)
+		if len(keys) != len(want) {
+			t.Errorf("%s: FindByPrefixes(%q) = %d keys, want %d keys once each", tt.name, tt.prefixes, len(keys), len(want))
+		}
+		for _, key := range keys {
+			if _, ok := want[key]; !ok {
+				t.Errorf("%s: FindByPrefixes(%q) has %q", tt.name, tt.prefixes, key)
+			}
+		}
+	}
+	got := trie.coveringPrefixes([]string{"/a/b", "/a/", "/a/", "/b", "/a/c/d", "/ab", "/\xe2\x82", "/€", "/\xe2"})
+	if want := []string{"/a/", "/ab", "/b", "/\xe2", "/€"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("coveringPrefixes() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,60 @@
+package gtrie
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestTrie_FindLongestMatchingPrefixInfo(t *testing.T) {
+	tests := []struct {
+		key  string
+		want PrefixInfo
+		ok   bool
+	}{
+		{"/interfaces/interface[name=1/3]/state/absss", PrefixInfo{Key: "/interfaces/interface[name=1/3]/state", Value: true, RuneLen: 37, ByteLen: 37}, true},
+		{"/interfaces", PrefixInfo{Key: "/interfaces", Value: true, RuneLen: 11, ByteLen: 11, Exact: true}, true},
+		{"/interfaces/", PrefixInfo{Key: "/interfaces", Value: true, RuneLen: 11, ByteLen: 11}, true},
+		{"/x", PrefixInfo{}, false},
+		{"", PrefixInfo{}, false},
+	}
+	for _, opts := range [][]Option{nil, {WithAtomicReads()}} {
+		trie := New(opts...)
+		for _, key := range gnmiFixture {
+			trie.Add(key, true)
+		}
+		for _, tt := range tests {
+			got, ok := trie.FindLongestMatchingPrefixInfo(tt.key)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("FindLongestMatchingPrefixInfo(%q) = %+v, %v, want %+v, %v", tt.key, got, ok, tt.want, tt.ok)
+			}
+		}
+	}
+
+	trie := New(WithKeyTransform(strings.ToLower))
+	trie.Add("/Ünï", 1)
+	trie.MovePrefix("/ü", "/ab")
+	got, ok := trie.FindLongestMatchingPrefixInfo("/ABNÏ")
+	if want := (PrefixInfo{Key: "/abnï", Value: 1, RuneLen: 5, ByteLen: 6, Exact: true}); !ok || got != want {
+		t.Errorf("FindLongestMatchingPrefixInfo() of the key moved = %+v, want %+v", got, want)
+	}
+}
+
+func BenchmarkFindLongestMatchingPrefixInfo(b *testing.B) {
+	trie := New()
+	for i := 0; i < 100000; i++ {
+		trie.Add("/sub/"+strconv.Itoa(i), i)
+	}
+	b.Run("FindLongestMatchingPrefix", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _, _ = trie.FindLongestMatchingPrefix("/sub/12345/state")
+		}
+	})
+	b.Run("Info", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = trie.FindLongestMatchingPrefixInfo("/sub/12345/state")
+		}
+	})
+}
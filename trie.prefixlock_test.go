@@ -0,0 +1,204 @@
+package gtrie
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestTrie_WithPrefixLocks(t *testing.T) {
+	ops := []struct {
+		key    string
+		remove bool
+	}{
+		{"/interfaces/interface[name=1/1]", false},
+		{"/interfaces/interface[name=1/2]", false},
+		{"/interfaces", false},
+		{"/qos/queue", false},
+		{"/qos/queue/zz", false},
+		{"", false},
+		{"/", false},
+		{"a", false},
+		{"ab", false},
+		{"/interfaces/interface[name=1/2]", true},
+		{"/qos/queue/zz", true},
+		{"/interfaces/interface[name=1/2]", false},
+		{"/qos/queue", true},
+		{"/none", true},
+		{"ab", true},
+		{"a", true},
+		{"/", true},
+		{"/interfaces/interface[name=1/1]", true},
+	}
+	for name, opts := range map[string][]Option{
+		"plain":      {WithPrefixLocks('/')},
+		"sorted":     {WithPrefixLocks('/'), WithSortedChildren()},
+		"timestamps": {WithPrefixLocks('/'), WithTimestamps()},
+		"atomic":     {WithPrefixLocks('/'), WithAtomicReads()},
+	} {
+		trie, want := New(opts...), New()
+		for i, op := range ops {
+			if op.remove {
+				if got, w := trie.Remove(op.key), want.Remove(op.key); got != w {
+					t.Errorf("%s: Remove(%q) = %v, want %v", name, op.key, got, w)
+				}
+			} else {
+				trie.Add(op.key, i)
+				want.Add(op.key, i)
+			}
+			if got, w := trie.FindByPrefixAll(""), want.FindByPrefixAll(""); !reflect.DeepEqual(got, w) {
+				t.Errorf("%s: FindByPrefixAll() after %+v = %v, want %v", name, op, got, w)
+			}
+			for _, key := range []string{op.key, "/interfaces", "/qos/queue"} {
+				got, gok := trie.Find(key)
+				w, wok := want.Find(key)
+				if got != w || gok != wok {
+					t.Errorf("%s: Find(%q) after %+v = %v, %v, want %v, %v", name, key, op, got, gok, w, wok)
+				}
+			}
+			if trie.Size() != want.Size() {
+				t.Errorf("%s: Size() after %+v = %d, want %d", name, op, trie.Size(), want.Size())
+			}
+			// the masks of the shared nodes are fixed by the read lock of the trie.
+			trie.Keys()
+			checkNodes(t, trie.root, name != "atomic")
+		}
+		// the runes removed from the partitions are pruned by the masks.
+		if got := trie.FindByFuzzy("qz"); len(got) != 0 {
+			t.Errorf("%s: FindByFuzzy() = %v, want none", name, got)
+		}
+	}
+}
+
+func TestTrie_WithPrefixLocks_Concurrent(t *testing.T) {
+	const (
+		writers = 8
+		ops     = 300
+	)
+	trie := New(WithPrefixLocks('/'))
+	gens := &generations{}
+	var (
+		wg, writing sync.WaitGroup
+		done        atomic.Bool
+		errs        = make(chan error, writers)
+	)
+	for w := 0; w < writers; w++ {
+		writing.Add(1)
+		go func(w int) {
+			defer writing.Done()
+			for i := 0; i < ops; i++ {
+				key := fmt.Sprintf("/%c/%d", 'a'+w, i%16)
+				floor := gens.floor(key)
+				if i%3 == 2 {
+					if v := trie.Remove(key); v != nil {
+						floor.Store(v.(int64))
+					}
+				} else {
+					gen := gens.issue(key)
+					trie.Add(key, gen)
+					floor.Store(gen - 1)
+				}
+				// the partitions of a single key added and removed.
+				trie.Add(fmt.Sprintf("/%c", 'p'+w), w)
+				trie.Remove(fmt.Sprintf("/%c", 'p'+w))
+			}
+		}(w)
+	}
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; !done.Load(); i++ {
+			key := fmt.Sprintf("/%c/%d", 'a'+i%writers, i%16)
+			floor := gens.floor(key).Load()
+			if v, ok := trie.Find(key); ok {
+				if err := gens.check(key, v, floor); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; !done.Load(); i++ {
+			for k, v := range trie.FindByPrefixAll("/") {
+				if _, ok := v.(int64); ok {
+					if err := gens.check(k, v, 0); err != nil {
+						errs <- err
+						return
+					}
+				}
+			}
+			trie.FindByFuzzy("a1")
+			trie.Add("/", i)
+			trie.Remove("")
+		}
+	}()
+	writing.Wait()
+	done.Store(true)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+	if got := len(trie.Keys()); got != trie.Size() {
+		t.Errorf("Keys() = %d keys, want Size() %d", got, trie.Size())
+	}
+	checkNodes(t, trie.root, true)
+}
+
+// BenchmarkPrefixLocks adds and removes the keys under 8 first-level prefixes
+// by the parallel writers, each of which writes under one of the prefixes.
+func BenchmarkPrefixLocks(b *testing.B) {
+	for name, opts := range map[string][]Option{"global": nil, "prefix": {WithPrefixLocks('/')}} {
+		b.Run(name, func(b *testing.B) {
+			trie := New(opts...)
+			prefixes := []string{"/interfaces", "/qos", "/system", "/routing", "/lldp", "/acl", "/bgp", "/network"}
+			for _, prefix := range prefixes {
+				trie.Add(prefix, true)
+			}
+			var next atomic.Int64
+			b.ReportAllocs()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				prefix := prefixes[next.Add(1)%int64(len(prefixes))]
+				keys := make([]string, 64)
+				for i := range keys {
+					keys[i] = fmt.Sprintf("%s/item[id=%d]/state", prefix, i)
+				}
+				for i := 0; pb.Next(); i++ {
+					key := keys[i%len(keys)]
+					if i/len(keys)%2 == 0 {
+						trie.Add(key, i)
+					} else {
+						trie.Remove(key)
+					}
+				}
+			})
+		})
+	}
+}
+
+func TestTrie_WithPrefixLocks_Both(t *testing.T) {
+	a, b := New(WithPrefixLocks('/')), New(WithPrefixLocks('/'))
+	for _, key := range []string{"/a/1", "/a/2", "/b/1"} {
+		a.Add(key, key)
+		b.Add(key+"/x", key)
+	}
+	// the read locks of the partitions of both tries are released.
+	a.Merge(b)
+	a.OverlapsPrefix(b, 0)
+	a.OverlapsPrefix(a, 0)
+	a.Intersect(b)
+	for _, trie := range []*Trie{a, b} {
+		trie.Add("/a/3", true)
+		if !trie.TryAdd("/c", true, 0) {
+			t.Errorf("TryAdd() after Merge = false")
+		}
+	}
+	if a.Size() != 8 {
+		t.Errorf("Size() = %d, want 8", a.Size())
+	}
+}
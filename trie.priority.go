@@ -0,0 +1,53 @@
+package gtrie
+
+// AddWithPriority adds the key and value like Add with the priority `prio`
+// used by FindBestMatchingPrefix. The keys added by Add have the priority 0,
+// and Add of a key added with a priority resets it to 0. The priorities are
+// kept in a map by the terminal nodes created on the first use, so that
+// the trie without them does not grow.
+func (t *Trie) AddWithPriority(key string, v interface{}, prio int) {
+	if t == nil {
+		return
+	}
+	t.lock()
+	changed, err := t.addChanged(key, v)
+	if err == nil && prio != 0 {
+		if t.prios == nil {
+			t.prios = make(map[*trieNode]int)
+		}
+		t.prios[findTerm(t.root, t.canonical(key))] = prio
+	} else if err == nil && !changed && t.prios != nil {
+		// the node is kept for the value unchanged (WithSkipUnchanged).
+		delete(t.prios, findTerm(t.root, t.canonical(key)))
+	}
+	t.unlock()
+	if err != nil {
+		t.reject(key, err)
+	}
+}
+
+// FindBestMatchingPrefix finds the key having the highest priority of
+// AddWithPriority among the keys that are prefixes of the input `key`,
+// e.g. the route of the least administrative distance. The ties are broken
+// by the length of the keys, the longest first like FindLongestMatchingPrefix.
+// Like FindMatchingPrefix, the empty key is not matched.
+func (t *Trie) FindBestMatchingPrefix(key string) (string, interface{}, bool) {
+	if t == nil {
+		return "", nil, false
+	}
+	// the read lock is taken even in the atomic read mode for the map of the priorities.
+	t.rlock()
+	defer t.runlock()
+	nodes, ok := t.findPrefixMatchNodes(key)
+	if !ok {
+		return "", nil, false
+	}
+	// the nodes are in the order of the length; the later wins the tie.
+	best := nodes[0]
+	for _, n := range nodes[1:] {
+		if t.prios[n] >= t.prios[best] {
+			best = n
+		}
+	}
+	return best.key(), best.value, true
+}
@@ -0,0 +1,74 @@
+package gtrie
+
+import "testing"
+
+func TestTrie_FindBestMatchingPrefix(t *testing.T) {
+	for name, opts := range map[string][]Option{
+		"plain":  nil,
+		"atomic": {WithAtomicReads()},
+		"arena":  {WithArena(4)},
+	} {
+		trie := New(opts...)
+		trie.Add("10.", "default")
+		trie.Add("10.1.", "a")
+		trie.Add("10.1.2.", "b")
+		tests := []struct {
+			key  string
+			want string
+			ok   bool
+		}{
+			// equal priorities: the longest wins.
+			{"10.1.2.3", "10.1.2.", true},
+			{"10.1.9.9", "10.1.", true},
+			{"11.0.0.1", "", false},
+		}
+		check := func(step string) {
+			for _, tt := range tests {
+				key, _, ok := trie.FindBestMatchingPrefix(tt.key)
+				if key != tt.want || ok != tt.ok {
+					t.Errorf("%s: %s: FindBestMatchingPrefix(%q) = %q, %v, want %q, %v", name, step, tt.key, key, ok, tt.want, tt.ok)
+				}
+			}
+		}
+		check("default")
+
+		// a short high-priority prefix beats the longer ones.
+		trie.AddWithPriority("10.", "static", 10)
+		tests[0].want, tests[1].want = "10.", "10."
+		check("priority")
+		if _, v, _ := trie.FindBestMatchingPrefix("10.1.2.3"); v != "static" {
+			t.Errorf("%s: FindBestMatchingPrefix() value = %v", name, v)
+		}
+		// the ties of the priorities are broken by the length.
+		trie.AddWithPriority("10.1.", "a", 10)
+		tests[0].want, tests[1].want = "10.1.", "10.1."
+		check("tie")
+		trie.Compact(0)
+		check("compact")
+
+		// Add resets the priority.
+		trie.Add("10.1.", "a")
+		tests[0].want, tests[1].want = "10.", "10."
+		check("reset")
+		trie.Remove("10.")
+		tests[0].want, tests[1].want = "10.1.2.", "10.1."
+		check("remove")
+		trie.Clear()
+		trie.Add("10.", "default")
+		if _, v, _ := trie.FindBestMatchingPrefix("10.1"); v != "default" {
+			t.Errorf("%s: FindBestMatchingPrefix() after Clear = %v", name, v)
+		}
+	}
+}
+
+func TestTrie_AddWithPriorityRejected(t *testing.T) {
+	var rejected string
+	trie := New(WithMaxKeys(1), WithOnReject(func(key string, err error) {
+		rejected = key
+	}))
+	trie.AddWithPriority("a", 1, 5)
+	trie.AddWithPriority("b", 2, 5)
+	if rejected != "b" || trie.Size() != 1 || len(trie.prios) != 1 {
+		t.Errorf("AddWithPriority() over the limit rejected %q with %d keys and %d priorities", rejected, trie.Size(), len(trie.prios))
+	}
+}
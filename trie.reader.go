@@ -0,0 +1,55 @@
+package gtrie
+
+// Reader is the read-only surface of a Trie for the components that must not
+// modify it. *Trie satisfies Reader, so that a *Trie can be passed as a Reader
+// without giving the access to its mutations.
+type Reader interface {
+	Size() int
+	Find(key string) (interface{}, bool)
+	HasPrefix(prefix string) bool
+	Keys(prefix ...string) []string
+	Values() []interface{}
+	All(prefix ...string) map[string]interface{}
+
+	FindByPrefix(prefix string) []string
+	FindByPrefixValue(prefix string) []interface{}
+	FindByPrefixAll(prefix string) map[string]interface{}
+	FindByPrefixes(prefixes []string) []string
+	FindByPrefixesAll(prefixes []string) map[string]interface{}
+	FindMatchingPrefix(key string) ([]string, bool)
+	FindMatchingPrefixValue(key string) []interface{}
+	FindMatchingPrefixAll(key string) map[string]interface{}
+	FindLongestMatchingPrefix(key string) (string, interface{}, bool)
+	FindByFuzzy(key string) []string
+	FindByFuzzyValue(key string) []interface{}
+	FindByFuzzyAll(key string) map[string]interface{}
+	FindDescendants(prefix string) []string
+	FindAncestors(key string) []string
+	FindRelative(key string) []string
+	FindRelativeValues(key string) []interface{}
+	FindRelativeAll(key string) map[string]interface{}
+
+	Search(key string, stype SearchType, opts ...SearchOption) []string
+	SearchValues(key string, stype SearchType, opts ...SearchOption) []interface{}
+	SearchAll(key string, stype SearchType, opts ...SearchOption) map[string]interface{}
+	SearchE(key string, stype SearchType, opts ...SearchOption) ([]string, error)
+	SearchValuesE(key string, stype SearchType, opts ...SearchOption) ([]interface{}, error)
+	SearchAllE(key string, stype SearchType, opts ...SearchOption) (map[string]interface{}, error)
+	SearchWithOptions(key string, stype SearchType, opts ...SearchOption) ([]string, error)
+}
+
+// Writer is the mutations of a Trie. The mutators added to Trie later
+// may be added to Writer.
+type Writer interface {
+	Add(key string, value interface{})
+	AddE(key string, value interface{}) error
+	Remove(key string) interface{}
+	RemoveE(key string) (interface{}, error)
+	Clear()
+}
+
+// ReadWriter is both Reader and Writer.
+type ReadWriter interface {
+	Reader
+	Writer
+}
@@ -0,0 +1,41 @@
+package gtrie
+
+import (
+	"reflect"
+	"testing"
+)
+
+var (
+	_ Reader     = (*Trie)(nil)
+	_ Writer     = (*Trie)(nil)
+	_ ReadWriter = (*Trie)(nil)
+)
+
+// countKeys uses the trie through Reader only.
+func countKeys(r Reader, prefix string) int {
+	return len(r.FindByPrefix(prefix))
+}
+
+func TestReader(t *testing.T) {
+	var w Writer = New()
+	for _, key := range gnmiFixture {
+		w.Add(key, true)
+	}
+	r := w.(Reader)
+	if got := countKeys(r, "/interfaces/interface[name=1/2]"); got != 6 {
+		t.Errorf("countKeys() = %d, want 6", got)
+	}
+	if _, ok := r.(Writer); !ok {
+		t.Errorf("the Reader of a *Trie is not a Writer")
+	}
+	// every method of Reader and Writer is a method of *Trie of the same type.
+	trie := reflect.TypeOf((*Trie)(nil))
+	for _, iface := range []reflect.Type{reflect.TypeOf((*Reader)(nil)).Elem(), reflect.TypeOf((*Writer)(nil)).Elem()} {
+		for i := 0; i < iface.NumMethod(); i++ {
+			m := iface.Method(i)
+			if tm, ok := trie.MethodByName(m.Name); !ok || tm.Type.NumIn() != m.Type.NumIn()+1 {
+				t.Errorf("%s.%s is not a method of *Trie", iface.Name(), m.Name)
+			}
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package gtrie
+
+import "sort"
+
+// RelativeSource is a bitset of the sub-searches of FindRelative finding a key.
+type RelativeSource uint8
+
+// The sub-searches of FindRelative.
+const (
+	// RelativeByPrefix - the key starts with the input (SearchByPrefix).
+	RelativeByPrefix RelativeSource = 1 << iota
+	// RelativeMatchingPrefix - the key is a prefix of the input (SearchMatcingPrefix).
+	RelativeMatchingPrefix
+	// RelativeApproximate - the input is a subsequence of the key (SearchApproximate).
+	RelativeApproximate
+)
+
+// Has reports whether all the sub-searches of `src` found the key.
+func (s RelativeSource) Has(src RelativeSource) bool {
+	return s&src == src
+}
+
+// RelativeResult is a key found by FindRelativeResults
+// labeled with the sub-searches that found it.
+type RelativeResult struct {
+	Key     string
+	Value   interface{}
+	Sources RelativeSource
+}
+
+// FindRelativeResults returns the keys and values of FindRelativeAll in lexicographic
+// order of the keys, each labeled with the sub-searches that found it.
+// For example, the keys found only by the fuzzy search are those of which
+// Sources is RelativeApproximate, and can be filtered out by
+//
+//	if r.Sources == RelativeApproximate { continue }
+//
+// Note that every key found by RelativeByPrefix is also found by RelativeApproximate
+// since a key starting with the input has the input as its subsequence.
+func (t *Trie) FindRelativeResults(key string) []RelativeResult {
+	if t == nil {
+		return nil
+	}
+	t.rlock()
+	defer t.runlock()
+	found := relativecollect(t.root, t.runes(key))
+	results := make([]RelativeResult, 0, len(found))
+	for n, src := range found {
+		results = append(results, RelativeResult{Key: n.key(), Value: n.value, Sources: src})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Key < results[j].Key
+	})
+	return results
+}
+
+// relativecollect returns all the terminal nodes relative to `key`
+// with the sub-searches that found them.
+func relativecollect(root *trieNode, key []rune) map[*trieNode]RelativeSource {
+	found := make(map[*trieNode]RelativeSource)
+	if node := findNode(root, string(key)); node != nil {
+		for _, n := range collectNodes(node) {
+			found[n] |= RelativeByPrefix
+		}
+	}
+	for _, n := range matchingprefixcollect(root, key, false) {
+		found[n] |= RelativeMatchingPrefix
+	}
+	for _, n := range fuzzycollectNodes(root, key, false) {
+		found[n] |= RelativeApproximate
+	}
+	return found
+}
@@ -0,0 +1,90 @@
+package gtrie
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTrie_FindRelativeResults(t *testing.T) {
+	const (
+		p = RelativeByPrefix
+		m = RelativeMatchingPrefix
+		a = RelativeApproximate
+	)
+	trie := newGNMITrie()
+	tests := []struct {
+		key    string
+		remove []string
+		want   []RelativeResult
+	}{
+		{
+			key: "/interfaces/interface[name=1/2]",
+			want: []RelativeResult{
+				{Key: "/interfaces", Sources: m},
+				{Key: "/interfaces/interface", Sources: m},
+				{Key: "/interfaces/interface[name=1/2]", Sources: p | m | a},
+				{Key: "/interfaces/interface[name=1/2]/state", Sources: p | a},
+				{Key: "/interfaces/interface[name=1/2]/state/admin-status", Sources: p | a},
+				{Key: "/interfaces/interface[name=1/2]/state/counters", Sources: p | a},
+				{Key: "/interfaces/interface[name=1/2]/state/enabled", Sources: p | a},
+				{Key: "/interfaces/interface[name=1/2]/state/oper-status", Sources: p | a},
+			},
+		},
+		{
+			// the example of example/main.go
+			key:    "/interfaces/interface/state",
+			remove: []string{"/interfaces", "/interfaces/interface"},
+			want: []RelativeResult{
+				{Key: "/interfaces/interface/state/counters", Sources: p | a},
+				{Key: "/interfaces/interface[name=1/1]/state/enabled", Sources: a},
+				{Key: "/interfaces/interface[name=1/2]/state", Sources: a},
+				{Key: "/interfaces/interface[name=1/2]/state/admin-status", Sources: a},
+				{Key: "/interfaces/interface[name=1/2]/state/counters", Sources: a},
+				{Key: "/interfaces/interface[name=1/2]/state/enabled", Sources: a},
+				{Key: "/interfaces/interface[name=1/2]/state/oper-status", Sources: a},
+				{Key: "/interfaces/interface[name=1/3]/state", Sources: a},
+				{Key: "/interfaces/interface[name=1/3]/state/admin-status", Sources: a},
+				{Key: "/interfaces/interface[name=1/3]/state/counters", Sources: a},
+				{Key: "/interfaces/interface[name=1/3]/state/enabled", Sources: a},
+				{Key: "/interfaces/interface[name=1/3]/state/oper-status", Sources: a},
+			},
+		},
+		{
+			key:  "/xyz",
+			want: []RelativeResult{},
+		},
+	}
+	for _, tt := range tests {
+		for _, key := range tt.remove {
+			trie.Remove(key)
+		}
+		keys := []string{}
+		for i := range tt.want {
+			tt.want[i].Value = true
+			keys = append(keys, tt.want[i].Key)
+		}
+		got := trie.FindRelativeResults(tt.key)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("FindRelativeResults(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+		if got := trie.FindRelative(tt.key); !reflect.DeepEqual(got, keys) {
+			t.Errorf("FindRelative(%q) = %v, want %v", tt.key, got, keys)
+		}
+		if got := trie.FindRelativeValues(tt.key); len(got) != len(keys) {
+			t.Errorf("FindRelativeValues(%q) = %v, want %d values", tt.key, got, len(keys))
+		}
+		if got := trie.FindRelativeAll(tt.key); len(got) != len(keys) {
+			t.Errorf("FindRelativeAll(%q) = %v, want %d keys", tt.key, got, len(keys))
+		}
+	}
+}
+
+func TestRelativeSource_Has(t *testing.T) {
+	s := RelativeByPrefix | RelativeApproximate
+	if !s.Has(RelativeByPrefix) || !s.Has(RelativeByPrefix|RelativeApproximate) {
+		t.Errorf("Has() = false, want true")
+	}
+	if s.Has(RelativeMatchingPrefix) || s.Has(RelativeByPrefix|RelativeMatchingPrefix) {
+		t.Errorf("Has() = true, want false")
+	}
+}
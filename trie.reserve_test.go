@@ -0,0 +1,91 @@
+package gtrie
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestTrie_Reserve(t *testing.T) {
+	keys := make([]string, 1000)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("/key/%x/%d", i*7919, i)
+	}
+	tries := map[string]*Trie{
+		"accurate":  New(WithExpectedKeys(len(keys), 12)),
+		"too small": New(WithExpectedKeys(10, 1)),
+		"too large": New(WithExpectedKeys(1000000, 100)),
+		"arena":     New(WithExpectedKeys(len(keys), 12), WithArena(16)),
+		"atomic":    New(WithExpectedKeys(len(keys), 12), WithAtomicReads()),
+		"reserved":  New(),
+	}
+	tries["reserved"].Add(keys[0], 0)
+	tries["reserved"].Reserve(len(keys))
+	for name, trie := range tries {
+		for i, key := range keys {
+			trie.Add(key, i)
+		}
+		if got := sortedKeys(trie.Keys()); !reflect.DeepEqual(got, sortedKeys(append([]string(nil), keys...))) {
+			t.Errorf("%s: Keys() = %d keys, want %d keys", name, len(got), len(keys))
+		}
+		for i, key := range keys[:10] {
+			if v, ok := trie.Find(key); !ok || v != i {
+				t.Errorf("%s: Find(%q) = %v, %v", name, key, v, ok)
+			}
+		}
+		checkNodes(t, trie.root, !trie.atomicReads)
+		// the nodes are taken from the slabs only as many as reserved.
+		if r := trie.reserved; r != nil && r.used > r.limit {
+			t.Errorf("%s: %d nodes used over the reservation of %d nodes", name, r.used, r.limit)
+		}
+		trie.Remove(keys[0])
+		trie.Clear()
+		if trie.Size() != 0 || trie.reserved != nil {
+			t.Errorf("%s: Clear() leaves %d keys or the reservation", name, trie.Size())
+		}
+	}
+	if tries["atomic"].reserved != nil {
+		t.Errorf("the atomic read mode has a reservation")
+	}
+	if tries["arena"].reserved != nil || tries["arena"].arena.size < 1000 {
+		t.Errorf("the arena is not enlarged by the hint")
+	}
+}
+
+func BenchmarkExpectedKeys(b *testing.B) {
+	f, err := os.Open("/usr/share/dict/words")
+	if err != nil {
+		b.Skip("no dictionary fixture")
+	}
+	var words []string
+	length := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		words = append(words, scanner.Text())
+		length += len(scanner.Text())
+	}
+	f.Close()
+	avgLen := length / len(words)
+	hints := []struct {
+		name string
+		opts []Option
+	}{
+		{"none", nil},
+		{"accurate", []Option{WithExpectedKeys(len(words), avgLen)}},
+		{"too-small", []Option{WithExpectedKeys(len(words)/100, avgLen)}},
+		{"too-large", []Option{WithExpectedKeys(len(words)*100, avgLen*10)}},
+	}
+	for _, h := range hints {
+		b.Run(h.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				trie := New(h.opts...)
+				for _, w := range words {
+					trie.Add(w, nil)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,274 @@
+package gtrie
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"strings"
+)
+
+// ErrInvalidResumeToken is returned by ParseResumeToken if the input is not
+// a ResumeToken encoded by ResumeToken.String.
+var ErrInvalidResumeToken = errors.New("gtrie: invalid resume token")
+
+// resumeTokenVersion heads the encoded form of ResumeToken.
+const resumeTokenVersion = 1
+
+// the flags of the encoded form of ResumeToken.
+const (
+	resumeStarted = 1 << iota
+	resumeDesc
+	resumeDone
+)
+
+// ResumeToken is the position of the pages of the keys starting with a prefix
+// walked in lexicographic order, or the order of WithKeyLess, by NextPage. It carries the prefix, the last key
+// of the page returned (the cursor) and the options of the pages, so that an API
+// server can hand it to the clients as an opaque string by String and take it back
+// by ParseResumeToken. The token is not signed; it only holds what it is given.
+// The zero value is the first page of all the keys without limit.
+type ResumeToken struct {
+	prefix string
+	cursor string
+	limit  int
+	flags  uint8
+}
+
+// NewResumeToken returns the token of the first page of the keys starting with
+// `prefix`. MaxResults limits the number of the keys per page and Descending
+// walks the keys in descending order; the other options are ignored.
+func NewResumeToken(prefix string, opts ...SearchOption) ResumeToken {
+	o := newSearchOptions(opts)
+	r := ResumeToken{prefix: prefix, limit: max(o.max, 0)}
+	if o.desc {
+		r.flags |= resumeDesc
+	}
+	return r
+}
+
+// Done reports whether the page returned with the token by NextPage was the last.
+// NextPage can still be called with the token for the keys added after the cursor.
+func (r ResumeToken) Done() bool {
+	return r.flags&resumeDone != 0
+}
+
+// String returns the token encoded into a URL-safe string.
+func (r ResumeToken) String() string {
+	data := []byte{resumeTokenVersion, r.flags}
+	data = binary.AppendUvarint(data, uint64(r.limit))
+	data = binary.AppendUvarint(data, uint64(len(r.prefix)))
+	data = append(data, r.prefix...)
+	data = append(data, r.cursor...)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// ParseResumeToken returns the token encoded by String.
+// It returns ErrInvalidResumeToken if `s` is not in the form of String
+// or the cursor of the token does not start with its prefix.
+func ParseResumeToken(s string) (ResumeToken, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(data) < 2 || data[0] != resumeTokenVersion || data[1]&^(resumeStarted|resumeDesc|resumeDone) != 0 {
+		return ResumeToken{}, ErrInvalidResumeToken
+	}
+	r := ResumeToken{flags: data[1]}
+	data = data[2:]
+	limit, n := binary.Uvarint(data)
+	if n <= 0 || limit > uint64(maxInt) {
+		return ResumeToken{}, ErrInvalidResumeToken
+	}
+	data = data[n:]
+	size, n := binary.Uvarint(data)
+	if n <= 0 || size > uint64(len(data)-n) {
+		return ResumeToken{}, ErrInvalidResumeToken
+	}
+	data = data[n:]
+	r.limit = int(limit)
+	r.prefix, r.cursor = string(data[:size]), string(data[size:])
+	if !strings.HasPrefix(r.cursor, r.prefix) && r.flags&resumeStarted != 0 {
+		return ResumeToken{}, ErrInvalidResumeToken
+	}
+	return r, nil
+}
+
+// maxInt is the greatest int.
+const maxInt = int(^uint(0) >> 1)
+
+// MarshalText implements encoding.TextMarshaler.
+func (r ResumeToken) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (r *ResumeToken) UnmarshalText(text []byte) error {
+	token, err := ParseResumeToken(string(text))
+	if err != nil {
+		return err
+	}
+	*r = token
+	return nil
+}
+
+// NextPage returns the keys and values of the page of the `token` in the order
+// of the token and the token of the next page. The page starts at the key next
+// to the cursor of the token, i.e. the Successor of the cursor among the keys
+// starting with the prefix (the Predecessor if descending), so that the page
+// resumes even if the cursor is removed in between. No key is skipped or
+// repeated across the pages, except the keys added or removed in between:
+// the keys added before the cursor are not returned. The token returned is
+// Done if no key follows the page. In the order of WithKeyLess, all the keys
+// after the cursor are collected for the page.
+func (t *Trie) NextPage(token ResumeToken) ([]KV, ResumeToken) {
+	if t == nil {
+		return nil, ResumeToken{}
+	}
+	return t.nextPage(token, t.keyLess)
+}
+
+// nextPage is NextPage in the order of `less`, or lexicographic order if nil.
+func (t *Trie) nextPage(token ResumeToken, less func(a, b string) bool) ([]KV, ResumeToken) {
+	root := t.readRoot()
+	defer t.readDone()
+	next := token
+	next.flags |= resumeDone
+	prefix := t.canonical(token.prefix)
+	node := findNode(root, prefix)
+	if node == nil {
+		return nil, next
+	}
+	desc := token.flags&resumeDesc != 0
+	started := token.flags&resumeStarted != 0
+	var kvs []KV
+	visit := func(n *trieNode) bool {
+		if token.limit > 0 && len(kvs) == token.limit {
+			// a key follows the page.
+			next.flags &^= resumeDone
+			return false
+		}
+		kvs = append(kvs, KV{Key: n.key(), Value: n.value})
+		return true
+	}
+	if less != nil {
+		terms := collectNodes(node)
+		if started {
+			terms = t.after(terms, token.cursor, desc)
+		}
+		k := 0
+		if token.limit > 0 {
+			k = token.limit + 1
+		}
+		for _, n := range selectNodes(terms, k, less, desc) {
+			if !visit(n) {
+				break
+			}
+		}
+	} else {
+		stack := []*trieNode{node}
+		if started {
+			cursor := t.canonical(token.cursor)
+			if !strings.HasPrefix(cursor, prefix) {
+				return nil, next
+			}
+			stack = seekStack(node, []rune(cursor[len(prefix):]), desc)
+		}
+		walkSortedStack(stack, desc, visit)
+	}
+	if len(kvs) > 0 {
+		next.cursor = kvs[len(kvs)-1].Key
+		next.flags |= resumeStarted
+	}
+	return kvs, next
+}
+
+// Successor returns the least key greater than the `key` in lexicographic order
+// of the canonical keys, or the order of WithKeyLess, and its value.
+// The `key` need not exist in the trie.
+func (t *Trie) Successor(key string) (string, interface{}, bool) {
+	if t == nil {
+		return "", nil, false
+	}
+	return t.neighbor(key, false)
+}
+
+// Predecessor returns the greatest key less than the `key` in lexicographic
+// order of the canonical keys, or the order of WithKeyLess, and its value.
+// The `key` need not exist in the trie.
+func (t *Trie) Predecessor(key string) (string, interface{}, bool) {
+	if t == nil {
+		return "", nil, false
+	}
+	return t.neighbor(key, true)
+}
+
+// neighbor returns the key next to the `key`, or previous to it if desc.
+func (t *Trie) neighbor(key string, desc bool) (string, interface{}, bool) {
+	root := t.readRoot()
+	defer t.readDone()
+	var found *trieNode
+	if t.keyLess != nil {
+		if terms := selectNodes(t.after(collectNodes(root), key, desc), 1, t.keyLess, desc); len(terms) > 0 {
+			found = terms[0]
+		}
+	} else {
+		walkSortedStack(seekStack(root, t.runes(key), desc), desc, func(n *trieNode) bool {
+			found = n
+			return false
+		})
+	}
+	if found == nil {
+		return "", nil, false
+	}
+	return found.key(), found.value, true
+}
+
+// after returns the terminal nodes after the `key` in the order of WithKeyLess,
+// or before it if desc, reusing the slice of `terms`.
+func (t *Trie) after(terms []*trieNode, key string, desc bool) []*trieNode {
+	found := terms[:0]
+	for _, n := range terms {
+		if (!desc && t.keyLess(key, n.key())) || (desc && t.keyLess(n.key(), key)) {
+			found = append(found, n)
+		}
+	}
+	return found
+}
+
+// seekStack returns the stack of walkSortedStack walking the keys under the node
+// greater than the key of the `rest` runes under the node, or less if desc.
+// Along the path of the key, the children after its runes (before them and
+// the terminals of the nodes if desc) are stacked, the deeper on the top,
+// so that the walk resumes even if the path is cut short.
+func seekStack(node *trieNode, rest []rune, desc bool) []*trieNode {
+	var stack []*trieNode
+	for _, r := range rest {
+		children := sortedChildren(node, false)
+		if desc {
+			// the terminal (nul) is the least, popped last.
+			for _, c := range children {
+				if c.rval < r {
+					stack = append(stack, c)
+				}
+			}
+		} else {
+			for i := len(children) - 1; i >= 0; i-- {
+				if c := children[i]; c.rval > r {
+					stack = append(stack, c)
+				}
+			}
+		}
+		c, ok := node.children[r]
+		if !ok {
+			return stack
+		}
+		node = c
+	}
+	if !desc {
+		// the keys extending the key are greater than the key.
+		children := sortedChildren(node, false)
+		for i := len(children) - 1; i >= 0; i-- {
+			if c := children[i]; c.rval != nul {
+				stack = append(stack, c)
+			}
+		}
+	}
+	return stack
+}
@@ -0,0 +1,182 @@
+package gtrie
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// pageAll walks all the pages of the token round-tripping the token by its string
+// and calls `between` between the pages with the last key returned.
+func pageAll(t *testing.T, trie *Trie, token ResumeToken, between func(cursor string)) []string {
+	t.Helper()
+	var keys []string
+	for i := 0; ; i++ {
+		if i > trie.Size()+1 {
+			t.Fatalf("NextPage() does not end")
+		}
+		kvs, next := trie.NextPage(token)
+		for _, kv := range kvs {
+			keys = append(keys, kv.Key)
+		}
+		if next.Done() {
+			return keys
+		}
+		var err error
+		if token, err = ParseResumeToken(next.String()); err != nil || token != next {
+			t.Fatalf("ParseResumeToken(%q) = %+v, %v, want %+v", next.String(), token, err, next)
+		}
+		if between != nil {
+			between(kvs[len(kvs)-1].Key)
+		}
+	}
+}
+
+func TestTrie_NextPage(t *testing.T) {
+	for name, opts := range map[string][]Option{"plain": nil, "sorted": {WithSortedChildren()}} {
+		trie := New(opts...)
+		for _, key := range gnmiFixture {
+			trie.Add(key, key)
+		}
+		all := sortedKeys(trie.FindByPrefix("/interfaces/interface["))
+		desc := slices.Clone(all)
+		slices.Reverse(desc)
+		for _, limit := range []int{0, 1, 3, len(all), len(all) + 1} {
+			if got := pageAll(t, trie, NewResumeToken("/interfaces/interface[", MaxResults(limit)), nil); !reflect.DeepEqual(got, all) {
+				t.Errorf("%s: the pages of %d keys = %v, want %v", name, limit, got, all)
+			}
+			if got := pageAll(t, trie, NewResumeToken("/interfaces/interface[", MaxResults(limit), Descending()), nil); !reflect.DeepEqual(got, desc) {
+				t.Errorf("%s: the descending pages of %d keys = %v, want %v", name, limit, got, desc)
+			}
+		}
+		if got := pageAll(t, trie, ResumeToken{}, nil); !reflect.DeepEqual(got, sortedKeys(trie.Keys())) {
+			t.Errorf("%s: the pages of the zero token = %v", name, got)
+		}
+		if kvs, next := trie.NextPage(NewResumeToken("/none")); kvs != nil || !next.Done() {
+			t.Errorf("%s: NextPage() of no key = %v, %v", name, kvs, next.Done())
+		}
+	}
+}
+
+func TestTrie_NextPageMutated(t *testing.T) {
+	keys := []string{"/a", "/a/1", "/a/2", "/b", "/b/1", "/c", "/c/1/x", "/d"}
+	newTrie := func() *Trie {
+		trie := New()
+		for _, key := range keys {
+			trie.Add(key, key)
+		}
+		return trie
+	}
+	for _, desc := range []bool{false, true} {
+		opts := []SearchOption{MaxResults(2)}
+		want := slices.Clone(keys)
+		if desc {
+			opts = append(opts, Descending())
+			slices.Reverse(want)
+		}
+		// the cursor removed between the pages.
+		trie := newTrie()
+		got := pageAll(t, trie, NewResumeToken("/", opts...), func(cursor string) {
+			trie.Remove(cursor)
+		})
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("desc %v: the pages removing the cursor = %v, want %v", desc, got, want)
+		}
+		// the whole path of the cursor removed between the pages.
+		trie = newTrie()
+		got = pageAll(t, trie, NewResumeToken("/", opts...), func(cursor string) {
+			for _, key := range trie.FindByPrefix(cursor[:2]) {
+				if key >= cursor == !desc {
+					trie.Remove(key)
+				}
+			}
+		})
+		if len(got) == 0 || !slices.IsSortedFunc(got, func(a, b string) int {
+			if desc {
+				a, b = b, a
+			}
+			return strings.Compare(a, b)
+		}) {
+			t.Errorf("desc %v: the pages removing the subtree of the cursor = %v", desc, got)
+		}
+		// the keys added before and after the cursor between the pages.
+		trie = newTrie()
+		added := false
+		got = pageAll(t, trie, NewResumeToken("/", opts...), func(cursor string) {
+			if !added {
+				trie.Add("/0", "before")
+				trie.Add("/e", "after")
+				added = true
+			}
+		})
+		// only the key after the cursor in the order is walked.
+		if desc {
+			want = append(want, "/0")
+		} else {
+			want = append(want, "/e")
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("desc %v: the pages adding the keys = %v, want %v", desc, got, want)
+		}
+	}
+}
+
+func TestTrie_Successor(t *testing.T) {
+	trie := New()
+	for _, key := range []string{"", "/a", "/a/1", "/a/2", "/b", "/ü"} {
+		trie.Add(key, key)
+	}
+	tests := []struct {
+		key       string
+		succ, pre string
+		sok, pok  bool
+	}{
+		{"", "/a", "", true, false},
+		{"/", "/a", "", true, true},
+		{"/a", "/a/1", "", true, true},
+		{"/a/", "/a/1", "/a", true, true},
+		{"/a/1", "/a/2", "/a", true, true},
+		{"/a/15", "/a/2", "/a/1", true, true},
+		{"/a/3", "/b", "/a/2", true, true},
+		{"/c", "/ü", "/b", true, true},
+		{"/ü", "", "/b", false, true},
+		{"z", "", "/ü", false, true},
+	}
+	for _, tt := range tests {
+		if key, v, ok := trie.Successor(tt.key); key != tt.succ || ok != tt.sok || (ok && v != key) {
+			t.Errorf("Successor(%q) = %q, %v, %v, want %q, %v", tt.key, key, v, ok, tt.succ, tt.sok)
+		}
+		if key, _, ok := trie.Predecessor(tt.key); key != tt.pre || ok != tt.pok {
+			t.Errorf("Predecessor(%q) = %q, %v, want %q, %v", tt.key, key, ok, tt.pre, tt.pok)
+		}
+	}
+}
+
+func TestParseResumeToken(t *testing.T) {
+	token := NewResumeToken("/a", MaxResults(10), Descending())
+	data, err := json.Marshal(map[string]ResumeToken{"token": token})
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var got map[string]ResumeToken
+	if err := json.Unmarshal(data, &got); err != nil || got["token"] != token {
+		t.Errorf("json.Unmarshal(%s) = %+v, %v, want %+v", data, got, err, token)
+	}
+	for _, s := range []string{"", "!", "AQ", "AgA", "AQgA", "AQAKBQ", "AQEAAi9hL2I", "AQEAAi9hL2I="} {
+		if _, err := ParseResumeToken(s); !errors.Is(err, ErrInvalidResumeToken) {
+			t.Errorf("ParseResumeToken(%q) error = %v, want %v", s, err, ErrInvalidResumeToken)
+		}
+	}
+	// the cursor out of the prefix.
+	bad := ResumeToken{prefix: "/a", cursor: "/b", flags: resumeStarted}
+	if _, err := ParseResumeToken(bad.String()); !errors.Is(err, ErrInvalidResumeToken) {
+		t.Errorf("ParseResumeToken() of the cursor out of the prefix error = %v", err)
+	}
+	good := ResumeToken{prefix: "/a", cursor: "/a/b", limit: 3, flags: resumeStarted}
+	if got, err := ParseResumeToken(good.String()); err != nil || got != good {
+		t.Errorf("ParseResumeToken() = %+v, %v, want %+v", got, err, good)
+	}
+}
@@ -0,0 +1,206 @@
+package gtrie
+
+// patternNode is a node of the pattern trie built by AddPattern.
+// The children are classified into the literal runes and a parameter.
+type patternNode struct {
+	literal map[rune]*patternNode
+	param   *patternNode // the node after a parameter
+	term    bool
+	value   interface{}
+	names   []string // the parameter names of the pattern ending at this node in order
+}
+
+// patternToken is a literal rune or a parameter of a pattern.
+type patternToken struct {
+	r     rune
+	param string
+}
+
+// parsePattern parses the `pattern` into tokens. "{name}" is a parameter
+// and a '{' not closed by '}' is a literal rune.
+func parsePattern(pattern string) []patternToken {
+	runes := []rune(pattern)
+	tokens := make([]patternToken, 0, len(runes))
+	for i := 0; i < len(runes); i++ {
+		if runes[i] == '{' {
+			end := i + 1
+			for end < len(runes) && runes[end] != '}' {
+				end++
+			}
+			if end < len(runes) && end > i+1 {
+				tokens = append(tokens, patternToken{param: string(runes[i+1 : end])})
+				i = end
+				continue
+			}
+		}
+		tokens = append(tokens, patternToken{r: runes[i]})
+	}
+	return tokens
+}
+
+// AddPattern adds the path template `pattern` with the value for Match.
+// A parameter "{name}" in the pattern captures a non-empty part of the path:
+// inside a bracketed predicate (e.g. "[name={ifname}]"), it captures up to
+// the closing ']' including '/'; otherwise it captures up to the next '/'.
+// The patterns are kept apart from the keys added by Add.
+func (t *Trie) AddPattern(pattern string, value interface{}) {
+	if t == nil {
+		return
+	}
+	t.lock()
+	defer t.unlock()
+	if t.patterns == nil {
+		t.patterns = &patternNode{}
+	}
+	node := t.patterns
+	var names []string
+	for _, tok := range parsePattern(pattern) {
+		if tok.param != "" {
+			if node.param == nil {
+				node.param = &patternNode{}
+			}
+			node = node.param
+			names = append(names, tok.param)
+			continue
+		}
+		if node.literal == nil {
+			node.literal = make(map[rune]*patternNode)
+		}
+		n, ok := node.literal[tok.r]
+		if !ok {
+			n = &patternNode{}
+			node.literal[tok.r] = n
+		}
+		node = n
+	}
+	node.term = true
+	node.value = value
+	node.names = names
+}
+
+// Match finds the pattern added by AddPattern matching the `path` and
+// returns its value and the parameter values captured by name.
+// If more than one pattern matches, the most specific one wins:
+// from the start of the path, a literal rune beats a parameter at the first
+// position the patterns differ. The positions of the path a pattern node fails
+// to match from are remembered, so that a path of n runes costs at most n
+// captures per parameter node and position instead of growing by the power of
+// the parameters (a path of 80 dashes against "/{a}-{b}-{c}-{d}-{e}/x" took 2.6s).
+func (t *Trie) Match(path string) (interface{}, map[string]string, bool) {
+	if t == nil {
+		return nil, nil, false
+	}
+	t.rlock()
+	defer t.runlock()
+	if t.patterns == nil {
+		return nil, nil, false
+	}
+	m := &patternMatch{path: []rune(path)}
+	m.inBracket = bracketed(m.path)
+	node := m.match(t.patterns, 0)
+	if node == nil {
+		return nil, nil, false
+	}
+	params := make(map[string]string, len(node.names))
+	for i, name := range node.names {
+		params[name] = string(m.path[m.captures[i][0]:m.captures[i][1]])
+	}
+	return node.value, params, true
+}
+
+// patternMatch is the state of Match.
+type patternMatch struct {
+	path      []rune
+	inBracket []bool
+	// captures is the start and the end of the runes captured by the parameters.
+	captures [][2]int
+	// failed marks the positions of the path the nodes are known not to match
+	// from, so that each node is tried at most once per position instead of
+	// once per combination of the captures before it.
+	failed map[*patternNode][]bool
+}
+
+// match returns the terminal node matching path[i:] from the node
+// trying the literal child before the parameter child.
+func (m *patternMatch) match(node *patternNode, i int) *patternNode {
+	if i == len(m.path) {
+		if node.term {
+			return node
+		}
+		return nil
+	}
+	if m.failed[node] != nil && m.failed[node][i] {
+		return nil
+	}
+	found := m.matchNext(node, i)
+	if found == nil {
+		if m.failed == nil {
+			m.failed = make(map[*patternNode][]bool)
+		}
+		if m.failed[node] == nil {
+			m.failed[node] = make([]bool, len(m.path))
+		}
+		m.failed[node][i] = true
+	}
+	return found
+}
+
+// matchNext returns the terminal node matching path[i:] from the children of the node.
+func (m *patternMatch) matchNext(node *patternNode, i int) *patternNode {
+	if c, ok := node.literal[m.path[i]]; ok {
+		if found := m.match(c, i+1); found != nil {
+			return found
+		}
+	}
+	if node.param == nil {
+		return nil
+	}
+	// try the shortest capture first so that more literal runes are matched.
+	for end, last := i+1, m.captureEnd(i); end <= last; end++ {
+		m.captures = append(m.captures, [2]int{i, end})
+		if found := m.match(node.param, end); found != nil {
+			return found
+		}
+		m.captures = m.captures[:len(m.captures)-1]
+	}
+	return nil
+}
+
+// captureEnd returns the end of the longest capture of a parameter starting at `i`.
+func (m *patternMatch) captureEnd(i int) int {
+	end := i
+	if m.inBracket[i] {
+		for end < len(m.path) && m.inBracket[end] {
+			end++
+		}
+		return end
+	}
+	for end < len(m.path) && (m.inBracket[end] || m.path[end] != '/') {
+		end++
+	}
+	return end
+}
+
+// bracketed reports whether each rune of the path is inside a bracketed predicate.
+// The brackets themselves are not inside and '\' escapes the next rune in a predicate.
+func bracketed(path []rune) []bool {
+	in := make([]bool, len(path))
+	inside := false
+	for i := 0; i < len(path); i++ {
+		switch {
+		case !inside && path[i] == '[':
+			inside = true
+		case inside && path[i] == ']':
+			inside = false
+		case inside && path[i] == '\\':
+			in[i] = true
+			if i+1 < len(path) {
+				i++
+				in[i] = true
+			}
+		default:
+			in[i] = inside
+		}
+	}
+	return in
+}
@@ -0,0 +1,73 @@
+package gtrie
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTrie_Match(t *testing.T) {
+	trie := New()
+	trie.AddPattern("/interfaces/interface[name={ifname}]/state/{leaf}", "leaf")
+	trie.AddPattern("/interfaces/interface[name={ifname}]/state/counters", "counters")
+	trie.AddPattern("/interfaces/interface[name={ifname}]/state", "state")
+	trie.AddPattern("/interfaces/interface[name=mgmt]/state/{leaf}", "mgmt")
+	trie.AddPattern("/interfaces/{elem}/config", "config")
+	trie.AddPattern("/lldp/{a}/{b}", "lldp")
+	trie.AddPattern("/files/{name}.json", "json")
+	trie.AddPattern("/files/{name}", "file")
+
+	tests := []struct {
+		path   string
+		want   interface{}
+		params map[string]string
+		ok     bool
+	}{
+		{"/interfaces/interface[name=1/2]/state/oper-status", "leaf", map[string]string{"ifname": "1/2", "leaf": "oper-status"}, true},
+		{"/interfaces/interface[name=1/2]/state/counters", "counters", map[string]string{"ifname": "1/2"}, true},
+		{"/interfaces/interface[name=eth0]/state", "state", map[string]string{"ifname": "eth0"}, true},
+		{"/interfaces/interface[name=mgmt]/state/enabled", "mgmt", map[string]string{"leaf": "enabled"}, true},
+		{"/interfaces/interface[name=mgmt]/state/counters", "mgmt", map[string]string{"leaf": "counters"}, true},
+		{`/interfaces/interface[name=a\]b/c]/state`, "state", map[string]string{"ifname": `a\]b/c`}, true},
+		{"/interfaces/interface[name=1/2]/config", "config", map[string]string{"elem": "interface[name=1/2]"}, true},
+		{"/lldp/x/y", "lldp", map[string]string{"a": "x", "b": "y"}, true},
+		{"/files/a.json", "json", map[string]string{"name": "a"}, true},
+		{"/files/a.yaml", "file", map[string]string{"name": "a.yaml"}, true},
+		{"/lldp/x", nil, nil, false},
+		{"/lldp/x/y/z", nil, nil, false},
+		{"/interfaces/interface[name=]/state", nil, nil, false},
+		{"/interfaces/interface[name=1/2]/state/", nil, nil, false},
+	}
+	for _, tt := range tests {
+		v, params, ok := trie.Match(tt.path)
+		if v != tt.want || ok != tt.ok || !reflect.DeepEqual(params, tt.params) {
+			t.Errorf("Match(%q) = %v, %v, %v, want %v, %v, %v", tt.path, v, params, ok, tt.want, tt.params, tt.ok)
+		}
+	}
+	if trie.Size() != 0 {
+		t.Errorf("Size() = %d, want 0 for the patterns", trie.Size())
+	}
+	if _, _, ok := New().Match("/a"); ok {
+		t.Errorf("Match() = true without patterns")
+	}
+}
+
+func TestTrie_MatchBacktracking(t *testing.T) {
+	trie := New()
+	trie.AddPattern("/{a}-{b}-{c}-{d}-{e}/x", true)
+	want := map[string]string{"a": "1", "b": "2", "c": "3", "d": "4", "e": "5-6"}
+	if _, params, ok := trie.Match("/1-2-3-4-5-6/x"); !ok || !reflect.DeepEqual(params, want) {
+		t.Errorf("Match() = %v, %v, want %v", params, ok, want)
+	}
+	// the path failing at the end takes the retries of all the captures
+	// of the parameters without the failures memoized, e.g. 2.6s for 80 runes.
+	path := "/" + strings.Repeat("-", 400) + "/y"
+	start := time.Now()
+	if _, _, ok := trie.Match(path); ok {
+		t.Errorf("Match() = true, want false")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Match() of %d runes takes %v", len(path), elapsed)
+	}
+}
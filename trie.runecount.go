@@ -0,0 +1,94 @@
+package gtrie
+
+import "math"
+
+// runeCounts is the largest number of the occurrences of the runes on a path
+// from a node down to a key, counted per bucket of the runes folded into 16
+// buckets. The masks only tell whether a rune is under a node, so a subtree
+// having a rune once is not pruned for a query needing it twice (e.g. "aa"
+// or "1/1"). A path matching a query has the occurrences of the runes of the
+// query at least in every bucket, so a subtree is skipped only if a bucket
+// of the node counts fewer runes than the query, i.e. it provably has no match.
+// The runes folded into the same bucket and the counts saturated at 255 only
+// make the pruning weaker.
+type runeCounts [16]uint8
+
+// countBucket returns the bucket of the rune `r` in runeCounts.
+// The rune is hashed by the Fibonacci hashing as wideBit.
+func countBucket(r rune) uint8 {
+	return uint8(uint32(r) * 2654435769 >> 28)
+}
+
+func (c *runeCounts) add(r rune) {
+	b := countBucket(r)
+	if c[b] < math.MaxUint8 {
+		c[b]++
+	}
+}
+
+// max sets the counts to the larger of their own and the counts of `o`.
+func (c *runeCounts) max(o *runeCounts) {
+	for i := range c {
+		c[i] = max(c[i], o[i])
+	}
+}
+
+// covers reports whether the counts reach the counts of the runes.
+func (c *runeCounts) covers(runes []rune) bool {
+	var need runeCounts
+	for _, r := range runes {
+		b := countBucket(r)
+		if need[b]++; need[b] > c[b] {
+			return false
+		}
+	}
+	return true
+}
+
+// WithFuzzyCounting maintains the counts of the runes on the paths per node
+// (16 bytes) in addition to the masks, so that the fuzzy search prunes the
+// subtrees having the runes searched but fewer of them than the query, and
+// the subtrees of the keys of the runes out of the 64-bit mask such as the digits
+// and '/'. It costs the update of the counts on the path per Add and Remove.
+// The fuzzy search of the interface paths of BenchmarkFuzzyCounting, whose
+// digits repeat heavily, prunes the subtrees without a match (10.8ms to 0.55ms).
+func WithFuzzyCounting() Option {
+	return func(t *Trie) {
+		t.counting = true
+		t.root.extend().counts = new(runeCounts)
+	}
+}
+
+// countSuffixes returns the counts of all the suffixes of the `runes`;
+// the i-th counts are of runes[i:].
+func countSuffixes(runes []rune) []runeCounts {
+	counts := make([]runeCounts, len(runes)+1)
+	for i := len(runes) - 1; i >= 0; i-- {
+		counts[i] = counts[i+1]
+		counts[i].add(runes[i])
+	}
+	return counts
+}
+
+// updateCounts recalculates the counts of the node from its own rune and
+// the counts of its children and returns true if they are changed.
+// It returns false if the node has no counts.
+func updateCounts(n *trieNode) bool {
+	if n.ext == nil || n.ext.counts == nil {
+		return false
+	}
+	var c runeCounts
+	for _, child := range n.children {
+		if child.ext != nil && child.ext.counts != nil {
+			c.max(child.ext.counts)
+		}
+	}
+	if n.rval != nul {
+		c.add(n.rval)
+	}
+	if c == *n.ext.counts {
+		return false
+	}
+	*n.ext.counts = c
+	return true
+}
@@ -0,0 +1,143 @@
+package gtrie
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/neoul/gtrie/gtrietest"
+)
+
+// interfaceKeys returns the paths of `n` interfaces and their subinterfaces,
+// whose digits and slashes repeat heavily, e.g. "/if/1/1/1/1". The queries of
+// the counts, e.g. "1111", need more digits in a key than the two of
+// a list entry of gtrietest.GeneratePathKeys.
+func interfaceKeys(n int, seed int64) []string {
+	rng := rand.New(rand.NewSource(seed))
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("/if/%d/%d/%d/%d", 1+rng.Intn(4), 1+rng.Intn(8), 1+rng.Intn(48), rng.Intn(16))
+	}
+	return keys
+}
+
+// countsOf returns the counts of the runes of the node and the paths under it.
+func countsOf(n *trieNode) runeCounts {
+	var c runeCounts
+	for _, child := range n.children {
+		o := countsOf(child)
+		c.max(&o)
+	}
+	if n.rval != nul {
+		c.add(n.rval)
+	}
+	return c
+}
+
+// checkCounts checks the counts of the runes of the nodes under the node.
+func checkCounts(t *testing.T, node *trieNode) {
+	t.Helper()
+	walkNodes(node, func(n *trieNode) {
+		if n.term {
+			return
+		}
+		if n.ext == nil || n.ext.counts == nil {
+			t.Fatalf("%q (depth %d) has no counts", n.rval, n.depth)
+		}
+		if want := countsOf(n); *n.ext.counts != want {
+			t.Fatalf("counts of %q (depth %d) = %v, want %v", n.rval, n.depth, *n.ext.counts, want)
+		}
+	})
+}
+
+func TestTrie_FuzzyCounting(t *testing.T) {
+	keys := append(interfaceKeys(500, 1), "aa", "abab", "/a/b/a", "")
+	queries := []string{"", "1/1", "11", "1111", "/1/1/1/1", "4/8/48", "2222", "aa", "aaa", "bb", "/if/3/3", "f11/15"}
+	options := map[string][]Option{
+		"plain":  {WithFuzzyCounting()},
+		"atomic": {WithFuzzyCounting(), WithAtomicReads()},
+		"arena":  {WithFuzzyCounting(), WithArena(64)},
+		"wide":   {WithFuzzyCounting(), WithWideMask()},
+	}
+	for name, opts := range options {
+		trie := New(opts...)
+		for i, key := range keys {
+			trie.Add(key, i)
+		}
+		mutations := []func(trie *Trie){
+			func(trie *Trie) {},
+			func(trie *Trie) {
+				for _, key := range keys[:200] {
+					trie.Remove(key)
+				}
+			},
+			func(trie *Trie) { trie.MovePrefix("/if/4/", "/if/44/") },
+			func(trie *Trie) {
+				other := New()
+				for _, key := range interfaceKeys(100, 2) {
+					other.Add(key, key)
+				}
+				trie.Merge(other)
+			},
+			func(trie *Trie) { trie.Compact(0) },
+			func(trie *Trie) { trie.Clear() },
+		}
+		for i, mutate := range mutations {
+			mutate(trie)
+			checkCounts(t, trie.root)
+			model := gtrietest.NewModelTrie()
+			gtrietest.LoadKeys(model, trie.FindByPrefix(""), nil)
+			for _, q := range queries {
+				got, want := sortedKeys(trie.FindByFuzzy(q)), model.FindByFuzzy(q)
+				if !slices.Equal(got, want) {
+					t.Errorf("%s: mutation %d: FindByFuzzy(%q) = %d keys, want %d keys", name, i, q, len(got), len(want))
+				}
+				if got := len(trie.FindByFuzzyMatches(q)); got != len(want) {
+					t.Errorf("%s: mutation %d: FindByFuzzyMatches(%q) = %d matches, want %d", name, i, q, got, len(want))
+				}
+			}
+		}
+	}
+}
+
+func TestTrie_FuzzyCountingPruned(t *testing.T) {
+	trie := New(WithFuzzyCounting())
+	trie.Add("/1/2", nil)
+	trie.Add("/3/1", nil)
+	// the subtrees of a single '1' are pruned for "11".
+	if !fuzzyPruned(trie.root, []rune("11")) {
+		t.Errorf("fuzzyPruned(11) = false, want true")
+	}
+	if fuzzyPruned(trie.root, []rune("/1")) {
+		t.Errorf("fuzzyPruned(/1) = true, want false")
+	}
+	// the counts are saturated, not wrapped.
+	var c runeCounts
+	for i := 0; i < 300; i++ {
+		c.add('1')
+	}
+	if c[countBucket('1')] != 255 {
+		t.Errorf("count of 300 runes = %d, want 255", c[countBucket('1')])
+	}
+}
+
+func BenchmarkFuzzyCounting(b *testing.B) {
+	keys := interfaceKeys(20000, 1)
+	queries := []string{"1/1/1", "2/2/2", "11/11", "4/4/44", "3/33/3", "1111"}
+	for _, counting := range []bool{false, true} {
+		var opts []Option
+		if counting {
+			opts = append(opts, WithFuzzyCounting())
+		}
+		trie := New(opts...)
+		for _, key := range keys {
+			trie.Add(key, true)
+		}
+		b.Run(fmt.Sprintf("counting=%v", counting), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				trie.FindByFuzzy(queries[i%len(queries)])
+			}
+		})
+	}
+}
@@ -1,12 +1,23 @@
 package gtrie
 
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
 // SearchType of Search func
-//  [SearchExactly, SearchByPrefix, SearchLongestMatchingPrefix, SearchMatcingPrefix, SearchApproximate]
+//
+//	[SearchExactly, SearchByPrefix, SearchLongestMatchingPrefix, SearchMatcingPrefix, SearchApproximate,
+//	 SearchAllRelativeKey, SearchSuffix, SearchWildcard, SearchWithinDistance(k)]
 type SearchType int
 
 const (
 	// SearchExactly - finds the key exactly matching to input `key`.
-	SearchExactly = 0
+	SearchExactly SearchType = 0
 
 	// SearchByPrefix - finds all matching keys that starts with the input `key`
 	// The input `key` is the prefix of the keys found.
@@ -24,10 +35,147 @@ const (
 
 	// SearchAllRelativeKey = SearchByPrefix + SearchMatcingPrefix + SearchApproximate
 	SearchAllRelativeKey SearchType = 5
+
+	// SearchSuffix - finds all matching keys that ends with the input `key`.
+	SearchSuffix SearchType = 6
+
+	// SearchWildcard - finds all matching keys with the wildcard pattern `key`.
+	// '*' matches any sequence of runes and '?' matches a single rune.
+	SearchWildcard SearchType = 7
+
+	// searchWithinDistance is the base of the SearchType values built by SearchWithinDistance.
+	// The lower bits of the value hold the edit distance.
+	searchWithinDistance SearchType = 1 << 16
 )
 
+// SearchWithinDistance returns a SearchType that finds all keys
+// within the Levenshtein (edit) distance `k` from the input `key`.
+func SearchWithinDistance(k int) SearchType {
+	if k < 0 {
+		k = 0
+	}
+	if k >= int(searchWithinDistance) {
+		k = int(searchWithinDistance) - 1
+	}
+	return searchWithinDistance | SearchType(k)
+}
+
+// distance returns the edit distance of the SearchType built by SearchWithinDistance.
+func (s SearchType) distance() (int, bool) {
+	if s&^(searchWithinDistance-1) != searchWithinDistance {
+		return 0, false
+	}
+	return int(s & (searchWithinDistance - 1)), true
+}
+
+// ErrUnknownSearchType is returned if the SearchType is not supported.
+var ErrUnknownSearchType = errors.New("gtrie: unknown search type")
+
+var searchTypeNames = [...]string{
+	SearchExactly:               "exactly",
+	SearchByPrefix:              "by-prefix",
+	SearchLongestMatchingPrefix: "longest-matching-prefix",
+	SearchMatcingPrefix:         "matching-prefix",
+	SearchApproximate:           "approximate",
+	SearchAllRelativeKey:        "all-relative-key",
+	SearchSuffix:                "suffix",
+	SearchWildcard:              "wildcard",
+}
+
+const withinDistanceName = "within-distance"
+
+// valid reports whether the SearchType is supported.
+func (s SearchType) valid() bool {
+	if s >= 0 && int(s) < len(searchTypeNames) {
+		return true
+	}
+	_, ok := s.distance()
+	return ok
+}
+
+// String returns the name of the SearchType, e.g. "by-prefix" or "within-distance(2)".
+func (s SearchType) String() string {
+	if s >= 0 && int(s) < len(searchTypeNames) {
+		return searchTypeNames[s]
+	}
+	if k, ok := s.distance(); ok {
+		return withinDistanceName + "(" + strconv.Itoa(k) + ")"
+	}
+	return "SearchType(" + strconv.Itoa(int(s)) + ")"
+}
+
+// ParseSearchType returns the SearchType named `s`.
+// The name is one of the String() results and is case-insensitive.
+func ParseSearchType(s string) (SearchType, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	for i := range searchTypeNames {
+		if searchTypeNames[i] == name {
+			return SearchType(i), nil
+		}
+	}
+	if strings.HasPrefix(name, withinDistanceName+"(") && strings.HasSuffix(name, ")") {
+		arg := name[len(withinDistanceName)+1 : len(name)-1]
+		k, err := strconv.Atoi(arg)
+		if err == nil && k >= 0 && k < int(searchWithinDistance) {
+			return SearchWithinDistance(k), nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %q", ErrUnknownSearchType, s)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s SearchType) MarshalText() ([]byte, error) {
+	if !s.valid() {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownSearchType, int(s))
+	}
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *SearchType) UnmarshalText(text []byte) error {
+	stype, err := ParseSearchType(string(text))
+	if err != nil {
+		return err
+	}
+	*s = stype
+	return nil
+}
+
+// SearchE is the same as Search except that it returns
+// ErrUnknownSearchType for an unsupported stype (SearchType).
+func (t *Trie) SearchE(key string, stype SearchType, opts ...SearchOption) ([]string, error) {
+	if !stype.valid() {
+		return nil, ErrUnknownSearchType
+	}
+	return t.Search(key, stype, opts...), nil
+}
+
+// SearchValuesE is the same as SearchValues except that it returns
+// ErrUnknownSearchType for an unsupported stype (SearchType).
+func (t *Trie) SearchValuesE(key string, stype SearchType, opts ...SearchOption) ([]interface{}, error) {
+	if !stype.valid() {
+		return nil, ErrUnknownSearchType
+	}
+	return t.SearchValues(key, stype, opts...), nil
+}
+
+// SearchAllE is the same as SearchAll except that it returns
+// ErrUnknownSearchType for an unsupported stype (SearchType).
+func (t *Trie) SearchAllE(key string, stype SearchType, opts ...SearchOption) (map[string]interface{}, error) {
+	if !stype.valid() {
+		return nil, ErrUnknownSearchType
+	}
+	return t.SearchAll(key, stype, opts...), nil
+}
+
 // Search finds all matching keys according to stype (SearchType).
-func (t *Trie) Search(key string, stype SearchType) []string {
+// With the search options, e.g. MaxResults and Offset for a page of the keys,
+// it is SearchWithOptions ignoring the error.
+func (t *Trie) Search(key string, stype SearchType, opts ...SearchOption) []string {
+	if len(opts) > 0 {
+		keys, _ := t.SearchWithOptions(key, stype, opts...)
+		return keys
+	}
 	switch stype {
 	case SearchExactly:
 		if _, ok := t.Find(key); ok {
@@ -47,13 +195,31 @@ func (t *Trie) Search(key string, stype SearchType) []string {
 		return t.FindByFuzzy(key)
 	case SearchAllRelativeKey:
 		return t.FindRelative(key)
+	case SearchSuffix:
+		return t.FindBySuffix(key)
+	case SearchWildcard:
+		return t.FindByWildcard(key)
+	default:
+		if k, ok := stype.distance(); ok {
+			return t.FindWithinDistance(key, k)
+		}
 	}
 	return nil
 }
 
 // SearchValues finds all matching keys according to stype (SearchType)
-// and returns all the values of the matching keys.
-func (t *Trie) SearchValues(key string, stype SearchType) []interface{} {
+// and returns all the values of the matching keys. With the search options,
+// the values are returned in the order and the page of the keys of SearchWithOptions.
+func (t *Trie) SearchValues(key string, stype SearchType, opts ...SearchOption) []interface{} {
+	if t == nil {
+		return nil
+	}
+	if len(opts) > 0 {
+		t.rlock()
+		defer t.runlock()
+		nodes, _ := t.searchPage(key, stype, newSearchOptions(opts))
+		return nodeValues(nodes)
+	}
 	switch stype {
 	case SearchExactly:
 		if v, ok := t.Find(key); ok {
@@ -71,12 +237,31 @@ func (t *Trie) SearchValues(key string, stype SearchType) []interface{} {
 		return t.FindByFuzzyValue(key)
 	case SearchAllRelativeKey:
 		return t.FindRelativeValues(key)
+	case SearchSuffix:
+		return t.FindBySuffixValue(key)
+	case SearchWildcard:
+		return t.FindByWildcardValue(key)
+	default:
+		if k, ok := stype.distance(); ok {
+			return t.FindWithinDistanceValue(key, k)
+		}
 	}
 	return nil
 }
 
 // SearchAll finds all matching keys and values according to stype (SearchType).
-func (t *Trie) SearchAll(key string, stype SearchType) map[string]interface{} {
+// With the search options, the keys of the page of SearchWithOptions are returned.
+// Use SearchDetailed to know which search found each key.
+func (t *Trie) SearchAll(key string, stype SearchType, opts ...SearchOption) map[string]interface{} {
+	if t == nil {
+		return nil
+	}
+	if len(opts) > 0 {
+		t.rlock()
+		defer t.runlock()
+		nodes, _ := t.searchPage(key, stype, newSearchOptions(opts))
+		return nodeMap(nodes)
+	}
 	switch stype {
 	case SearchExactly:
 		if v, ok := t.Find(key); ok {
@@ -94,83 +279,339 @@ func (t *Trie) SearchAll(key string, stype SearchType) map[string]interface{} {
 		return t.FindByFuzzyAll(key)
 	case SearchAllRelativeKey:
 		return t.FindRelativeAll(key)
+	case SearchSuffix:
+		return t.FindBySuffixAll(key)
+	case SearchWildcard:
+		return t.FindByWildcardAll(key)
+	default:
+		if k, ok := stype.distance(); ok {
+			return t.FindWithinDistanceAll(key, k)
+		}
 	}
 	return nil
 }
 
-// FindRelative finds all relative keys against to the input `key`.
-// It returns the result of (FindByPrefix + FindMatchingPrefix + FindByFuzzy)
-func (t *Trie) FindRelative(key string) []string {
-	t.mu.RLock()
-	defer t.mu.RUnlock()
-	m := map[string]interface{}{}
-	node := findNode(t.root, []rune(key))
-	if node != nil {
-		m = collectAll(node)
-	}
-	nodes, ok := t.findPrefixMatchNodes(key)
-	if ok {
+// searchOptions is the configuration of SearchWithOptions.
+type searchOptions struct {
+	max    int
+	offset int
+	sorted bool
+	order  SearchOrder
+	desc   bool
+	delim  rune
+	fold   bool
+}
+
+// SearchOrder is the order of the keys sorted by SearchWithOptions.
+// An order by the relevance is left until the search results are scored.
+type SearchOrder int
+
+const (
+	// Lexicographic sorts the keys in lexicographic order.
+	Lexicographic SearchOrder = iota
+	// ByLength sorts the keys by length and then lexicographically like FindByFuzzy.
+	ByLength
+)
+
+// SearchOption configures SearchWithOptions.
+type SearchOption func(o *searchOptions)
+
+// newSearchOptions returns the search options configured by `opts`.
+func newSearchOptions(opts []SearchOption) *searchOptions {
+	o := &searchOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// MaxResults limits the number of the keys returned to `n`.
+// No limit is applied if `n` is zero or less. The keys are sorted in
+// lexicographic order if no order is given, so that the result is deterministic.
+func MaxResults(n int) SearchOption {
+	return func(o *searchOptions) {
+		o.max = n
+	}
+}
+
+// Offset skips the first `n` keys of the sorted result, e.g. Offset(20) and
+// MaxResults(10) for the third page of ten keys. The keys are sorted in
+// lexicographic order if no order is given. No key is returned
+// if `n` is past the end of the result.
+func Offset(n int) SearchOption {
+	return func(o *searchOptions) {
+		o.offset = max(n, 0)
+	}
+}
+
+// Sorted sorts the keys returned in lexicographic order.
+// If MaxResults is also given, the first `n` keys of the sorted result are returned.
+func Sorted() SearchOption {
+	return func(o *searchOptions) {
+		o.sorted = true
+	}
+}
+
+// OrderBy sorts the keys returned in the `order`.
+// If MaxResults is also given, the first `n` keys of the sorted result are returned.
+func OrderBy(order SearchOrder) SearchOption {
+	return func(o *searchOptions) {
+		o.sorted = true
+		o.order = order
+	}
+}
+
+// Descending sorts the keys returned in descending lexicographic order,
+// or in the reverse of the order given by OrderBy.
+// If MaxResults is also given, the last `n` keys of the sorted result are returned
+// from the greatest, e.g. for the "last N keys" view.
+func Descending() SearchOption {
+	return func(o *searchOptions) {
+		o.sorted = true
+		o.desc = true
+	}
+}
+
+// SegmentDelim sets the delimiter of the key segments.
+// '*' and '?' of SearchWildcard never match across the delimiter `r`,
+// so that "/interfaces/*/state" only matches a single segment.
+func SegmentDelim(r rune) SearchOption {
+	return func(o *searchOptions) {
+		o.delim = r
+	}
+}
+
+// CaseFold makes the search case-insensitive under Unicode simple case folding.
+func CaseFold() SearchOption {
+	return func(o *searchOptions) {
+		o.fold = true
+	}
+}
+
+// SearchWithOptions finds all matching keys according to stype (SearchType)
+// and the search options. ErrUnknownSearchType is returned if stype is not supported.
+// In lexicographic order without CaseFold and the key transform, the keys are
+// walked in the order and the walk stops at the end of the page of MaxResults
+// and Offset, except for SearchAllRelativeKey; otherwise the keys are sorted
+// after all of them are found.
+func (t *Trie) SearchWithOptions(key string, stype SearchType, opts ...SearchOption) ([]string, error) {
+	if t == nil {
+		return new(Trie).SearchWithOptions(key, stype, opts...)
+	}
+	t.rlock()
+	defer t.runlock()
+	nodes, err := t.searchPage(key, stype, newSearchOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+	return nodeKeys(nodes), nil
+}
+
+// searchNodes returns all the terminal nodes matching to stype (SearchType).
+func (t *Trie) searchNodes(key string, stype SearchType, o *searchOptions) ([]*trieNode, error) {
+	runes := t.runes(key)
+	switch stype {
+	case SearchExactly:
+		var terms []*trieNode
+		for _, n := range findNodes(t.root, runes, o.fold) {
+			if c, ok := n.children[nul]; ok && c.term {
+				terms = append(terms, c)
+			}
+		}
+		return terms, nil
+	case SearchByPrefix:
+		var terms []*trieNode
+		for _, n := range findNodes(t.root, runes, o.fold) {
+			terms = append(terms, collectNodes(n)...)
+		}
+		return terms, nil
+	case SearchLongestMatchingPrefix:
+		terms := matchingprefixcollect(t.root, runes, o.fold)
+		if len(terms) == 0 {
+			return nil, nil
+		}
+		return terms[len(terms)-1:], nil
+	case SearchMatcingPrefix:
+		return matchingprefixcollect(t.root, runes, o.fold), nil
+	case SearchApproximate:
+		return fuzzycollectNodes(t.root, runes, o.fold), nil
+	case SearchAllRelativeKey:
+		var terms []*trieNode
+		for _, n := range findNodes(t.root, runes, o.fold) {
+			terms = append(terms, collectNodes(n)...)
+		}
+		terms = append(terms, matchingprefixcollect(t.root, runes, o.fold)...)
+		terms = append(terms, fuzzycollectNodes(t.root, runes, o.fold)...)
+		return uniqueNodes(terms), nil
+	case SearchSuffix:
+		return suffixcollect(t.root, runes, o.fold), nil
+	case SearchWildcard:
+		return wildcardcollect(t.root, parseWildcard(string(runes)), o.delim, o.fold), nil
+	default:
+		if k, ok := stype.distance(); ok {
+			return distancecollect(t.root, runes, k, o.fold), nil
+		}
+	}
+	return nil, ErrUnknownSearchType
+}
+
+// uniqueNodes removes the duplicated nodes keeping the first occurrence.
+func uniqueNodes(nodes []*trieNode) []*trieNode {
+	seen := make(map[*trieNode]struct{}, len(nodes))
+	unique := nodes[:0]
+	for _, n := range nodes {
+		if _, ok := seen[n]; ok {
+			continue
+		}
+		seen[n] = struct{}{}
+		unique = append(unique, n)
+	}
+	return unique
+}
+
+// matchingprefixcollect returns all the terminal nodes whose key is a prefix of `key`
+// in order from the shortest to the longest.
+func matchingprefixcollect(node *trieNode, key []rune, fold bool) []*trieNode {
+	if node == nil {
+		return nil
+	}
+	var terms []*trieNode
+	nodes := []*trieNode{node}
+	for _, r := range key {
+		next := make([]*trieNode, 0, len(nodes))
 		for _, n := range nodes {
-			m[n.path] = n.value
+			for cr, c := range n.children {
+				if cr != nul && runeEqual(cr, r, fold) {
+					next = append(next, c)
+					if t, ok := c.children[nul]; ok && t.term {
+						terms = append(terms, t)
+					}
+				}
+			}
+		}
+		if len(next) == 0 {
+			break
 		}
+		nodes = next
 	}
-	keys := t.FindByFuzzy(key)
-	for i := range keys {
-		m[keys[i]], _ = t.Find(key)
+	return terms
+}
+
+// fuzzycollectNodes returns all the terminal nodes matching to `partial` by fuzzy search.
+// The bitmask pruning is only applied to the exact (not case-folded) search.
+func fuzzycollectNodes(node *trieNode, partial []rune, fold bool) []*trieNode {
+	return fuzzycollectNodesCtx(node, partial, fold, nil)
+}
+
+// fuzzycollectNodesCtx is fuzzycollectNodes stopping with the partial result
+// if the canceler `c` is canceled.
+func fuzzycollectNodesCtx(node *trieNode, partial []rune, fold bool, c *canceler) []*trieNode {
+	if node == nil {
+		return nil
 	}
-	keys = make([]string, 0, len(m))
-	for k := range m {
-		keys = append(keys, k)
+	if len(partial) == 0 {
+		return collectNodesCtx(node, c)
 	}
+
+	var (
+		i     int
+		p     potentialSubtree
+		terms []*trieNode
+	)
+
+	potential := []potentialSubtree{potentialSubtree{node: node, idx: 0}}
+	for l := len(potential); l > 0; l = len(potential) {
+		if c.canceled() {
+			break
+		}
+		i = l - 1
+		p = potential[i]
+		potential = potential[:i]
+		if !fold {
+			if fuzzyPruned(p.node, partial[p.idx:]) {
+				continue
+			}
+		}
+
+		if p.node.rval != nul && runeEqual(p.node.rval, partial[p.idx], fold) {
+			p.idx++
+			if p.idx == len(partial) {
+				terms = append(terms, collectNodesCtx(p.node, c)...)
+				continue
+			}
+		}
+
+		for _, child := range p.node.children {
+			potential = append(potential, potentialSubtree{node: child, idx: p.idx})
+		}
+	}
+	return terms
+}
+
+// FindRelative finds all relative keys against to the input `key`
+// in lexicographic order. A key is relative to the input if
+//
+//   - it starts with the input (FindByPrefix, RelativeByPrefix),
+//   - it is a prefix of the input including the input itself
+//     (FindMatchingPrefix, RelativeMatchingPrefix) or
+//   - the input is a subsequence of it, i.e. the runes of the input appear
+//     in the key in the same order (FindByFuzzy, RelativeApproximate).
+//
+// Each key is returned once even if it is found by more than one of them.
+// Use FindRelativeResults to know which of them found the key.
+func (t *Trie) FindRelative(key string) []string {
+	if t == nil {
+		return nil
+	}
+	t.rlock()
+	defer t.runlock()
+	if t.metrics != nil {
+		defer t.observe(MetricSearchRelative, time.Now())
+	}
+	keys := make([]string, 0)
+	for n := range relativecollect(t.root, t.runes(key)) {
+		keys = append(keys, n.key())
+	}
+	sort.Strings(keys)
 	return keys
 }
 
-// FindRelativeValues finds all relative values against to the input `key`.
-// It returns the result of (FindByPrefix + FindMatchingPrefix + FindByFuzzy)
+// FindRelativeValues returns the values of the keys found by FindRelative
+// in lexicographic order of the keys.
 func (t *Trie) FindRelativeValues(key string) []interface{} {
-	t.mu.RLock()
-	defer t.mu.RUnlock()
-	m := map[string]interface{}{}
-	node := findNode(t.root, []rune(key))
-	if node != nil {
-		m = collectAll(node)
-	}
-	nodes, ok := t.findPrefixMatchNodes(key)
-	if ok {
-		for _, n := range nodes {
-			m[n.path] = n.value
-		}
+	if t == nil {
+		return nil
 	}
-	keys := t.FindByFuzzy(key)
-	for i := range keys {
-		m[keys[i]], _ = t.Find(key)
+	t.rlock()
+	defer t.runlock()
+	if t.metrics != nil {
+		defer t.observe(MetricSearchRelative, time.Now())
 	}
-	values := make([]interface{}, 0, len(m))
-	for k := range m {
-		values = append(values, k)
+	found := relativecollect(t.root, t.runes(key))
+	nodes := make([]*trieNode, 0, len(found))
+	for n := range found {
+		nodes = append(nodes, n)
 	}
-	return values
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].key() < nodes[j].key()
+	})
+	return nodeValues(nodes)
 }
 
-// FindRelativeAll finds all relative keys against to the input `key`.
-// It returns the result of (FindByPrefix + FindMatchingPrefix + FindByFuzzy)
+// FindRelativeAll returns the keys found by FindRelative and their values.
 func (t *Trie) FindRelativeAll(key string) map[string]interface{} {
-	t.mu.RLock()
-	defer t.mu.RUnlock()
-	m := make(map[string]interface{})
-	node := findNode(t.root, []rune(key))
-	if node != nil {
-		m = collectAll(node)
-	}
-	nodes, ok := t.findPrefixMatchNodes(key)
-	if ok {
-		for _, n := range nodes {
-			m[n.path] = n.value
-		}
+	if t == nil {
+		return nil
+	}
+	t.rlock()
+	defer t.runlock()
+	if t.metrics != nil {
+		defer t.observe(MetricSearchRelative, time.Now())
 	}
-	fm := t.FindByFuzzyAll(key)
-	for k, v := range fm {
-		m[k] = v
+	found := relativecollect(t.root, t.runes(key))
+	m := make(map[string]interface{}, len(found))
+	for n := range found {
+		m[n.key()] = n.value
 	}
 	return m
 }
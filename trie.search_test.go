@@ -0,0 +1,353 @@
+package gtrie
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// gnmiFixture is the gNMI path data set used in example/main.go.
+var gnmiFixture = []string{
+	"/interfaces",
+	"/interfaces/interface",
+	"/interfaces/interface[name=1/2]",
+	"/interfaces/interface[name=1/2]/state",
+	"/interfaces/interface[name=1/2]/state/oper-status",
+	"/interfaces/interface[name=1/2]/state/enabled",
+	"/interfaces/interface[name=1/1]/state/enabled",
+	"/interfaces/interface[name=1/2]/state/admin-status",
+	"/interfaces/interface[name=1/2]/state/counters",
+	"/interfaces/interface[name=1/3]",
+	"/interfaces/interface[name=1/3]/state",
+	"/interfaces/interface[name=1/3]/state/oper-status",
+	"/interfaces/interface[name=1/3]/state/enabled",
+	"/interfaces/interface[name=1/3]/state/enabled",
+	"/interfaces/interface[name=1/3]/state/admin-status",
+	"/interfaces/interface[name=1/3]/state/counters",
+	"/interfaces/interface/state/counters",
+}
+
+func newGNMITrie() *Trie {
+	trie := New()
+	for _, key := range gnmiFixture {
+		trie.Add(key, true)
+	}
+	return trie
+}
+
+func sortedKeys(keys []string) []string {
+	sort.Strings(keys)
+	return keys
+}
+
+func TestTrie_SearchWithOptions(t *testing.T) {
+	trie := newGNMITrie()
+	tests := []struct {
+		name  string
+		key   string
+		stype SearchType
+		opts  []SearchOption
+		want  []string
+	}{
+		{
+			name:  "Sorted",
+			key:   "/interfaces/interface[name=1/2]/state/",
+			stype: SearchByPrefix,
+			opts:  []SearchOption{Sorted()},
+			want: []string{
+				"/interfaces/interface[name=1/2]/state/admin-status",
+				"/interfaces/interface[name=1/2]/state/counters",
+				"/interfaces/interface[name=1/2]/state/enabled",
+				"/interfaces/interface[name=1/2]/state/oper-status",
+			},
+		},
+		{
+			name:  "MaxResults",
+			key:   "/interfaces/interface[name=1/2]/state/",
+			stype: SearchByPrefix,
+			opts:  []SearchOption{Sorted(), MaxResults(2)},
+			want: []string{
+				"/interfaces/interface[name=1/2]/state/admin-status",
+				"/interfaces/interface[name=1/2]/state/counters",
+			},
+		},
+		{
+			name:  "OrderByLength",
+			key:   "/interfaces/interface[name=1/2]/state/",
+			stype: SearchByPrefix,
+			opts:  []SearchOption{OrderBy(ByLength)},
+			want: []string{
+				"/interfaces/interface[name=1/2]/state/enabled",
+				"/interfaces/interface[name=1/2]/state/counters",
+				"/interfaces/interface[name=1/2]/state/oper-status",
+				"/interfaces/interface[name=1/2]/state/admin-status",
+			},
+		},
+		{
+			name:  "OrderByLengthDescending",
+			key:   "/interfaces/interface[name=1/2]/state/",
+			stype: SearchByPrefix,
+			opts:  []SearchOption{OrderBy(ByLength), Descending(), MaxResults(2)},
+			want: []string{
+				"/interfaces/interface[name=1/2]/state/admin-status",
+				"/interfaces/interface[name=1/2]/state/oper-status",
+			},
+		},
+		{
+			name:  "CaseFold",
+			key:   "/INTERFACES/Interface[NAME=1/3]",
+			stype: SearchExactly,
+			opts:  []SearchOption{CaseFold()},
+			want:  []string{"/interfaces/interface[name=1/3]"},
+		},
+		{
+			name:  "CaseFoldLongestMatchingPrefix",
+			key:   "/Interfaces/Interface[name=1/3]/STATE/absss",
+			stype: SearchLongestMatchingPrefix,
+			opts:  []SearchOption{CaseFold()},
+			want:  []string{"/interfaces/interface[name=1/3]/state"},
+		},
+		{
+			name:  "WithoutCaseFold",
+			key:   "/INTERFACES",
+			stype: SearchByPrefix,
+			want:  []string{},
+		},
+		{
+			name:  "Suffix",
+			key:   "/enabled",
+			stype: SearchSuffix,
+			opts:  []SearchOption{Sorted()},
+			want: []string{
+				"/interfaces/interface[name=1/1]/state/enabled",
+				"/interfaces/interface[name=1/2]/state/enabled",
+				"/interfaces/interface[name=1/3]/state/enabled",
+			},
+		},
+		{
+			name:  "Wildcard",
+			key:   "/interfaces/*/state",
+			stype: SearchWildcard,
+			opts:  []SearchOption{Sorted()},
+			want: []string{
+				"/interfaces/interface[name=1/2]/state",
+				"/interfaces/interface[name=1/3]/state",
+			},
+		},
+		{
+			name:  "WildcardSegmentDelim",
+			key:   "/interfaces/*/state",
+			stype: SearchWildcard,
+			opts:  []SearchOption{Sorted(), SegmentDelim('/')},
+			want:  []string{},
+		},
+		{
+			name:  "WildcardSegmentDelimCounters",
+			key:   "/interfaces/interface*/state/counters",
+			stype: SearchWildcard,
+			opts:  []SearchOption{Sorted(), SegmentDelim('/')},
+			want: []string{
+				"/interfaces/interface/state/counters",
+			},
+		},
+		{
+			name:  "WithinDistance",
+			key:   "/interfaces/interface[name=1/4]",
+			stype: SearchWithinDistance(1),
+			opts:  []SearchOption{Sorted()},
+			want: []string{
+				"/interfaces/interface[name=1/2]",
+				"/interfaces/interface[name=1/3]",
+			},
+		},
+		{
+			name:  "WithinDistanceCaseFold",
+			key:   "/INTERFACES/interface[name=1/4]",
+			stype: SearchWithinDistance(1),
+			opts:  []SearchOption{Sorted(), CaseFold()},
+			want: []string{
+				"/interfaces/interface[name=1/2]",
+				"/interfaces/interface[name=1/3]",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := trie.SearchWithOptions(tt.key, tt.stype, tt.opts...)
+			if err != nil {
+				t.Fatalf("Trie.SearchWithOptions() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Trie.SearchWithOptions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrie_SearchWithOptionsMatchesSearch(t *testing.T) {
+	trie := newGNMITrie()
+	key := "/interfaces/interface[name=1/2]/state"
+	for _, stype := range []SearchType{
+		SearchExactly, SearchByPrefix, SearchLongestMatchingPrefix, SearchMatcingPrefix,
+		SearchApproximate, SearchAllRelativeKey, SearchSuffix, SearchWildcard, SearchWithinDistance(2),
+	} {
+		got, err := trie.SearchWithOptions(key, stype, Sorted())
+		if err != nil {
+			t.Fatalf("Trie.SearchWithOptions(%d) error = %v", stype, err)
+		}
+		want := sortedKeys(append([]string{}, trie.Search(key, stype)...))
+		if len(got) != len(want) || (len(want) > 0 && !reflect.DeepEqual(got, want)) {
+			t.Errorf("Trie.SearchWithOptions(%d) = %v, want %v", stype, got, want)
+		}
+	}
+}
+
+func TestTrie_SearchWithOptionsUnknownType(t *testing.T) {
+	trie := newGNMITrie()
+	for _, stype := range []SearchType{-1, 100, searchWithinDistance << 1} {
+		if _, err := trie.SearchWithOptions("/interfaces", stype); !errors.Is(err, ErrUnknownSearchType) {
+			t.Errorf("Trie.SearchWithOptions(%d) error = %v, want %v", stype, err, ErrUnknownSearchType)
+		}
+	}
+}
+
+func TestTrie_FindByWildcard(t *testing.T) {
+	trie := New()
+	for _, key := range []string{"a*c", "abc", "ac", "abbc", "a?c"} {
+		trie.Add(key, nil)
+	}
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{"a*c", []string{"a*c", "a?c", "abbc", "abc", "ac"}},
+		{"a?c", []string{"a*c", "a?c", "abc"}},
+		{`a\*c`, []string{"a*c"}},
+		{`a\?c`, []string{"a?c"}},
+		{"**", []string{"a*c", "a?c", "abbc", "abc", "ac"}},
+		{"b*", []string{}},
+	}
+	for _, tt := range tests {
+		if got := sortedKeys(trie.FindByWildcard(tt.pattern)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Trie.FindByWildcard(%q) = %v, want %v", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestTrie_FindWithinDistance(t *testing.T) {
+	trie := New()
+	for _, key := range []string{"foo", "fob", "food", "bar", ""} {
+		trie.Add(key, nil)
+	}
+	tests := []struct {
+		key  string
+		k    int
+		want []string
+	}{
+		{"foo", 0, []string{"foo"}},
+		{"foo", 1, []string{"fob", "foo", "food"}},
+		{"fo", 2, []string{"", "fob", "foo", "food"}},
+		{"xyz", 2, []string{}},
+	}
+	for _, tt := range tests {
+		if got := sortedKeys(trie.FindWithinDistance(tt.key, tt.k)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Trie.FindWithinDistance(%q, %d) = %v, want %v", tt.key, tt.k, got, tt.want)
+		}
+	}
+}
+
+func TestSearchType_String(t *testing.T) {
+	tests := []struct {
+		stype SearchType
+		want  string
+	}{
+		{SearchExactly, "exactly"},
+		{SearchByPrefix, "by-prefix"},
+		{SearchLongestMatchingPrefix, "longest-matching-prefix"},
+		{SearchMatcingPrefix, "matching-prefix"},
+		{SearchApproximate, "approximate"},
+		{SearchAllRelativeKey, "all-relative-key"},
+		{SearchSuffix, "suffix"},
+		{SearchWildcard, "wildcard"},
+		{SearchWithinDistance(0), "within-distance(0)"},
+		{SearchWithinDistance(3), "within-distance(3)"},
+		{SearchType(-1), "SearchType(-1)"},
+		{SearchType(42), "SearchType(42)"},
+	}
+	for _, tt := range tests {
+		if got := tt.stype.String(); got != tt.want {
+			t.Errorf("SearchType(%d).String() = %q, want %q", int(tt.stype), got, tt.want)
+		}
+		if !tt.stype.valid() {
+			continue
+		}
+		parsed, err := ParseSearchType(tt.want)
+		if err != nil || parsed != tt.stype {
+			t.Errorf("ParseSearchType(%q) = %v, %v, want %v", tt.want, parsed, err, tt.stype)
+		}
+		text, err := tt.stype.MarshalText()
+		if err != nil {
+			t.Fatalf("SearchType.MarshalText() error = %v", err)
+		}
+		var unmarshaled SearchType
+		if err := unmarshaled.UnmarshalText(text); err != nil || unmarshaled != tt.stype {
+			t.Errorf("SearchType.UnmarshalText(%q) = %v, %v, want %v", text, unmarshaled, err, tt.stype)
+		}
+	}
+}
+
+func TestSearchType_JSON(t *testing.T) {
+	type config struct {
+		Mode SearchType `json:"mode"`
+	}
+	for _, stype := range []SearchType{SearchExactly, SearchMatcingPrefix, SearchWildcard, SearchWithinDistance(2)} {
+		b, err := json.Marshal(config{Mode: stype})
+		if err != nil {
+			t.Fatalf("json.Marshal() error = %v", err)
+		}
+		var c config
+		if err := json.Unmarshal(b, &c); err != nil || c.Mode != stype {
+			t.Errorf("json round-trip of %s = %s, %v, %v", stype, b, c.Mode, err)
+		}
+	}
+	if _, err := json.Marshal(config{Mode: SearchType(42)}); !errors.Is(err, ErrUnknownSearchType) {
+		t.Errorf("json.Marshal(SearchType(42)) error = %v, want %v", err, ErrUnknownSearchType)
+	}
+}
+
+func TestParseSearchType(t *testing.T) {
+	if stype, err := ParseSearchType(" By-Prefix "); err != nil || stype != SearchByPrefix {
+		t.Errorf("ParseSearchType() = %v, %v, want %v", stype, err, SearchByPrefix)
+	}
+	for _, s := range []string{"", "prefix", "within-distance(-1)", "within-distance(x)", "within-distance"} {
+		if _, err := ParseSearchType(s); !errors.Is(err, ErrUnknownSearchType) {
+			t.Errorf("ParseSearchType(%q) error = %v, want %v", s, err, ErrUnknownSearchType)
+		}
+	}
+	var stype SearchType
+	if err := stype.UnmarshalText([]byte("invalid")); !errors.Is(err, ErrUnknownSearchType) {
+		t.Errorf("SearchType.UnmarshalText() error = %v, want %v", err, ErrUnknownSearchType)
+	}
+}
+
+func TestTrie_SearchE(t *testing.T) {
+	trie := newGNMITrie()
+	if keys := trie.Search("/interfaces", SearchType(42)); keys != nil {
+		t.Errorf("Trie.Search() = %v, want nil", keys)
+	}
+	if keys, err := trie.SearchE("/interfaces", SearchType(42)); keys != nil || !errors.Is(err, ErrUnknownSearchType) {
+		t.Errorf("Trie.SearchE() = %v, %v, want nil, %v", keys, err, ErrUnknownSearchType)
+	}
+	if values, err := trie.SearchValuesE("/interfaces", SearchType(-1)); values != nil || !errors.Is(err, ErrUnknownSearchType) {
+		t.Errorf("Trie.SearchValuesE() = %v, %v, want nil, %v", values, err, ErrUnknownSearchType)
+	}
+	if m, err := trie.SearchAllE("/interfaces", SearchType(-1)); m != nil || !errors.Is(err, ErrUnknownSearchType) {
+		t.Errorf("Trie.SearchAllE() = %v, %v, want nil, %v", m, err, ErrUnknownSearchType)
+	}
+	keys, err := trie.SearchE("/interfaces", SearchExactly)
+	if err != nil || !reflect.DeepEqual(keys, []string{"/interfaces"}) {
+		t.Errorf("Trie.SearchE() = %v, %v", keys, err)
+	}
+}
@@ -0,0 +1,103 @@
+package gtrie
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+func TestTrie_WithSegmentInterning(t *testing.T) {
+	keys := append([]string{"", "/", "//", "a", "/a//b/", "苹果/大蒜"}, gnmiFixture...)
+	trie := New(WithSegmentInterning('/'))
+	want := New()
+	for i, key := range keys {
+		trie.Add(key, i)
+		want.Add(key, i)
+	}
+	if got, want := trie.All(), want.All(); !reflect.DeepEqual(got, want) {
+		t.Errorf("All() = %v, want %v", got, want)
+	}
+	if got, want := sortedKeys(trie.FindByPrefix("/interfaces/interface[")), sortedKeys(want.FindByPrefix("/interfaces/interface[")); !reflect.DeepEqual(got, want) {
+		t.Errorf("FindByPrefix() = %v, want %v", got, want)
+	}
+	if key, v, ok := trie.FindLongestMatchingPrefix("/interfaces/interface[name=1/3]/state/x"); !ok || key != "/interfaces/interface[name=1/3]/state" || v != 16 {
+		t.Errorf("FindLongestMatchingPrefix() = %q, %v, %v", key, v, ok)
+	}
+	// "", "a", "b", "苹果", "大蒜", "interfaces", "interface", "interface[name=1", "2]", "1]", "3]",
+	// "state", "oper-status", "enabled", "admin-status" and "counters".
+	if got := len(*trie.segments.segs.Load()); got != 16 {
+		t.Errorf("the number of the segments = %d, want 16", got)
+	}
+
+	for _, key := range keys[:4] {
+		trie.Remove(key)
+		want.Remove(key)
+	}
+	if got, want := trie.All(), want.All(); !reflect.DeepEqual(got, want) {
+		t.Errorf("All() after Remove = %v, want %v", got, want)
+	}
+	trie.Clear()
+	trie.Add("/interfaces/interface", true)
+	if got := trie.Keys(); !reflect.DeepEqual(got, []string{"/interfaces/interface"}) {
+		t.Errorf("Keys() after Clear = %v", got)
+	}
+}
+
+func TestTrie_WithSegmentInterningAtomic(t *testing.T) {
+	trie := New(WithSegmentInterning('/'), WithAtomicReads())
+	var wg sync.WaitGroup
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				for _, key := range trie.FindByPrefix("/dev") {
+					if v, ok := trie.Find(key); !ok || v != key {
+						t.Errorf("Find(%q) = %v, %v", key, v, ok)
+						return
+					}
+				}
+			}
+		}()
+	}
+	for i := 0; i < 200; i++ {
+		key := fmt.Sprintf("/devices/device[id=%d]/state/seg%d", i%10, i)
+		trie.Add(key, key)
+	}
+	wg.Wait()
+}
+
+// segmentPaths is the number of the synthetic paths of the interning benchmark:
+// 1024 devices, 128 interfaces per device and 8 leaves per interface.
+const segmentPaths = 1024 * 128 * 8
+
+func benchmarkSegmentInterning(b *testing.B, opts ...Option) {
+	leaves := []string{"admin-status", "oper-status", "enabled", "mtu", "description", "counters/in-octets", "counters/out-octets", "last-change"}
+	var heap uint64
+	for i := 0; i < b.N; i++ {
+		before := heapAlloc()
+		trie := New(opts...)
+		// the keys are built on the fly to be retained only by the trie.
+		for d := 0; trie.Size() < segmentPaths; d++ {
+			for i := 0; i < 128; i++ {
+				for _, leaf := range leaves {
+					trie.Add(fmt.Sprintf("/devices/device[name=dev%d]/interfaces/interface[name=eth%d]/state/%s", d, i, leaf), nil)
+				}
+			}
+		}
+		heap = heapAlloc() - before
+		runtime.KeepAlive(trie)
+	}
+	b.ReportMetric(float64(heap)/float64(segmentPaths), "heap-bytes/key")
+}
+
+func BenchmarkSegmentInterning(b *testing.B) {
+	b.Run("plain", func(b *testing.B) {
+		benchmarkSegmentInterning(b)
+	})
+	b.Run("interned", func(b *testing.B) {
+		benchmarkSegmentInterning(b, WithSegmentInterning('/'))
+	})
+}
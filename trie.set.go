@@ -0,0 +1,263 @@
+package gtrie
+
+import (
+	"sync"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+// setNode is the node of Set. Unlike trieNode, the membership of a key is
+// marked on the node of its last rune, so that no terminal node, value and
+// key string are stored per key.
+type setNode struct {
+	rval      rune
+	term      bool
+	mask      uint64
+	parent    *setNode
+	children  map[rune]*setNode
+	termCount int
+}
+
+// Set is a set of keys stored in an R-Way Trie without values.
+// It is lighter than Trie storing nil or true values only for the key membership.
+type Set struct {
+	mu   sync.RWMutex
+	root *setNode
+	size atomic.Int64
+}
+
+// NewSet creates a new empty Set.
+func NewSet() *Set {
+	return &Set{root: &setNode{children: make(map[rune]*setNode)}}
+}
+
+// Size returns the number of the keys in the set.
+func (s *Set) Size() int {
+	return int(s.size.Load())
+}
+
+// Add adds the `key` to the set. It returns false if the key already exists.
+func (s *Set) Add(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.add(key)
+}
+
+func (s *Set) add(key string) bool {
+	if n := findSetNode(s.root, key); n != nil && n.term {
+		return false
+	}
+	runes := []rune(key)
+	node := s.root
+	node.mask |= maskruneslice(runes)
+	node.termCount++
+	for i, r := range runes {
+		c, ok := node.children[r]
+		if !ok {
+			c = &setNode{rval: r, parent: node, children: make(map[rune]*setNode)}
+			node.children[r] = c
+		}
+		c.mask |= maskruneslice(runes[i:])
+		c.termCount++
+		node = c
+	}
+	node.term = true
+	s.size.Add(1)
+	return true
+}
+
+// Has returns true if the `key` is in the set.
+func (s *Set) Has(key string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	n := findSetNode(s.root, key)
+	return n != nil && n.term
+}
+
+// Remove removes the `key` from the set. It returns false if the key does not exist.
+func (s *Set) Remove(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	node := findSetNode(s.root, key)
+	if node == nil || !node.term {
+		return false
+	}
+	node.term = false
+	s.size.Add(-1)
+	for ; node.parent != nil; node = node.parent {
+		node.termCount--
+		if node.termCount <= 0 {
+			// the children of the node detached are left as they are.
+			delete(node.parent.children, node.rval)
+		}
+	}
+	node.termCount--
+	// recalculate the masks of the remaining nodes on the path.
+	for n := findSetNodeLongest(s.root, key); n != nil; n = n.parent {
+		n.mask = uint64(1) << uint64(n.rval-'a')
+		for _, c := range n.children {
+			n.mask |= c.mask
+		}
+	}
+	return true
+}
+
+// KeysByPrefix returns all the keys starting with `prefix`.
+func (s *Set) KeysByPrefix(prefix string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	node := findSetNode(s.root, prefix)
+	if node == nil {
+		return nil
+	}
+	return setcollect(node, []rune(prefix), nil)
+}
+
+// Keys returns all the keys in the set.
+func (s *Set) Keys() []string {
+	return s.KeysByPrefix("")
+}
+
+// FuzzyKeys performs a fuzzy search like Trie.FindByFuzzy.
+// It returns all the keys having the runes of `key` in the same order.
+func (s *Set) FuzzyKeys(key string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	partial := []rune(key)
+	if len(partial) == 0 {
+		return setcollect(s.root, nil, nil)
+	}
+	var keys []string
+	var walk func(n *setNode, path []rune, idx int)
+	walk = func(n *setNode, path []rune, idx int) {
+		m := maskruneslice(partial[idx:])
+		if n.mask&m != m {
+			return
+		}
+		if n.parent != nil && n.rval == partial[idx] {
+			idx++
+			if idx == len(partial) {
+				keys = setcollect(n, path, keys)
+				return
+			}
+		}
+		for r, c := range n.children {
+			walk(c, append(path, r), idx)
+		}
+	}
+	walk(s.root, nil, 0)
+	return keys
+}
+
+// LongestMatchingPrefix returns the longest key of the set that is a prefix of the `key`.
+func (s *Set) LongestMatchingPrefix(key string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	found := -1
+	node := s.root
+	if node.term {
+		found = 0
+	}
+	for i := 0; i < len(key); {
+		r, size := utf8.DecodeRuneInString(key[i:])
+		n, ok := node.children[r]
+		if !ok {
+			break
+		}
+		i += size
+		if n.term {
+			found = i
+		}
+		node = n
+	}
+	if found < 0 {
+		return "", false
+	}
+	return key[:found], true
+}
+
+// Union returns a new Set having the keys in the set or the `other`.
+func (s *Set) Union(other *Set) *Set {
+	u := NewSet()
+	for _, key := range s.Keys() {
+		u.add(key)
+	}
+	for _, key := range other.Keys() {
+		u.add(key)
+	}
+	return u
+}
+
+// Intersect returns a new Set having the keys in both of the set and the `other`.
+func (s *Set) Intersect(other *Set) *Set {
+	u := NewSet()
+	for _, key := range s.Keys() {
+		if other.Has(key) {
+			u.add(key)
+		}
+	}
+	return u
+}
+
+// Difference returns a new Set having the keys in the set but not in the `other`.
+func (s *Set) Difference(other *Set) *Set {
+	u := NewSet()
+	for _, key := range s.Keys() {
+		if !other.Has(key) {
+			u.add(key)
+		}
+	}
+	return u
+}
+
+// ToTrie returns a new Trie having all the keys of the set with the same `value`.
+func (s *Set) ToTrie(value interface{}) *Trie {
+	t := New()
+	for _, key := range s.Keys() {
+		t.add(key, value)
+	}
+	return t
+}
+
+// KeySet returns a new Set having all the keys of the trie.
+func (t *Trie) KeySet() *Set {
+	s := NewSet()
+	for _, key := range t.Keys() {
+		s.add(key)
+	}
+	return s
+}
+
+// findSetNode finds the node reachable from the node by the runes of `key`.
+func findSetNode(node *setNode, key string) *setNode {
+	for _, r := range key {
+		if node = node.children[r]; node == nil {
+			return nil
+		}
+	}
+	return node
+}
+
+// findSetNodeLongest finds the deepest node reachable from the node by the runes of `key`.
+func findSetNodeLongest(node *setNode, key string) *setNode {
+	for _, r := range key {
+		n, ok := node.children[r]
+		if !ok {
+			break
+		}
+		node = n
+	}
+	return node
+}
+
+// setcollect appends all the keys under the node to `keys`.
+// `path` is the runes from the root to the node.
+func setcollect(node *setNode, path []rune, keys []string) []string {
+	if node.term {
+		keys = append(keys, string(path))
+	}
+	for r, c := range node.children {
+		keys = setcollect(c, append(path, r), keys)
+	}
+	return keys
+}
@@ -0,0 +1,138 @@
+package gtrie
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func newSet(keys ...string) *Set {
+	s := NewSet()
+	for _, key := range keys {
+		s.Add(key)
+	}
+	return s
+}
+
+func TestSet(t *testing.T) {
+	s := newSet(gnmiFixture...)
+	if s.Size() != 16 {
+		t.Errorf("Size() = %d, want 16", s.Size())
+	}
+	if s.Add("/interfaces") {
+		t.Errorf("Add() of an existing key = true")
+	}
+	if !s.Has("/interfaces/interface") || s.Has("/interfaces/interf") || s.Has("/none") {
+		t.Errorf("Has() fails")
+	}
+	trie := newGNMITrie()
+	for _, prefix := range []string{"", "/interfaces/interface[name=1/2]", "/none"} {
+		if got, want := sortedKeys(s.KeysByPrefix(prefix)), sortedKeys(trie.FindByPrefix(prefix)); !reflect.DeepEqual(got, want) {
+			t.Errorf("KeysByPrefix(%q) = %v, want %v", prefix, got, want)
+		}
+	}
+	for _, key := range []string{"", "state", "1/3]count", "/xyz"} {
+		if got, want := sortedKeys(s.FuzzyKeys(key)), sortedKeys(trie.FindByFuzzy(key)); !reflect.DeepEqual(got, want) {
+			t.Errorf("FuzzyKeys(%q) = %v, want %v", key, got, want)
+		}
+	}
+	for _, key := range []string{"/interfaces/interface[name=1/2]/config", "/interfaces/interfac", "/none"} {
+		want, _, wantOK := trie.FindLongestMatchingPrefix(key)
+		if got, ok := s.LongestMatchingPrefix(key); got != want || ok != wantOK {
+			t.Errorf("LongestMatchingPrefix(%q) = %q, %v, want %q, %v", key, got, ok, want, wantOK)
+		}
+	}
+
+	for _, key := range gnmiFixture[:9] {
+		s.Remove(key)
+		trie.Remove(key)
+	}
+	if s.Remove("/interfaces") {
+		t.Errorf("Remove() of a removed key = true")
+	}
+	if got, want := sortedKeys(s.Keys()), sortedKeys(trie.Keys()); !reflect.DeepEqual(got, want) {
+		t.Errorf("Keys() after Remove = %v, want %v", got, want)
+	}
+	if got, want := sortedKeys(s.FuzzyKeys("1/2")), sortedKeys(trie.FindByFuzzy("1/2")); !reflect.DeepEqual(got, want) {
+		t.Errorf("FuzzyKeys() after Remove = %v, want %v", got, want)
+	}
+	if s.Size() != trie.Size() {
+		t.Errorf("Size() after Remove = %d, want %d", s.Size(), trie.Size())
+	}
+	if got, ok := newSet("", "ü", "üb").LongestMatchingPrefix("übx"); got != "üb" || !ok {
+		t.Errorf("LongestMatchingPrefix() = %q, %v, want üb", got, ok)
+	}
+}
+
+func TestSet_Operations(t *testing.T) {
+	a := newSet("a", "ab", "abc", "b")
+	b := newSet("ab", "b", "c")
+	tests := []struct {
+		name string
+		got  *Set
+		want []string
+	}{
+		{"Union", a.Union(b), []string{"a", "ab", "abc", "b", "c"}},
+		{"Intersect", a.Intersect(b), []string{"ab", "b"}},
+		{"Difference", a.Difference(b), []string{"a", "abc"}},
+		{"DifferenceSelf", a.Difference(a), nil},
+	}
+	for _, tt := range tests {
+		if got := sortedKeys(tt.got.Keys()); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s() = %v, want %v", tt.name, got, tt.want)
+		}
+		if tt.got.Size() != len(tt.want) {
+			t.Errorf("%s().Size() = %d, want %d", tt.name, tt.got.Size(), len(tt.want))
+		}
+	}
+}
+
+func TestSet_Conversion(t *testing.T) {
+	trie := newGNMITrie()
+	s := trie.KeySet()
+	if got, want := sortedKeys(s.Keys()), sortedKeys(trie.Keys()); !reflect.DeepEqual(got, want) {
+		t.Errorf("KeySet() = %v, want %v", got, want)
+	}
+	if got, want := s.ToTrie(true).All(), trie.All(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ToTrie() = %v, want %v", got, want)
+	}
+}
+
+func benchmarkMembership(b *testing.B, add func(keys []string) interface{}) {
+	keys := make([]string, 0, 100000)
+	for i := 0; i < cap(keys); i++ {
+		keys = append(keys, fmt.Sprintf("/key/%x/%d", i*7919, i))
+	}
+	var before, after runtime.MemStats
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		v := add(keys)
+		runtime.GC()
+		runtime.ReadMemStats(&after)
+		runtime.KeepAlive(v)
+	}
+	b.ReportMetric(float64(after.HeapAlloc-before.HeapAlloc)/float64(len(keys)), "heap-bytes/key")
+}
+
+func BenchmarkMembershipTrie(b *testing.B) {
+	benchmarkMembership(b, func(keys []string) interface{} {
+		t := New()
+		for _, key := range keys {
+			t.Add(key, nil)
+		}
+		return t
+	})
+}
+
+func BenchmarkMembershipSet(b *testing.B) {
+	benchmarkMembership(b, func(keys []string) interface{} {
+		s := NewSet()
+		for _, key := range keys {
+			s.Add(key)
+		}
+		return s
+	})
+}
@@ -0,0 +1,44 @@
+package gtrie
+
+import "slices"
+
+// WithChildCapacity sets the initial capacity of the children map of
+// the interior nodes to `n` for the workloads with a known high fanout,
+// so that the maps do not grow step by step.
+func WithChildCapacity(n int) Option {
+	return func(t *Trie) {
+		if n < 0 {
+			n = 0
+		}
+		t.childCap = n
+	}
+}
+
+// Shrink reallocates the children maps (and the children in order of
+// WithSortedChildren) of all the nodes sized to their current
+// number of the children. Go maps never shrink, so the trie that held many keys
+// keeps the memory of the maps after the keys are removed until Shrink is called.
+// Shrink takes the write lock for a walk of the whole trie.
+func (t *Trie) Shrink() {
+	if t == nil {
+		return
+	}
+	t.lock()
+	defer t.unlock()
+	nodes := []*trieNode{t.writableRoot()}
+	for l := len(nodes); l != 0; l = len(nodes) {
+		n := nodes[l-1]
+		nodes = nodes[:l-1]
+		children := make(map[rune]*trieNode, len(n.children))
+		for r, c := range n.children {
+			c = t.writable(n, c)
+			children[r] = c
+			nodes = append(nodes, c)
+		}
+		n.children = children
+		if sorted := n.ordered(); sorted != nil {
+			sorted := slices.Clone(*sorted)
+			n.ext.sorted = &sorted
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package gtrie
+
+import (
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func heapAlloc() uint64 {
+	var ms runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&ms)
+	return ms.HeapAlloc
+}
+
+func TestTrie_Shrink(t *testing.T) {
+	const fanout = 5000
+	trie := New()
+	keys := make([]string, 0, fanout)
+	for i := 0; i < fanout; i++ {
+		keys = append(keys, "/"+string(rune(0x4e00+i)))
+	}
+	for _, key := range keys {
+		trie.Add(key, nil)
+	}
+	for _, key := range keys[10:] {
+		trie.Remove(key)
+	}
+	before := heapAlloc()
+	trie.Shrink()
+	after := heapAlloc()
+	t.Logf("heap before Shrink %d bytes, after %d bytes", before, after)
+	if after >= before {
+		t.Errorf("Shrink() does not free the memory: before %d, after %d", before, after)
+	}
+	if got := sortedKeys(trie.Keys()); !reflect.DeepEqual(got, keys[:10]) {
+		t.Errorf("Keys() after Shrink = %v, want %v", got, keys[:10])
+	}
+	trie.Add(keys[10], nil)
+	if trie.Size() != 11 || !trie.HasPrefix(keys[10]) {
+		t.Errorf("Add() after Shrink fails")
+	}
+}
+
+func TestTrie_WithChildCapacity(t *testing.T) {
+	trie := New(WithChildCapacity(64))
+	for _, key := range gnmiFixture {
+		trie.Add(key, true)
+	}
+	if got, want := trie.All(), newGNMITrie().All(); !reflect.DeepEqual(got, want) {
+		t.Errorf("All() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,30 @@
+package gtrie
+
+// WithSkipUnchanged makes Add skip the write of a value to an existing key
+// if `eq` reports the old and the new value are equal. The key skipped is
+// left as it is: its UpdatedAt of WithTimestamps is not bumped, its place
+// in the insertion order is not refreshed, its priority of AddWithPriority
+// is kept unless set by AddWithPriority, and MetricAdd is not counted.
+// Merge skips the keys of the same value in the same way.
+// `eq` is called under the write lock and must not use the trie.
+func WithSkipUnchanged(eq func(old, new interface{}) bool) Option {
+	return func(t *Trie) {
+		t.unchanged = eq
+	}
+}
+
+// AddChanged adds the key and value like Add and returns true if the value
+// is written, or false if the key is rejected by the limits of the trie
+// or the value is unchanged by WithSkipUnchanged.
+func (t *Trie) AddChanged(key string, value interface{}) bool {
+	if t == nil {
+		return false
+	}
+	t.lock()
+	changed, err := t.addChanged(key, value)
+	t.unlock()
+	if err != nil {
+		t.reject(key, err)
+	}
+	return changed
+}
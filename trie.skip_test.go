@@ -0,0 +1,95 @@
+package gtrie
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTrie_SkipUnchanged(t *testing.T) {
+	now := time.Unix(1000, 0)
+	trie := New(
+		WithSkipUnchanged(func(old, new interface{}) bool { return old == new }),
+		WithTimestamps(), WithClock(func() time.Time { return now }),
+		WithInsertionOrder(RefreshOnOverwrite()),
+	)
+	sink := newFakeSink()
+	trie.Instrument(sink)
+
+	if !trie.AddChanged("/a", 1) || !trie.AddChanged("/b", 2) {
+		t.Fatalf("AddChanged() of the new keys = false")
+	}
+	now = now.Add(time.Second)
+	if trie.AddChanged("/a", 1) {
+		t.Errorf("AddChanged() of the same value = true")
+	}
+	trie.Add("/a", 1)
+	if got := sink.counters[MetricAdd]; got != 2 {
+		t.Errorf("MetricAdd = %d, want 2 for the writes skipped", got)
+	}
+	if meta, _ := trie.Meta("/a"); !meta.UpdatedAt.Equal(time.Unix(1000, 0)) {
+		t.Errorf("UpdatedAt = %v, want not bumped", meta.UpdatedAt)
+	}
+	if got := trie.KeysInOrder(); !reflect.DeepEqual(got, []string{"/a", "/b"}) {
+		t.Errorf("KeysInOrder() = %v, want not refreshed", got)
+	}
+
+	if !trie.AddChanged("/a", 3) {
+		t.Errorf("AddChanged() of a new value = false")
+	}
+	if got := sink.counters[MetricAdd]; got != 3 {
+		t.Errorf("MetricAdd = %d, want 3", got)
+	}
+	if meta, _ := trie.Meta("/a"); !meta.UpdatedAt.Equal(now) {
+		t.Errorf("UpdatedAt = %v, want %v", meta.UpdatedAt, now)
+	}
+	if got := trie.KeysInOrder(); !reflect.DeepEqual(got, []string{"/b", "/a"}) {
+		t.Errorf("KeysInOrder() = %v, want refreshed", got)
+	}
+
+	other := New()
+	other.Add("/a", 3)
+	other.Add("/b", 4)
+	if n := trie.Merge(other); n != 0 {
+		t.Errorf("Merge() = %d, want 0", n)
+	}
+	if got := sink.counters[MetricAdd]; got != 4 {
+		t.Errorf("MetricAdd after Merge() = %d, want 4 for /b only", got)
+	}
+}
+
+func TestTrie_AddChanged(t *testing.T) {
+	var rejected []string
+	trie := New(WithMaxKeys(1), WithOnReject(func(key string, err error) {
+		rejected = append(rejected, key)
+	}))
+	if !trie.AddChanged("/a", 1) {
+		t.Errorf("AddChanged() = false")
+	}
+	// without WithSkipUnchanged, the same value is written again.
+	if !trie.AddChanged("/a", 1) {
+		t.Errorf("AddChanged() of the same value = false")
+	}
+	if trie.AddChanged("/b", 2) || !reflect.DeepEqual(rejected, []string{"/b"}) {
+		t.Errorf("AddChanged() over the limit is not rejected: %v", rejected)
+	}
+}
+
+func TestTrie_SkipUnchangedPriority(t *testing.T) {
+	trie := New(WithSkipUnchanged(func(old, new interface{}) bool { return old == new }))
+	trie.AddWithPriority("/a", "a", 10)
+	trie.AddWithPriority("/a/b", "b", 0)
+	if key, _, _ := trie.FindBestMatchingPrefix("/a/b/c"); key != "/a" {
+		t.Errorf("FindBestMatchingPrefix() = %q, want /a", key)
+	}
+	// Add of the same value is skipped with the priority.
+	trie.Add("/a", "a")
+	if key, _, _ := trie.FindBestMatchingPrefix("/a/b/c"); key != "/a" {
+		t.Errorf("FindBestMatchingPrefix() = %q, want /a", key)
+	}
+	// AddWithPriority of the same value still sets the priority.
+	trie.AddWithPriority("/a", "a", 0)
+	if key, _, _ := trie.FindBestMatchingPrefix("/a/b/c"); key != "/a/b" {
+		t.Errorf("FindBestMatchingPrefix() = %q, want /a/b", key)
+	}
+}
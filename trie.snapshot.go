@@ -0,0 +1,50 @@
+package gtrie
+
+import (
+	"iter"
+	"sort"
+)
+
+// KeysSnapshot returns a copy of all the keys starting with `prefix`
+// in lexicographic order. The keys are collected under the read lock
+// and the returned slice is never affected by the later mutations.
+func (t *Trie) KeysSnapshot(prefix string) []string {
+	if t == nil {
+		return nil
+	}
+	t.rlock()
+	node := findNode(t.root, t.canonical(prefix))
+	var keys []string
+	if node != nil {
+		keys = nodeKeys(collectNodes(node))
+	}
+	t.runlock()
+	sort.Strings(keys)
+	return keys
+}
+
+// IterSnapshot returns an iterator over the keys and values starting with `prefix`
+// in lexicographic order of the keys. The pairs are copied under the read lock
+// when the iteration starts and then yielded without holding any lock,
+// so that a slow consumer never stalls the writers.
+// The trade-off is the memory for the copy and that the mutations made
+// during the iteration are not observed, unlike FindByPrefix and its variants
+// that reflect the current trie but hold the read lock until they return.
+func (t *Trie) IterSnapshot(prefix string) iter.Seq2[string, interface{}] {
+	if t == nil {
+		return noKVs
+	}
+	return func(yield func(string, interface{}) bool) {
+		t.rlock()
+		var kvs []KV
+		if node := findNode(t.root, t.canonical(prefix)); node != nil {
+			kvs = nodeKVs(collectNodes(node))
+		}
+		t.runlock()
+		for _, kv := range kvs {
+			if !yield(kv.Key, kv.Value) {
+				return
+			}
+		}
+	}
+}
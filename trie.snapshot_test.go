@@ -0,0 +1,74 @@
+package gtrie
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTrie_KeysSnapshot(t *testing.T) {
+	trie := newGNMITrie()
+	want := trie.FindByPrefix("/interfaces/interface[name=1/")
+	sortedKeys(want)
+	got := trie.KeysSnapshot("/interfaces/interface[name=1/")
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("KeysSnapshot() = %v, want %v", got, want)
+	}
+	trie.Add("/interfaces/interface[name=1/9]", true)
+	if len(got) != len(want) {
+		t.Errorf("KeysSnapshot() is affected by Add()")
+	}
+	if got := trie.KeysSnapshot("/none"); len(got) != 0 {
+		t.Errorf("KeysSnapshot() = %v, want empty", got)
+	}
+}
+
+func TestTrie_IterSnapshot(t *testing.T) {
+	trie := New()
+	for i := 0; i < 10; i++ {
+		trie.Add(fmt.Sprintf("/k/%d", i), i)
+	}
+	var keys []string
+	for k, v := range trie.IterSnapshot("/k/") {
+		if want := fmt.Sprintf("/k/%d", v); k != want {
+			t.Errorf("IterSnapshot() yields %s, %v", k, v)
+		}
+		keys = append(keys, k)
+		if len(keys) == 3 {
+			break
+		}
+	}
+	if want := []string{"/k/0", "/k/1", "/k/2"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("IterSnapshot() = %v, want %v", keys, want)
+	}
+}
+
+func TestTrie_IterSnapshotConcurrentMutation(t *testing.T) {
+	trie := New()
+	for i := 0; i < 10; i++ {
+		trie.Add(fmt.Sprintf("/k/%d", i), i)
+	}
+	n := 0
+	for k := range trie.IterSnapshot("/k/") {
+		// a slow consumer must not block the writers.
+		done := make(chan struct{})
+		go func() {
+			trie.Remove(k)
+			trie.Add("/k/new"+k, n)
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatalf("writer is blocked by IterSnapshot")
+		}
+		n++
+	}
+	if n != 10 {
+		t.Errorf("IterSnapshot() yields %d pairs, want 10", n)
+	}
+	if got := len(trie.FindByPrefix("/k/new")); got != 10 {
+		t.Errorf("FindByPrefix() returns %d keys, want 10", got)
+	}
+}
@@ -0,0 +1,137 @@
+package gtrie
+
+import (
+	"iter"
+	"sort"
+)
+
+// WithSortedChildren maintains the children of every node in rune order
+// on Add and Remove, so that the ordered searches (e.g. FindByPrefixDesc and
+// SearchWithOptions with Sorted) walk the children without sorting them per node.
+// It costs a slice of the children per node (about 32 bytes and 8 bytes per child)
+// and a binary search and an insertion into the slice per node created or removed.
+// The sorted prefix search of BenchmarkSortedFindByPrefix is then faster than
+// even the unsorted FindByPrefix walking the children maps (2.1ms to 13ms),
+// while sorting on the visit takes 15ms or more.
+func WithSortedChildren() Option {
+	return func(t *Trie) {
+		t.sorted = true
+		t.root.extend().sorted = new([]*trieNode)
+	}
+}
+
+// sortedChildren returns the children of the node in rune order,
+// descending if `desc` is true. The terminal child (nul) comes first
+// in ascending order since a key precedes the longer keys it prefixes.
+// The children maintained in order (WithSortedChildren) are returned as is
+// in ascending order; they must not be modified.
+func sortedChildren(node *trieNode, desc bool) []*trieNode {
+	ordered := node.ordered()
+	if ordered != nil && !desc {
+		return *ordered
+	}
+	children := make([]*trieNode, 0, len(node.children))
+	if ordered != nil {
+		sorted := *ordered
+		for i := len(sorted) - 1; i >= 0; i-- {
+			children = append(children, sorted[i])
+		}
+		return children
+	}
+	for _, c := range node.children {
+		children = append(children, c)
+	}
+	sort.Slice(children, func(i, j int) bool {
+		if desc {
+			return children[i].rval > children[j].rval
+		}
+		return children[i].rval < children[j].rval
+	})
+	return children
+}
+
+// walkSorted calls `fn` for the terminal nodes under the node in lexicographic order
+// of the keys (descending if `desc` is true) until `fn` returns false.
+func walkSorted(node *trieNode, desc bool, fn func(n *trieNode) bool) {
+	walkSortedStack([]*trieNode{node}, desc, fn)
+}
+
+// walkSortedStack is walkSorted of the stack of the nodes to walk from the top,
+// which are walked in order if the top is the first node.
+func walkSortedStack(nodes []*trieNode, desc bool, fn func(n *trieNode) bool) {
+	for l := len(nodes); l != 0; l = len(nodes) {
+		n := nodes[l-1]
+		nodes = nodes[:l-1]
+		if n.term {
+			if !fn(n) {
+				return
+			}
+			continue
+		}
+		// push the children in the opposite order so that the first is popped first.
+		if ordered := n.ordered(); ordered != nil {
+			sorted := *ordered
+			if desc {
+				nodes = append(nodes, sorted...)
+			} else {
+				for i := len(sorted) - 1; i >= 0; i-- {
+					nodes = append(nodes, sorted[i])
+				}
+			}
+			continue
+		}
+		children := sortedChildren(n, !desc)
+		nodes = append(nodes, children...)
+	}
+}
+
+// sortedcollect returns up to `max` terminal nodes under the node in lexicographic order
+// of the keys (descending if `desc` is true). No limit is applied if `max` is zero or less.
+func sortedcollect(node *trieNode, desc bool, max int) []*trieNode {
+	terms := make([]*trieNode, 0, node.termCount)
+	walkSorted(node, desc, func(n *trieNode) bool {
+		terms = append(terms, n)
+		return max <= 0 || len(terms) < max
+	})
+	return terms
+}
+
+// FindByPrefixDesc returns all the keys starting with `prefix`
+// in descending lexicographic order, or the order of WithKeyLess.
+func (t *Trie) FindByPrefixDesc(prefix string) []string {
+	if t == nil {
+		return nil
+	}
+	t.rlock()
+	defer t.runlock()
+	node := findNode(t.root, t.canonical(prefix))
+	if node == nil {
+		return nil
+	}
+	if t.keyLess != nil {
+		return nodeKeys(selectNodes(collectNodes(node), 0, t.keyLess, true))
+	}
+	return nodeKeys(sortedcollect(node, true, 0))
+}
+
+// IterByPrefixDesc returns an iterator over the keys and values starting with `prefix`
+// in descending lexicographic order of the keys, from the greatest key.
+// The keys are visited lazily, so breaking the loop early costs only the keys yielded,
+// except in the order of WithKeyLess, which sorts all the keys first.
+// The read lock is held during the iteration; the trie must not be modified in the loop.
+func (t *Trie) IterByPrefixDesc(prefix string) iter.Seq2[string, interface{}] {
+	if t == nil {
+		return noKVs
+	}
+	return func(yield func(string, interface{}) bool) {
+		t.rlock()
+		defer t.runlock()
+		node := findNode(t.root, t.canonical(prefix))
+		if node == nil {
+			return
+		}
+		t.walkKeyOrder(node, true, func(n *trieNode) bool {
+			return yield(n.key(), n.value)
+		})
+	}
+}
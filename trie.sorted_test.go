@@ -0,0 +1,157 @@
+package gtrie
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"testing"
+)
+
+func TestTrie_FindByPrefixDesc(t *testing.T) {
+	for _, trie := range []*Trie{newGNMITrie(), New()} {
+		addFromFile(trie, "fixtures/test.txt")
+		trie.Add("", true)
+		for _, prefix := range []string{"", "/interfaces/interface", "/interfaces/interface[name=1/2]/", "ba", "/none"} {
+			want := sortedKeys(trie.FindByPrefix(prefix))
+			sort.Sort(sort.Reverse(sort.StringSlice(want)))
+			got := trie.FindByPrefixDesc(prefix)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("FindByPrefixDesc(%q) = %v, want %v", prefix, got, want)
+			}
+		}
+	}
+}
+
+func TestTrie_IterByPrefixDesc(t *testing.T) {
+	trie := newGNMITrie()
+	want := trie.FindByPrefixDesc("/interfaces/interface[name=1/3]")
+	var got []string
+	for k, v := range trie.IterByPrefixDesc("/interfaces/interface[name=1/3]") {
+		if v != true {
+			t.Errorf("IterByPrefixDesc() yields %s, %v", k, v)
+		}
+		got = append(got, k)
+		if len(got) == 3 {
+			break
+		}
+	}
+	if !reflect.DeepEqual(got, want[:3]) {
+		t.Errorf("IterByPrefixDesc() = %v, want %v", got, want[:3])
+	}
+	for k := range trie.IterByPrefixDesc("/none") {
+		t.Errorf("IterByPrefixDesc() yields %s", k)
+	}
+}
+
+func TestTrie_SearchWithOptions_Descending(t *testing.T) {
+	trie := newGNMITrie()
+	all := trie.FindByPrefixDesc("/interfaces/interface[name=1/2]")
+	got, err := trie.SearchWithOptions("/interfaces/interface[name=1/2]", SearchByPrefix, Descending(), MaxResults(2))
+	if err != nil || !reflect.DeepEqual(got, all[:2]) {
+		t.Errorf("SearchWithOptions(Descending, MaxResults) = %v, %v, want %v", got, err, all[:2])
+	}
+	asc, _ := trie.SearchWithOptions("/interfaces/interface[name=1/2]", SearchByPrefix, Sorted(), MaxResults(2))
+	if want := sortedKeys(trie.FindByPrefix("/interfaces/interface[name=1/2]"))[:2]; !reflect.DeepEqual(asc, want) {
+		t.Errorf("SearchWithOptions(Sorted, MaxResults) = %v, want %v", asc, want)
+	}
+	got, err = trie.SearchWithOptions("/interfaces/interface[name=1/?]/state", SearchWildcard, Descending())
+	want := []string{
+		"/interfaces/interface[name=1/3]/state",
+		"/interfaces/interface[name=1/2]/state",
+	}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("SearchWithOptions(SearchWildcard, Descending) = %v, %v, want %v", got, err, want)
+	}
+}
+
+// checkSorted checks the children in order of the nodes under the node.
+func checkSorted(t *testing.T, node *trieNode) {
+	t.Helper()
+	walkNodes(node, func(n *trieNode) {
+		if n.term {
+			return
+		}
+		if n.ordered() == nil {
+			t.Fatalf("%q (depth %d) has no children in order", n.rval, n.depth)
+		}
+		want := make([]*trieNode, 0, len(n.children))
+		for _, c := range n.children {
+			want = append(want, c)
+		}
+		sort.Slice(want, func(i, j int) bool { return want[i].rval < want[j].rval })
+		if !slices.Equal(*n.ordered(), want) {
+			t.Fatalf("children in order of %q (depth %d) = %d nodes, want %d nodes", n.rval, n.depth, len(*n.ordered()), len(want))
+		}
+	})
+}
+
+func TestTrie_WithSortedChildren(t *testing.T) {
+	for name, opts := range map[string][]Option{
+		"plain":  nil,
+		"atomic": {WithAtomicReads()},
+		"arena":  {WithArena(64)},
+	} {
+		trie := New(append(opts, WithSortedChildren())...)
+		want := New()
+		for _, tr := range []*Trie{trie, want} {
+			addFromFile(tr, "fixtures/test.txt")
+			tr.Add("", true)
+			for _, key := range gnmiFixture {
+				tr.Add(key, true)
+			}
+			for i := 0; i < 500; i++ {
+				tr.Add(fmt.Sprintf("%x/%d", i*7919, i), i)
+			}
+		}
+		checkSorted(t, trie.root)
+		for _, key := range want.Keys() {
+			if len(key)%3 == 0 {
+				trie.Remove(key)
+				want.Remove(key)
+			}
+		}
+		trie.MovePrefix("ba", "zz")
+		want.MovePrefix("ba", "zz")
+		trie.Compact(0)
+		trie.Shrink()
+		checkSorted(t, trie.root)
+		for _, prefix := range []string{"", "a", "zz", "/interfaces/", "1"} {
+			if got, want := trie.FindByPrefixDesc(prefix), want.FindByPrefixDesc(prefix); !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: FindByPrefixDesc(%q) = %d keys, want %d keys", name, prefix, len(got), len(want))
+			}
+			got, _ := trie.SearchWithOptions(prefix, SearchByPrefix, Sorted(), MaxResults(10))
+			if want, _ := want.SearchWithOptions(prefix, SearchByPrefix, Sorted(), MaxResults(10)); !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: SearchWithOptions(%q) = %v, want %v", name, prefix, got, want)
+			}
+		}
+		trie.Clear()
+		trie.Add("b", 1)
+		trie.Add("a", 1)
+		checkSorted(t, trie.root)
+		if got := trie.FindByPrefixDesc(""); !reflect.DeepEqual(got, []string{"b", "a"}) {
+			t.Errorf("%s: FindByPrefixDesc() after Clear = %v", name, got)
+		}
+	}
+}
+
+func BenchmarkSortedFindByPrefix(b *testing.B) {
+	sorted := dictTrie(b, WithSortedChildren())
+	unsorted := dictTrie(b)
+	prefixes := []string{"a", "co", "pre", "un"}
+	b.Run("unsorted", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			unsorted.FindByPrefix(prefixes[i%len(prefixes)])
+		}
+	})
+	b.Run("sorted-on-visit", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			unsorted.SearchWithOptions(prefixes[i%len(prefixes)], SearchByPrefix, Sorted())
+		}
+	})
+	b.Run("sorted-children", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			sorted.SearchWithOptions(prefixes[i%len(prefixes)], SearchByPrefix, Sorted())
+		}
+	})
+}
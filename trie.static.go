@@ -0,0 +1,403 @@
+package gtrie
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"io"
+	"math"
+)
+
+// ErrInvalidStaticTrie is returned by ReadStaticTrie if the input is not
+// a StaticTrie written by StaticTrie.WriteTo.
+var ErrInvalidStaticTrie = errors.New("gtrie: invalid static trie")
+
+// staticMagic and staticVersion head the serialized form of StaticTrie.
+const (
+	staticMagic   = "GTDA"
+	staticVersion = 1
+)
+
+// StaticTrie is a read-only trie compiled by Trie.Compile into a double-array
+// (DARTS-style) layout. A state is an index of the two arrays; the transition
+// from the state `s` by the code `c` goes to `base[s]+c` if `check[base[s]+c]`
+// is `s`. The keys are walked byte by byte with the code `b+1` for a byte `b`
+// and the code 0 for the end of a key, whose slot keeps the index of the value
+// as `-(index+1)` in the base array.
+//
+// A StaticTrie is safe for concurrent use since it is never modified.
+type StaticTrie struct {
+	base   []int32
+	check  []int32
+	values []interface{} // the values in lexicographic order of the keys
+	span   int32         // the largest code in use plus one
+	mapped *mappedFile   // the file of OpenMapped holding the values instead of `values`
+}
+
+// Compile converts the current keys and values of the trie into a StaticTrie.
+// The keys are compiled in the canonical form, but the key transform is not
+// carried over so that the keys given to the StaticTrie must be canonical.
+func (t *Trie) Compile() *StaticTrie {
+	if t == nil {
+		return new(Trie).Compile()
+	}
+	t.rlock()
+	kvs := nodeKVs(collectNodes(t.root))
+	t.runlock()
+	b := &staticBuilder{}
+	b.grow(len(kvs) + 1)
+	b.used[0] = true
+	b.build(kvs, 0, len(kvs), 0, 0)
+	s := &StaticTrie{
+		base:   b.base[:b.size],
+		check:  b.check[:b.size],
+		values: make([]interface{}, len(kvs)),
+	}
+	for i, kv := range kvs {
+		s.values[i] = kv.Value
+	}
+	s.span = s.maxCode() + 1
+	return s
+}
+
+// staticBuilder places the states of the sorted keys into the double array.
+type staticBuilder struct {
+	base  []int32
+	check []int32
+	used  []bool
+	size  int // the number of the slots in use including the gaps
+	free  int // the first slot not used
+}
+
+// grow extends the arrays to `n` slots at least.
+func (b *staticBuilder) grow(n int) {
+	if n <= len(b.base) {
+		return
+	}
+	if c := 2 * len(b.base); n < c {
+		n = c
+	}
+	for i := len(b.check); i < n; i++ {
+		b.base = append(b.base, 0)
+		b.check = append(b.check, -1)
+		b.used = append(b.used, false)
+	}
+}
+
+// build places the children of the state `s` for the keys kvs[lo:hi]
+// sharing the first `depth` bytes and then the states under them.
+func (b *staticBuilder) build(kvs []KV, lo, hi, depth int, s int32) {
+	var codes []int32
+	var starts []int
+	for i := lo; i < hi; i++ {
+		c := int32(0)
+		if depth < len(kvs[i].Key) {
+			c = int32(kvs[i].Key[depth]) + 1
+		}
+		if l := len(codes); l == 0 || codes[l-1] != c {
+			codes = append(codes, c)
+			starts = append(starts, i)
+		}
+	}
+	if len(codes) == 0 {
+		return
+	}
+	starts = append(starts, hi)
+	base := b.fit(codes)
+	b.base[s] = base
+	for _, c := range codes {
+		b.used[base+c] = true
+		b.check[base+c] = s
+		if int(base+c) >= b.size {
+			b.size = int(base+c) + 1
+		}
+	}
+	for b.used[b.free] {
+		b.free++
+		b.grow(b.free + 1)
+	}
+	for i, c := range codes {
+		if c == 0 {
+			// the keys are sorted so that the end of a key is the first child.
+			b.base[base] = -int32(starts[i]) - 1
+			continue
+		}
+		b.build(kvs, starts[i], starts[i+1], depth+1, base+c)
+	}
+}
+
+// fit returns the smallest base placing all the `codes` on the free slots.
+func (b *staticBuilder) fit(codes []int32) int32 {
+	base := int32(b.free) - codes[0]
+	if base < 1 {
+		base = 1
+	}
+	for ; ; base++ {
+		b.grow(int(base+codes[len(codes)-1]) + 1)
+		ok := true
+		for _, c := range codes {
+			if b.used[base+c] {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return base
+		}
+	}
+}
+
+// Size returns the number of the keys in the static trie.
+func (s *StaticTrie) Size() int {
+	if s.mapped != nil {
+		return s.mapped.values
+	}
+	return len(s.values)
+}
+
+// maxCode returns the largest code of the transitions in use, which bounds
+// the codes probed for the children of a state.
+func (s *StaticTrie) maxCode() int32 {
+	var code int32
+	for t, state := range s.check {
+		if state >= 0 {
+			code = max(code, int32(t)-s.base[state])
+		}
+	}
+	return min(code, 256)
+}
+
+// next returns the state reached from the state by the byte `c`.
+func (s *StaticTrie) next(state int32, c byte) (int32, bool) {
+	t := s.base[state] + int32(c) + 1
+	if t < 0 || int(t) >= len(s.check) || s.check[t] != state {
+		return 0, false
+	}
+	return t, true
+}
+
+// value returns the index of the value if a key ends at the state.
+func (s *StaticTrie) value(state int32) (int, bool) {
+	t := s.base[state]
+	if t < 0 || int(t) >= len(s.check) || s.check[t] != state || s.base[t] >= 0 {
+		return 0, false
+	}
+	idx := int(-s.base[t] - 1)
+	if idx < 0 || idx >= s.Size() {
+		return 0, false
+	}
+	return idx, true
+}
+
+// valueAt returns the value of the index.
+func (s *StaticTrie) valueAt(idx int) interface{} {
+	if s.mapped != nil {
+		return s.mapped.value(idx)
+	}
+	return s.values[idx]
+}
+
+// walk returns the state reached from the root by `key`.
+func (s *StaticTrie) walk(key string) (int32, bool) {
+	var state int32
+	if len(s.check) == 0 {
+		return 0, false
+	}
+	for i := 0; i < len(key); i++ {
+		var ok bool
+		if state, ok = s.next(state, key[i]); !ok {
+			return 0, false
+		}
+	}
+	return state, true
+}
+
+// Find finds and returns the value of the `key`.
+func (s *StaticTrie) Find(key string) (interface{}, bool) {
+	state, ok := s.walk(key)
+	if !ok {
+		return nil, false
+	}
+	idx, ok := s.value(state)
+	if !ok {
+		return nil, false
+	}
+	return s.valueAt(idx), true
+}
+
+// FindByPrefix returns all the keys starting with `prefix` in lexicographic order.
+func (s *StaticTrie) FindByPrefix(prefix string) []string {
+	state, ok := s.walk(prefix)
+	if !ok {
+		return nil
+	}
+	return s.collect(state, []byte(prefix), nil)
+}
+
+// collect appends all the keys under the state to `keys` in lexicographic order.
+// `path` is the bytes from the root to the state.
+func (s *StaticTrie) collect(state int32, path []byte, keys []string) []string {
+	base := s.base[state]
+	for c := int32(0); c < s.span && int(base+c) < len(s.check); c++ {
+		if base+c < 0 || s.check[base+c] != state {
+			continue
+		}
+		if c == 0 {
+			keys = append(keys, string(path))
+			continue
+		}
+		keys = s.collect(base+c, append(path, byte(c-1)), keys)
+	}
+	return keys
+}
+
+// FindLongestMatchingPrefix finds the longest key that is a prefix of the input `key`
+// and returns the key and its value like Trie.FindLongestMatchingPrefix.
+func (s *StaticTrie) FindLongestMatchingPrefix(key string) (string, interface{}, bool) {
+	kvs := s.CommonPrefixSearch(key)
+	if len(kvs) == 0 {
+		return "", nil, false
+	}
+	kv := kvs[len(kvs)-1]
+	return kv.Key, kv.Value, true
+}
+
+// CommonPrefixSearch returns all the keys that are the prefixes of the input `key`
+// and their values from the shortest to the longest like Trie.FindMatchingPrefixKV.
+func (s *StaticTrie) CommonPrefixSearch(key string) []KV {
+	var kvs []KV
+	if len(s.check) == 0 {
+		return nil
+	}
+	var state int32
+	for i := 0; i < len(key); i++ {
+		var ok bool
+		if state, ok = s.next(state, key[i]); !ok {
+			break
+		}
+		if idx, ok := s.value(state); ok {
+			kvs = append(kvs, KV{Key: key[:i+1], Value: s.valueAt(idx)})
+		}
+	}
+	return kvs
+}
+
+// countingWriter counts the bytes written to the underlying writer.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
+// WriteTo writes the static trie to `w` and returns the number of the bytes written.
+// The double array is written as little-endian 32-bit integers followed by
+// the values encoded by encoding/gob, so that the values must be of the types
+// gob can encode and the types other than the basic ones must be registered
+// by gob.Register to be read back by ReadStaticTrie.
+func (s *StaticTrie) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	bw := bufio.NewWriter(cw)
+	values := s.values
+	if s.mapped != nil {
+		values = make([]interface{}, s.Size())
+		for i := range values {
+			values[i] = s.valueAt(i)
+		}
+	}
+	header := []uint32{staticVersion, uint32(len(s.base)), uint32(len(values))}
+	if _, err := bw.WriteString(staticMagic); err != nil {
+		return cw.n, err
+	}
+	for _, v := range []interface{}{header, s.base, s.check} {
+		if err := binary.Write(bw, binary.LittleEndian, v); err != nil {
+			return cw.n, err
+		}
+	}
+	if err := gob.NewEncoder(bw).Encode(values); err != nil {
+		return cw.n, err
+	}
+	err := bw.Flush()
+	return cw.n, err
+}
+
+// ReadStaticTrie reads a static trie written by StaticTrie.WriteTo from `r`.
+// It returns ErrInvalidStaticTrie if the input is not in the format.
+func ReadStaticTrie(r io.Reader) (*StaticTrie, error) {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(staticMagic))
+	if _, err := io.ReadFull(br, magic); err != nil || string(magic) != staticMagic {
+		return nil, ErrInvalidStaticTrie
+	}
+	header := make([]uint32, 3)
+	if err := binary.Read(br, binary.LittleEndian, header); err != nil {
+		return nil, err
+	}
+	if header[0] != staticVersion {
+		return nil, ErrInvalidStaticTrie
+	}
+	// the states are allocated once they are read in full.
+	data, ok := readSection(br, 8*uint64(header[1]))
+	if !ok {
+		return nil, ErrInvalidStaticTrie
+	}
+	s := &StaticTrie{
+		base:  make([]int32, header[1]),
+		check: make([]int32, header[1]),
+	}
+	sr := bytes.NewReader(data)
+	for _, v := range []interface{}{s.base, s.check} {
+		if err := binary.Read(sr, binary.LittleEndian, v); err != nil {
+			return nil, err
+		}
+	}
+	if err := gob.NewDecoder(br).Decode(&s.values); err != nil {
+		return nil, err
+	}
+	if len(s.values) != int(header[2]) {
+		return nil, ErrInvalidStaticTrie
+	}
+	if len(s.values) == 0 {
+		s.values = nil
+	}
+	if !s.valid() {
+		return nil, ErrInvalidStaticTrie
+	}
+	s.span = s.maxCode() + 1
+	return s, nil
+}
+
+// readSection reads `size` bytes from `r` into a buffer growing as the input
+// arrives, so that a size corrupted fails at the end of the input instead of
+// being allocated up front. It reports false if the input is shorter.
+func readSection(r io.Reader, size uint64) ([]byte, bool) {
+	if size > math.MaxInt64 {
+		return nil, false
+	}
+	var buf bytes.Buffer
+	if _, err := io.CopyN(&buf, r, int64(size)); err != nil {
+		return nil, false
+	}
+	return buf.Bytes(), true
+}
+
+// valid reports whether the states and the value indexes are in range
+// so that the lookups never panic on a corrupted input.
+func (s *StaticTrie) valid() bool {
+	n := int32(len(s.check))
+	for i := range s.check {
+		if s.check[i] < -1 || s.check[i] >= n {
+			return false
+		}
+		if s.base[i] < 0 && int(-s.base[i]-1) >= len(s.values) {
+			return false
+		}
+	}
+	return true
+}
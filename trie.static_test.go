@@ -0,0 +1,178 @@
+package gtrie
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"os"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func newStaticFixture() *Trie {
+	trie := New()
+	for i, key := range gnmiFixture {
+		trie.Add(key, i)
+	}
+	for i, key := range []string{"苹果 沂水县", "苹果", "大蒜", "大豆", "a", "ab", "abc"} {
+		trie.Add(key, -i)
+	}
+	return trie
+}
+
+func checkStatic(t *testing.T, trie *Trie, s *StaticTrie) {
+	t.Helper()
+	if s.Size() != trie.Size() {
+		t.Errorf("Size() = %d, want %d", s.Size(), trie.Size())
+	}
+	for _, key := range trie.Keys() {
+		v, ok := s.Find(key)
+		want, _ := trie.Find(key)
+		if !ok || v != want {
+			t.Errorf("Find(%q) = %v, %v, want %v, true", key, v, ok, want)
+		}
+	}
+	for _, key := range []string{"", "/interfaces/", "/interfaces/interface[name=1/2]/state/x", "苹", "abcd", "zzz"} {
+		if v, ok := s.Find(key); ok {
+			t.Errorf("Find(%q) = %v, true, want not found", key, v)
+		}
+	}
+	for _, prefix := range []string{"", "/interfaces/interface[name=1/", "/interfaces/interface[name=1/3]/state", "苹", "ab", "/x"} {
+		got, want := s.FindByPrefix(prefix), sortedKeys(trie.FindByPrefix(prefix))
+		if len(got) != 0 || len(want) != 0 {
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("FindByPrefix(%q) = %v, want %v", prefix, got, want)
+			}
+		}
+	}
+	for _, key := range []string{"/interfaces/interface[name=1/2]/state/enabled/x", "/interfaces/interface[name=1/9]", "苹果 沂水县县", "abx", "zzz", ""} {
+		k1, v1, ok1 := s.FindLongestMatchingPrefix(key)
+		k2, v2, ok2 := trie.FindLongestMatchingPrefix(key)
+		if k1 != k2 || v1 != v2 || ok1 != ok2 {
+			t.Errorf("FindLongestMatchingPrefix(%q) = %q, %v, %v, want %q, %v, %v", key, k1, v1, ok1, k2, v2, ok2)
+		}
+		got, want := s.CommonPrefixSearch(key), trie.FindMatchingPrefixKV(key)
+		if len(got) != 0 || len(want) != 0 {
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("CommonPrefixSearch(%q) = %v, want %v", key, got, want)
+			}
+		}
+	}
+}
+
+func TestTrie_Compile(t *testing.T) {
+	trie := newStaticFixture()
+	checkStatic(t, trie, trie.Compile())
+
+	empty := New().Compile()
+	if empty.Size() != 0 {
+		t.Errorf("Size() of an empty static trie = %d", empty.Size())
+	}
+	if _, ok := empty.Find(""); ok {
+		t.Errorf("Find() of an empty static trie found a key")
+	}
+	if keys := empty.FindByPrefix(""); len(keys) != 0 {
+		t.Errorf("FindByPrefix() of an empty static trie = %v", keys)
+	}
+}
+
+func TestStaticTrie_WriteTo(t *testing.T) {
+	trie := newStaticFixture()
+	var buf bytes.Buffer
+	n, err := trie.Compile().WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+	if n != int64(buf.Len()) {
+		t.Errorf("WriteTo() = %d, want %d", n, buf.Len())
+	}
+	s, err := ReadStaticTrie(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("ReadStaticTrie() error = %v", err)
+	}
+	checkStatic(t, trie, s)
+
+	buf.Reset()
+	if _, err := New().Compile().WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo() of an empty static trie error = %v", err)
+	}
+	if s, err := ReadStaticTrie(&buf); err != nil || s.Size() != 0 {
+		t.Errorf("ReadStaticTrie() of an empty static trie = %v, %v", s, err)
+	}
+
+	if _, err := ReadStaticTrie(bytes.NewReader([]byte("foo\nbar\n"))); err != ErrInvalidStaticTrie {
+		t.Errorf("ReadStaticTrie() of an invalid input error = %v, want %v", err, ErrInvalidStaticTrie)
+	}
+	// the header of 4G states is not allocated for the input cut short.
+	header := binary.LittleEndian.AppendUint32(binary.LittleEndian.AppendUint32([]byte(staticMagic), staticVersion), math.MaxUint32)
+	header = binary.LittleEndian.AppendUint32(header, 0)
+	if _, err := ReadStaticTrie(bytes.NewReader(append(header, 1, 2, 3, 4))); err != ErrInvalidStaticTrie {
+		t.Errorf("ReadStaticTrie() of a corrupted header error = %v, want %v", err, ErrInvalidStaticTrie)
+	}
+}
+
+func dictTrie(b *testing.B, opts ...Option) *Trie {
+	f, err := os.Open("/usr/share/dict/words")
+	if err != nil {
+		b.Skip("no dictionary fixture")
+	}
+	defer f.Close()
+	trie := New(opts...)
+	if _, err := trie.ReadKeys(f, nil); err != nil {
+		b.Fatal(err)
+	}
+	return trie
+}
+
+func BenchmarkStaticMemory(b *testing.B) {
+	trie := dictTrie(b)
+	keys := trie.Keys()
+	var dynamic, static uint64
+	for i := 0; i < b.N; i++ {
+		before := heapAlloc()
+		d := New()
+		for _, key := range keys {
+			d.Add(key, nil)
+		}
+		dynamic = heapAlloc() - before
+		before = heapAlloc()
+		s := d.Compile()
+		static = heapAlloc() - before
+		runtime.KeepAlive(d)
+		runtime.KeepAlive(s)
+	}
+	b.ReportMetric(float64(dynamic)/float64(len(keys)), "dynamic-bytes/key")
+	b.ReportMetric(float64(static)/float64(len(keys)), "static-bytes/key")
+}
+
+func BenchmarkStaticFind(b *testing.B) {
+	trie := dictTrie(b)
+	keys := trie.Keys()
+	s := trie.Compile()
+	b.Run("dynamic", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			trie.Find(keys[i%len(keys)])
+		}
+	})
+	b.Run("static", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			s.Find(keys[i%len(keys)])
+		}
+	})
+}
+
+func BenchmarkStaticFindByPrefix(b *testing.B) {
+	trie := dictTrie(b)
+	s := trie.Compile()
+	b.Run("dynamic", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = trie.FindByPrefix("fo")
+		}
+	})
+	b.Run("static", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = s.FindByPrefix("fo")
+		}
+	})
+}
@@ -0,0 +1,184 @@
+package gtrie
+
+import (
+	"bufio"
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+var (
+	// ErrInvalidStream is returned by StreamFrom if the input is not
+	// the stream written by StreamTo.
+	ErrInvalidStream = errors.New("gtrie: invalid stream")
+	// ErrStreamValue is returned by StreamTo for a value that has no bytes
+	// to be streamed, i.e. neither []byte, string, nil nor encoding.BinaryMarshaler.
+	ErrStreamValue = errors.New("gtrie: value not streamable")
+)
+
+const (
+	streamMagic   = "GTST"
+	streamVersion = 1
+	// streamBatch is the number of the keys per batch if not given.
+	streamBatch = 1024
+)
+
+// The stream of StreamTo is the magic "GTST" and the version (a byte) followed
+// by the batches of the keys and values in lexicographic order of the keys:
+//
+//	batch  the number of the entries (uvarint), the length of the entries
+//	       in bytes (uvarint) and the entries
+//	entry  the length of the key (uvarint), the key, the length of
+//	       the value (uvarint) and the value
+//
+// The stream ends with the batch of no entry, so that a stream cut short
+// is detected by StreamFrom.
+
+// StreamTo writes the keys and values of the trie to `w` in lexicographic order
+// of the keys by the batches of `batch` keys (1024 if zero or less), so that
+// the memory is bounded by a batch regardless of the size of the trie.
+// The values are written as their bytes: []byte, string and
+// encoding.BinaryMarshaler as they are and nil as empty; the other values
+// are rejected with ErrStreamValue before the batch of the value is written.
+//
+// Each batch is read as a page of NextPage under the read lock, which is
+// released while the batch is written, so that the writers are not blocked
+// for the whole stream. The stream is not a snapshot of the trie: the keys
+// neither added nor removed during the stream are written once in order,
+// with their values at the time of their batches; the keys added after
+// the batch written last are written, while the keys added before it and
+// the keys removed are not, as NextPage.
+func (t *Trie) StreamTo(w io.Writer, batch int) error {
+	if t == nil {
+		return new(Trie).StreamTo(w, batch)
+	}
+	if batch <= 0 {
+		batch = streamBatch
+	}
+	bw := bufio.NewWriter(w)
+	bw.WriteString(streamMagic)
+	bw.WriteByte(streamVersion)
+	var (
+		body  []byte
+		token = NewResumeToken("", MaxResults(batch))
+		kvs   []KV
+	)
+	for !token.Done() {
+		kvs, token = t.nextPage(token, nil)
+		body = body[:0]
+		for _, kv := range kvs {
+			value, err := streamValue(kv.Value)
+			if err != nil {
+				return fmt.Errorf("%w: %q: %v", ErrStreamValue, kv.Key, err)
+			}
+			body = binary.AppendUvarint(body, uint64(len(kv.Key)))
+			body = append(body, kv.Key...)
+			body = binary.AppendUvarint(body, uint64(len(value)))
+			body = append(body, value...)
+		}
+		if len(kvs) == 0 {
+			continue
+		}
+		if err := writeBatch(bw, len(kvs), body); err != nil {
+			return err
+		}
+		// the batch leaves the buffer before the next page is read.
+		if err := bw.Flush(); err != nil {
+			return err
+		}
+	}
+	if err := writeBatch(bw, 0, nil); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// streamValue returns the bytes of the value streamed.
+func streamValue(v interface{}) ([]byte, error) {
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		return v, nil
+	case string:
+		return []byte(v), nil
+	case encoding.BinaryMarshaler:
+		return v.MarshalBinary()
+	}
+	return nil, fmt.Errorf("%T", v)
+}
+
+// writeBatch writes the header of the batch of `n` entries and the entries.
+func writeBatch(w *bufio.Writer, n int, body []byte) error {
+	header := binary.AppendUvarint(nil, uint64(n))
+	header = binary.AppendUvarint(header, uint64(len(body)))
+	w.Write(header)
+	_, err := w.Write(body)
+	return err
+}
+
+// StreamFrom reads the stream written by StreamTo from `r` and calls `fn`
+// with each key and value in the order of the stream, e.g. to add them to
+// a trie. The value is a copy that `fn` can retain. The reading stops at
+// the first error of `fn`, which is returned. It returns ErrInvalidStream
+// if the input is not a stream of StreamTo or is cut short; the entries
+// of the batches read before are already passed to `fn`.
+// Only a batch is held in memory at a time.
+func StreamFrom(r io.Reader, fn func(key string, value []byte) error) error {
+	br := bufio.NewReader(r)
+	header := make([]byte, len(streamMagic)+1)
+	if _, err := io.ReadFull(br, header); err != nil || string(header[:len(streamMagic)]) != streamMagic || header[len(streamMagic)] != streamVersion {
+		return ErrInvalidStream
+	}
+	var body bytes.Buffer
+	for {
+		n, err := binary.ReadUvarint(br)
+		if err != nil {
+			return ErrInvalidStream
+		}
+		size, err := binary.ReadUvarint(br)
+		if err != nil || (n == 0) != (size == 0) {
+			return ErrInvalidStream
+		}
+		if n == 0 {
+			return nil
+		}
+		// an entry takes 2 bytes at least.
+		if n > size/2 || size > math.MaxInt64 {
+			return ErrInvalidStream
+		}
+		// the body grows as it is read, not by the size given by the input.
+		body.Reset()
+		if _, err := io.CopyN(&body, br, int64(size)); err != nil {
+			return ErrInvalidStream
+		}
+		for data := body.Bytes(); n > 0; n-- {
+			key, rest, ok := streamField(data)
+			if !ok {
+				return ErrInvalidStream
+			}
+			value, rest, ok := streamField(rest)
+			if !ok || (n == 1 && len(rest) != 0) {
+				return ErrInvalidStream
+			}
+			data = rest
+			if err := fn(string(key), append([]byte(nil), value...)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// streamField returns the length-prefixed field at the head of `data` and the rest.
+func streamField(data []byte) ([]byte, []byte, bool) {
+	size, n := binary.Uvarint(data)
+	if n <= 0 || size > uint64(len(data)-n) {
+		return nil, nil, false
+	}
+	data = data[n:]
+	return data[:size], data[size:], true
+}
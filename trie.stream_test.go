@@ -0,0 +1,173 @@
+package gtrie
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestTrie_StreamTo(t *testing.T) {
+	n := 1000000
+	if testing.Short() {
+		n = 10000
+	}
+	src := New()
+	for i := 0; i < n; i++ {
+		src.Add("/subtree/"+strconv.Itoa(i), []byte(strconv.Itoa(i)))
+	}
+	r, w := io.Pipe()
+	go func() {
+		w.CloseWithError(src.StreamTo(w, 4096))
+	}()
+	dst := New()
+	last := ""
+	err := StreamFrom(r, func(key string, value []byte) error {
+		if key <= last {
+			return fmt.Errorf("%q streamed after %q", key, last)
+		}
+		last = key
+		dst.Add(key, value)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamFrom() error = %v", err)
+	}
+	if dst.Size() != src.Size() {
+		t.Fatalf("Size() = %d, want %d", dst.Size(), src.Size())
+	}
+	for key, value := range src.All() {
+		if v, ok := dst.Find(key); !ok || !bytes.Equal(v.([]byte), value.([]byte)) {
+			t.Fatalf("Find(%q) = %v, %v, want %s", key, v, ok, value)
+		}
+	}
+}
+
+func TestTrie_StreamToValues(t *testing.T) {
+	trie := New()
+	trie.Add("", "root")
+	trie.Add("/b", []byte{0, 1})
+	trie.Add("/a", nil)
+	trie.Add("/c", netip.MustParseAddr("10.0.0.1"))
+	var buf bytes.Buffer
+	if err := trie.StreamTo(&buf, 0); err != nil {
+		t.Fatalf("StreamTo() error = %v", err)
+	}
+	var got []string
+	if err := StreamFrom(&buf, func(key string, value []byte) error {
+		got = append(got, fmt.Sprintf("%s=%x", key, value))
+		return nil
+	}); err != nil {
+		t.Fatalf("StreamFrom() error = %v", err)
+	}
+	want := []string{"=726f6f74", "/a=", "/b=0001", "/c=0a000001"}
+	if !slices.Equal(got, want) {
+		t.Errorf("StreamFrom() = %v, want %v", got, want)
+	}
+
+	trie.Add("/d", 1)
+	buf.Reset()
+	if err := trie.StreamTo(&buf, 2); !errors.Is(err, ErrStreamValue) || !strings.Contains(err.Error(), `"/d"`) {
+		t.Errorf("StreamTo() of an int error = %v, want %v", err, ErrStreamValue)
+	}
+	// the batches before the value rejected are written, but not its batch.
+	count := 0
+	if err := StreamFrom(&buf, func(string, []byte) error {
+		count++
+		return nil
+	}); !errors.Is(err, ErrInvalidStream) || count != 4 {
+		t.Errorf("StreamFrom() of the stream cut = %d, %v, want 4, %v", count, err, ErrInvalidStream)
+	}
+}
+
+// mutatingWriter calls `fn` after each write of the stream.
+type mutatingWriter struct {
+	bytes.Buffer
+	fn func()
+}
+
+func (w *mutatingWriter) Write(p []byte) (int, error) {
+	n, err := w.Buffer.Write(p)
+	w.fn()
+	return n, err
+}
+
+func TestTrie_StreamToMutated(t *testing.T) {
+	trie := New()
+	for i := 0; i < 100; i++ {
+		trie.Add(fmt.Sprintf("/k/%03d", i), []byte("v"))
+	}
+	i := 0
+	w := &mutatingWriter{fn: func() {
+		// the writers are not blocked between the batches.
+		trie.Add(fmt.Sprintf("/k/%03d/new", i), []byte("new"))
+		trie.Add(fmt.Sprintf("/a/%03d", i), []byte("before"))
+		trie.Remove(fmt.Sprintf("/k/%03d", 99-i))
+		i++
+	}}
+	if err := trie.StreamTo(w, 10); err != nil {
+		t.Fatalf("StreamTo() error = %v", err)
+	}
+	var keys []string
+	if err := StreamFrom(&w.Buffer, func(key string, _ []byte) error {
+		keys = append(keys, key)
+		return nil
+	}); err != nil {
+		t.Fatalf("StreamFrom() error = %v", err)
+	}
+	if !slices.IsSorted(keys) || len(slices.Compact(slices.Clone(keys))) != len(keys) {
+		t.Errorf("the keys streamed are not sorted or unique: %v", keys)
+	}
+	// the keys never removed are streamed.
+	for k := 0; k < 100-i; k++ {
+		if _, found := slices.BinarySearch(keys, fmt.Sprintf("/k/%03d", k)); !found {
+			t.Errorf("%q not streamed", fmt.Sprintf("/k/%03d", k))
+		}
+	}
+	for _, key := range keys {
+		if strings.HasPrefix(key, "/a/") {
+			t.Errorf("%q added before the cursor is streamed", key)
+		}
+	}
+}
+
+func TestStreamFrom(t *testing.T) {
+	trie := New()
+	trie.Add("/a", []byte("1"))
+	trie.Add("/b", []byte("2"))
+	var buf bytes.Buffer
+	if err := trie.StreamTo(&buf, 1); err != nil {
+		t.Fatalf("StreamTo() error = %v", err)
+	}
+	data := buf.Bytes()
+	stop := errors.New("stop")
+	if err := StreamFrom(bytes.NewReader(data), func(key string, _ []byte) error {
+		return stop
+	}); err != stop {
+		t.Errorf("StreamFrom() of the callback failing error = %v, want %v", err, stop)
+	}
+	for _, bad := range [][]byte{
+		nil,
+		[]byte("GTST"),
+		[]byte("GTST\x02\x00\x00"),
+		data[:len(data)-1],
+		append(slices.Clone(data[:5]), 1, 0),
+		append(slices.Clone(data[:5]), 1, 3, 5, 'x', 0),
+		append(slices.Clone(data[:5]), 2, 4, 1, 'x', 0, 0),
+		// the batch of 1<<62 bytes not allocated.
+		binary.AppendUvarint(binary.AppendUvarint([]byte("GTST\x01"), 1), 1<<62),
+		binary.AppendUvarint(binary.AppendUvarint([]byte("GTST\x01"), 1), 1<<40),
+		binary.AppendUvarint(binary.AppendUvarint([]byte("GTST\x01"), 1), math.MaxUint64),
+	} {
+		if err := StreamFrom(bytes.NewReader(bad), func(string, []byte) error { return nil }); !errors.Is(err, ErrInvalidStream) {
+			t.Errorf("StreamFrom(%q) error = %v, want %v", bad, err, ErrInvalidStream)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package gtrie
+
+import (
+	"time"
+	"unicode/utf8"
+)
+
+// StripOption is an option of FindByPrefixRelative.
+type StripOption func(o *stripOptions)
+
+type stripOptions struct {
+	delim rune
+	trim  bool
+}
+
+// TrimDelimiter also drops the `delim` leading the keys relative to the prefix.
+// The keys differing only by the delimiter (e.g. "/a" and "/a/" relative to "/a")
+// collide on the same relative key; the key without the delimiter is kept.
+func TrimDelimiter(delim rune) StripOption {
+	return func(o *stripOptions) {
+		o.delim = delim
+		o.trim = true
+	}
+}
+
+// FindByPrefixRelative returns all the keys and values starting with `prefix`
+// like FindByPrefixAll, but the keys have the `prefix` removed.
+// The relative keys share the memory of the keys stored in the trie,
+// so that they are cheaper than trimming the keys of FindByPrefixAll.
+// With a key transform, the relative keys are of the canonical keys.
+func (t *Trie) FindByPrefixRelative(prefix string, opts ...StripOption) map[string]interface{} {
+	if t == nil {
+		return nil
+	}
+	var o stripOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	root := t.readRoot()
+	defer t.readDone()
+	if t.metrics != nil {
+		defer t.observe(MetricSearchPrefix, time.Now())
+	}
+	prefix = t.canonical(prefix)
+	node := findNode(root, prefix)
+	if node == nil {
+		return nil
+	}
+	terms := collectNodes(node)
+	all := make(map[string]interface{}, len(terms))
+	for _, n := range terms {
+		rel := t.canonical(n.key())[len(prefix):]
+		if o.trim {
+			if r, size := utf8.DecodeRuneInString(rel); size > 0 && r == o.delim {
+				rel = rel[size:]
+				if _, ok := all[rel]; ok {
+					continue
+				}
+			}
+		}
+		all[rel] = n.value
+	}
+	return all
+}
@@ -0,0 +1,47 @@
+package gtrie
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTrie_FindByPrefixRelative(t *testing.T) {
+	trie := newGNMITrie()
+	for _, prefix := range []string{"", "/interfaces", "/interfaces/interface[name=1/", "/interfaces/interface[name=1/2]", "/x"} {
+		want := map[string]interface{}{}
+		for key, v := range trie.FindByPrefixAll(prefix) {
+			want[strings.TrimPrefix(key, prefix)] = v
+		}
+		got := trie.FindByPrefixRelative(prefix)
+		if len(got) != 0 || len(want) != 0 {
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("FindByPrefixRelative(%q) = %v, want %v", prefix, got, want)
+			}
+		}
+	}
+
+	trie = New()
+	for i, key := range []string{"/a", "/a/", "/a/b", "/a/b/c", "/ab", "/a//d"} {
+		trie.Add(key, i)
+	}
+	tests := []struct {
+		prefix string
+		want   map[string]interface{}
+	}{
+		{"/a/", map[string]interface{}{"": 1, "b": 2, "b/c": 3, "d": 5}},
+		// "/a/" and "/ab" collide with "/a" and "/a/b".
+		{"/a", map[string]interface{}{"": 0, "b": 4, "b/c": 3, "/d": 5}},
+	}
+	for _, tt := range tests {
+		if got := trie.FindByPrefixRelative(tt.prefix, TrimDelimiter('/')); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("FindByPrefixRelative(%q, TrimDelimiter()) = %v, want %v", tt.prefix, got, tt.want)
+		}
+	}
+
+	trie = New(WithKeyTransform(strings.ToLower))
+	trie.Add("/A/B", 1)
+	if got := trie.FindByPrefixRelative("/A"); !reflect.DeepEqual(got, map[string]interface{}{"/b": 1}) {
+		t.Errorf("FindByPrefixRelative() with a key transform = %v", got)
+	}
+}
@@ -0,0 +1,350 @@
+package gtrie
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"math/bits"
+	"sort"
+)
+
+// ErrInvalidSuccinctTrie is returned by ReadSuccinctTrie if the input is not
+// a SuccinctTrie written by SuccinctTrie.WriteTo.
+var ErrInvalidSuccinctTrie = errors.New("gtrie: invalid succinct trie")
+
+// succinctMagic and succinctVersion head the serialized form of SuccinctTrie.
+const (
+	succinctMagic   = "GTLS"
+	succinctVersion = 1
+)
+
+// rankBlockWords is the number of the words in a block of the rank directory.
+const rankBlockWords = 8
+
+// bitVector is a vector of bits supporting rank and select
+// by a directory of the ones counted per block of rankBlockWords words.
+type bitVector struct {
+	words []uint64
+	n     int      // the number of the bits
+	ranks []uint32 // the number of the ones before each block
+}
+
+// push appends a bit to the vector.
+func (v *bitVector) push(bit bool) {
+	if v.n%64 == 0 {
+		v.words = append(v.words, 0)
+	}
+	if bit {
+		v.words[v.n/64] |= 1 << uint(v.n%64)
+	}
+	v.n++
+}
+
+// get returns the bit at `i`.
+func (v *bitVector) get(i int) bool {
+	return v.words[i/64]&(1<<uint(i%64)) != 0
+}
+
+// index builds the rank directory after all the bits are pushed.
+func (v *bitVector) index() {
+	v.ranks = make([]uint32, len(v.words)/rankBlockWords+1)
+	var ones uint32
+	for i, w := range v.words {
+		if i%rankBlockWords == 0 {
+			v.ranks[i/rankBlockWords] = ones
+		}
+		ones += uint32(bits.OnesCount64(w))
+	}
+	if len(v.words)%rankBlockWords == 0 {
+		v.ranks[len(v.ranks)-1] = ones
+	}
+}
+
+// ones returns the number of the ones in the vector.
+func (v *bitVector) ones() int {
+	return v.rank1(v.n)
+}
+
+// rank1 returns the number of the ones before `i`.
+func (v *bitVector) rank1(i int) int {
+	w := i / 64
+	r := int(v.ranks[w/rankBlockWords])
+	for j := w - w%rankBlockWords; j < w; j++ {
+		r += bits.OnesCount64(v.words[j])
+	}
+	if i%64 != 0 {
+		r += bits.OnesCount64(v.words[w] & (1<<uint(i%64) - 1))
+	}
+	return r
+}
+
+// select1 returns the position of the k-th one (0-based).
+func (v *bitVector) select1(k int) int {
+	// the last block having k ones or less before it.
+	b := sort.Search(len(v.ranks), func(b int) bool { return int(v.ranks[b]) > k }) - 1
+	k -= int(v.ranks[b])
+	for w := b * rankBlockWords; ; w++ {
+		c := bits.OnesCount64(v.words[w])
+		if k < c {
+			return w*64 + selectInWord(v.words[w], k)
+		}
+		k -= c
+	}
+}
+
+// select0 returns the position of the k-th zero (0-based).
+func (v *bitVector) select0(k int) int {
+	zeros := func(b int) int { return b*rankBlockWords*64 - int(v.ranks[b]) }
+	b := sort.Search(len(v.ranks), func(b int) bool { return zeros(b) > k }) - 1
+	k -= zeros(b)
+	for w := b * rankBlockWords; ; w++ {
+		c := 64 - bits.OnesCount64(v.words[w])
+		if k < c {
+			return w*64 + selectInWord(^v.words[w], k)
+		}
+		k -= c
+	}
+}
+
+// selectInWord returns the position of the k-th one (0-based) in the word.
+func selectInWord(w uint64, k int) int {
+	for ; k > 0; k-- {
+		w &= w - 1
+	}
+	return bits.TrailingZeros64(w)
+}
+
+// SuccinctTrie is a read-only set of keys compiled by Trie.CompileSuccinct
+// into the level-order unary degree sequence (LOUDS) of the trie.
+// The nodes are numbered in breadth-first order from the root 0 and each node
+// is encoded by a one per child followed by a zero, so that the structure
+// takes about two bits and a byte of label per node in addition to a bit
+// marking the node where a key ends. The children are found by rank and select
+// over the bits, which is slower than Trie and StaticTrie.
+//
+// The keys are walked byte by byte. Each key is identified by an id from 0 to
+// Size()-1, which is the index of its value returned by CompileSuccinct.
+// A SuccinctTrie is safe for concurrent use since it is never modified.
+type SuccinctTrie struct {
+	louds  bitVector
+	labels []byte    // the label of the node i is labels[i-1]
+	terms  bitVector // whether a key ends at each node
+}
+
+// CompileSuccinct converts the current keys of the trie into a SuccinctTrie
+// and returns it with the values indexed by the key ids.
+// The keys are compiled in the canonical form, but the key transform is not
+// carried over so that the keys given to the SuccinctTrie must be canonical.
+func (t *Trie) CompileSuccinct() (*SuccinctTrie, []interface{}) {
+	if t == nil {
+		return new(Trie).CompileSuccinct()
+	}
+	t.rlock()
+	kvs := nodeKVs(collectNodes(t.root))
+	t.runlock()
+	type span struct {
+		lo, hi int
+	}
+	s := &SuccinctTrie{}
+	values := make([]interface{}, 0, len(kvs))
+	// the nodes of a level are the spans of kvs sharing the first `depth` bytes.
+	level := []span{{0, len(kvs)}}
+	for depth := 0; len(level) > 0; depth++ {
+		var next []span
+		for _, sp := range level {
+			lo := sp.lo
+			// the keys are sorted so that the key ending at the node is the first.
+			term := lo < sp.hi && len(kvs[lo].Key) == depth
+			s.terms.push(term)
+			if term {
+				values = append(values, kvs[lo].Value)
+				lo++
+			}
+			for lo < sp.hi {
+				c := kvs[lo].Key[depth]
+				hi := lo + 1
+				for hi < sp.hi && kvs[hi].Key[depth] == c {
+					hi++
+				}
+				s.louds.push(true)
+				s.labels = append(s.labels, c)
+				next = append(next, span{lo, hi})
+				lo = hi
+			}
+			s.louds.push(false)
+		}
+		level = next
+	}
+	s.louds.index()
+	s.terms.index()
+	return s, values
+}
+
+// Size returns the number of the keys in the succinct trie.
+func (s *SuccinctTrie) Size() int {
+	return s.terms.ones()
+}
+
+// children returns the id of the first child of the node and the number of the children.
+func (s *SuccinctTrie) children(node int) (int, int) {
+	start := 0
+	if node > 0 {
+		start = s.louds.select0(node-1) + 1
+	}
+	end := s.louds.select0(node)
+	return s.louds.rank1(start) + 1, end - start
+}
+
+// child returns the child of the node labeled `c`.
+func (s *SuccinctTrie) child(node int, c byte) (int, bool) {
+	first, n := s.children(node)
+	labels := s.labels[first-1 : first-1+n]
+	i := sort.Search(n, func(i int) bool { return labels[i] >= c })
+	if i == n || labels[i] != c {
+		return 0, false
+	}
+	return first + i, true
+}
+
+// walk returns the node reached from the root by `key`.
+func (s *SuccinctTrie) walk(key string) (int, bool) {
+	var node int
+	for i := 0; i < len(key); i++ {
+		var ok bool
+		if node, ok = s.child(node, key[i]); !ok {
+			return 0, false
+		}
+	}
+	return node, true
+}
+
+// Find returns the id of the `key` if the key is in the succinct trie.
+func (s *SuccinctTrie) Find(key string) (int, bool) {
+	node, ok := s.walk(key)
+	if !ok || !s.terms.get(node) {
+		return -1, false
+	}
+	return s.terms.rank1(node), true
+}
+
+// Key reconstructs the key of the `id` from the path to the root.
+// It returns false if the id is out of range.
+func (s *SuccinctTrie) Key(id int) (string, bool) {
+	if id < 0 || id >= s.Size() {
+		return "", false
+	}
+	var key []byte
+	for node := s.terms.select1(id); node > 0; {
+		key = append(key, s.labels[node-1])
+		// the parent is the number of the zeros before the one of the node.
+		node = s.louds.select1(node-1) - (node - 1)
+	}
+	for i, j := 0, len(key)-1; i < j; i, j = i+1, j-1 {
+		key[i], key[j] = key[j], key[i]
+	}
+	return string(key), true
+}
+
+// FindByPrefix returns all the keys starting with `prefix` in lexicographic order.
+// The keys are reconstructed from the labels on the walk.
+func (s *SuccinctTrie) FindByPrefix(prefix string) []string {
+	node, ok := s.walk(prefix)
+	if !ok {
+		return nil
+	}
+	return s.collect(node, []byte(prefix), nil)
+}
+
+// collect appends all the keys under the node to `keys` in lexicographic order.
+// `path` is the bytes from the root to the node.
+func (s *SuccinctTrie) collect(node int, path []byte, keys []string) []string {
+	if s.terms.get(node) {
+		keys = append(keys, string(path))
+	}
+	first, n := s.children(node)
+	for c := first; c < first+n; c++ {
+		keys = s.collect(c, append(path, s.labels[c-1]), keys)
+	}
+	return keys
+}
+
+// WriteTo writes the succinct trie to `w` and returns the number of the bytes written.
+// The values are not written; keep them by the key ids if needed.
+func (s *SuccinctTrie) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	bw := bufio.NewWriter(cw)
+	header := []uint64{succinctVersion, uint64(s.louds.n), uint64(len(s.labels)), uint64(s.terms.n)}
+	if _, err := bw.WriteString(succinctMagic); err != nil {
+		return cw.n, err
+	}
+	for _, v := range []interface{}{header, s.louds.words, s.labels, s.terms.words} {
+		if err := binary.Write(bw, binary.LittleEndian, v); err != nil {
+			return cw.n, err
+		}
+	}
+	err := bw.Flush()
+	return cw.n, err
+}
+
+// ReadSuccinctTrie reads a succinct trie written by SuccinctTrie.WriteTo from `r`.
+// It returns ErrInvalidSuccinctTrie if the input is not in the format.
+func ReadSuccinctTrie(r io.Reader) (*SuccinctTrie, error) {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(succinctMagic))
+	if _, err := io.ReadFull(br, magic); err != nil || string(magic) != succinctMagic {
+		return nil, ErrInvalidSuccinctTrie
+	}
+	header := make([]uint64, 4)
+	if err := binary.Read(br, binary.LittleEndian, header); err != nil {
+		return nil, err
+	}
+	nodes := header[3]
+	// the root has no label and every node has a one and a zero but the root has a zero only.
+	if header[0] != succinctVersion || nodes == 0 || nodes > math.MaxInt64/4 || header[2] != nodes-1 || header[1] != 2*nodes-1 {
+		return nil, ErrInvalidSuccinctTrie
+	}
+	louds, terms := (header[1]+63)/64, (header[3]+63)/64
+	// the bits and labels are allocated once they are read in full.
+	data, ok := readSection(br, 8*louds+header[2]+8*terms)
+	if !ok {
+		return nil, ErrInvalidSuccinctTrie
+	}
+	s := &SuccinctTrie{
+		louds:  bitVector{n: int(header[1]), words: make([]uint64, louds)},
+		labels: make([]byte, header[2]),
+		terms:  bitVector{n: int(header[3]), words: make([]uint64, terms)},
+	}
+	sr := bytes.NewReader(data)
+	for _, v := range []interface{}{s.louds.words, s.labels, s.terms.words} {
+		if err := binary.Read(sr, binary.LittleEndian, v); err != nil {
+			return nil, err
+		}
+	}
+	s.louds.index()
+	s.terms.index()
+	if !s.valid() {
+		return nil, ErrInvalidSuccinctTrie
+	}
+	return s, nil
+}
+
+// valid reports whether every node is numbered after its parent and
+// the number of the ones matches the labels so that the walks terminate in range.
+func (s *SuccinctTrie) valid() bool {
+	var zeros, ones int
+	for i := 0; i < s.louds.n; i++ {
+		if !s.louds.get(i) {
+			zeros++
+			continue
+		}
+		ones++
+		// the node `ones` is a child of the node `zeros`.
+		if zeros >= ones {
+			return false
+		}
+	}
+	return ones == len(s.labels) && !s.louds.get(s.louds.n-1)
+}
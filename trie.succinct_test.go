@@ -0,0 +1,186 @@
+package gtrie
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestBitVector(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	var v bitVector
+	var bits []bool
+	for i := 0; i < 5000; i++ {
+		bit := r.Intn(3) == 0
+		v.push(bit)
+		bits = append(bits, bit)
+	}
+	v.index()
+	var ones, zeros int
+	for i, bit := range bits {
+		if got := v.rank1(i); got != ones {
+			t.Fatalf("rank1(%d) = %d, want %d", i, got, ones)
+		}
+		if bit {
+			if got := v.select1(ones); got != i {
+				t.Fatalf("select1(%d) = %d, want %d", ones, got, i)
+			}
+			ones++
+		} else {
+			if got := v.select0(zeros); got != i {
+				t.Fatalf("select0(%d) = %d, want %d", zeros, got, i)
+			}
+			zeros++
+		}
+	}
+	if got := v.ones(); got != ones {
+		t.Errorf("ones() = %d, want %d", got, ones)
+	}
+}
+
+func newSuccinctFixture() *Trie {
+	trie := newStaticFixture()
+	trie.Add("", "empty")
+	for i := 0; i < 3000; i++ {
+		trie.Add(fmt.Sprintf("/interfaces/interface[name=%d/%d]/state/counters/in-octets", i%7, i), i)
+	}
+	return trie
+}
+
+func checkSuccinct(t *testing.T, trie *Trie, s *SuccinctTrie, values []interface{}) {
+	t.Helper()
+	if s.Size() != trie.Size() {
+		t.Errorf("Size() = %d, want %d", s.Size(), trie.Size())
+	}
+	ids := make(map[int]bool, trie.Size())
+	for key, want := range trie.All() {
+		id, ok := s.Find(key)
+		if !ok {
+			t.Errorf("Find(%q) not found", key)
+			continue
+		}
+		if ids[id] {
+			t.Errorf("Find(%q) = %d, which is a duplicate id", key, id)
+		}
+		ids[id] = true
+		if values != nil && values[id] != want {
+			t.Errorf("values[Find(%q)] = %v, want %v", key, values[id], want)
+		}
+		if got, ok := s.Key(id); !ok || got != key {
+			t.Errorf("Key(%d) = %q, %v, want %q, true", id, got, ok, key)
+		}
+	}
+	for _, key := range []string{"/interfaces/", "/interfaces/interface[name=1/2]/state/x", "苹", "abcd", "zzz"} {
+		if id, ok := s.Find(key); ok {
+			t.Errorf("Find(%q) = %d, true, want not found", key, id)
+		}
+	}
+	if _, ok := s.Key(s.Size()); ok {
+		t.Errorf("Key(%d) is found out of range", s.Size())
+	}
+	for _, prefix := range []string{"", "/interfaces/interface[name=1/", "/interfaces/interface[name=3/10", "苹", "ab", "/x"} {
+		got, want := s.FindByPrefix(prefix), sortedKeys(trie.FindByPrefix(prefix))
+		if len(got) != 0 || len(want) != 0 {
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("FindByPrefix(%q) = %d keys, want %d keys", prefix, len(got), len(want))
+			}
+		}
+	}
+}
+
+func TestTrie_CompileSuccinct(t *testing.T) {
+	trie := newSuccinctFixture()
+	s, values := trie.CompileSuccinct()
+	if len(values) != trie.Size() {
+		t.Errorf("CompileSuccinct() returns %d values, want %d", len(values), trie.Size())
+	}
+	checkSuccinct(t, trie, s, values)
+
+	empty, values := New().CompileSuccinct()
+	if empty.Size() != 0 || len(values) != 0 {
+		t.Errorf("CompileSuccinct() of an empty trie = %d keys, %d values", empty.Size(), len(values))
+	}
+	if _, ok := empty.Find(""); ok {
+		t.Errorf("Find() of an empty succinct trie found a key")
+	}
+	if keys := empty.FindByPrefix(""); len(keys) != 0 {
+		t.Errorf("FindByPrefix() of an empty succinct trie = %v", keys)
+	}
+}
+
+func TestSuccinctTrie_WriteTo(t *testing.T) {
+	trie := newSuccinctFixture()
+	src, values := trie.CompileSuccinct()
+	var buf bytes.Buffer
+	n, err := src.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+	if n != int64(buf.Len()) {
+		t.Errorf("WriteTo() = %d, want %d", n, buf.Len())
+	}
+	data := append([]byte(nil), buf.Bytes()...)
+	s, err := ReadSuccinctTrie(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("ReadSuccinctTrie() error = %v", err)
+	}
+	checkSuccinct(t, trie, s, values)
+
+	buf.Reset()
+	empty, _ := New().CompileSuccinct()
+	if _, err := empty.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo() of an empty succinct trie error = %v", err)
+	}
+	if s, err := ReadSuccinctTrie(&buf); err != nil || s.Size() != 0 {
+		t.Errorf("ReadSuccinctTrie() of an empty succinct trie = %v, %v", s, err)
+	}
+
+	if _, err := ReadSuccinctTrie(bytes.NewReader([]byte("foo\nbar\n"))); err != ErrInvalidSuccinctTrie {
+		t.Errorf("ReadSuccinctTrie() of an invalid input error = %v, want %v", err, ErrInvalidSuccinctTrie)
+	}
+	// drop the first child of the root so that the ones do not match the labels.
+	corrupted := data
+	corrupted[len(succinctMagic)+32] &^= 1
+	if _, err := ReadSuccinctTrie(bytes.NewReader(corrupted)); err != ErrInvalidSuccinctTrie {
+		t.Errorf("ReadSuccinctTrie() of a corrupted input error = %v, want %v", err, ErrInvalidSuccinctTrie)
+	}
+	// the header of 1<<58 nodes is not allocated for the input cut short.
+	header := []byte(succinctMagic)
+	for _, v := range []uint64{succinctVersion, 1<<59 - 1, 1<<58 - 1, 1 << 58} {
+		header = binary.LittleEndian.AppendUint64(header, v)
+	}
+	if _, err := ReadSuccinctTrie(bytes.NewReader(append(header, 1, 2, 3))); err != ErrInvalidSuccinctTrie {
+		t.Errorf("ReadSuccinctTrie() of a corrupted header error = %v, want %v", err, ErrInvalidSuccinctTrie)
+	}
+}
+
+func BenchmarkSuccinctMemory(b *testing.B) {
+	trie := dictTrie(b)
+	var static, succinct uint64
+	for i := 0; i < b.N; i++ {
+		before := heapAlloc()
+		s1 := trie.Compile()
+		static = heapAlloc() - before
+		before = heapAlloc()
+		s2, _ := trie.CompileSuccinct()
+		succinct = heapAlloc() - before
+		runtime.KeepAlive(s1)
+		runtime.KeepAlive(s2)
+	}
+	b.ReportMetric(float64(static)/float64(trie.Size()), "static-bytes/key")
+	b.ReportMetric(float64(succinct)/float64(trie.Size()), "succinct-bytes/key")
+}
+
+func BenchmarkSuccinctFind(b *testing.B) {
+	trie := dictTrie(b)
+	keys := trie.Keys()
+	s, _ := trie.CompileSuccinct()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Find(keys[i%len(keys)])
+	}
+}
@@ -0,0 +1,102 @@
+package gtrie
+
+import "sort"
+
+// suggestion is a candidate key of SuggestCorrections.
+type suggestion struct {
+	node   *trieNode
+	dist   int // edit distance to the input
+	common int // common prefix length with the input
+	length int // key length in runes
+}
+
+// SuggestCorrections returns up to `limit` keys within the Levenshtein (edit) distance
+// `maxDist` from the `input`, ranked by the edit distance, then by the length of
+// the prefix shared with the `input` (longer first), then by the key length (shorter first).
+// No limit is applied if `limit` is zero or less.
+func (t *Trie) SuggestCorrections(input string, maxDist, limit int) []string {
+	if t == nil {
+		return nil
+	}
+	t.rlock()
+	defer t.runlock()
+	found := suggestcollect(t.root, t.runes(input), maxDist)
+	sort.Slice(found, func(i, j int) bool {
+		a, b := found[i], found[j]
+		if a.dist != b.dist {
+			return a.dist < b.dist
+		}
+		if a.common != b.common {
+			return a.common > b.common
+		}
+		if a.length != b.length {
+			return a.length < b.length
+		}
+		return t.lessKey(a.node.key(), b.node.key())
+	})
+	if limit > 0 && len(found) > limit {
+		found = found[:limit]
+	}
+	keys := make([]string, 0, len(found))
+	for _, s := range found {
+		keys = append(keys, s.node.key())
+	}
+	return keys
+}
+
+// suggestcollect is distancecollect also tracking the common prefix length
+// of each key with `key` on the same walk.
+func suggestcollect(node *trieNode, key []rune, k int) []suggestion {
+	if node == nil || k < 0 {
+		return nil
+	}
+	var found []suggestion
+	row := make([]int, len(key)+1)
+	for i := range row {
+		row[i] = i
+	}
+	if c, ok := node.children[nul]; ok && c.term && row[len(key)] <= k {
+		found = append(found, suggestion{node: c, dist: row[len(key)]})
+	}
+	var walk func(n *trieNode, prev []int, common int)
+	walk = func(n *trieNode, prev []int, common int) {
+		// the common prefix grows only while all the runes so far match the key.
+		depth := n.depth
+		if common == depth-1 && depth <= len(key) && key[depth-1] == n.rval {
+			common = depth
+		}
+		cur := make([]int, len(prev))
+		cur[0] = prev[0] + 1
+		least := cur[0]
+		for i := 1; i < len(cur); i++ {
+			cost := 1
+			if key[i-1] == n.rval {
+				cost = 0
+			}
+			cur[i] = min(cur[i-1]+1, prev[i]+1, prev[i-1]+cost)
+			if cur[i] < least {
+				least = cur[i]
+			}
+		}
+		if least > k {
+			return
+		}
+		for r, c := range n.children {
+			if r == nul {
+				if c.term && cur[len(key)] <= k {
+					found = append(found, suggestion{
+						node: c, dist: cur[len(key)], common: common, length: depth,
+					})
+				}
+				continue
+			}
+			walk(c, cur, common)
+		}
+	}
+	for r, c := range node.children {
+		if r != nul {
+			walk(c, row, 0)
+		}
+	}
+	return found
+}
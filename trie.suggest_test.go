@@ -0,0 +1,35 @@
+package gtrie
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTrie_SuggestCorrections(t *testing.T) {
+	trie := newGNMITrie()
+	for _, key := range []string{"foo", "foosball", "football", "foreboding", "forementioned", "foretold", "forbidden"} {
+		trie.Add(key, nil)
+	}
+	tests := []struct {
+		input   string
+		maxDist int
+		limit   int
+		want    []string
+	}{
+		{"foretoldme", 2, 1, []string{"foretold"}},
+		{"foretoldme", 3, 0, []string{"foretold"}},
+		// "fool" is at distance 1 from "foo" and "foot" is not stored;
+		// "foo" shares the longest prefix among the keys at distance 1.
+		{"fool", 1, 0, []string{"foo"}},
+		// the same distance is ranked by the common prefix, then by the length.
+		{"foosbal", 2, 0, []string{"foosball", "football"}},
+		{"/interface/interfaces", 2, 0, []string{"/interfaces/interface"}},
+		{"xyz", 1, 0, []string{}},
+	}
+	for _, tt := range tests {
+		got := trie.SuggestCorrections(tt.input, tt.maxDist, tt.limit)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SuggestCorrections(%q, %d, %d) = %v, want %v", tt.input, tt.maxDist, tt.limit, got, tt.want)
+		}
+	}
+}
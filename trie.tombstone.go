@@ -0,0 +1,103 @@
+package gtrie
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// tombstone is a key removed by RemoveSoft with its value
+// and the time of the removal in Unix nanoseconds.
+type tombstone struct {
+	key     string
+	value   interface{}
+	removed int64
+}
+
+// RemoveSoft removes the `key` like Remove, but leaves its tombstone, so that
+// the key removed is listed by Tombstones until it is purged by PurgeTombstones
+// and can be restored by Undelete. The key soft-removed is not a key of the trie:
+// it is not found by Find and the other searches, and not counted by Size and
+// the term counts of the nodes. Adding the key again, e.g. by Add or Merge,
+// revives it with the new value and discards its tombstone. It returns false if the key does not exist.
+// The tombstones are not moved or copied by MovePrefix and CopyPrefix, and
+// discarded by Clear.
+func (t *Trie) RemoveSoft(key string) bool {
+	if t == nil {
+		return false
+	}
+	t.lock()
+	defer t.unlock()
+	ckey := t.canonical(key)
+	node := findTerm(t.root, ckey)
+	if node == nil {
+		return false
+	}
+	key = node.key()
+	value, _ := t.remove(key)
+	if t.tombs == nil {
+		t.tombs = make(map[string]tombstone)
+	}
+	t.tombs[ckey] = tombstone{key: key, value: value, removed: t.now().UnixNano()}
+	return true
+}
+
+// Tombstones returns the sorted keys starting with `prefix`
+// removed by RemoveSoft and not purged yet.
+func (t *Trie) Tombstones(prefix string) []string {
+	if t == nil {
+		return nil
+	}
+	t.rlock()
+	defer t.runlock()
+	prefix = t.canonical(prefix)
+	var keys []string
+	for ckey, tomb := range t.tombs {
+		if strings.HasPrefix(ckey, prefix) {
+			keys = append(keys, tomb.key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Undelete restores the `key` removed by RemoveSoft with its value removed.
+// It returns false if the key has no tombstone or is rejected by the limits
+// of the trie; the tombstone of the key rejected is kept.
+func (t *Trie) Undelete(key string) bool {
+	if t == nil {
+		return false
+	}
+	t.lock()
+	tomb, ok := t.tombs[t.canonical(key)]
+	var err error
+	if ok {
+		err = t.add(tomb.key, tomb.value)
+	}
+	t.unlock()
+	if err != nil {
+		t.reject(tomb.key, err)
+		return false
+	}
+	return ok
+}
+
+// PurgeTombstones discards the tombstones of the keys soft-removed
+// `olderThan` ago or earlier by the clock of WithClock, e.g.
+// PurgeTombstones(0) discards all. It returns the number of the tombstones discarded.
+func (t *Trie) PurgeTombstones(olderThan time.Duration) int {
+	if t == nil {
+		return 0
+	}
+	t.lock()
+	defer t.unlock()
+	cutoff := t.now().Add(-olderThan).UnixNano()
+	count := 0
+	for ckey, tomb := range t.tombs {
+		if tomb.removed <= cutoff {
+			delete(t.tombs, ckey)
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,108 @@
+package gtrie
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTrie_RemoveSoft(t *testing.T) {
+	for name, opts := range map[string][]Option{
+		"plain":       nil,
+		"atomic":      {WithAtomicReads()},
+		"transformed": {WithKeyTransform(strings.ToLower)},
+	} {
+		now := time.Unix(1000, 0)
+		trie := New(append(opts, WithClock(func() time.Time { return now }))...)
+		for _, key := range []string{"/a", "/a/x", "/a/y", "/b"} {
+			trie.Add(key, key)
+		}
+		if !trie.RemoveSoft("/a/x") || !trie.RemoveSoft("/b") {
+			t.Fatalf("%s: RemoveSoft() = false", name)
+		}
+		if trie.RemoveSoft("/a/x") || trie.RemoveSoft("/c") {
+			t.Errorf("%s: RemoveSoft() of no key = true", name)
+		}
+		// the keys soft-removed are not live.
+		if _, ok := trie.Find("/a/x"); ok {
+			t.Errorf("%s: Find() of the key soft-removed is found", name)
+		}
+		if got := trie.FindByPrefix("/a"); !reflect.DeepEqual(sortedKeys(got), []string{"/a", "/a/y"}) {
+			t.Errorf("%s: FindByPrefix() = %v", name, got)
+		}
+		if trie.Size() != 2 || trie.root.termCount != 2 {
+			t.Errorf("%s: Size() = %d, termCount = %d, want 2", name, trie.Size(), trie.root.termCount)
+		}
+		if got := trie.Tombstones(""); !reflect.DeepEqual(got, []string{"/a/x", "/b"}) {
+			t.Errorf("%s: Tombstones() = %v", name, got)
+		}
+		if got := trie.Tombstones("/A"); name == "transformed" && !reflect.DeepEqual(got, []string{"/a/x"}) {
+			t.Errorf("%s: Tombstones() of the prefix = %v", name, got)
+		}
+
+		// Undelete restores the value removed.
+		if !trie.Undelete("/a/x") || trie.Undelete("/a/x") || trie.Undelete("/c") {
+			t.Errorf("%s: Undelete() is not true only for the tombstone", name)
+		}
+		if v, ok := trie.Find("/a/x"); !ok || v != "/a/x" || trie.Size() != 3 {
+			t.Errorf("%s: Find() of the key undeleted = %v, %v with Size() %d", name, v, ok, trie.Size())
+		}
+		// Add revives the key with the new value.
+		trie.Add("/b", "new")
+		if v, _ := trie.Find("/b"); v != "new" || trie.Size() != 4 || len(trie.Tombstones("")) != 0 {
+			t.Errorf("%s: Find() of the key revived = %v with Size() %d and tombstones %v", name, v, trie.Size(), trie.Tombstones(""))
+		}
+		if trie.Undelete("/b") {
+			t.Errorf("%s: Undelete() of the key revived = true", name)
+		}
+		// Merge revives the key with the value merged as well.
+		trie.RemoveSoft("/b")
+		other := New()
+		other.Add("/b", "merged")
+		if n := trie.Merge(other); n != 1 || len(trie.Tombstones("")) != 0 {
+			t.Errorf("%s: Merge() over the tombstone = %d with tombstones %v", name, n, trie.Tombstones(""))
+		}
+		if trie.Undelete("/b") {
+			t.Errorf("%s: Undelete() of the key merged = true", name)
+		}
+		if v, _ := trie.Find("/b"); v != "merged" || trie.Size() != 4 {
+			t.Errorf("%s: Find() of the key merged = %v with Size() %d", name, v, trie.Size())
+		}
+		checkNodes(t, trie.root, name != "atomic")
+
+		// PurgeTombstones discards the tombstones by their ages.
+		trie.RemoveSoft("/a")
+		now = now.Add(time.Minute)
+		trie.RemoveSoft("/a/y")
+		if n := trie.PurgeTombstones(time.Hour); n != 0 {
+			t.Errorf("%s: PurgeTombstones(1h) = %d, want 0", name, n)
+		}
+		if n := trie.PurgeTombstones(time.Minute); n != 1 || !reflect.DeepEqual(trie.Tombstones(""), []string{"/a/y"}) {
+			t.Errorf("%s: PurgeTombstones(1m) = %d with %v left", name, n, trie.Tombstones(""))
+		}
+		if trie.Undelete("/a") {
+			t.Errorf("%s: Undelete() of the key purged = true", name)
+		}
+		if n := trie.PurgeTombstones(0); n != 1 || trie.Tombstones("") != nil || trie.Size() != 2 {
+			t.Errorf("%s: PurgeTombstones(0) = %d with Size() %d", name, n, trie.Size())
+		}
+	}
+}
+
+func TestTrie_UndeleteRejected(t *testing.T) {
+	trie := New(WithMaxKeys(1))
+	trie.Add("/a", 1)
+	trie.RemoveSoft("/a")
+	trie.Add("/b", 2)
+	if trie.Undelete("/a") {
+		t.Errorf("Undelete() over the limit = true")
+	}
+	if got := trie.Tombstones(""); !reflect.DeepEqual(got, []string{"/a"}) {
+		t.Errorf("Tombstones() after the rejection = %v", got)
+	}
+	trie.Clear()
+	if got := trie.Tombstones(""); got != nil {
+		t.Errorf("Tombstones() after Clear() = %v", got)
+	}
+}
@@ -0,0 +1,152 @@
+package gtrie
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TraceKind is the kind of the mutation reported to the tracer of SetTracer.
+type TraceKind int
+
+// The kinds of the mutations traced.
+const (
+	TraceAdd        TraceKind = iota + 1 // a key added or replaced, including by CopyPrefix, Merge and Txn
+	TraceRemove                          // a key removed
+	TraceClear                           // all the keys removed by Clear
+	TraceMovePrefix                      // the keys moved by MovePrefix
+)
+
+func (k TraceKind) String() string {
+	switch k {
+	case TraceAdd:
+		return "Add"
+	case TraceRemove:
+		return "Remove"
+	case TraceClear:
+		return "Clear"
+	case TraceMovePrefix:
+		return "MovePrefix"
+	}
+	return fmt.Sprintf("TraceKind(%d)", int(k))
+}
+
+// TraceOp is a mutation of the trie reported to the tracer of SetTracer.
+type TraceOp struct {
+	Kind TraceKind
+	// Key is the key added or removed, or the old prefix of MovePrefix.
+	// It is empty for Clear.
+	Key string
+	// NewKey is the new prefix of MovePrefix.
+	NewKey string
+	// Time is when the mutation is made by the clock of WithClock or time.Now.
+	Time time.Time
+	// Caller is the "file:line" of the call of the trie from outside the package
+	// if TraceCaller is given.
+	Caller string
+}
+
+// TraceOption is the option of SetTracer.
+type TraceOption func(*tracer)
+
+// TraceCaller captures the caller of each mutation traced into TraceOp.Caller.
+// It walks the stack on every mutation, so that it is for debugging only.
+func TraceCaller() TraceOption {
+	return func(tr *tracer) {
+		tr.caller = true
+	}
+}
+
+// tracer keeps the mutations traced under the write lock until it is released.
+type tracer struct {
+	fn      func(op TraceOp)
+	caller  bool
+	pending []TraceOp
+	// the deliveries are ordered by the tickets taken under the write lock.
+	mu         sync.Mutex
+	done       sync.Cond
+	last, next uint64
+}
+
+// SetTracer installs the hook `fn` called with each mutation of the trie,
+// e.g. to find which call removed a key. The mutations are collected under
+// the write lock and `fn` is called after the lock is released in the order
+// of the mutations, so that `fn` may block or read the trie, but must not
+// modify it. SetTracer(nil) uninstalls the hook; the mutations are not traced
+// at all (only a nil check) if no hook is installed.
+func (t *Trie) SetTracer(fn func(op TraceOp), opts ...TraceOption) {
+	if t == nil {
+		return
+	}
+	t.lock()
+	defer t.unlock()
+	if fn == nil {
+		t.tracer = nil
+		return
+	}
+	t.tracer = &tracer{fn: fn}
+	t.tracer.done.L = &t.tracer.mu
+	for _, opt := range opts {
+		opt(t.tracer)
+	}
+}
+
+// trace records the mutation under the write lock.
+func (t *Trie) trace(kind TraceKind, key, newKey string) {
+	op := TraceOp{Kind: kind, Key: key, NewKey: newKey, Time: t.now()}
+	if t.tracer.caller {
+		op.Caller = caller()
+	}
+	t.tracer.pending = append(t.tracer.pending, op)
+}
+
+// deliver releases the write lock `mu` and calls the hook with the mutations
+// traced under the lock after the mutations traced before are delivered.
+// The write lock is not held while waiting, so that the hook can read the trie.
+func (tr *tracer) deliver(mu *sync.RWMutex) {
+	ops := tr.pending
+	tr.pending = nil
+	tr.last++
+	ticket := tr.last
+	mu.Unlock()
+	tr.mu.Lock()
+	for tr.next+1 != ticket {
+		tr.done.Wait()
+	}
+	tr.mu.Unlock()
+	defer func() {
+		tr.mu.Lock()
+		tr.next = ticket
+		tr.done.Broadcast()
+		tr.mu.Unlock()
+	}()
+	for _, op := range ops {
+		tr.fn(op)
+	}
+}
+
+// packageDir is the directory of the source files of the package,
+// whose frames are skipped by caller.
+var packageDir = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Dir(file)
+}()
+
+// caller returns the "file:line" of the first frame outside the package.
+// The tests of the package are taken as the callers.
+func caller() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		f, more := frames.Next()
+		if filepath.Dir(f.File) != packageDir || strings.HasSuffix(f.File, "_test.go") {
+			return fmt.Sprintf("%s:%d", f.File, f.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package gtrie
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+// nextLine returns the "file:line" of the line after the call.
+func nextLine() string {
+	_, file, line, _ := runtime.Caller(1)
+	return fmt.Sprintf("%s:%d", file, line+1)
+}
+
+func TestTrie_SetTracer(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithAtomicReads()}} {
+		now := time.Unix(1000, 0)
+		trie := New(append(opts, WithClock(func() time.Time { return now }))...)
+		trie.Add("/untraced", 0)
+		var got []TraceOp
+		trie.SetTracer(func(op TraceOp) {
+			got = append(got, op)
+		}, TraceCaller())
+
+		var want []TraceOp
+		step := func(kind TraceKind, key, newKey, caller string) {
+			want = append(want, TraceOp{Kind: kind, Key: key, NewKey: newKey, Time: now, Caller: caller})
+			now = now.Add(time.Second)
+		}
+		line := nextLine()
+		trie.Add("/a/x", 1)
+		step(TraceAdd, "/a/x", "", line)
+		line = nextLine()
+		trie.Add("/a/x", 2)
+		step(TraceAdd, "/a/x", "", line)
+		line = nextLine()
+		trie.Remove("/a/x")
+		step(TraceRemove, "/a/x", "", line)
+		trie.Remove("/none")
+		line = nextLine()
+		trie.Add("/a/y", 3)
+		step(TraceAdd, "/a/y", "", line)
+		line = nextLine()
+		trie.MovePrefix("/a/", "/b/")
+		step(TraceMovePrefix, "/a/", "/b/", line)
+		line = nextLine()
+		trie.Clear()
+		step(TraceClear, "", "", line)
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("the trace is\n%v\nwant\n%v", got, want)
+		}
+
+		trie.SetTracer(nil)
+		trie.Add("/untraced", 0)
+		if len(got) != len(want) {
+			t.Errorf("the trace after SetTracer(nil) = %v", got[len(want):])
+		}
+	}
+}
+
+func TestTrie_SetTracerOutsideLock(t *testing.T) {
+	trie := New()
+	var mu sync.Mutex
+	last := make(map[string]int)
+	trie.SetTracer(func(op TraceOp) {
+		// the hook can read the trie.
+		if _, ok := trie.Find(op.Key); !ok {
+			t.Errorf("Find(%q) in the hook = false", op.Key)
+		}
+		var g, i int
+		fmt.Sscanf(op.Key, "/%d/%d", &g, &i)
+		mu.Lock()
+		defer mu.Unlock()
+		if n, ok := last[fmt.Sprint(g)]; ok && i != n+1 {
+			t.Errorf("%q is traced after /%d/%d", op.Key, g, n)
+		}
+		last[fmt.Sprint(g)] = i
+	})
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				trie.Add(fmt.Sprintf("/%d/%d", g, i), i)
+			}
+		}(g)
+	}
+	wg.Wait()
+	if len(last) != 8 {
+		t.Errorf("the trace has the keys of %d goroutines, want 8", len(last))
+	}
+	if op := TraceKind(9).String(); op != "TraceKind(9)" || TraceMovePrefix.String() != "MovePrefix" {
+		t.Errorf("String() = %q", op)
+	}
+}
+
+func BenchmarkTracer(b *testing.B) {
+	keys := make([]string, 1024)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("/interfaces/interface[name=%d]/state", i)
+	}
+	for _, bench := range []struct {
+		name string
+		fn   func(op TraceOp)
+		opts []TraceOption
+	}{
+		{"nil", nil, nil},
+		{"set", func(op TraceOp) {}, nil},
+		{"caller", func(op TraceOp) {}, []TraceOption{TraceCaller()}},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			trie := New()
+			trie.SetTracer(bench.fn, bench.opts...)
+			for i := 0; i < b.N; i++ {
+				trie.Add(keys[i%len(keys)], i)
+			}
+		})
+	}
+}
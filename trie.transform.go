@@ -0,0 +1,192 @@
+package gtrie
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// KeyTransform converts a key into its canonical form.
+// The keys having the same canonical form are regarded as the same key.
+type KeyTransform func(key string) string
+
+// WithKeyTransform makes the trie store and look up the keys by the canonical form
+// converted by `fn`, while the original key added last is kept and returned as the key.
+// For example, with FoldDiacritics(), Find("cafe") finds the value added by Add("café")
+// and FindByPrefix("caf") returns "café".
+//
+// The transform is applied to the input keys of Add, Remove, Find and
+// the prefix, matching prefix, fuzzy, suffix, wildcard and distance searches,
+// which match the canonical keys.
+// The keys rebuilt from the trie structure (e.g. LongestCommonPrefix) are in the canonical form.
+func WithKeyTransform(fn KeyTransform) Option {
+	return func(t *Trie) {
+		t.transform = fn
+	}
+}
+
+// canonical returns the `key` converted by the key transform of the trie.
+func (t *Trie) canonical(key string) string {
+	if t.transform != nil {
+		key = t.transform(key)
+	}
+	return key
+}
+
+// runes returns the runes of the `key` converted by the key transform of the trie.
+func (t *Trie) runes(key string) []rune {
+	return []rune(t.canonical(key))
+}
+
+// FindWith finds the value of the `key` comparing the keys by the `transform`
+// instead of the key transform of the trie. If `transform` is nil,
+// the original key stored must be equal to the `key` exactly.
+// `transform` must not be coarser than the key transform of the trie,
+// i.e. the keys equal by `transform` must be equal by the key transform of the trie.
+func (t *Trie) FindWith(key string, transform KeyTransform) (interface{}, bool) {
+	if t == nil {
+		return nil, false
+	}
+	t.rlock()
+	defer t.runlock()
+	node := findNode(t.root, t.canonical(key))
+	if node == nil {
+		return nil, false
+	}
+	node, ok := node.children[nul]
+	if !ok || !node.term {
+		return nil, false
+	}
+	if transformKey(transform, node.key()) != transformKey(transform, key) {
+		return nil, false
+	}
+	return node.value, true
+}
+
+// FindByPrefixWith returns all the keys starting with `prefix` comparing the keys by the `transform`
+// instead of the key transform of the trie. If `transform` is nil,
+// the original keys stored must start with the `prefix` exactly.
+// `transform` must not be coarser than the key transform of the trie.
+func (t *Trie) FindByPrefixWith(prefix string, transform KeyTransform) []string {
+	if t == nil {
+		return nil
+	}
+	t.rlock()
+	defer t.runlock()
+	node := findNode(t.root, t.canonical(prefix))
+	if node == nil {
+		return nil
+	}
+	prefix = transformKey(transform, prefix)
+	var keys []string
+	for _, n := range collectNodes(node) {
+		if strings.HasPrefix(transformKey(transform, n.key()), prefix) {
+			keys = append(keys, n.key())
+		}
+	}
+	return keys
+}
+
+// FindCollisions returns the keys of the trie colliding under the `transform`,
+// i.e. the groups of the keys converted to the same form by the `transform`
+// by the form, e.g. to check the keys before a key transform of WithKeyTransform
+// or WithGNMIPathNormalization is enabled. The keys of a group are in lexicographic
+// order; a key of the form of no other key is not returned.
+// The trie is walked once and only the first key of each form is held apart from
+// the groups. It returns an empty map if no keys collide or `transform` is nil.
+func (t *Trie) FindCollisions(transform KeyTransform) map[string][]string {
+	if t == nil {
+		return make(map[string][]string)
+	}
+	groups := make(map[string][]string)
+	if transform == nil {
+		return groups
+	}
+	t.rlock()
+	defer t.runlock()
+	first := make(map[string]string, t.root.termCount)
+	walkTerms(t.root, func(n *trieNode) {
+		key := n.key()
+		form := transform(key)
+		f, ok := first[form]
+		if !ok {
+			first[form] = key
+			return
+		}
+		if groups[form] == nil {
+			groups[form] = []string{f}
+		}
+		groups[form] = append(groups[form], key)
+	})
+	for _, keys := range groups {
+		sort.Strings(keys)
+	}
+	return groups
+}
+
+func transformKey(transform KeyTransform, key string) string {
+	if transform == nil {
+		return key
+	}
+	return transform(key)
+}
+
+// FoldDiacritics returns a KeyTransform removing the diacritical marks,
+// e.g. "café" and "cafe\u0301" become "cafe". The precomposed Latin letters are
+// decomposed as the canonical decomposition (NFD) and then all the nonspacing marks (Mn)
+// are removed.
+func FoldDiacritics() KeyTransform {
+	return foldDiacritics
+}
+
+func foldDiacritics(key string) string {
+	var b strings.Builder
+	b.Grow(len(key))
+	for _, r := range key {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		if base, ok := diacriticBase[r]; ok {
+			r = base
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// diacriticBase maps the precomposed Latin letters
+// (U+00C0-U+024F and U+1E00-U+1EFF) to their base letters.
+var diacriticBase = func() map[rune]rune {
+	from := []rune(diacriticFrom)
+	to := []rune(diacriticTo)
+	m := make(map[rune]rune, len(from))
+	for i := range from {
+		m[from[i]] = to[i]
+	}
+	return m
+}()
+
+// diacriticFrom and diacriticTo are generated from the Unicode canonical decomposition.
+const diacriticFrom = "ÀÁÂÃÄÅÇÈÉÊËÌÍÎÏÑÒÓÔÕÖÙÚÛÜÝàáâãäåçèéêëìíîïñòóôõöù" +
+	"úûüýÿĀāĂăĄąĆćĈĉĊċČčĎďĒēĔĕĖėĘęĚěĜĝĞğĠġĢģĤĥĨĩĪīĬĭĮ" +
+	"įİĴĵĶķĹĺĻļĽľŃńŅņŇňŌōŎŏŐőŔŕŖŗŘřŚśŜŝŞşŠšŢţŤťŨũŪūŬŭ" +
+	"ŮůŰűŲųŴŵŶŷŸŹźŻżŽžƠơƯưǍǎǏǐǑǒǓǔǕǖǗǘǙǚǛǜǞǟǠǡǢǣǦǧǨǩǪ" +
+	"ǫǬǭǮǯǰǴǵǸǹǺǻǼǽǾǿȀȁȂȃȄȅȆȇȈȉȊȋȌȍȎȏȐȑȒȓȔȕȖȗȘșȚțȞȟȦȧ" +
+	"ȨȩȪȫȬȭȮȯȰȱȲȳḀḁḂḃḄḅḆḇḈḉḊḋḌḍḎḏḐḑḒḓḔḕḖḗḘḙḚḛḜḝḞḟḠḡḢḣ" +
+	"ḤḥḦḧḨḩḪḫḬḭḮḯḰḱḲḳḴḵḶḷḸḹḺḻḼḽḾḿṀṁṂṃṄṅṆṇṈṉṊṋṌṍṎṏṐṑṒṓ" +
+	"ṔṕṖṗṘṙṚṛṜṝṞṟṠṡṢṣṤṥṦṧṨṩṪṫṬṭṮṯṰṱṲṳṴṵṶṷṸṹṺṻṼṽṾṿẀẁẂẃ" +
+	"ẄẅẆẇẈẉẊẋẌẍẎẏẐẑẒẓẔẕẖẗẘẙẛẠạẢảẤấẦầẨẩẪẫẬậẮắẰằẲẳẴẵẶặẸ" +
+	"ẹẺẻẼẽẾếỀềỂểỄễỆệỈỉỊịỌọỎỏỐốỒồỔổỖỗỘộỚớỜờỞởỠỡỢợỤụỦủỨ" +
+	"ứỪừỬửỮữỰựỲỳỴỵỶỷỸỹ"
+
+const diacriticTo = "AAAAAACEEEEIIIINOOOOOUUUUYaaaaaaceeeeiiiinooooou" +
+	"uuuyyAaAaAaCcCcCcCcDdEeEeEeEeEeGgGgGgGgHhIiIiIiI" +
+	"iIJjKkLlLlLlNnNnNnOoOoOoRrRrRrSsSsSsSsTtTtUuUuUu" +
+	"UuUuUuWwYyYZzZzZzOoUuAaIiOoUuUuUuUuUuAaAaÆæGgKkO" +
+	"oOoƷʒjGgNnAaÆæØøAaAaEeEeIiIiOoOoRrRrUuUuSsTtHhAa" +
+	"EeOoOoOoOoYyAaBbBbBbCcDdDdDdDdDdEeEeEeEeEeFfGgHh" +
+	"HhHhHhHhIiIiKkKkKkLlLlLlLlMmMmMmNnNnNnNnOoOoOoOo" +
+	"PpPpRrRrRrRrSsSsSsSsSsTtTtTtTtUuUuUuUuUuVvVvWwWw" +
+	"WwWwWwXxXxYyZzZzZzhtwyſAaAaAaAaAaAaAaAaAaAaAaAaE" +
+	"eEeEeEeEeEeEeEeIiIiOoOoOoOoOoOoOoOoOoOoOoOoUuUuU" +
+	"uUuUuUuUuYyYyYyYy"
@@ -0,0 +1,168 @@
+package gtrie
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFoldDiacritics(t *testing.T) {
+	fold := FoldDiacritics()
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"café", "cafe"},
+		{"café", "cafe"},
+		{"Ångström", "Angstrom"},
+		{"Ångström", "Angstrom"},
+		{"naïve façade", "naive facade"},
+		{"Tiếng Việt", "Tieng Viet"},
+		{"苹果", "苹果"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := fold(tt.in); got != tt.want {
+			t.Errorf("FoldDiacritics()(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTrie_WithKeyTransform(t *testing.T) {
+	trie := New(WithKeyTransform(FoldDiacritics()))
+	trie.Add("café", 1)
+	trie.Add("Ångström", 2)
+	trie.Add("naïve", 3)
+
+	for _, key := range []string{"cafe", "café", "café"} {
+		if v, ok := trie.Find(key); !ok || v != 1 {
+			t.Errorf("Find(%q) = %v, %v, want 1", key, v, ok)
+		}
+	}
+	if got, want := trie.FindByPrefix("caf"), []string{"café"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FindByPrefix() = %v, want %v", got, want)
+	}
+	if got, want := trie.FindByPrefix("Ångs"), []string{"Ångström"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FindByPrefix() = %v, want %v", got, want)
+	}
+	if k, v, ok := trie.FindLongestMatchingPrefix("naive-bayes"); !ok || k != "naïve" || v != 3 {
+		t.Errorf("FindLongestMatchingPrefix() = %q, %v, %v", k, v, ok)
+	}
+
+	// per-call override for the exact match
+	if _, ok := trie.FindWith("cafe", nil); ok {
+		t.Errorf("FindWith(\"cafe\", nil) finds the key added as \"café\"")
+	}
+	if v, ok := trie.FindWith("café", nil); !ok || v != 1 {
+		t.Errorf("FindWith(\"café\", nil) = %v, %v, want 1", v, ok)
+	}
+	// compose is finer than FoldDiacritics; it only unifies the decomposed "é".
+	compose := strings.NewReplacer("e\u0301", "é").Replace
+	if v, ok := trie.FindWith("cafe\u0301", compose); !ok || v != 1 {
+		t.Errorf("FindWith(\"cafe\\u0301\", compose) = %v, %v, want 1", v, ok)
+	}
+	if _, ok := trie.FindWith("cafe", compose); ok {
+		t.Errorf("FindWith(\"cafe\", compose) finds the key added as \"café\"")
+	}
+	if got := trie.FindByPrefixWith("Angs", nil); len(got) != 0 {
+		t.Errorf("FindByPrefixWith(\"Angs\", nil) = %v, want none", got)
+	}
+	if got, want := trie.FindByPrefixWith("Ångs", nil), []string{"Ångström"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FindByPrefixWith(\"Ångs\", nil) = %v, want %v", got, want)
+	}
+
+	// the keys of the same canonical form are the same key.
+	trie.Add("cafe", 4)
+	if trie.Size() != 3 {
+		t.Errorf("Size() = %d, want 3", trie.Size())
+	}
+	if got, want := trie.FindByPrefix("caf"), []string{"cafe"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FindByPrefix() = %v, want %v", got, want)
+	}
+	if v := trie.Remove("café"); v != 4 {
+		t.Errorf("Remove() = %v, want 4", v)
+	}
+	if trie.Size() != 2 || trie.HasPrefix("caf") {
+		t.Errorf("Remove() leaves the key")
+	}
+}
+
+func TestTrie_WithKeyTransformPatterns(t *testing.T) {
+	trie := New(WithKeyTransform(strings.ToLower))
+	trie.Add("Interface/Eth0", 1)
+	trie.Add("Interface/Eth1", 2)
+	want := []string{"Interface/Eth0"}
+	for _, suffix := range []string{"eth0", "ETH0", "/Eth0"} {
+		if got := trie.FindBySuffix(suffix); !reflect.DeepEqual(got, want) {
+			t.Errorf("FindBySuffix(%q) = %v, want %v", suffix, got, want)
+		}
+		if got := trie.FindBySuffixAll(suffix); !reflect.DeepEqual(got, map[string]interface{}{want[0]: 1}) {
+			t.Errorf("FindBySuffixAll(%q) = %v", suffix, got)
+		}
+		if got := trie.Search(suffix, SearchSuffix); !reflect.DeepEqual(got, want) {
+			t.Errorf("Search(%q, SearchSuffix) = %v, want %v", suffix, got, want)
+		}
+		if got, _ := trie.SearchWithOptions(suffix, SearchSuffix, Sorted()); !reflect.DeepEqual(got, want) {
+			t.Errorf("SearchWithOptions(%q, SearchSuffix, Sorted) = %v, want %v", suffix, got, want)
+		}
+		if e := trie.Explain(SearchSuffix, suffix, want[0]); !e.Matched {
+			t.Errorf("Explain(SearchSuffix, %q) = %+v", suffix, e)
+		}
+	}
+	for _, pattern := range []string{"inter*0", "INTER*0", "Inter?ace/ETH0"} {
+		if got := trie.FindByWildcard(pattern); !reflect.DeepEqual(got, want) {
+			t.Errorf("FindByWildcard(%q) = %v, want %v", pattern, got, want)
+		}
+		if got := trie.FindByWildcardValue(pattern); !reflect.DeepEqual(got, []interface{}{1}) {
+			t.Errorf("FindByWildcardValue(%q) = %v", pattern, got)
+		}
+		if got := trie.Search(pattern, SearchWildcard); !reflect.DeepEqual(got, want) {
+			t.Errorf("Search(%q, SearchWildcard) = %v, want %v", pattern, got, want)
+		}
+		if got, _ := trie.SearchWithOptions(pattern, SearchWildcard, Sorted()); !reflect.DeepEqual(got, want) {
+			t.Errorf("SearchWithOptions(%q, SearchWildcard, Sorted) = %v, want %v", pattern, got, want)
+		}
+		if e := trie.Explain(SearchWildcard, pattern, want[0]); !e.Matched {
+			t.Errorf("Explain(SearchWildcard, %q) = %+v", pattern, e)
+		}
+	}
+	if got := trie.FindWithinDistance("INTERFACE/ETH2", 1); len(got) != 2 {
+		t.Errorf("FindWithinDistance() = %v, want both", got)
+	}
+}
+
+func TestTrie_FindCollisions(t *testing.T) {
+	trie := New()
+	for _, key := range []string{
+		"/Interfaces/Interface[name=eth0]", "/interfaces/interface[name=eth0]", "/INTERFACES/interface[name=eth0]",
+		"/interfaces/interface[name=eth1]",
+		"/a/b[x=1][y=2]", "/a/b[y=2][x=1]", "/a/b[ x=1 ][y=2]", "/a/b[x=1][y=3]",
+		"/System", "/system",
+	} {
+		trie.Add(key, nil)
+	}
+	want := map[string][]string{
+		"/interfaces/interface[name=eth0]": {"/INTERFACES/interface[name=eth0]", "/Interfaces/Interface[name=eth0]", "/interfaces/interface[name=eth0]"},
+		"/system":                          {"/System", "/system"},
+	}
+	if got := trie.FindCollisions(strings.ToLower); !reflect.DeepEqual(got, want) {
+		t.Errorf("FindCollisions(ToLower) = %v, want %v", got, want)
+	}
+	want = map[string][]string{
+		"/a/b[x=1][y=2]": {"/a/b[ x=1 ][y=2]", "/a/b[x=1][y=2]", "/a/b[y=2][x=1]"},
+	}
+	if got := trie.FindCollisions(NormalizeGNMIPath); !reflect.DeepEqual(got, want) {
+		t.Errorf("FindCollisions(NormalizeGNMIPath) = %v, want %v", got, want)
+	}
+	if got := trie.FindCollisions(nil); len(got) != 0 {
+		t.Errorf("FindCollisions(nil) = %v, want none", got)
+	}
+	// the keys found collide in the trie of the transform.
+	folded := New(WithKeyTransform(strings.ToLower))
+	for _, key := range trie.Keys() {
+		folded.Add(key, nil)
+	}
+	if folded.Size() != trie.Size()-3 {
+		t.Errorf("Size() with the transform = %d, want %d", folded.Size(), trie.Size()-3)
+	}
+}
@@ -0,0 +1,135 @@
+package gtrie
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// treeValue is the field of the value of a key in the objects of the tree JSON.
+const treeValue = "_value"
+
+var (
+	// ErrReservedSegment is returned by MarshalTreeJSON if a segment of a key
+	// is the name of the value field "_value".
+	ErrReservedSegment = errors.New("gtrie: reserved segment _value")
+	// ErrInvalidTreeJSON is returned by UnmarshalTreeJSON if the input is not
+	// the nested objects of MarshalTreeJSON.
+	ErrInvalidTreeJSON = errors.New("gtrie: invalid tree json")
+)
+
+// splitSegments splits the `key` by the delimiter `delim` after the leading
+// one, if any. The delimiters in the key predicates of the gNMI path elements
+// (e.g. "interface[name=1/2]") and the delimiters escaped by EscapeSegment
+// do not split the key. The key "" has no segment.
+func splitSegments(key string, delim rune) []string {
+	if key == "" {
+		return nil
+	}
+	d := string(delim)
+	key = strings.TrimPrefix(key, d)
+	var segs []string
+	start := 0
+	for i := 0; i < len(key); {
+		if key[i] == keyEscape && delim != keyEscape {
+			i += 2
+			continue
+		}
+		if key[i] == '[' {
+			if end := predicateEnd(key, i+1); end >= 0 {
+				i = end + 1
+				continue
+			}
+		}
+		if strings.HasPrefix(key[i:], d) {
+			segs = append(segs, key[start:i])
+			i += len(d)
+			start = i
+			continue
+		}
+		i++
+	}
+	return append(segs, key[start:])
+}
+
+// MarshalTreeJSON returns the keys and values of the trie as the nested JSON
+// objects of the segments of the keys split by `delim`, with the value of
+// a key in the "_value" field of the object of its last segment, e.g.
+//
+//	{"interfaces":{"interface[name=1/2]":{"state":{"enabled":{"_value":true}}}}}
+//
+// for the key "/interfaces/interface[name=1/2]/state/enabled". An object can
+// have both "_value" and the objects of the longer keys. The keys are taken
+// as the paths from the root: the leading delimiter is not a segment, so
+// "a" and "/a" are the same object, and the key "" is "_value" of the top object.
+// The delimiters in the key predicates of the gNMI path elements do not split
+// the keys. It returns ErrReservedSegment if a segment is "_value".
+func (t *Trie) MarshalTreeJSON(delim rune) ([]byte, error) {
+	if t == nil {
+		return new(Trie).MarshalTreeJSON(delim)
+	}
+	root := t.readRoot()
+	kvs := nodeKVs(collectNodes(root))
+	t.readDone()
+	tree := make(map[string]interface{})
+	for _, kv := range kvs {
+		obj := tree
+		for _, seg := range splitSegments(kv.Key, delim) {
+			if seg == treeValue {
+				return nil, fmt.Errorf("%w: %q", ErrReservedSegment, kv.Key)
+			}
+			child, ok := obj[seg].(map[string]interface{})
+			if !ok {
+				child = make(map[string]interface{})
+				obj[seg] = child
+			}
+			obj = child
+		}
+		obj[treeValue] = kv.Value
+	}
+	return json.Marshal(tree)
+}
+
+// UnmarshalTreeJSON adds the keys and values of the nested JSON objects of
+// MarshalTreeJSON to the trie. The keys are rebuilt with the leading delimiter,
+// e.g. the key "a" marshaled is added as "/a". The values are decoded by
+// encoding/json into interface{}, e.g. a number into float64.
+// It returns ErrInvalidTreeJSON if an object has a field that is neither
+// "_value" nor an object; no key is added then.
+func (t *Trie) UnmarshalTreeJSON(data []byte, delim rune) error {
+	if t == nil {
+		return ErrNilTrie
+	}
+	var tree map[string]interface{}
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTreeJSON, err)
+	}
+	var kvs []KV
+	d := string(delim)
+	var walk func(obj map[string]interface{}, key string) error
+	walk = func(obj map[string]interface{}, key string) error {
+		for seg, v := range obj {
+			if seg == treeValue {
+				kvs = append(kvs, KV{Key: key, Value: v})
+				continue
+			}
+			child, ok := v.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("%w: %q of %q is not an object", ErrInvalidTreeJSON, seg, key)
+			}
+			// the segments of the top object follow the leading delimiter.
+			if err := walk(child, key+d+seg); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(tree, ""); err != nil {
+		return err
+	}
+	for _, kv := range kvs {
+		t.Add(kv.Key, kv.Value)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package gtrie
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestTrie_MarshalTreeJSON(t *testing.T) {
+	trie := newGNMITrie()
+	got, err := trie.MarshalTreeJSON('/')
+	if err != nil {
+		t.Fatalf("MarshalTreeJSON() error = %v", err)
+	}
+	golden, err := os.ReadFile("fixtures/gnmi_tree.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want bytes.Buffer
+	if err := json.Compact(&want, golden); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want.Bytes()) {
+		t.Errorf("MarshalTreeJSON() = %s, want %s", got, want.Bytes())
+	}
+
+	restored := New()
+	if err := restored.UnmarshalTreeJSON(got, '/'); err != nil {
+		t.Fatalf("UnmarshalTreeJSON() error = %v", err)
+	}
+	if !reflect.DeepEqual(restored.All(), trie.All()) {
+		t.Errorf("UnmarshalTreeJSON() = %v, want %v", restored.All(), trie.All())
+	}
+}
+
+func TestTrie_MarshalTreeJSONEdges(t *testing.T) {
+	trie := New()
+	for key, v := range map[string]interface{}{"": "root", "/": "slash", "/a": 1.0, "/a/": "a/", "/a/b": []interface{}{"x"}, "|b|c": nil} {
+		trie.Add(key, v)
+	}
+	data, err := trie.MarshalTreeJSON('/')
+	if err != nil {
+		t.Fatalf("MarshalTreeJSON() error = %v", err)
+	}
+	want := `{"":{"_value":"slash"},"_value":"root","a":{"":{"_value":"a/"},"_value":1,"b":{"_value":["x"]}},"|b|c":{"_value":null}}`
+	if string(data) != want {
+		t.Errorf("MarshalTreeJSON() = %s, want %s", data, want)
+	}
+	restored := New()
+	if err := restored.UnmarshalTreeJSON(data, '/'); err != nil {
+		t.Fatalf("UnmarshalTreeJSON() error = %v", err)
+	}
+	// the key without the leading delimiter is restored with it.
+	trie.Add("/|b|c", nil)
+	trie.Remove("|b|c")
+	if !reflect.DeepEqual(restored.All(), trie.All()) {
+		t.Errorf("UnmarshalTreeJSON() = %v, want %v", restored.All(), trie.All())
+	}
+
+	trie.Add("/x/_value", true)
+	if _, err := trie.MarshalTreeJSON('/'); !errors.Is(err, ErrReservedSegment) {
+		t.Errorf("MarshalTreeJSON() error = %v, want %v", err, ErrReservedSegment)
+	}
+	for _, data := range []string{`[]`, `{"a":1}`, `{"a":{"b":"c"}}`, `{`} {
+		trie := New()
+		if err := trie.UnmarshalTreeJSON([]byte(data), '/'); !errors.Is(err, ErrInvalidTreeJSON) || trie.Size() != 0 {
+			t.Errorf("UnmarshalTreeJSON(%s) error = %v with %d keys, want %v", data, err, trie.Size(), ErrInvalidTreeJSON)
+		}
+	}
+}
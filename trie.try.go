@@ -0,0 +1,79 @@
+package gtrie
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrLockTimeout is returned if the lock of the trie is not acquired in time.
+var ErrLockTimeout = errors.New("gtrie: lock timeout")
+
+// maxTryBackoff is the longest wait between the attempts to acquire the lock.
+const maxTryBackoff = time.Millisecond
+
+// tryLock calls `try` until it acquires the lock or `timeout` elapses.
+// The wait between the attempts grows exponentially up to maxTryBackoff.
+func tryLock(try func() bool, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	backoff := 10 * time.Microsecond
+	for !try() {
+		remain := time.Until(deadline)
+		if remain <= 0 {
+			return false
+		}
+		time.Sleep(min(backoff, remain))
+		backoff = min(backoff*2, maxTryBackoff)
+	}
+	return true
+}
+
+// TryAdd is Add giving up if the write lock is not acquired within `timeout`.
+// It returns false if the key and value are not added for the timeout
+// or for the limits of the trie.
+func (t *Trie) TryAdd(key string, value interface{}, timeout time.Duration) bool {
+	if t == nil {
+		return false
+	}
+	t.init()
+	if !tryLock(t.tryLock, timeout) {
+		return false
+	}
+	err := t.add(key, value)
+	t.unlock()
+	if err != nil {
+		t.reject(key, err)
+		return false
+	}
+	return true
+}
+
+// TryFind is Find giving up with ErrLockTimeout if the read lock is not acquired
+// within `timeout`. It never waits in the atomic read mode (WithAtomicReads).
+func (t *Trie) TryFind(key string, timeout time.Duration) (interface{}, bool, error) {
+	if t == nil {
+		return nil, false, nil
+	}
+	if t.atomicReads {
+		v, ok := t.Find(key)
+		return v, ok, nil
+	}
+	t.init()
+	if !tryLock(t.tryRLock, timeout) {
+		return nil, false, ErrLockTimeout
+	}
+	defer t.runlock()
+	node := findNode(t.root, t.canonical(key))
+	if node != nil {
+		node = node.children[nul]
+	}
+	if node == nil || !node.term {
+		if t.metrics != nil {
+			t.metrics.IncCounter(MetricFindMiss)
+		}
+		return nil, false, nil
+	}
+	if t.metrics != nil {
+		t.metrics.IncCounter(MetricFindHit)
+	}
+	return node.value, true, nil
+}
@@ -0,0 +1,78 @@
+package gtrie
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTrie_TryAddTryFind(t *testing.T) {
+	trie := New()
+	if !trie.TryAdd("/a", 1, time.Millisecond) {
+		t.Fatalf("TryAdd() = false without contention")
+	}
+	if v, ok, err := trie.TryFind("/a", time.Millisecond); v != 1 || !ok || err != nil {
+		t.Errorf("TryFind() = %v, %v, %v, want 1, true, nil", v, ok, err)
+	}
+	if v, ok, err := trie.TryFind("/b", time.Millisecond); v != nil || ok || err != nil {
+		t.Errorf("TryFind() = %v, %v, %v, want nil, false, nil", v, ok, err)
+	}
+
+	// hold the write lock from a helper goroutine.
+	locked := make(chan struct{})
+	release := make(chan struct{})
+	go func() {
+		trie.mu.Lock()
+		close(locked)
+		<-release
+		trie.mu.Unlock()
+	}()
+	<-locked
+	const timeout = 20 * time.Millisecond
+	start := time.Now()
+	if trie.TryAdd("/b", 2, timeout) {
+		t.Errorf("TryAdd() = true while the lock is held")
+	}
+	if elapsed := time.Since(start); elapsed < timeout || elapsed > 10*timeout {
+		t.Errorf("TryAdd() gives up in %v, want about %v", elapsed, timeout)
+	}
+	start = time.Now()
+	if _, _, err := trie.TryFind("/a", timeout); !errors.Is(err, ErrLockTimeout) {
+		t.Errorf("TryFind() error = %v, want %v", err, ErrLockTimeout)
+	}
+	if elapsed := time.Since(start); elapsed < timeout || elapsed > 10*timeout {
+		t.Errorf("TryFind() gives up in %v, want about %v", elapsed, timeout)
+	}
+
+	// the lock released during the wait is acquired.
+	time.AfterFunc(timeout, func() { close(release) })
+	if !trie.TryAdd("/b", 2, time.Second) {
+		t.Errorf("TryAdd() = false after the lock is released")
+	}
+	if v, ok, err := trie.TryFind("/b", time.Second); v != 2 || !ok || err != nil {
+		t.Errorf("TryFind() = %v, %v, %v, want 2, true, nil", v, ok, err)
+	}
+}
+
+func TestTrie_TryAddPrefixLocks(t *testing.T) {
+	trie := New(WithPrefixLocks('/'))
+	trie.Add("/a/1", 1)
+	trie.Add("/q/1", 2)
+	trie.Add("/q/z", 3)
+	// the removal from the partition leaves the masks of the shared nodes stale.
+	trie.Remove("/q/z")
+	if !trie.plocks.stale.Load() {
+		t.Fatalf("Remove() leaves the masks fixed")
+	}
+	if !trie.TryAdd("/b", 4, time.Millisecond) {
+		t.Fatalf("TryAdd() = false without contention")
+	}
+	// the masks are fixed by the write lock of TryAdd.
+	if trie.plocks.stale.Load() {
+		t.Errorf("TryAdd() leaves the masks stale")
+	}
+	checkNodes(t, trie.root, true)
+	if got := trie.FindByFuzzy("z"); len(got) != 0 {
+		t.Errorf("FindByFuzzy() = %v, want none", got)
+	}
+}
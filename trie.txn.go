@@ -0,0 +1,176 @@
+package gtrie
+
+import (
+	"fmt"
+	"strings"
+)
+
+type txnOpType int
+
+const (
+	txnAdd txnOpType = iota
+	txnRemove
+	txnRemoveByPrefix
+)
+
+type txnOp struct {
+	typ   txnOpType
+	key   string
+	value interface{}
+}
+
+// Txn is a buffered set of changes to the trie.
+// The changes are not visible to the others until the transaction is committed.
+type Txn struct {
+	t   *Trie
+	ops []txnOp
+}
+
+// Txn runs `fn` with a new transaction. If fn returns nil, all the changes
+// made through the transaction are applied to the trie under a single write lock
+// so that the readers never observe the intermediate state.
+// If fn returns an error, nothing is applied and the error is returned.
+// The adds are checked against the limits of the trie as they are applied:
+// if an add is rejected, the changes applied before it are rolled back and
+// the error of the add is returned with the key, as well as reported to
+// WithOnReject. The keys removed and restored by the rollback get the new
+// positions of WithInsertionOrder and the new timestamps of WithTimestamps.
+func (t *Trie) Txn(fn func(tx *Txn) error) error {
+	if t == nil {
+		return ErrNilTrie
+	}
+	tx := &Txn{t: t}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if len(tx.ops) == 0 {
+		return nil
+	}
+	t.lock()
+	var undo []txnUndo
+	for _, op := range tx.ops {
+		switch op.typ {
+		case txnAdd:
+			undo = t.saveUndo(undo, op.key)
+			if err := t.add(op.key, op.value); err != nil {
+				t.rollback(undo)
+				t.unlock()
+				t.reject(op.key, err)
+				return fmt.Errorf("%w: %q", err, op.key)
+			}
+		case txnRemove:
+			undo = t.saveUndo(undo, op.key)
+			t.remove(op.key)
+		case txnRemoveByPrefix:
+			if node := findNode(t.root, t.canonical(op.key)); node != nil {
+				for _, n := range collectNodes(node) {
+					undo = t.saveUndo(undo, n.key())
+				}
+			}
+			t.removeByPrefix(op.key)
+		}
+	}
+	t.unlock()
+	return nil
+}
+
+// txnUndo is the state of a key before an operation of a transaction,
+// restored by rollback.
+type txnUndo struct {
+	key     string
+	value   interface{}
+	present bool
+	tomb    *tombstone
+}
+
+// saveUndo appends the state of the `key` before it is changed to `undo`.
+func (t *Trie) saveUndo(undo []txnUndo, key string) []txnUndo {
+	ckey := t.canonical(key)
+	u := txnUndo{key: key}
+	if n := findTerm(t.root, ckey); n != nil {
+		u.key, u.value, u.present = n.key(), n.value, true
+	}
+	if tomb, ok := t.tombs[ckey]; ok {
+		u.tomb = &tomb
+	}
+	return append(undo, u)
+}
+
+// rollback restores the states of the keys in `undo` in reverse order under
+// the write lock. The limits of the trie are not applied to the keys restored,
+// and the mutations of the transaction are not traced.
+func (t *Trie) rollback(undo []txnUndo) {
+	limits, tracer := t.limits, t.tracer
+	t.limits, t.tracer = nil, nil
+	for i := len(undo) - 1; i >= 0; i-- {
+		u := undo[i]
+		if u.present {
+			t.add(u.key, u.value)
+		} else {
+			t.remove(u.key)
+		}
+		if u.tomb != nil {
+			t.tombs[t.canonical(u.key)] = *u.tomb
+		}
+	}
+	t.limits, t.tracer = limits, tracer
+	if tracer != nil {
+		// the pending mutations are all of the transaction, delivered by
+		// the unlock of the transactions before.
+		tracer.pending = nil
+	}
+}
+
+// Add adds the key and value to the transaction.
+func (tx *Txn) Add(key string, value interface{}) {
+	tx.ops = append(tx.ops, txnOp{typ: txnAdd, key: key, value: value})
+}
+
+// Remove removes the key in the transaction.
+func (tx *Txn) Remove(key string) {
+	tx.ops = append(tx.ops, txnOp{typ: txnRemove, key: key})
+}
+
+// RemoveByPrefix removes all the keys starting with `prefix` in the transaction.
+func (tx *Txn) RemoveByPrefix(prefix string) {
+	tx.ops = append(tx.ops, txnOp{typ: txnRemoveByPrefix, key: prefix})
+}
+
+// Find finds the value of the key. The pending changes of the transaction
+// take precedence over the trie.
+func (tx *Txn) Find(key string) (interface{}, bool) {
+	k := string(tx.t.runes(key))
+	for i := len(tx.ops) - 1; i >= 0; i-- {
+		op := tx.ops[i]
+		switch op.typ {
+		case txnAdd:
+			if string(tx.t.runes(op.key)) == k {
+				return op.value, true
+			}
+		case txnRemove:
+			if string(tx.t.runes(op.key)) == k {
+				return nil, false
+			}
+		case txnRemoveByPrefix:
+			if strings.HasPrefix(k, string(tx.t.runes(op.key))) {
+				return nil, false
+			}
+		}
+	}
+	return tx.t.Find(key)
+}
+
+// removeByPrefix removes all the keys starting with `prefix` under the write lock.
+// It returns the number of the keys removed.
+func (t *Trie) removeByPrefix(prefix string) int {
+	node := findNode(t.root, t.canonical(prefix))
+	if node == nil {
+		return 0
+	}
+	terms := collectNodes(node)
+	keys := nodeKeys(terms)
+	for _, key := range keys {
+		t.remove(key)
+	}
+	return len(keys)
+}
@@ -0,0 +1,246 @@
+package gtrie
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestTrie_Txn(t *testing.T) {
+	trie := New()
+	trie.Add("/a", 1)
+	trie.Add("/b/1", 2)
+	trie.Add("/b/2", 3)
+
+	errAbort := errors.New("abort")
+	err := trie.Txn(func(tx *Txn) error {
+		tx.Add("/c", 4)
+		tx.Remove("/a")
+		return errAbort
+	})
+	if err != errAbort {
+		t.Fatalf("Txn() = %v, want %v", err, errAbort)
+	}
+	if _, ok := trie.Find("/c"); ok {
+		t.Errorf("aborted Txn applied Add")
+	}
+	if _, ok := trie.Find("/a"); !ok {
+		t.Errorf("aborted Txn applied Remove")
+	}
+
+	err = trie.Txn(func(tx *Txn) error {
+		tx.RemoveByPrefix("/b/")
+		if _, ok := tx.Find("/b/1"); ok {
+			t.Errorf("tx.Find(/b/1) found the removed key")
+		}
+		tx.Add("/b/1", 20)
+		if v, ok := tx.Find("/b/1"); !ok || v != 20 {
+			t.Errorf("tx.Find(/b/1) = %v, %v, want 20, true", v, ok)
+		}
+		tx.Remove("/a")
+		if _, ok := tx.Find("/a"); ok {
+			t.Errorf("tx.Find(/a) found the removed key")
+		}
+		if v, ok := tx.Find("/b/2"); ok {
+			t.Errorf("tx.Find(/b/2) = %v, want not found", v)
+		}
+		if _, ok := trie.Find("/a"); !ok {
+			t.Errorf("pending Txn is visible to the trie")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Txn() = %v", err)
+	}
+	want := map[string]interface{}{"/b/1": 20}
+	if got := trie.All(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("All() = %v, want %v", got, want)
+	}
+	if trie.Size() != 1 {
+		t.Errorf("Size() = %d, want 1", trie.Size())
+	}
+}
+
+func TestTrie_TxnAtomic(t *testing.T) {
+	trie := New()
+	for i := 0; i < 10; i++ {
+		trie.Add(fmt.Sprintf("/cfg/old/%d", i), i)
+	}
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				if n := len(trie.FindByPrefix("/cfg/")); n != 10 && n != 20 {
+					t.Errorf("reader observed %d keys, want 10 or 20", n)
+					return
+				}
+			}
+		}()
+	}
+	err := trie.Txn(func(tx *Txn) error {
+		tx.RemoveByPrefix("/cfg/")
+		for i := 0; i < 20; i++ {
+			tx.Add(fmt.Sprintf("/cfg/new/%d", i), i)
+		}
+		return nil
+	})
+	close(done)
+	wg.Wait()
+	if err != nil {
+		t.Fatalf("Txn() = %v", err)
+	}
+	if n := len(trie.FindByPrefix("/cfg/new/")); n != 20 {
+		t.Errorf("FindByPrefix() returns %d keys, want 20", n)
+	}
+}
+
+func TestTrie_TxnLimits(t *testing.T) {
+	trie := New(WithMaxKeys(4), WithMaxChildren(2))
+	trie.Add("/a", 1)
+	trie.Add("/b", 2)
+	// the adds are checked after the removes before them.
+	err := trie.Txn(func(tx *Txn) error {
+		tx.RemoveByPrefix("/a")
+		tx.Add("/c", 3)
+		tx.Add("/c/1", 4)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Txn() error = %v", err)
+	}
+	for _, tt := range []struct {
+		keys []string
+		err  error
+	}{
+		{[]string{"/d"}, ErrTooManyChildren},
+		{[]string{"/c/2", "/c/3"}, ErrTrieFull},
+	} {
+		err := trie.Txn(func(tx *Txn) error {
+			tx.Remove("/b")
+			tx.Add("/b/1", 5)
+			for _, key := range tt.keys {
+				tx.Add(key, 6)
+			}
+			return nil
+		})
+		if !errors.Is(err, tt.err) {
+			t.Errorf("Txn() of %v error = %v, want %v", tt.keys, err, tt.err)
+		}
+		// nothing is applied, even the changes before the add rejected.
+		if got := sortedKeys(trie.Keys()); !reflect.DeepEqual(got, []string{"/b", "/c", "/c/1"}) {
+			t.Errorf("Keys() after Txn() of %v = %v", tt.keys, got)
+		}
+	}
+}
+
+// TestTrie_TxnLimitsRandom checks that a transaction is applied if and only if
+// its changes applied one by one are not rejected.
+func TestTrie_TxnLimitsRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	opts := []Option{
+		WithMaxKeys(12), WithMaxChildren(3), WithMaxKeyLength(5),
+		WithValueSizer(func(v interface{}) int { return v.(int) }), WithMaxBytes(60),
+	}
+	key := func() string {
+		b := []byte{'/'}
+		for n := rng.Intn(6); n > 0; n-- {
+			b = append(b, "abcd"[rng.Intn(4)])
+		}
+		return string(b)
+	}
+	trie := New(opts...)
+	for i := 0; i < 500; i++ {
+		var ops []txnOp
+		for n := rng.Intn(6) + 1; n > 0; n-- {
+			op := txnOp{typ: txnAdd, key: key(), value: rng.Intn(10)}
+			switch rng.Intn(6) {
+			case 0:
+				op.typ = txnRemove
+			case 1:
+				op.typ = txnRemoveByPrefix
+			}
+			ops = append(ops, op)
+		}
+		// the changes are applied one by one to a copy of the trie.
+		want := New(opts...)
+		for k, v := range trie.All() {
+			want.Add(k, v)
+		}
+		var wantErr error
+		for _, op := range ops {
+			switch op.typ {
+			case txnAdd:
+				wantErr = want.AddE(op.key, op.value)
+			case txnRemove:
+				want.Remove(op.key)
+			case txnRemoveByPrefix:
+				want.removeByPrefix(op.key)
+			}
+			if wantErr != nil {
+				break
+			}
+		}
+		before := trie.All()
+		err := trie.Txn(func(tx *Txn) error {
+			tx.ops = ops
+			return nil
+		})
+		if !errors.Is(err, wantErr) || (err == nil) != (wantErr == nil) {
+			t.Fatalf("Txn(%v) error = %v, want %v", ops, err, wantErr)
+		}
+		got := trie.All()
+		if err == nil {
+			before = want.All()
+		}
+		if !reflect.DeepEqual(got, before) {
+			t.Fatalf("Txn(%v) = %v, want %v", ops, got, before)
+		}
+		checkNodes(t, trie.root, true)
+	}
+}
+
+func TestTrie_TxnRollback(t *testing.T) {
+	trie := New(WithMaxKeys(3))
+	trie.Add("/a", 1)
+	trie.Add("/b", 2)
+	trie.Add("/t", 3)
+	trie.RemoveSoft("/t")
+	var traced []TraceOp
+	trie.SetTracer(func(op TraceOp) { traced = append(traced, op) })
+	err := trie.Txn(func(tx *Txn) error {
+		tx.Add("/t", 4)
+		tx.Add("/a", 5)
+		tx.RemoveByPrefix("/b")
+		tx.Add("/c", 6)
+		tx.Add("/d", 7)
+		return nil
+	})
+	if !errors.Is(err, ErrTrieFull) {
+		t.Fatalf("Txn() error = %v, want %v", err, ErrTrieFull)
+	}
+	// the keys, the values and the tombstones are restored without the traces.
+	if got := trie.All(); !reflect.DeepEqual(got, map[string]interface{}{"/a": 1, "/b": 2}) {
+		t.Errorf("All() after the rollback = %v", got)
+	}
+	if got := trie.Tombstones(""); !reflect.DeepEqual(got, []string{"/t"}) {
+		t.Errorf("Tombstones() after the rollback = %v", got)
+	}
+	if len(traced) != 0 {
+		t.Errorf("the rollback traces %v", traced)
+	}
+	checkNodes(t, trie.root, true)
+	if !trie.Undelete("/t") {
+		t.Errorf("Undelete() after the rollback = false")
+	}
+}
@@ -0,0 +1,152 @@
+package gtrie
+
+// FindString returns the value of the `key` if the value is a string.
+// It returns false if the key is not found or the value is not a string.
+func (t *Trie) FindString(key string) (string, bool) {
+	v, ok := t.Find(key)
+	if !ok {
+		return "", false
+	}
+	s, ok := v.(string)
+	return s, ok
+}
+
+// FindInt returns the value of the `key` if the value is an int.
+// It returns false if the key is not found or the value is not an int.
+func (t *Trie) FindInt(key string) (int, bool) {
+	v, ok := t.Find(key)
+	if !ok {
+		return 0, false
+	}
+	i, ok := v.(int)
+	return i, ok
+}
+
+// FindBool returns the value of the `key` if the value is a bool.
+// It returns false if the key is not found or the value is not a bool.
+func (t *Trie) FindBool(key string) (bool, bool) {
+	v, ok := t.Find(key)
+	if !ok {
+		return false, false
+	}
+	b, ok := v.(bool)
+	return b, ok
+}
+
+// FindByPrefixStrings returns all the keys starting with `prefix`
+// and their string values. The keys having non-string values are skipped.
+// It returns nil if no key starts with `prefix`, as FindByPrefixAll.
+func (t *Trie) FindByPrefixStrings(prefix string) map[string]string {
+	if t == nil {
+		return nil
+	}
+	t.rlock()
+	defer t.runlock()
+	return stringValues(prefixcollect(t.root, t.canonical(prefix)))
+}
+
+// FindByPrefixInts returns all the keys starting with `prefix`
+// and their int values. The keys having non-int values are skipped.
+func (t *Trie) FindByPrefixInts(prefix string) map[string]int {
+	if t == nil {
+		return nil
+	}
+	t.rlock()
+	defer t.runlock()
+	return intValues(prefixcollect(t.root, t.canonical(prefix)))
+}
+
+// FindByPrefixBools returns all the keys starting with `prefix`
+// and their bool values. The keys having non-bool values are skipped.
+func (t *Trie) FindByPrefixBools(prefix string) map[string]bool {
+	if t == nil {
+		return nil
+	}
+	t.rlock()
+	defer t.runlock()
+	return boolValues(prefixcollect(t.root, t.canonical(prefix)))
+}
+
+// FindByFuzzyStrings performs a fuzzy search and returns the keys found
+// and their string values. The keys having non-string values are skipped.
+func (t *Trie) FindByFuzzyStrings(key string) map[string]string {
+	if t == nil {
+		return nil
+	}
+	t.rlock()
+	defer t.runlock()
+	return stringValues(fuzzycollectNodes(t.root, t.runes(key), false))
+}
+
+// FindByFuzzyInts performs a fuzzy search and returns the keys found
+// and their int values. The keys having non-int values are skipped.
+func (t *Trie) FindByFuzzyInts(key string) map[string]int {
+	if t == nil {
+		return nil
+	}
+	t.rlock()
+	defer t.runlock()
+	return intValues(fuzzycollectNodes(t.root, t.runes(key), false))
+}
+
+// FindByFuzzyBools performs a fuzzy search and returns the keys found
+// and their bool values. The keys having non-bool values are skipped.
+func (t *Trie) FindByFuzzyBools(key string) map[string]bool {
+	if t == nil {
+		return nil
+	}
+	t.rlock()
+	defer t.runlock()
+	return boolValues(fuzzycollectNodes(t.root, t.runes(key), false))
+}
+
+// prefixcollect returns all the terminal nodes starting with `prefix`.
+func prefixcollect(node *trieNode, prefix string) []*trieNode {
+	node = findNode(node, prefix)
+	if node == nil {
+		return nil
+	}
+	return collectNodes(node)
+}
+
+// stringValues, intValues and boolValues return the keys and the values of
+// the type of the terminal nodes, or nil if no node is found as FindByPrefixAll;
+// the map is empty, not nil, if the nodes found have no value of the type.
+func stringValues(nodes []*trieNode) map[string]string {
+	if len(nodes) == 0 {
+		return nil
+	}
+	m := make(map[string]string, len(nodes))
+	for _, n := range nodes {
+		if s, ok := n.value.(string); ok {
+			m[n.key()] = s
+		}
+	}
+	return m
+}
+
+func intValues(nodes []*trieNode) map[string]int {
+	if len(nodes) == 0 {
+		return nil
+	}
+	m := make(map[string]int, len(nodes))
+	for _, n := range nodes {
+		if i, ok := n.value.(int); ok {
+			m[n.key()] = i
+		}
+	}
+	return m
+}
+
+func boolValues(nodes []*trieNode) map[string]bool {
+	if len(nodes) == 0 {
+		return nil
+	}
+	m := make(map[string]bool, len(nodes))
+	for _, n := range nodes {
+		if b, ok := n.value.(bool); ok {
+			m[n.key()] = b
+		}
+	}
+	return m
+}
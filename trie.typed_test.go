@@ -0,0 +1,77 @@
+package gtrie
+
+import (
+	"reflect"
+	"testing"
+)
+
+func newTypedTrie() *Trie {
+	trie := New()
+	trie.Add("/a/string", "value")
+	trie.Add("/a/int", 10)
+	trie.Add("/a/bool", true)
+	trie.Add("/a/nil", nil)
+	trie.Add("/b/string", "other")
+	return trie
+}
+
+func TestTrie_FindTyped(t *testing.T) {
+	trie := newTypedTrie()
+	if s, ok := trie.FindString("/a/string"); !ok || s != "value" {
+		t.Errorf("FindString() = %q, %v", s, ok)
+	}
+	if s, ok := trie.FindString("/a/int"); ok || s != "" {
+		t.Errorf("FindString() of an int = %q, %v", s, ok)
+	}
+	if i, ok := trie.FindInt("/a/int"); !ok || i != 10 {
+		t.Errorf("FindInt() = %d, %v", i, ok)
+	}
+	if i, ok := trie.FindInt("/a/nil"); ok || i != 0 {
+		t.Errorf("FindInt() of nil = %d, %v", i, ok)
+	}
+	if b, ok := trie.FindBool("/a/bool"); !ok || !b {
+		t.Errorf("FindBool() = %v, %v", b, ok)
+	}
+	if b, ok := trie.FindBool("/none"); ok || b {
+		t.Errorf("FindBool() of a missing key = %v, %v", b, ok)
+	}
+}
+
+func TestTrie_FindByPrefixTyped(t *testing.T) {
+	trie := newTypedTrie()
+	if got, want := trie.FindByPrefixStrings("/"), map[string]string{"/a/string": "value", "/b/string": "other"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FindByPrefixStrings() = %v, want %v", got, want)
+	}
+	if got, want := trie.FindByPrefixInts("/a"), map[string]int{"/a/int": 10}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FindByPrefixInts() = %v, want %v", got, want)
+	}
+	if got, want := trie.FindByPrefixBools("/b"), map[string]bool{}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FindByPrefixBools() = %v, want %v", got, want)
+	}
+	// nil for no key found as FindByPrefixAll.
+	if got := trie.FindByPrefixStrings("/none"); got != nil {
+		t.Errorf("FindByPrefixStrings() of a missing prefix = %#v, want nil", got)
+	}
+	if got := trie.FindByPrefixInts("/none"); got != nil {
+		t.Errorf("FindByPrefixInts() of a missing prefix = %#v, want nil", got)
+	}
+	if got := trie.FindByPrefixBools("/none"); got != nil {
+		t.Errorf("FindByPrefixBools() of a missing prefix = %#v, want nil", got)
+	}
+}
+
+func TestTrie_FindByFuzzyTyped(t *testing.T) {
+	trie := newTypedTrie()
+	if got, want := trie.FindByFuzzyStrings("string"), map[string]string{"/a/string": "value", "/b/string": "other"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FindByFuzzyStrings() = %v, want %v", got, want)
+	}
+	if got, want := trie.FindByFuzzyInts("an"), map[string]int{"/a/int": 10}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FindByFuzzyInts() = %v, want %v", got, want)
+	}
+	if got, want := trie.FindByFuzzyBools("ab"), map[string]bool{"/a/bool": true}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FindByFuzzyBools() = %v, want %v", got, want)
+	}
+	if got := trie.FindByFuzzyStrings("zz"); got != nil {
+		t.Errorf("FindByFuzzyStrings() of no match = %#v, want nil", got)
+	}
+}
@@ -0,0 +1,32 @@
+package gtrie
+
+import "time"
+
+// WalkMatchingPrefix calls `fn` with the key, the value and the depth in runes
+// of each key that is a prefix of the input `key`, in order from the shortest,
+// like FindMatchingPrefixAll but without collecting them. The walk stops
+// when `fn` returns false. It does not allocate for the walk itself.
+// `fn` is called under the read lock and must not modify the trie.
+func (t *Trie) WalkMatchingPrefix(key string, fn func(prefix string, value interface{}, depth int) bool) {
+	if t == nil {
+		return
+	}
+	root := t.readRoot()
+	defer t.readDone()
+	if t.metrics != nil {
+		defer t.observe(MetricSearchMatchingPrefix, time.Now())
+	}
+	node := root
+	for _, r := range t.canonical(key) {
+		n, ok := node.children[r]
+		if !ok {
+			return
+		}
+		if c, ok := n.children[nul]; ok && c.term {
+			if !fn(c.key(), c.value, n.depth) {
+				return
+			}
+		}
+		node = n
+	}
+}
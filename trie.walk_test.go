@@ -0,0 +1,58 @@
+package gtrie
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTrie_WalkMatchingPrefix(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithAtomicReads()}} {
+		trie := New(opts...)
+		for _, key := range []string{"", "/", "/a", "/a/b", "/a/b/c", "/a/x", "/ü/b"} {
+			trie.Add(key, len(key))
+		}
+		type visit struct {
+			prefix string
+			value  interface{}
+			depth  int
+		}
+		tests := []struct {
+			key   string
+			limit int
+			want  []visit
+		}{
+			{"/a/b/c/d", 10, []visit{{"/", 1, 1}, {"/a", 2, 2}, {"/a/b", 4, 4}, {"/a/b/c", 6, 6}}},
+			{"/a/b/c/d", 2, []visit{{"/", 1, 1}, {"/a", 2, 2}}},
+			{"/ü/b", 10, []visit{{"/", 1, 1}, {"/ü/b", 5, 4}}},
+			{"x", 10, nil},
+		}
+		for _, tt := range tests {
+			var got []visit
+			trie.WalkMatchingPrefix(tt.key, func(prefix string, value interface{}, depth int) bool {
+				got = append(got, visit{prefix, value, depth})
+				return len(got) < tt.limit
+			})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("WalkMatchingPrefix(%q) visits %v, want %v", tt.key, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestTrie_WalkMatchingPrefixAllocs(t *testing.T) {
+	trie := newGNMITrie()
+	count := 0
+	fn := func(prefix string, value interface{}, depth int) bool {
+		count++
+		return true
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		trie.WalkMatchingPrefix("/interfaces/interface[name=1/2]/state/counters", fn)
+	})
+	if allocs != 0 {
+		t.Errorf("WalkMatchingPrefix allocates %v times per run, want 0", allocs)
+	}
+	if count == 0 {
+		t.Errorf("WalkMatchingPrefix visits no key")
+	}
+}
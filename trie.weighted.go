@@ -0,0 +1,44 @@
+package gtrie
+
+import (
+	"math"
+	"math/rand"
+)
+
+// PickWeighted picks a key starting with `prefix` at random with the probability
+// proportional to the weight of its value given by `weight` and returns the key
+// and its value. The keys of zero, negative or NaN weights are never picked;
+// it returns false if no key has a positive weight. The subtree of `prefix`
+// is traversed once by the weighted reservoir sampling (A-Res) without
+// collecting the keys. If `rng` is nil, the source of math/rand is used.
+func (t *Trie) PickWeighted(prefix string, weight func(v interface{}) float64, rng *rand.Rand) (string, interface{}, bool) {
+	if t == nil {
+		return "", nil, false
+	}
+	float := rand.Float64
+	if rng != nil {
+		float = rng.Float64
+	}
+	root := t.readRoot()
+	defer t.readDone()
+	node := findNode(root, t.canonical(prefix))
+	if node == nil {
+		return "", nil, false
+	}
+	var picked *trieNode
+	best := math.Inf(-1)
+	walkTerms(node, func(n *trieNode) {
+		w := weight(n.value)
+		if !(w > 0) {
+			return
+		}
+		// the key of A-Res u^(1/w) compared in the log not to underflow.
+		if k := math.Log(1-float()) / w; picked == nil || k > best {
+			picked, best = n, k
+		}
+	})
+	if picked == nil {
+		return "", nil, false
+	}
+	return picked.key(), picked.value, true
+}
@@ -0,0 +1,59 @@
+package gtrie
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestTrie_PickWeighted(t *testing.T) {
+	trie := New()
+	weights := map[string]float64{
+		"/routes/a/1": 1,
+		"/routes/a/2": 3,
+		"/routes/a/3": 6,
+		"/routes/a/4": 0,
+		"/routes/a/5": -1,
+		"/routes/b/1": 100,
+	}
+	for key, w := range weights {
+		trie.Add(key, w)
+	}
+	weight := func(v interface{}) float64 { return v.(float64) }
+	rng := rand.New(rand.NewSource(1))
+
+	const draws = 20000
+	counts := map[string]int{}
+	for i := 0; i < draws; i++ {
+		key, v, ok := trie.PickWeighted("/routes/a/", weight, rng)
+		if !ok || v != weights[key] {
+			t.Fatalf("PickWeighted() = %q, %v, %v", key, v, ok)
+		}
+		counts[key]++
+	}
+	for key, w := range weights {
+		if key[len("/routes/")] != 'a' {
+			continue
+		}
+		want := math.Max(w, 0) / 10
+		if got := float64(counts[key]) / draws; math.Abs(got-want) > 0.02 {
+			t.Errorf("the ratio of %q = %.3f, want %.3f", key, got, want)
+		}
+	}
+	if counts["/routes/a/4"] != 0 || counts["/routes/a/5"] != 0 {
+		t.Errorf("the keys of no weight are picked: %v", counts)
+	}
+
+	trie.Remove("/routes/a/1")
+	trie.Remove("/routes/a/2")
+	trie.Remove("/routes/a/3")
+	if key, _, ok := trie.PickWeighted("/routes/a/", weight, rng); ok {
+		t.Errorf("PickWeighted() of the zero weights = %q, want false", key)
+	}
+	if key, _, ok := trie.PickWeighted("/none", weight, nil); ok {
+		t.Errorf("PickWeighted() of the prefix not found = %q, want false", key)
+	}
+	if key, _, ok := trie.PickWeighted("", weight, nil); !ok || key != "/routes/b/1" {
+		t.Errorf("PickWeighted() = %q, %v, want the only key of a positive weight", key, ok)
+	}
+}
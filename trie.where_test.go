@@ -0,0 +1,188 @@
+package gtrie
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newWhereFixture() *Trie {
+	trie := New()
+	for i := 0; i < 100; i++ {
+		trie.Add(fmt.Sprintf("/interfaces/interface[name=1/%d]/state/enabled", i), i%3 == 0)
+		trie.Add(fmt.Sprintf("/interfaces/interface[name=1/%d]", i), i)
+	}
+	trie.Add("/interfaces", "root")
+	return trie
+}
+
+// whereCounter returns the predicate accepting the values `true`
+// and counting the calls by the keys.
+func whereCounter(seen map[string]int) func(key string, v interface{}) bool {
+	return func(key string, v interface{}) bool {
+		seen[key]++
+		return v == true
+	}
+}
+
+func checkWhere(t *testing.T, name string, got, all map[string]interface{}, seen map[string]int) {
+	t.Helper()
+	want := make(map[string]interface{})
+	for key, v := range all {
+		if v == true {
+			want[key] = v
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("%s = %d keys, want %d keys", name, len(got), len(want))
+	}
+	for key, n := range seen {
+		if _, ok := all[key]; !ok || n != 1 {
+			t.Errorf("%s calls the predicate with %q %d times", name, key, n)
+		}
+	}
+	if len(seen) != len(all) {
+		t.Errorf("%s calls the predicate with %d keys, want %d", name, len(seen), len(all))
+	}
+}
+
+func TestTrie_FindWhere(t *testing.T) {
+	trie := newWhereFixture()
+	for _, prefix := range []string{"/interfaces", "/interfaces/interface[name=1/1", "/none"} {
+		seen := make(map[string]int)
+		got := trie.FindByPrefixWhere(prefix, whereCounter(seen))
+		checkWhere(t, fmt.Sprintf("FindByPrefixWhere(%q)", prefix), got, trie.FindByPrefixAll(prefix), seen)
+	}
+	for _, key := range []string{"/i[1/1]e", "enabled", "zzz", ""} {
+		seen := make(map[string]int)
+		got := trie.FindByFuzzyWhere(key, whereCounter(seen))
+		checkWhere(t, fmt.Sprintf("FindByFuzzyWhere(%q)", key), got, trie.FindByFuzzyAll(key), seen)
+	}
+	key := "/interfaces/interface[name=1/3]/state/enabled/x"
+	seen := make(map[string]int)
+	got := trie.FindMatchingPrefixWhere(key, whereCounter(seen))
+	checkWhere(t, "FindMatchingPrefixWhere()", got, trie.FindMatchingPrefixAll(key), seen)
+	if len(got) != 1 {
+		t.Errorf("FindMatchingPrefixWhere() = %v", got)
+	}
+}
+
+func TestTrie_FindWhereOptions(t *testing.T) {
+	trie := newWhereFixture()
+	enabled := func(key string, v interface{}) bool { return v == true }
+
+	// the first 20 keys under /interfaces whose value is enabled.
+	calls := 0
+	got := trie.FindByPrefixWhere("/interfaces", func(key string, v interface{}) bool {
+		calls++
+		return v == true
+	}, MaxResults(20), Sorted())
+	var want []string
+	for _, key := range sortedKeys(trie.FindByPrefix("/interfaces")) {
+		if v, _ := trie.Find(key); v == true && len(want) < 20 {
+			want = append(want, key)
+		}
+	}
+	if keys := sortedKeys(keysOf(got)); !reflect.DeepEqual(keys, want) {
+		t.Errorf("FindByPrefixWhere() with MaxResults(20) = %v, want %v", keys, want)
+	}
+	if calls >= trie.Size() {
+		t.Errorf("FindByPrefixWhere() with MaxResults(20) calls the predicate %d times", calls)
+	}
+	got = trie.FindByPrefixWhere("/interfaces", enabled, MaxResults(3), Descending())
+	want = sortedKeys(keysOf(trie.FindByPrefixWhere("/interfaces", enabled)))
+	if keys := sortedKeys(keysOf(got)); !reflect.DeepEqual(keys, want[len(want)-3:]) {
+		t.Errorf("FindByPrefixWhere() descending = %v, want %v", keys, want[len(want)-3:])
+	}
+	if got := trie.FindByPrefixWhere("/interfaces", enabled, MaxResults(5)); len(got) != 5 {
+		t.Errorf("FindByPrefixWhere() with MaxResults(5) = %d keys", len(got))
+	}
+	if got := trie.FindByFuzzyWhere("enabled", enabled, MaxResults(5)); len(got) != 5 {
+		t.Errorf("FindByFuzzyWhere() with MaxResults(5) = %d keys", len(got))
+	}
+	all := func(key string, v interface{}) bool { return true }
+	got = trie.FindMatchingPrefixWhere("/interfaces/interface[name=1/3]/state/enabled", all, MaxResults(2))
+	if keys := sortedKeys(keysOf(got)); !reflect.DeepEqual(keys, []string{"/interfaces", "/interfaces/interface[name=1/3]"}) {
+		t.Errorf("FindMatchingPrefixWhere() with MaxResults(2) = %v", keys)
+	}
+
+	upper := strings.ToUpper("/interfaces/interface[name=1/3]")
+	if got := trie.FindByPrefixWhere(upper, enabled, CaseFold()); len(got) != 1 {
+		t.Errorf("FindByPrefixWhere() with CaseFold() = %v", got)
+	}
+	if got := trie.FindMatchingPrefixWhere(upper, all, CaseFold()); len(got) != 2 {
+		t.Errorf("FindMatchingPrefixWhere() with CaseFold() = %v", got)
+	}
+	if got := trie.FindByFuzzyWhere("ENABLED", enabled, CaseFold()); len(got) != 34 {
+		t.Errorf("FindByFuzzyWhere() with CaseFold() = %d keys, want 34", len(got))
+	}
+}
+
+func keysOf(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func TestTrie_CountWhere(t *testing.T) {
+	trie := newWhereFixture()
+	enabled := func(key string, v interface{}) bool { return v == true }
+	for _, prefix := range []string{"", "/interfaces", "/interfaces/interface[name=1/1", "/interfaces/interface[name=1/3]", "/none"} {
+		all := trie.FindByPrefixAll(prefix)
+		if got := trie.CountByPrefix(prefix); got != len(all) {
+			t.Errorf("CountByPrefix(%q) = %d, want %d", prefix, got, len(all))
+		}
+		if got := trie.CountWhere(prefix, nil); got != len(all) {
+			t.Errorf("CountWhere(%q, nil) = %d, want %d", prefix, got, len(all))
+		}
+		seen := make(map[string]int)
+		want := trie.FindByPrefixWhere(prefix, enabled)
+		if got := trie.CountWhere(prefix, whereCounter(seen)); got != len(want) || len(seen) != len(all) {
+			t.Errorf("CountWhere(%q) = %d with %d keys seen, want %d with %d", prefix, got, len(seen), len(want), len(all))
+		}
+		if got := trie.ExistsWhere(prefix, enabled); got != (len(want) > 0) {
+			t.Errorf("ExistsWhere(%q) = %v, want %v", prefix, got, len(want) > 0)
+		}
+	}
+	calls := 0
+	ok := trie.ExistsWhere("/interfaces", func(key string, v interface{}) bool {
+		calls++
+		return v == true
+	})
+	if !ok || calls >= trie.Size() {
+		t.Errorf("ExistsWhere() = %v after %d calls of the predicate", ok, calls)
+	}
+	trie.RemoveSoft("/interfaces")
+	if got := trie.CountByPrefix("/interfaces"); got != 200 {
+		t.Errorf("CountByPrefix() after RemoveSoft() = %d, want 200", got)
+	}
+}
+
+func BenchmarkCountWhere(b *testing.B) {
+	trie := New()
+	for i := 0; i < 100000; i++ {
+		trie.Add(fmt.Sprintf("/interfaces/interface[name=%d]/enabled", i), i%2 == 0)
+	}
+	trie.Add("/other", true)
+	enabled := func(key string, v interface{}) bool { return v == true }
+	b.Run("CountWhere", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = trie.CountWhere("/interfaces/", enabled)
+		}
+	})
+	b.Run("FindByPrefixAll", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			count := 0
+			for key, v := range trie.FindByPrefixAll("/interfaces/") {
+				if enabled(key, v) {
+					count++
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,179 @@
+package gtrie
+
+import "slices"
+
+// nodeExt is the state of a node maintained only with the options
+// WithSortedChildren, WithWideMask, WithFuzzyCounting and WithValueSizer.
+type nodeExt struct {
+	// sorted is the children in rune order (WithSortedChildren), or nil.
+	sorted *[]*trieNode
+	// wide is the wide mask of the node (WithWideMask), or nil.
+	wide *wideMask
+	// counts is the counts of the runes of the node (WithFuzzyCounting), or nil.
+	counts *runeCounts
+	// sized reports whether the bytes of the values are maintained (WithValueSizer).
+	sized bool
+	// bytes is the sum of the sizes of the values of the keys under the node.
+	bytes int
+}
+
+// ordered returns the children of the node in rune order,
+// or nil if the order is not maintained.
+func (n *trieNode) ordered() *[]*trieNode {
+	if n.ext == nil {
+		return nil
+	}
+	return n.ext.sorted
+}
+
+// extend returns the extension of the node creating it if not set.
+func (n *trieNode) extend() *nodeExt {
+	if n.ext == nil {
+		n.ext = &nodeExt{}
+	}
+	return n.ext
+}
+
+// empty returns the extension of the same options for a node without
+// any child; nil if `e` is nil.
+func (e *nodeExt) empty() *nodeExt {
+	if e == nil {
+		return nil
+	}
+	c := &nodeExt{}
+	if e.sorted != nil {
+		c.sorted = new([]*trieNode)
+	}
+	if e.wide != nil {
+		c.wide = new(wideMask)
+	}
+	if e.counts != nil {
+		c.counts = new(runeCounts)
+	}
+	c.sized = e.sized
+	return c
+}
+
+// clone returns a copy of the extension that can be modified apart from `e`.
+func (e *nodeExt) clone() *nodeExt {
+	if e == nil {
+		return nil
+	}
+	c := &nodeExt{}
+	if e.sorted != nil {
+		sorted := slices.Clone(*e.sorted)
+		c.sorted = &sorted
+	}
+	if e.wide != nil {
+		wide := *e.wide
+		c.wide = &wide
+	}
+	if e.counts != nil {
+		counts := *e.counts
+		c.counts = &counts
+	}
+	c.sized, c.bytes = e.sized, e.bytes
+	return c
+}
+
+// wideMask is the set of the runes under a node folded into 256 bits.
+// Unlike the 64-bit mask, which only has the bits of the 64 runes from 'a',
+// every rune has a bit, so that the keys of a large alphabet (e.g. unicode
+// paths) are pruned by the fuzzy search too. The runes folded into the same
+// bit only make the pruning weaker; a subtree is skipped only if it lacks
+// the bit of a rune searched, i.e. it provably has no match.
+type wideMask [4]uint64
+
+// wideBit returns the bit of the rune `r` in a wideMask.
+// The rune is hashed by the Fibonacci hashing not to fold the runes
+// of a script (a block of the adjacent code points) together.
+func wideBit(r rune) uint8 {
+	return uint8(uint32(r) * 2654435769 >> 24)
+}
+
+func (m *wideMask) add(r rune) {
+	b := wideBit(r)
+	m[b>>6] |= 1 << (b & 63)
+}
+
+func (m *wideMask) or(o *wideMask) {
+	for i := range m {
+		m[i] |= o[i]
+	}
+}
+
+// covers reports whether the mask has the bits of all the runes.
+func (m *wideMask) covers(runes []rune) bool {
+	for _, r := range runes {
+		b := wideBit(r)
+		if m[b>>6]&(1<<(b&63)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// WithWideMask maintains a 256-bit mask of the runes per node in addition to
+// the 64-bit mask, which has no bit for the runes out of the 64 runes from 'a'
+// and so cannot prune the fuzzy search of the keys drawing from a large
+// alphabet. It costs about 48 bytes per node and the update of the masks on the path
+// per Add and Remove. The fuzzy search of the unicode keys of
+// BenchmarkWideMaskFuzzy prunes the subtrees without a match (0.7ms to 23ms).
+func WithWideMask() Option {
+	return func(t *Trie) {
+		t.wide = true
+		t.root.extend().wide = new(wideMask)
+	}
+}
+
+// wideSuffixes returns the wide masks of all the suffixes of the `runes`;
+// the i-th mask is of runes[i:].
+func wideSuffixes(runes []rune) []wideMask {
+	wides := make([]wideMask, len(runes)+1)
+	for i := len(runes) - 1; i >= 0; i-- {
+		wides[i] = wides[i+1]
+		wides[i].add(runes[i])
+	}
+	return wides
+}
+
+// updateWide recalculates the wide mask of the node from its own rune and
+// the wide masks of its children and returns true if it is changed.
+// It returns false if the node has no wide mask.
+func updateWide(n *trieNode) bool {
+	if n.ext == nil || n.ext.wide == nil {
+		return false
+	}
+	var m wideMask
+	if n.rval != nul {
+		m.add(n.rval)
+	}
+	for _, c := range n.children {
+		if c.ext != nil && c.ext.wide != nil {
+			m.or(c.ext.wide)
+		}
+	}
+	if m == *n.ext.wide {
+		return false
+	}
+	*n.ext.wide = m
+	return true
+}
+
+// fuzzyPruned reports whether the subtree of the node provably has no key
+// matching the rest of the fuzzy search `partial`, i.e. the masks of the node
+// lack a rune of `partial`. The wide mask and the counts of the runes are
+// checked if the node has them.
+func fuzzyPruned(n *trieNode, partial []rune) bool {
+	m := maskruneslice(partial)
+	if (n.mask & m) != m {
+		return true
+	}
+	if n.ext == nil {
+		return false
+	}
+	if n.ext.wide != nil && !n.ext.wide.covers(partial) {
+		return true
+	}
+	return n.ext.counts != nil && !n.ext.counts.covers(partial)
+}
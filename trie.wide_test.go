@@ -0,0 +1,162 @@
+package gtrie
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+	"unsafe"
+)
+
+// unicodeKeys returns the paths of `n` keys of the segments drawn from
+// the Hangul syllables and the Greek letters, beyond the 64-bit mask,
+// which the ASCII keys of gtrietest.GeneratePathKeys hardly reach.
+func unicodeKeys(n int, seed int64) []string {
+	rng := rand.New(rand.NewSource(seed))
+	segment := func() string {
+		runes := make([]rune, 2+rng.Intn(3))
+		for i := range runes {
+			if rng.Intn(2) == 0 {
+				runes[i] = rune(0xAC00 + rng.Intn(400))
+			} else {
+				runes[i] = rune(0x03B1 + rng.Intn(24))
+			}
+		}
+		return string(runes)
+	}
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = "/" + segment() + "/" + segment() + "/" + segment()
+	}
+	return keys
+}
+
+// wideOf returns the wide mask of the runes of the node and its descendants.
+func wideOf(n *trieNode) wideMask {
+	var m wideMask
+	if n.rval != nul {
+		m.add(n.rval)
+	}
+	for _, c := range n.children {
+		w := wideOf(c)
+		m.or(&w)
+	}
+	return m
+}
+
+// checkWide checks the wide masks of the nodes under the node.
+func checkWide(t *testing.T, node *trieNode) {
+	t.Helper()
+	walkNodes(node, func(n *trieNode) {
+		if n.term {
+			return
+		}
+		if n.ext == nil || n.ext.wide == nil {
+			t.Fatalf("%q (depth %d) has no wide mask", n.rval, n.depth)
+		}
+		if want := wideOf(n); *n.ext.wide != want {
+			t.Fatalf("wide mask of %q (depth %d) = %x, want %x", n.rval, n.depth, *n.ext.wide, want)
+		}
+	})
+}
+
+func TestTrie_WideMask(t *testing.T) {
+	if size := unsafe.Sizeof(trieNode{}); size > 112 {
+		t.Errorf("the node is %d bytes, want 112 or less", size)
+	}
+	keys := unicodeKeys(500, 1)
+	queries := []string{"/ααα", "γ/δ", string([]rune(keys[7])[1:4]), keys[100], "/ωψ가", "ab"}
+	for _, key := range keys[:20] {
+		runes := []rune(key)
+		queries = append(queries, string([]rune{runes[2], runes[len(runes)/2+1], runes[len(runes)-1]}))
+	}
+	options := map[string][]Option{
+		"plain":  {WithWideMask()},
+		"atomic": {WithWideMask(), WithAtomicReads()},
+		"arena":  {WithWideMask(), WithArena(64)},
+		"sorted": {WithWideMask(), WithSortedChildren()},
+	}
+	for name, opts := range options {
+		trie, plain := New(opts...), New()
+		for i, key := range keys {
+			trie.Add(key, i)
+			plain.Add(key, i)
+		}
+		mutations := []func(trie *Trie){
+			func(trie *Trie) {},
+			func(trie *Trie) {
+				for _, key := range keys[:200] {
+					trie.Remove(key)
+				}
+			},
+			func(trie *Trie) { trie.MovePrefix(keys[300][:3], "/ζ") },
+			func(trie *Trie) {
+				other := New()
+				for _, key := range unicodeKeys(100, 2) {
+					other.Add(key, key)
+				}
+				trie.Merge(other)
+			},
+			func(trie *Trie) { trie.Compact(0) },
+			func(trie *Trie) { trie.Clear() },
+		}
+		for i, mutate := range mutations {
+			mutate(trie)
+			mutate(plain)
+			checkWide(t, trie.root)
+			for _, q := range queries {
+				got, want := sortedKeys(trie.FindByFuzzy(q)), sortedKeys(plain.FindByFuzzy(q))
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("%s: mutation %d: FindByFuzzy(%q) = %d keys, want %d keys", name, i, q, len(got), len(want))
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkWideMaskFuzzy(b *testing.B) {
+	keys := unicodeKeys(20000, 1)
+	queries := make([]string, 100)
+	for i := range queries {
+		// a rune of each segment of a key.
+		runes := []rune(keys[i*7])
+		queries[i] = fmt.Sprintf("%c%c%c", runes[2], runes[len(runes)/2+1], runes[len(runes)-1])
+	}
+	// the ratio of the runes of the keys without a bit of their own;
+	// the 64-bit mask has no bit for them, the wide mask folds them.
+	bits := map[uint8]int{}
+	runes := map[rune]bool{}
+	for _, key := range keys {
+		for _, r := range key {
+			if !runes[r] {
+				runes[r] = true
+				bits[wideBit(r)]++
+			}
+		}
+	}
+	aliased := map[bool]float64{}
+	for r := range runes {
+		if maskruneslice([]rune{r}) == 0 {
+			aliased[false]++
+		}
+		if bits[wideBit(r)] > 1 {
+			aliased[true]++
+		}
+	}
+	for _, wide := range []bool{false, true} {
+		var opts []Option
+		if wide {
+			opts = append(opts, WithWideMask())
+		}
+		trie := New(opts...)
+		for _, key := range keys {
+			trie.Add(key, true)
+		}
+		b.Run(fmt.Sprintf("wide=%v", wide), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				trie.FindByFuzzy(queries[i%len(queries)])
+			}
+			b.ReportMetric(aliased[wide]/float64(len(runes)), "aliased/rune")
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package gtrie
+
+import (
+	"bytes"
+	"context"
+	"hash/fnv"
+	"io"
+	"iter"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// zeroArgs returns the arguments of the method type `mt` that are zero
+// or empty, but usable, e.g. an empty trie and a function returning zero.
+func zeroArgs(mt reflect.Type) []reflect.Value {
+	args := make([]reflect.Value, 0, mt.NumIn())
+	for i := 0; i < mt.NumIn(); i++ {
+		in := mt.In(i)
+		var v reflect.Value
+		switch {
+		case in == reflect.TypeOf(&Trie{}):
+			v = reflect.ValueOf(&Trie{})
+		case in == reflect.TypeOf((*context.Context)(nil)).Elem():
+			v = reflect.ValueOf(context.Background())
+		case in == reflect.TypeOf((*io.Writer)(nil)).Elem():
+			v = reflect.ValueOf(io.Discard)
+		case in == reflect.TypeOf((*io.Reader)(nil)).Elem():
+			v = reflect.ValueOf(bytes.NewReader(nil))
+		case in == reflect.TypeOf(fnv.New64a):
+			v = reflect.ValueOf(fnv.New64a)
+		case in.Kind() == reflect.Func:
+			v = reflect.MakeFunc(in, func(args []reflect.Value) []reflect.Value {
+				results := make([]reflect.Value, in.NumOut())
+				for i := range results {
+					results[i] = reflect.Zero(in.Out(i))
+				}
+				return results
+			})
+		case in.Kind() == reflect.Slice && mt.IsVariadic() && i == mt.NumIn()-1:
+			v = reflect.MakeSlice(in, 0, 0)
+		default:
+			v = reflect.Zero(in)
+		}
+		args = append(args, v)
+	}
+	return args
+}
+
+func TestTrie_ZeroValue(t *testing.T) {
+	methods := reflect.TypeOf(&Trie{})
+	for i := 0; i < methods.NumMethod(); i++ {
+		m := methods.Method(i)
+		t.Run(m.Name, func(t *testing.T) {
+			trie := &Trie{}
+			v := reflect.ValueOf(trie).Method(i)
+			args := zeroArgs(v.Type())
+			if v.Type().IsVariadic() {
+				v.CallSlice(args)
+			} else {
+				v.Call(args)
+			}
+			// the trie is still usable after the method.
+			trie.Add("/a", 1)
+			if v, ok := trie.Find("/a"); !ok || v != 1 {
+				t.Errorf("Find() after %s = %v, %v", m.Name, v, ok)
+			}
+		})
+	}
+}
+
+func TestTrie_NilReceiver(t *testing.T) {
+	methods := reflect.TypeOf(&Trie{})
+	for i := 0; i < methods.NumMethod(); i++ {
+		m := methods.Method(i)
+		t.Run(m.Name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Errorf("%s of nil panics: %v", m.Name, r)
+				}
+			}()
+			v := reflect.ValueOf((*Trie)(nil)).Method(i)
+			args := zeroArgs(v.Type())
+			var results []reflect.Value
+			if v.Type().IsVariadic() {
+				results = v.CallSlice(args)
+			} else {
+				results = v.Call(args)
+			}
+			// the iterators returned are of no key.
+			for _, r := range results {
+				if seq, ok := r.Interface().(iter.Seq2[string, interface{}]); ok {
+					for key := range seq {
+						t.Errorf("%s of nil iterates %q", m.Name, key)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestTrie_NilReceiverErrors(t *testing.T) {
+	var trie *Trie
+	if err := trie.AddE("/a", 1); err != ErrNilTrie {
+		t.Errorf("AddE() of nil = %v, want %v", err, ErrNilTrie)
+	}
+	if err := trie.Txn(func(tx *Txn) error { return nil }); err != ErrNilTrie {
+		t.Errorf("Txn() of nil = %v, want %v", err, ErrNilTrie)
+	}
+	if _, err := trie.ReadKeys(strings.NewReader("/a\n"), 1); err != ErrNilTrie {
+		t.Errorf("ReadKeys() of nil = %v, want %v", err, ErrNilTrie)
+	}
+	// the nil trie is written as an empty trie.
+	var buf bytes.Buffer
+	if err := trie.StreamTo(&buf, 0); err != nil {
+		t.Fatalf("StreamTo() of nil error = %v", err)
+	}
+	if err := StreamFrom(&buf, func(key string, _ []byte) error {
+		t.Errorf("StreamTo() of nil streams %q", key)
+		return nil
+	}); err != nil {
+		t.Errorf("StreamFrom() of nil error = %v", err)
+	}
+	if s := trie.Compile(); s == nil || s.Size() != 0 {
+		t.Errorf("Compile() of nil = %v", s)
+	}
+}
+
+func TestTrie_ZeroValueEmbedded(t *testing.T) {
+	type registry struct {
+		name  string
+		paths Trie
+	}
+	r := &registry{name: "r"}
+	if _, ok := r.paths.Find("/a"); ok {
+		t.Errorf("Find() of the zero value found a key")
+	}
+	r.paths.Add("/a/b", 1)
+	r.paths.Add("/a/c", 2)
+	if got := sortedKeys(r.paths.FindByPrefix("/a/")); !reflect.DeepEqual(got, []string{"/a/b", "/a/c"}) {
+		t.Errorf("FindByPrefix() = %v", got)
+	}
+	if got := r.paths.Remove("/a/b"); got != 1 || r.paths.Size() != 1 {
+		t.Errorf("Remove() = %v with Size() %d", got, r.paths.Size())
+	}
+}
@@ -2,11 +2,20 @@ package gtrie
 
 import (
 	"bufio"
+	"errors"
+	"fmt"
+	"io"
 	"log"
+	"math/rand"
 	"os"
 	"reflect"
 	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+
+	"github.com/neoul/gtrie/gtrietest"
 )
 
 func addFromFile(t *Trie, path string) {
@@ -15,13 +24,9 @@ func addFromFile(t *Trie, path string) {
 		log.Fatal(err)
 	}
 
-	reader := bufio.NewScanner(file)
-
-	for reader.Scan() {
-		t.Add(reader.Text(), nil)
-	}
+	defer file.Close()
 
-	if reader.Err() != nil {
+	if _, err := t.ReadKeys(file, nil); err != nil {
 		log.Fatal(err)
 	}
 }
@@ -324,21 +329,27 @@ func TestFindByFuzzySorting(t *testing.T) {
 		"frosty",
 		"bfrza",
 		"foo/bart/baz.go",
+		// the keys of the same length are sorted lexicographically.
+		"zfza",
+		"afzz",
+		"mfz0",
 	}
 
 	for _, key := range setup {
 		trie.Add(key, nil)
 	}
 
-	actual := trie.FindByFuzzy("fz")
-	expected := []string{"bfrza", "foo/bart/baz.go"}
-
-	if len(actual) != len(expected) {
-		t.Fatalf("expected len %d got %d", len(expected), len(actual))
-	}
-	for i, v := range expected {
-		if actual[i] != v {
-			t.Errorf("Expected %s got %s", v, actual[i])
+	expected := []string{"afzz", "mfz0", "zfza", "bfrza", "foo/bart/baz.go"}
+	// the map iteration order changes on every run.
+	for n := 0; n < 20; n++ {
+		actual := trie.FindByFuzzy("fz")
+		if len(actual) != len(expected) {
+			t.Fatalf("expected len %d got %d", len(expected), len(actual))
+		}
+		for i, v := range expected {
+			if actual[i] != v {
+				t.Fatalf("Expected %s got %s", v, actual[i])
+			}
 		}
 	}
 
@@ -735,3 +746,557 @@ func TestTrie_FindRelativeAll(t *testing.T) {
 		t.Errorf("got result(%d), expect(12)", len(m))
 	}
 }
+
+func TestTrie_DistinctValues(t *testing.T) {
+	trie := New()
+	for i, key := range gnmiFixture {
+		switch {
+		case strings.HasPrefix(key, "/interfaces/interface[name=1/2]"):
+			trie.Add(key, "1/2")
+		case strings.HasPrefix(key, "/interfaces/interface[name=1/3]"):
+			trie.Add(key, "1/3")
+		default:
+			trie.Add(key, i%2)
+		}
+	}
+
+	values := trie.DistinctValues(nil)
+	if len(values) != 4 {
+		t.Errorf("DistinctValues(nil) = %v, want 4 values", values)
+	}
+	values = trie.DistinctValues(func(a, b interface{}) bool {
+		_, sa := a.(string)
+		_, sb := b.(string)
+		return sa == sb
+	})
+	if len(values) != 2 {
+		t.Errorf("DistinctValues(equal) = %v, want 2 values", values)
+	}
+	if values := New().DistinctValues(nil); len(values) != 0 {
+		t.Errorf("DistinctValues() of an empty trie = %v", values)
+	}
+}
+
+func TestTrie_ValuesWhere(t *testing.T) {
+	trie := New()
+	for i, key := range gnmiFixture {
+		trie.Add(key, i)
+	}
+	var visited int
+	values := trie.ValuesWhere(func(key string, v interface{}) bool {
+		visited++
+		return strings.HasSuffix(key, "/enabled")
+	})
+	if visited != trie.Size() {
+		t.Errorf("ValuesWhere() visited %d keys, want %d", visited, trie.Size())
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i].(int) < values[j].(int) })
+	if want := []interface{}{5, 6, 13}; !reflect.DeepEqual(values, want) {
+		t.Errorf("ValuesWhere() = %v, want %v", values, want)
+	}
+}
+
+func TestTrie_SizeConcurrent(t *testing.T) {
+	trie := New()
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				key := fmt.Sprintf("/w%d/%d", w, i)
+				trie.Add(key, i)
+				if i%2 == 0 {
+					trie.Remove(key)
+				}
+			}
+		}(w)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			if size := trie.Size(); size < 0 || size > 2000 {
+				t.Errorf("Size() = %d", size)
+			}
+			trie.HasPrefix("/w1")
+			trie.FindMatchingPrefix("/w1/10")
+		}
+	}()
+	wg.Wait()
+	<-done
+	if size := trie.Size(); size != 1000 {
+		t.Errorf("Size() = %d, want 1000", size)
+	}
+	trie.Clear()
+	if size := trie.Size(); size != 0 {
+		t.Errorf("Size() after Clear() = %d, want 0", size)
+	}
+}
+
+func TestTrie_LongestCommonPrefix(t *testing.T) {
+	trie := New()
+	for _, key := range []string{
+		"/interfaces",
+		"/interfaces/interface",
+		"/interfaces/interface[name=1/2]",
+		"/interfaces/interface[name=1/2]/state",
+		"/interfaces/interface[name=1/2]/state/oper-status",
+		"/interfaces/interface[name=1/2]/state/enabled",
+		"/interfaces/interface[name=1/1]/state/enabled",
+		"/interfaces/interface[name=1/2]/state/admin-status",
+		"/interfaces/interface[name=1/2]/state/counters",
+		"/interfaces/interface[name=1/3]",
+		"/interfaces/interface[name=1/3]/state",
+		"/interfaces/interface/state/counters",
+	} {
+		trie.Add(key, true)
+	}
+	tests := []struct {
+		prefix string
+		want   string
+	}{
+		// "/interfaces" is a key, so it is the longest prefix shared by all the keys.
+		{"/interfaces", "/interfaces"},
+		{"", "/interfaces"},
+		{"/interfaces/interface[", "/interfaces/interface[name=1/"},
+		{"/interfaces/interface[name=1/2]/s", "/interfaces/interface[name=1/2]/state"},
+		{"/interfaces/interface[name=1/1", "/interfaces/interface[name=1/1]/state/enabled"},
+		{"/none", ""},
+	}
+	for _, tt := range tests {
+		if got := trie.LongestCommonPrefix(tt.prefix); got != tt.want {
+			t.Errorf("LongestCommonPrefix(%q) = %q, want %q", tt.prefix, got, tt.want)
+		}
+	}
+
+	// after the keys interrupting the descent are removed,
+	// the keys diverge after "/interfaces/interface".
+	trie.Remove("/interfaces")
+	trie.Remove("/interfaces/interface")
+	if got, want := trie.LongestCommonPrefix("/interfaces"), "/interfaces/interface"; got != want {
+		t.Errorf("LongestCommonPrefix() = %q, want %q", got, want)
+	}
+
+	if got := New().LongestCommonPrefix(""); got != "" {
+		t.Errorf("LongestCommonPrefix() of an empty trie = %q", got)
+	}
+	trie.Clear()
+	if got := trie.LongestCommonPrefix(""); got != "" {
+		t.Errorf("LongestCommonPrefix() of a cleared trie = %q", got)
+	}
+}
+
+func TestTrie_HasPrefixAny(t *testing.T) {
+	trie := newGNMITrie()
+	tests := []struct {
+		prefixes []string
+		want     string
+		wantOk   bool
+	}{
+		{[]string{"/none", "/system", "/interfaces/interface[name=1/3]"}, "/interfaces/interface[name=1/3]", true},
+		{[]string{"/interfaces/interface[name=1/2]/st", "/interfaces"}, "/interfaces/interface[name=1/2]/st", true},
+		{[]string{"/none", "/system"}, "", false},
+		{nil, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := trie.HasPrefixAny(tt.prefixes)
+		if got != tt.want || ok != tt.wantOk {
+			t.Errorf("HasPrefixAny(%v) = %q, %v, want %q, %v", tt.prefixes, got, ok, tt.want, tt.wantOk)
+		}
+	}
+	if _, ok := New().HasPrefixAny([]string{""}); ok {
+		t.Errorf("HasPrefixAny() of an empty trie returns true")
+	}
+}
+
+func TestTrie_OverlapsPrefix(t *testing.T) {
+	newTrie := func(keys ...string) *Trie {
+		trie := New()
+		for _, key := range keys {
+			trie.Add(key, true)
+		}
+		return trie
+	}
+	tests := []struct {
+		name     string
+		a, b     *Trie
+		minDepth int
+		want     bool
+	}{
+		{"disjoint", newTrie("/a/1", "/a/2"), newTrie("/b/1"), 2, false},
+		{"disjoint-root", newTrie("/a/1"), newTrie("/b/1"), 1, true},
+		{"nested", newTrie("/a"), newTrie("/a/b/c"), 2, true},
+		{"nested-too-deep", newTrie("/a"), newTrie("/a/b/c"), 3, false},
+		{"partial", newTrie("/x/1", "/a/b/1"), newTrie("/a/b/2", "/y"), 4, true},
+		{"partial-too-deep", newTrie("/x/1", "/a/b/1"), newTrie("/a/b/2", "/y"), 6, false},
+		{"empty", New(), newTrie("/a"), 0, false},
+		{"nil", newTrie("/a"), nil, 0, false},
+	}
+	for _, tt := range tests {
+		if got := tt.a.OverlapsPrefix(tt.b, tt.minDepth); got != tt.want {
+			t.Errorf("%s: OverlapsPrefix(%d) = %v, want %v", tt.name, tt.minDepth, got, tt.want)
+		}
+		if tt.b != nil {
+			if got := tt.b.OverlapsPrefix(tt.a, tt.minDepth); got != tt.want {
+				t.Errorf("%s: reversed OverlapsPrefix(%d) = %v, want %v", tt.name, tt.minDepth, got, tt.want)
+			}
+		}
+	}
+	trie := newTrie("/a/b")
+	if !trie.OverlapsPrefix(trie, 4) || trie.OverlapsPrefix(trie, 5) {
+		t.Errorf("OverlapsPrefix() against itself is wrong")
+	}
+}
+
+func TestTrie_FindAllocs(t *testing.T) {
+	trie := newGNMITrie()
+	trie.Add("/ünïcode/key", true)
+	tests := []struct {
+		name string
+		fn   func()
+	}{
+		{"Find", func() { trie.Find("/interfaces/interface[name=1/2]/state") }},
+		{"FindMultibyte", func() { trie.Find("/ünïcode/key") }},
+		{"FindMiss", func() { trie.Find("/interfaces/none") }},
+		{"HasPrefix", func() { trie.HasPrefix("/interfaces/interface[name=1/") }},
+		{"FindLongestMatchingPrefix", func() { trie.FindLongestMatchingPrefix("/interfaces/interface[name=1/2]/config") }},
+	}
+	for _, tt := range tests {
+		if n := testing.AllocsPerRun(100, tt.fn); n != 0 {
+			t.Errorf("%s allocates %v times per run, want 0", tt.name, n)
+		}
+	}
+}
+
+func TestTrie_FindIntoAllocs(t *testing.T) {
+	trie := newGNMITrie()
+	keys := make([]string, 0, 32)
+	values := make([]interface{}, 0, 32)
+	tests := []struct {
+		name string
+		fn   func()
+	}{
+		{"FindByPrefixInto", func() { keys = trie.FindByPrefixInto("/interfaces/interface[name=1/2]", keys[:0]) }},
+		{"FindByPrefixValueInto", func() { values = trie.FindByPrefixValueInto("/interfaces/interface[name=1/2]", values[:0]) }},
+		{"FindMatchingPrefixInto", func() { keys = trie.FindMatchingPrefixInto("/interfaces/interface[name=1/2]/state", keys[:0]) }},
+	}
+	for _, tt := range tests {
+		if n := testing.AllocsPerRun(100, tt.fn); n != 0 {
+			t.Errorf("%s allocates %v times per run, want 0", tt.name, n)
+		}
+	}
+
+	prefix := "/interfaces/interface[name=1/3]"
+	got := trie.FindByPrefixInto(prefix, []string{"x"})
+	if want := append([]string{"x"}, sortedKeys(trie.FindByPrefix(prefix))...); !reflect.DeepEqual(append(got[:1], sortedKeys(got[1:])...), want) {
+		t.Errorf("FindByPrefixInto() = %v, want %v", got, want)
+	}
+	if got := trie.FindByPrefixValueInto(prefix, nil); len(got) != 6 {
+		t.Errorf("FindByPrefixValueInto() = %v, want 6 values", got)
+	}
+	matched, _ := trie.FindMatchingPrefix("/interfaces/interface[name=1/2]/state")
+	if got := trie.FindMatchingPrefixInto("/interfaces/interface[name=1/2]/state", nil); !reflect.DeepEqual(got, matched) {
+		t.Errorf("FindMatchingPrefixInto() = %v, want %v", got, matched)
+	}
+	if got := trie.FindByPrefixInto("/none", nil); got != nil {
+		t.Errorf("FindByPrefixInto() = %v, want nil", got)
+	}
+}
+
+func BenchmarkFindByPrefixAll(b *testing.B) {
+	trie := New()
+	for i := 0; i < 100000; i++ {
+		trie.Add(fmt.Sprintf("/sub/%d", i), i)
+	}
+	trie.Add("/other", nil)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = trie.FindByPrefixAll("/sub/")
+	}
+}
+
+func BenchmarkFindMatchingPrefixLarge(b *testing.B) {
+	trie := New()
+	for i := 0; i < 1000000; i++ {
+		trie.Add(strconv.Itoa(i), i)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = trie.FindMatchingPrefix("123456789")
+	}
+}
+
+func TestTrie_AddE(t *testing.T) {
+	legacy, trie := New(), New()
+	for i, key := range gnmiFixture {
+		legacy.Add(key, i)
+		if err := trie.AddE(key, i); err != nil {
+			t.Errorf("AddE(%q) error = %v", key, err)
+		}
+	}
+	if !reflect.DeepEqual(trie.All(), legacy.All()) || trie.Size() != legacy.Size() {
+		t.Errorf("AddE() = %v, want %v", trie.All(), legacy.All())
+	}
+	for i, key := range gnmiFixture[:8] {
+		v1 := legacy.Remove(key)
+		v2, err := trie.RemoveE(key)
+		if err != nil || v1 != v2 {
+			t.Errorf("RemoveE(%q) = %v, %v, want %v, nil", key, v2, err, v1)
+		}
+		if i == 0 {
+			if v, err := trie.RemoveE(key); v != nil || err != nil {
+				t.Errorf("RemoveE(%q) of a removed key = %v, %v", key, v, err)
+			}
+		}
+	}
+	if !reflect.DeepEqual(trie.All(), legacy.All()) || trie.Size() != legacy.Size() {
+		t.Errorf("RemoveE() = %v, want %v", trie.All(), legacy.All())
+	}
+}
+
+func TestTrie_AddEErrors(t *testing.T) {
+	errs := []error{ErrFrozen, ErrJournal, ErrKeyTooLong, ErrTrieFull, ErrTooManyChildren}
+	for i, err := range errs {
+		wrapped := fmt.Errorf("add: %w", err)
+		for j, target := range errs {
+			if got := errors.Is(wrapped, target); got != (i == j) {
+				t.Errorf("errors.Is(%v, %v) = %v", wrapped, target, got)
+			}
+		}
+	}
+	trie := New(WithMaxKeyLength(4), WithMaxKeys(1))
+	trie.Add("foo", 1)
+	for key, want := range map[string]error{"foobar": ErrKeyTooLong, "bar": ErrTrieFull} {
+		if err := trie.AddE(key, 1); !errors.Is(err, want) {
+			t.Errorf("AddE(%q) error = %v, want %v", key, err, want)
+		}
+	}
+}
+
+func TestTrie_FindLongestMatchingPrefixSplit(t *testing.T) {
+	trie := New()
+	for _, key := range []string{"苹果 沂水县", "苹果", "大蒜", "大豆", "/a", "/a/b"} {
+		trie.Add(key, key)
+	}
+	tests := []struct {
+		key, prefix, suffix string
+		ok                  bool
+	}{
+		{"苹果/红富士", "苹果", "/红富士", true},
+		{"苹果 沂水县/x", "苹果 沂水县", "/x", true},
+		{"大蒜", "大蒜", "", true},
+		{"/a/bc", "/a/b", "c", true},
+		{"/a/", "/a", "/", true},
+		{"大", "", "大", false},
+		{"", "", "", false},
+	}
+	for _, tt := range tests {
+		prefix, suffix, v, ok := trie.FindLongestMatchingPrefixSplit(tt.key)
+		if prefix != tt.prefix || suffix != tt.suffix || ok != tt.ok {
+			t.Errorf("FindLongestMatchingPrefixSplit(%q) = %q, %q, %v, want %q, %q, %v", tt.key, prefix, suffix, ok, tt.prefix, tt.suffix, tt.ok)
+		}
+		if prefix+suffix != tt.key {
+			t.Errorf("FindLongestMatchingPrefixSplit(%q) splits into %q + %q", tt.key, prefix, suffix)
+		}
+		if ok && v != prefix {
+			t.Errorf("FindLongestMatchingPrefixSplit(%q) value = %v, want %q", tt.key, v, prefix)
+		}
+	}
+
+	// the input prefix is split even if its canonical form differs in length.
+	folded := New(WithKeyTransform(FoldDiacritics()))
+	folded.Add("naive", 1)
+	if prefix, suffix, v, ok := folded.FindLongestMatchingPrefixSplit("naïve-bayes"); prefix != "naïve" || suffix != "-bayes" || v != 1 || !ok {
+		t.Errorf("FindLongestMatchingPrefixSplit() = %q, %q, %v, %v", prefix, suffix, v, ok)
+	}
+}
+
+func TestTrie_FindMatchingPrefixOrdered(t *testing.T) {
+	trie := newGNMITrie()
+	trie.Add("苹果", 1)
+	tests := []struct {
+		key  string
+		want []PrefixHit
+	}{
+		{"/interfaces/interface[name=1/2]/state/counters/in-octets", []PrefixHit{
+			{"/interfaces", true, 11},
+			{"/interfaces/interface", true, 21},
+			{"/interfaces/interface[name=1/2]", true, 31},
+			{"/interfaces/interface[name=1/2]/state", true, 37},
+			{"/interfaces/interface[name=1/2]/state/counters", true, 46},
+		}},
+		{"苹果/x", []PrefixHit{{"苹果", 1, 2}}},
+		{"/x", nil},
+	}
+	for _, tt := range tests {
+		if got := trie.FindMatchingPrefixOrdered(tt.key); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("FindMatchingPrefixOrdered(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+	if got := New().FindMatchingPrefixOrdered("/x"); got != nil {
+		t.Errorf("FindMatchingPrefixOrdered() of an empty trie = %v", got)
+	}
+}
+
+// checkMasks checks that the mask of every node under the node is
+// the bit of its own rune and the masks of its children.
+func checkMasks(t *testing.T, node *trieNode) {
+	t.Helper()
+	mask := uint64(1) << uint64(node.rval-'a')
+	for _, c := range node.children {
+		checkMasks(t, c)
+		mask |= c.mask
+	}
+	if node.mask != mask {
+		t.Errorf("mask of %q (depth %d) = %b, want %b", node.rval, node.depth, node.mask, mask)
+	}
+}
+
+func TestTrie_RemoveMasks(t *testing.T) {
+	keys := []string{"abc", "abd", "abxyz", "axq", "bcd", "bczzz", "mnop", "mnopqrs", "zz"}
+	for _, opts := range [][]Option{nil, {WithAtomicReads()}} {
+		trie := New(opts...)
+		for _, key := range keys {
+			trie.Add(key, nil)
+		}
+		checkMasks(t, trie.root)
+		for _, key := range []string{"abxyz", "bczzz", "mnopqrs", "abc", "zz", "axq"} {
+			trie.Remove(key)
+			checkMasks(t, trie.root)
+		}
+		// the pruning does not keep the runes removed.
+		if got := trie.FindByFuzzy("z"); len(got) != 0 {
+			t.Errorf("FindByFuzzy() = %v, want none", got)
+		}
+		if got := sortedKeys(trie.FindByFuzzy("d")); !reflect.DeepEqual(got, []string{"abd", "bcd"}) {
+			t.Errorf("FindByFuzzy() = %v", got)
+		}
+	}
+}
+
+func TestTrie_RemoveMasksRandom(t *testing.T) {
+	keys := gtrietest.GeneratePathKeys(300, 5, 4, 1)
+	rng := rand.New(rand.NewSource(1))
+	// prefix returns a random prefix of a random key, which may end
+	// in the middle of a path element.
+	prefix := func() string {
+		key := keys[rng.Intn(len(keys))]
+		return key[:1+rng.Intn(len(key))]
+	}
+	for _, opts := range [][]Option{nil, {WithAtomicReads()}, {WithWideMask(), WithFuzzyCounting()}} {
+		trie, model := New(opts...), gtrietest.NewModelTrie()
+		gtrietest.LoadKeys(trie, keys, nil)
+		gtrietest.LoadKeys(model, keys, nil)
+		for i, key := range keys {
+			trie.Remove(key)
+			model.Remove(key)
+			checkMasks(t, trie.root)
+			if i%50 == 0 {
+				src, dst := prefix(), prefix()+"x"
+				if _, err := trie.MovePrefix(src, dst); err == nil {
+					moveModelPrefix(model, src, dst)
+				}
+				checkMasks(t, trie.root)
+			}
+			if i%25 == 0 && !reflect.DeepEqual(sortedKeys(trie.Keys()), model.Keys()) {
+				t.Fatalf("%d options: Keys() after Remove(%q) = %d keys, want %d", len(opts), key, trie.Size(), model.Size())
+			}
+		}
+	}
+}
+
+// moveModelPrefix renames the prefix `src` of the keys of the model to `dst`
+// as MovePrefix succeeding.
+func moveModelPrefix(model *gtrietest.ModelTrie, src, dst string) {
+	moved := model.All(src)
+	for key := range moved {
+		model.Remove(key)
+	}
+	for key, value := range moved {
+		model.Add(dst+key[len(src):], value)
+	}
+}
+
+// BenchmarkRemoveWideRoot removes and adds back the keys of the root of
+// 10k children, whose masks are looked up only until the runes removed
+// are found in a sibling (258µs to 12µs per Remove and Add).
+func BenchmarkRemoveWideRoot(b *testing.B) {
+	keys := make([]string, 10000)
+	trie := New()
+	for i := range keys {
+		keys[i] = fmt.Sprintf("%c/port/%d", rune(0x4E00+i), i)
+		trie.Add(keys[i], nil)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		key := keys[i%len(keys)]
+		trie.Remove(key)
+		trie.Add(key, nil)
+	}
+}
+
+type closer struct {
+	key    string
+	closed int
+}
+
+func (c *closer) Close() error {
+	c.closed++
+	return nil
+}
+
+func TestTrie_Drain(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithAtomicReads()}, {WithInsertionOrder(), WithArena(8)}} {
+		trie := New(opts...)
+		closers := make(map[string]*closer)
+		for _, key := range gnmiFixture {
+			c := &closer{key: key}
+			closers[key] = c
+			trie.Add(key, c)
+		}
+		drained := trie.Drain()
+		for key, v := range drained {
+			v.(io.Closer).Close()
+			if v != closers[key] {
+				t.Errorf("Drain() returns %q with the value of %q", key, v.(*closer).key)
+			}
+		}
+		for key, c := range closers {
+			if c.closed != 1 {
+				t.Errorf("the value of %q is closed %d times", key, c.closed)
+			}
+		}
+		if trie.Size() != 0 || len(trie.Keys()) != 0 {
+			t.Errorf("Drain() leaves %d keys", trie.Size())
+		}
+		if got := trie.Drain(); len(got) != 0 {
+			t.Errorf("Drain() of an empty trie = %v", got)
+		}
+		trie.Add("/a", 1)
+		if got := trie.Drain(); !reflect.DeepEqual(got, map[string]interface{}{"/a": 1}) {
+			t.Errorf("Drain() after Add() = %v", got)
+		}
+	}
+}
+
+func TestTrie_ClearFunc(t *testing.T) {
+	trie := newGNMITrie()
+	var keys []string
+	trie.ClearFunc(func(key string, v interface{}) {
+		keys = append(keys, key)
+		// the trie is not locked and has the keys added here only.
+		if trie.Size() != len(keys)-1 {
+			t.Errorf("Size() in ClearFunc() = %d, want %d", trie.Size(), len(keys)-1)
+		}
+		trie.Add("/readded"+key, v)
+	})
+	if want := sortedKeys(newGNMITrie().Keys()); !reflect.DeepEqual(keys, want) {
+		t.Errorf("ClearFunc() calls with %v, want %v", keys, want)
+	}
+	if trie.Size() != len(keys) {
+		t.Errorf("Size() = %d, want %d", trie.Size(), len(keys))
+	}
+}